@@ -0,0 +1,196 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/runs-on/config/internal/gitrev"
+	"github.com/runs-on/config/pkg/validate"
+)
+
+func runBisect(args []string) int {
+	flags := flag.NewFlagSet("bisect", flag.ContinueOnError)
+	var (
+		path  = flags.String("path", ".github/runs-on.yml", "Config file path, relative to the repository root")
+		good  = flags.String("good", "", "Revision known not to have the violation (required)")
+		bad   = flags.String("bad", "HEAD", "Revision known to have the violation")
+		match = flags.String("match", "", "Regular expression a diagnostic message must match (default: any error)")
+		rule  = flags.String("rule", "", "Rule ID a diagnostic must have, e.g. pool-runner-undefined (default: any error)")
+		repo  = flags.String("repo", ".", "Directory inside the git repository")
+	)
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: runs-on-config bisect --good <rev> [flags]\n")
+		fmt.Fprintf(os.Stderr, "\nFinds the first commit that introduced a validation failure in the config file.\n")
+		fmt.Fprintf(os.Stderr, "\nFlags:\n")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if *good == "" {
+		fmt.Fprintf(os.Stderr, "Error: --good is required\n")
+		flags.Usage()
+		return 2
+	}
+
+	var pattern *regexp.Regexp
+	if *match != "" {
+		var err error
+		if pattern, err = regexp.Compile(*match); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: invalid --match expression: %v\n", err)
+			return 2
+		}
+	}
+
+	if *rule != "" {
+		if _, ok := validate.LookupRule(*rule); !ok {
+			fmt.Fprintf(os.Stderr, "Error: unknown rule %q (run 'runs-on-config explain' to list rules)\n", *rule)
+			return 2
+		}
+	}
+
+	ctx := context.Background()
+	r, err := gitrev.Open(ctx, *repo)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	b := &bisector{repo: r, path: *path, paths: []string{*path}, rule: *rule, pattern: pattern}
+	commit, diags, err := b.run(ctx, *good, *bad)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	fmt.Printf("First bad commit: %s %s\n\n", commit.Hash, commit.Subject)
+	for _, diag := range diags {
+		fmt.Printf("  %s: %s: %s\n", formatLocation(diag), diag.Severity, diag.Message)
+	}
+
+	hunk, err := r.Diff(ctx, commit.Hash, b.paths...)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	fmt.Printf("\n%s", hunk)
+	return 0
+}
+
+// bisector searches a linear commit range for the first config violation
+type bisector struct {
+	repo *gitrev.Repo
+	path string
+	// paths are the config and the local configs it extends at the
+	// revisions validated so far
+	paths   []string
+	rule    string
+	pattern *regexp.Regexp
+}
+
+func (b *bisector) run(ctx context.Context, good, bad string) (gitrev.Commit, []validate.Diagnostic, error) {
+	goodCommit, err := b.repo.ResolveCommit(ctx, good)
+	if err != nil {
+		return gitrev.Commit{}, nil, err
+	}
+	badCommit, err := b.repo.ResolveCommit(ctx, bad)
+	if err != nil {
+		return gitrev.Commit{}, nil, err
+	}
+
+	if diags, err := b.violations(ctx, goodCommit.Hash); err != nil {
+		return gitrev.Commit{}, nil, err
+	} else if len(diags) > 0 {
+		return gitrev.Commit{}, nil, fmt.Errorf("good revision %s already has the violation", good)
+	}
+	if diags, err := b.violations(ctx, badCommit.Hash); err != nil {
+		return gitrev.Commit{}, nil, err
+	} else if len(diags) == 0 {
+		return gitrev.Commit{}, nil, fmt.Errorf("bad revision %s does not have the violation", bad)
+	}
+
+	// Only commits touching the config or the files it extends at good or
+	// bad can change the verdict
+	commits, err := b.repo.CommitsBetween(ctx, goodCommit.Hash, badCommit.Hash, b.paths...)
+	if err != nil {
+		return gitrev.Commit{}, nil, err
+	}
+	if len(commits) == 0 {
+		return gitrev.Commit{}, nil, fmt.Errorf("no commits between %s and %s modify %s", good, bad, strings.Join(b.paths, ", "))
+	}
+
+	// The newest commit touching the files has the same content as bad, so
+	// the search always converges on a commit with the violation
+	lo, hi := 0, len(commits)-1
+	for lo < hi {
+		mid := (lo + hi) / 2
+		diags, err := b.violations(ctx, commits[mid].Hash)
+		if err != nil {
+			return gitrev.Commit{}, nil, err
+		}
+		if len(diags) > 0 {
+			hi = mid
+		} else {
+			lo = mid + 1
+		}
+	}
+
+	found, err := b.violations(ctx, commits[lo].Hash)
+	if err != nil {
+		return gitrev.Commit{}, nil, err
+	}
+	return commits[lo], found, nil
+}
+
+// violations returns the diagnostics of the config at rev that count as the
+// violation being searched for: those with the rule and a message matching
+// the pattern given, or errors if neither is. A missing file has no
+// violations. Local _extends references are read at rev too.
+func (b *bisector) violations(ctx context.Context, rev string) ([]validate.Diagnostic, error) {
+	data, err := b.repo.ReadFile(ctx, rev, b.path)
+	if err != nil {
+		if errors.Is(err, gitrev.ErrNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	diags, err := validate.ValidateBytesWithOptions(ctx, data, b.path, validate.Options{
+		ReadExtends: func(path string) ([]byte, error) {
+			path = filepath.ToSlash(path)
+			if !slices.Contains(b.paths, path) {
+				b.paths = append(b.paths, path)
+			}
+			return b.repo.ReadFile(ctx, rev, path)
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var matched []validate.Diagnostic
+	for _, diag := range diags {
+		switch {
+		case b.rule != "" && diag.RuleID != b.rule:
+		case b.pattern != nil && !b.pattern.MatchString(diag.Message):
+		case b.rule == "" && b.pattern == nil && diag.Severity != validate.SeverityError:
+		default:
+			matched = append(matched, diag)
+		}
+	}
+	return matched, nil
+}
+
+func formatLocation(diag validate.Diagnostic) string {
+	if diag.Line > 0 {
+		return fmt.Sprintf("%s:%d:%d", diag.Path, diag.Line, diag.Column)
+	}
+	return diag.Path
+}
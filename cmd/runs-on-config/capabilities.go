@@ -0,0 +1,69 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/runs-on/config/internal/capabilities"
+)
+
+func runCapabilities(args []string) int {
+	flags := flag.NewFlagSet("capabilities", flag.ContinueOnError)
+	format := flags.String("format", "text", "Output format: text or json")
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: runs-on-config capabilities [flags]\n")
+		fmt.Fprintf(os.Stderr, "\nLists the schema versions, rules, output formats and fixes supported by\n")
+		fmt.Fprintf(os.Stderr, "this binary. Use -format json to feature-detect from scripts.\n")
+		fmt.Fprintf(os.Stderr, "\nFlags:\n")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	c := capabilities.Get()
+	switch *format {
+	case "json":
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(c); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return 1
+		}
+	case "text":
+		printCapabilities(c)
+	default:
+		fmt.Fprintf(os.Stderr, "Error: invalid format %q (valid: text, json)\n", *format)
+		return 2
+	}
+	return 0
+}
+
+func printCapabilities(c capabilities.Capabilities) {
+	fmt.Printf("Version: %s\n", c.Version)
+	fmt.Printf("Go:      %s\n", c.Build.GoVersion)
+	if c.Build.Revision != "" {
+		fmt.Printf("Commit:  %s\n", c.Build.Revision)
+	}
+	fmt.Printf("Schema:  %s\n", c.Build.SchemaDigest)
+
+	fmt.Printf("\nSchema versions:\n")
+	for _, version := range c.SchemaVersions {
+		fmt.Printf("  %-8s %s\n", version.Name, version.Description)
+	}
+	fmt.Printf("  Schema files: %s\n", strings.Join(c.SchemaSources, ", "))
+
+	fmt.Printf("\nRules:\n")
+	for _, rule := range c.Rules {
+		fixable := ""
+		if rule.Fixable {
+			fixable = " (fixable)"
+		}
+		fmt.Printf("  %-28s %-8s %s%s\n", rule.ID, rule.Severity, rule.Summary, fixable)
+	}
+
+	fmt.Printf("\nOutput formats: %s\n", strings.Join(c.OutputFormats, ", "))
+}
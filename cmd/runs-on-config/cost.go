@@ -0,0 +1,92 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/runs-on/config/pkg/config"
+	"github.com/runs-on/config/pkg/cost"
+)
+
+func runCost(args []string) int {
+	flags := flag.NewFlagSet("cost", flag.ContinueOnError)
+	outputFormat := flags.String("format", "text", "Output format: text or json")
+	pricesPath := flags.String("prices", "", "JSON price table to use instead of the embedded snapshot")
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: runs-on-config cost [flags] <file>\n")
+		fmt.Fprintf(os.Stderr, "\nEstimates the monthly on-demand and spot cost of the pools in a config,\n")
+		fmt.Fprintf(os.Stderr, "per pool and per schedule window. Hot instances are billed for compute and\n")
+		fmt.Fprintf(os.Stderr, "storage, stopped instances for storage only.\n")
+		fmt.Fprintf(os.Stderr, "\nFlags:\n")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() != 1 {
+		fmt.Fprintf(os.Stderr, "Error: expected one file\n")
+		flags.Usage()
+		return 2
+	}
+	if *outputFormat != "text" && *outputFormat != "json" {
+		fmt.Fprintf(os.Stderr, "Error: invalid format %q (valid: text, json)\n", *outputFormat)
+		return 2
+	}
+
+	prices := cost.EmbeddedPrices()
+	if *pricesPath != "" {
+		var err error
+		if prices, err = cost.LoadPrices(*pricesPath); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return 1
+		}
+	}
+	cfg, err := config.Load(flags.Arg(0))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	report := cost.Estimate(cfg, prices)
+	if *outputFormat == "json" {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(report); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return 1
+		}
+		return 0
+	}
+	printCost(report)
+	return 0
+}
+
+func printCost(report cost.Report) {
+	fmt.Printf("Prices: %s, updated %s (%s, estimates)\n", report.Region, report.PricesUpdated, report.Currency)
+	if len(report.Pools) == 0 {
+		fmt.Printf("\nNo pools defined.\n")
+		return
+	}
+
+	for _, pool := range report.Pools {
+		fmt.Printf("\nPool %s (runner %s)\n", pool.Name, pool.Runner)
+		if pool.Error != "" {
+			fmt.Printf("  Not estimated: %s\n", pool.Error)
+			continue
+		}
+		fmt.Printf("  Instances: %s on-demand, %s spot; %dGB volume\n", pool.OnDemandInstance, pool.SpotInstance, pool.VolumeGB)
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintf(w, "  Window\tHours/month\tHot\tStopped\tOn-demand\tSpot\n")
+		for _, window := range pool.Windows {
+			fmt.Fprintf(w, "  %s\t%.0f\t%d\t%d\t%.2f\t%.2f\n",
+				window.Name, window.HoursPerMonth, window.Hot, window.Stopped, window.OnDemand, window.Spot)
+		}
+		fmt.Fprintf(w, "  Total\t\t\t\t%.2f\t%.2f\n", pool.OnDemand, pool.Spot)
+		w.Flush()
+	}
+
+	fmt.Printf("\nTotal per month: %.2f %s on-demand, %.2f %s spot\n", report.OnDemand, report.Currency, report.Spot, report.Currency)
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/runs-on/config/pkg/config"
+)
+
+func runDiff(args []string) int {
+	flags := flag.NewFlagSet("diff", flag.ContinueOnError)
+	var (
+		format   = flags.String("format", "text", "Output format: text, json, json-patch (RFC 6902) or merge-patch (RFC 7386)")
+		exitCode = flags.Bool("exit-code", false, "Exit with status 1 when the configs differ")
+	)
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: runs-on-config diff [flags] <old.yml> <new.yml>\n")
+		fmt.Fprintf(os.Stderr, "\nCompares two configs after anchor expansion and normalization.\n")
+		fmt.Fprintf(os.Stderr, "\nFlags:\n")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() != 2 {
+		fmt.Fprintf(os.Stderr, "Error: expected two files\n")
+		flags.Usage()
+		return 2
+	}
+
+	oldConfig, err := loadNormalized(flags.Arg(0))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	newConfig, err := loadNormalized(flags.Arg(1))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	changes := config.DiffData(oldConfig, newConfig)
+
+	var output any
+	switch *format {
+	case "text":
+		outputDiffText(changes)
+	case "json":
+		if changes == nil {
+			changes = []config.Change{}
+		}
+		output = changes
+	case "json-patch":
+		ops := jsonPatch("", comparedFields(oldConfig), comparedFields(newConfig))
+		if ops == nil {
+			ops = []patchOperation{}
+		}
+		output = ops
+	case "merge-patch":
+		output = mergePatch(comparedFields(oldConfig), comparedFields(newConfig))
+	default:
+		fmt.Fprintf(os.Stderr, "Error: invalid format %q (valid: text, json, json-patch, merge-patch)\n", *format)
+		return 2
+	}
+	if output != nil {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(output); err != nil {
+			fmt.Fprintf(os.Stderr, "Error encoding JSON: %v\n", err)
+			return 1
+		}
+	}
+
+	if *exitCode && len(changes) > 0 {
+		return 1
+	}
+	return 0
+}
+
+func outputDiffText(changes []config.Change) {
+	if len(changes) == 0 {
+		fmt.Println("No semantic differences")
+		return
+	}
+
+	var section, name string
+	for _, change := range changes {
+		if change.Section != section {
+			fmt.Printf("%s:\n", change.Section)
+			section, name = change.Section, ""
+		}
+
+		indent := "  "
+		if change.Name != "" && change.Path != "" {
+			if change.Name != name {
+				fmt.Printf("  ~ %s\n", change.Name)
+				name = change.Name
+			}
+			indent = "      "
+		}
+
+		label := change.Path
+		if label == "" {
+			label = change.Name
+		}
+		switch change.Kind {
+		case config.ChangeAdded:
+			fmt.Printf("%s+ %s\n", indent, describe(label, change.New, change.Name == "" || change.Path != ""))
+		case config.ChangeRemoved:
+			fmt.Printf("%s- %s\n", indent, describe(label, change.Old, change.Name == "" || change.Path != ""))
+		case config.ChangeChanged:
+			if label == "" {
+				fmt.Printf("%s~ %s -> %s\n", indent, formatValue(change.Old), formatValue(change.New))
+			} else {
+				fmt.Printf("%s~ %s: %s -> %s\n", indent, label, formatValue(change.Old), formatValue(change.New))
+			}
+		}
+	}
+}
+
+// describe renders a label with its value, or just the value for unnamed
+// set members such as admins
+func describe(label string, value any, withValue bool) string {
+	switch {
+	case !withValue:
+		return label
+	case label == "":
+		return fmt.Sprintf("%v", value)
+	default:
+		return label + ": " + formatValue(value)
+	}
+}
+
+func formatValue(value any) string {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	return strings.TrimSpace(string(data))
+}
@@ -0,0 +1,303 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"html"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/runs-on/config/pkg/catalog"
+)
+
+// docSection is a titled part of a generated document
+type docSection struct {
+	title string
+	text  []string
+	table *docTable
+}
+
+// docTable is a simple table rendered as Markdown or HTML
+type docTable struct {
+	headers []string
+	rows    [][]string
+}
+
+func runDocs(args []string) int {
+	flags := flag.NewFlagSet("docs", flag.ContinueOnError)
+	var (
+		format = flags.String("format", "markdown", "Output format: markdown or html")
+		title  = flags.String("title", "RunsOn configuration", "Document title")
+	)
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: runs-on-config docs [flags] <file>\n")
+		fmt.Fprintf(os.Stderr, "\nGenerates human-readable documentation of the runners, pools, images and admins in a config.\n")
+		fmt.Fprintf(os.Stderr, "\nFlags:\n")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() != 1 {
+		fmt.Fprintf(os.Stderr, "Error: expected one file\n")
+		flags.Usage()
+		return 2
+	}
+
+	doc, err := loadNormalized(flags.Arg(0))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	sections := configSections(doc)
+
+	switch *format {
+	case "markdown", "md":
+		renderMarkdown(os.Stdout, *title, sections)
+	case "html":
+		renderHTML(os.Stdout, *title, sections)
+	default:
+		fmt.Fprintf(os.Stderr, "Error: invalid format %q (valid: markdown, html)\n", *format)
+		return 2
+	}
+	return 0
+}
+
+// configSections describes a normalized config as document sections
+func configSections(doc map[string]any) []docSection {
+	var sections []docSection
+
+	if ref, ok := doc["_extends"].(string); ok && ref != "" {
+		sections = append(sections, docSection{
+			title: "Extends",
+			text:  []string{fmt.Sprintf("This configuration extends `%s`.", ref)},
+		})
+	}
+
+	if runners := sortedEntries(doc["runners"]); len(runners) > 0 {
+		table := &docTable{headers: []string{"Runner", "CPU", "RAM (GB)", "Family", "Image", "Spot", "Options", "Label"}}
+		for _, entry := range runners {
+			var options []string
+			for _, key := range []string{"ssh", "private", "nested-virt", "debug"} {
+				if value, ok := entry.fields[key]; ok {
+					options = append(options, fmt.Sprintf("%s=%v", key, value))
+				}
+			}
+			for _, key := range []string{"volume", "retry", "extras", "tags"} {
+				if value, ok := entry.fields[key]; ok {
+					options = append(options, fmt.Sprintf("%s=%s", key, joinValue(value)))
+				}
+			}
+			table.rows = append(table.rows, []string{
+				entry.name,
+				joinValue(entry.fields["cpu"]),
+				joinValue(entry.fields["ram"]),
+				familyCell(entry.fields["family"]),
+				joinValue(entry.fields["image"]),
+				joinValue(entry.fields["spot"]),
+				strings.Join(options, ", "),
+				fmt.Sprintf("`runs-on=${{ github.run_id }}/runner=%s`", entry.name),
+			})
+		}
+		sections = append(sections, docSection{title: "Runners", table: table})
+	}
+
+	for _, entry := range sortedEntries(doc["pools"]) {
+		var text []string
+		text = append(text, fmt.Sprintf("Runner: `%s`", joinValue(entry.fields["runner"])))
+		env := entry.fields["env"]
+		if env == nil {
+			env = entry.fields["environment"]
+		}
+		if env != nil {
+			text = append(text, fmt.Sprintf("Environment: %s", joinValue(env)))
+		}
+		if tz, ok := entry.fields["timezone"]; ok {
+			text = append(text, fmt.Sprintf("Timezone: %s", joinValue(tz)))
+		}
+
+		section := docSection{title: "Pool " + entry.name, text: text}
+		if schedule, ok := entry.fields["schedule"].([]any); ok && len(schedule) > 0 {
+			table := &docTable{headers: []string{"Schedule", "Hot", "Stopped", "Days", "Time"}}
+			for _, item := range schedule {
+				s, ok := item.(map[string]any)
+				if !ok {
+					continue
+				}
+				match, _ := s["match"].(map[string]any)
+				table.rows = append(table.rows, []string{
+					joinValue(s["name"]),
+					joinValue(s["hot"]),
+					joinValue(s["stopped"]),
+					joinValue(match["day"]),
+					joinValue(match["time"]),
+				})
+			}
+			section.table = table
+		}
+		sections = append(sections, section)
+	}
+
+	if images := sortedEntries(doc["images"]); len(images) > 0 {
+		table := &docTable{headers: []string{"Image", "AMI", "Platform", "Arch", "Name", "Owner"}}
+		for _, entry := range images {
+			table.rows = append(table.rows, []string{
+				entry.name,
+				joinValue(entry.fields["ami"]),
+				joinValue(entry.fields["platform"]),
+				joinValue(entry.fields["arch"]),
+				joinValue(entry.fields["name"]),
+				joinValue(entry.fields["owner"]),
+			})
+		}
+		sections = append(sections, docSection{title: "Images", table: table})
+	}
+
+	if admins, ok := doc["admins"].([]any); ok && len(admins) > 0 {
+		var text []string
+		for _, admin := range admins {
+			text = append(text, fmt.Sprintf("- @%v", admin))
+		}
+		sections = append(sections, docSection{title: "Admins", text: text})
+	}
+
+	return sections
+}
+
+// namedEntry is a runner, pool or image with its fields
+type namedEntry struct {
+	name   string
+	fields map[string]any
+}
+
+func sortedEntries(section any) []namedEntry {
+	entries, ok := section.(map[string]any)
+	if !ok {
+		return nil
+	}
+	var result []namedEntry
+	for name, value := range entries {
+		fields, _ := value.(map[string]any)
+		result = append(result, namedEntry{name: name, fields: fields})
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].name < result[j].name })
+	return result
+}
+
+// familyCell renders a family field, showing what wildcards expand to
+func familyCell(value any) string {
+	items, ok := value.([]any)
+	if !ok {
+		return joinValue(value)
+	}
+	parts := make([]string, len(items))
+	for i, item := range items {
+		parts[i] = fmt.Sprintf("%v", item)
+		pattern, ok := item.(string)
+		if !ok || !catalog.IsPattern(pattern) {
+			continue
+		}
+		if names, err := catalog.Expand(pattern); err == nil && len(names) > 0 {
+			parts[i] = fmt.Sprintf("%s (%s)", pattern, strings.Join(names, ", "))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// joinValue renders a scalar or list field for display
+func joinValue(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case []any:
+		parts := make([]string, len(v))
+		for i, item := range v {
+			parts[i] = fmt.Sprintf("%v", item)
+		}
+		return strings.Join(parts, ", ")
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
+
+func renderMarkdown(w io.Writer, title string, sections []docSection) {
+	fmt.Fprintf(w, "# %s\n", title)
+	for _, section := range sections {
+		fmt.Fprintf(w, "\n## %s\n\n", section.title)
+		for _, line := range section.text {
+			fmt.Fprintf(w, "%s\n", line)
+		}
+		if section.table != nil {
+			if len(section.text) > 0 {
+				fmt.Fprintln(w)
+			}
+			writeMarkdownTable(w, section.table)
+		}
+	}
+}
+
+func writeMarkdownTable(w io.Writer, table *docTable) {
+	escape := func(cell string) string {
+		return strings.ReplaceAll(cell, "|", "\\|")
+	}
+	fmt.Fprintf(w, "| %s |\n", strings.Join(table.headers, " | "))
+	separators := make([]string, len(table.headers))
+	for i := range separators {
+		separators[i] = "---"
+	}
+	fmt.Fprintf(w, "| %s |\n", strings.Join(separators, " | "))
+	for _, row := range table.rows {
+		cells := make([]string, len(row))
+		for i, cell := range row {
+			cells[i] = escape(cell)
+		}
+		fmt.Fprintf(w, "| %s |\n", strings.Join(cells, " | "))
+	}
+}
+
+func renderHTML(w io.Writer, title string, sections []docSection) {
+	fmt.Fprintf(w, "<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n<title>%s</title>\n</head>\n<body>\n", html.EscapeString(title))
+	fmt.Fprintf(w, "<h1>%s</h1>\n", html.EscapeString(title))
+	for _, section := range sections {
+		fmt.Fprintf(w, "<h2>%s</h2>\n", html.EscapeString(section.title))
+		for _, line := range section.text {
+			fmt.Fprintf(w, "<p>%s</p>\n", markdownCode(strings.TrimPrefix(line, "- ")))
+		}
+		if section.table != nil {
+			fmt.Fprintf(w, "<table>\n<tr>")
+			for _, header := range section.table.headers {
+				fmt.Fprintf(w, "<th>%s</th>", html.EscapeString(header))
+			}
+			fmt.Fprintf(w, "</tr>\n")
+			for _, row := range section.table.rows {
+				fmt.Fprintf(w, "<tr>")
+				for _, cell := range row {
+					fmt.Fprintf(w, "<td>%s</td>", markdownCode(cell))
+				}
+				fmt.Fprintf(w, "</tr>\n")
+			}
+			fmt.Fprintf(w, "</table>\n")
+		}
+	}
+	fmt.Fprintf(w, "</body>\n</html>\n")
+}
+
+// markdownCode escapes text for HTML and turns `code` spans into <code>
+func markdownCode(text string) string {
+	parts := strings.Split(text, "`")
+	var b strings.Builder
+	for i, part := range parts {
+		escaped := html.EscapeString(part)
+		if i%2 == 1 && i < len(parts)-1 {
+			b.WriteString("<code>" + escaped + "</code>")
+		} else {
+			if i%2 == 1 {
+				b.WriteString("`")
+			}
+			b.WriteString(escaped)
+		}
+	}
+	return b.String()
+}
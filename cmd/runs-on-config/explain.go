@@ -0,0 +1,60 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/runs-on/config/pkg/validate"
+)
+
+func runExplain(args []string) int {
+	flags := flag.NewFlagSet("explain", flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: runs-on-config explain [rule-id]\n")
+		fmt.Fprintf(os.Stderr, "\nDescribes a validation rule. Lists all rules when no ID is given.\n")
+	}
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	if flags.NArg() == 0 {
+		for _, rule := range validate.Rules() {
+			fmt.Printf("%-24s %-8s %s\n", rule.ID, rule.Severity, rule.Summary)
+		}
+		return 0
+	}
+
+	rule, ok := validate.LookupRule(flags.Arg(0))
+	if !ok {
+		fmt.Fprintf(os.Stderr, "Error: unknown rule %q (run 'runs-on-config explain' to list rules)\n", flags.Arg(0))
+		return 1
+	}
+
+	if rule.Group != "" {
+		fmt.Printf("%s (%s, %s)\n\n", rule.ID, rule.Severity, rule.Group)
+	} else {
+		fmt.Printf("%s (%s)\n\n", rule.ID, rule.Severity)
+	}
+	fmt.Printf("%s\n\n", rule.Summary)
+	fmt.Printf("%s\n", rule.Description)
+	if rule.BadExample != "" {
+		fmt.Printf("\nBad:\n\n%s\n", indentBlock(rule.BadExample))
+	}
+	if rule.GoodExample != "" {
+		fmt.Printf("\nGood:\n\n%s\n", indentBlock(rule.GoodExample))
+	}
+	if rule.DocURL != "" {
+		fmt.Printf("\nDocumentation: %s\n", rule.DocURL)
+	}
+	return 0
+}
+
+func indentBlock(text string) string {
+	lines := strings.Split(strings.TrimRight(text, "\n"), "\n")
+	for i, line := range lines {
+		lines[i] = "    " + line
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,434 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/runs-on/config/pkg/catalog"
+	"github.com/runs-on/config/pkg/config"
+	"github.com/runs-on/config/pkg/extends"
+	"github.com/runs-on/config/pkg/validate"
+	"gopkg.in/yaml.v3"
+)
+
+// runnerFieldOrder is the order runner fields are listed in, following the
+// documentation. Unknown fields are listed after these, sorted by name.
+var runnerFieldOrder = []string{
+	"id", "cpu", "ram", "family", "image", "spot", "ssh", "nested-virt", "private",
+	"volume", "disk", "retry", "extras", "tags", "debug", "preinstall", "prerun",
+}
+
+// bootSteps are preinstall commands that typically add noticeable time to
+// instance boot, with a rough estimate of the time they add
+var bootSteps = []struct {
+	command     string
+	description string
+	seconds     int
+}{
+	{"apt-get update", "package index update", 10},
+	{"apt update", "package index update", 10},
+	{"apt-get install", "package install", 30},
+	{"apt install", "package install", 30},
+	{"yum install", "package install", 30},
+	{"dnf install", "package install", 30},
+	{"docker pull", "container image pull", 30},
+	{"pip install", "Python package install", 20},
+	{"npm install", "npm package install", 20},
+	{"curl ", "download", 5},
+	{"wget ", "download", 5},
+}
+
+// fieldSource records where an effective field was set
+type fieldSource struct {
+	path string
+	line int
+	// anchor is the anchor the field was merged from with <<, if any
+	anchor string
+}
+
+func (s fieldSource) String() string {
+	if s.path == "" {
+		return ""
+	}
+	location := fmt.Sprintf("%s:%d", s.path, s.line)
+	if s.anchor != "" {
+		location += fmt.Sprintf(" (from &%s)", s.anchor)
+	}
+	return location
+}
+
+func runExplainRunner(args []string) int {
+	flags := flag.NewFlagSet("explain-runner", flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: runs-on-config explain-runner <file> <runner>\n")
+		fmt.Fprintf(os.Stderr, "\nPrints everything resolved for one runner: effective fields and where they are set, instance candidates, image resolution, preinstall boot additions and applicable warnings.\n")
+	}
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() != 2 {
+		fmt.Fprintf(os.Stderr, "Error: expected a file and a runner name\n")
+		flags.Usage()
+		return 2
+	}
+	path, name := flags.Arg(0), flags.Arg(1)
+
+	doc, err := extends.Load(path, extends.Options{})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	config.Normalize(doc.Data)
+
+	runners, _ := doc.Data["runners"].(map[string]any)
+	runner, ok := runners[name].(map[string]any)
+	if !ok {
+		var names []string
+		for key := range runners {
+			names = append(names, key)
+		}
+		sort.Strings(names)
+		fmt.Fprintf(os.Stderr, "Error: runner %q is not defined in %s (runners: %s)\n", name, path, strings.Join(names, ", "))
+		return 1
+	}
+
+	definedIn, runnerKey, runnerNode, err := findEntry(doc.Sources, "runners", name)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	sources := fieldSources(definedIn, runnerNode)
+
+	fmt.Printf("Runner %s\n", name)
+	fmt.Printf("  Defined in: %s:%d", definedIn, runnerKey.Line)
+	if definedIn != path {
+		fmt.Printf(" (through _extends)")
+	}
+	fmt.Println()
+	fmt.Printf("  Label:      runs-on=${{ github.run_id }}/runner=%s\n", name)
+
+	fmt.Printf("\nFields:\n")
+	for _, key := range sortedRunnerFields(runner) {
+		value := joinValue(runner[key])
+		if key == "preinstall" || key == "prerun" {
+			value = fmt.Sprintf("(%d lines)", len(scriptLines(runner[key])))
+		}
+		fmt.Printf("  %-12s %-28s %s\n", key, value, sources[key])
+	}
+
+	image, imageArch := explainImage(doc.Data, doc.Sources, runner)
+	explainCandidates(runner, imageArch)
+	explainBoot(runner, image)
+
+	if err := explainWarnings(definedIn, name, runnerKey, runnerNode, sources); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// explainImage prints how the runner's image is resolved and returns the
+// image spec if it is defined in the config, along with the image
+// architecture when known
+func explainImage(doc map[string]any, sources []string, runner map[string]any) (map[string]any, string) {
+	fmt.Printf("\nImage:\n")
+	ref, _ := runner["image"].(string)
+	if ref == "" {
+		fmt.Printf("  Not set; RunsOn uses its default image\n")
+		return nil, ""
+	}
+
+	images, _ := doc["images"].(map[string]any)
+	image, ok := images[ref].(map[string]any)
+	if !ok {
+		if catalog.IsImage(ref) {
+			fmt.Printf("  %s is not defined in images; it is resolved by RunsOn as a built-in image\n", ref)
+		} else {
+			fmt.Printf("  %s is neither defined in images nor a known built-in image\n", ref)
+		}
+		switch {
+		case strings.HasSuffix(ref, "-arm64"):
+			return nil, "arm64"
+		case strings.HasSuffix(ref, "-x64"):
+			return nil, "x64"
+		}
+		return nil, ""
+	}
+
+	if path, key, _, err := findEntry(sources, "images", ref); err == nil {
+		fmt.Printf("  %s is defined in images at %s:%d\n", ref, path, key.Line)
+	}
+	if ami, ok := image["ami"]; ok {
+		fmt.Printf("  Uses AMI %v\n", ami)
+	} else {
+		var criteria []string
+		for _, key := range []string{"name", "owner", "platform", "arch"} {
+			if value, ok := image[key]; ok {
+				criteria = append(criteria, fmt.Sprintf("%s=%v", key, value))
+			}
+		}
+		fmt.Printf("  Uses the most recent AMI matching %s\n", strings.Join(criteria, ", "))
+	}
+	arch, _ := image["arch"].(string)
+	switch arch {
+	case "amd64", "x86_64":
+		arch = "x64"
+	case "aarch64":
+		arch = "arm64"
+	}
+	return image, arch
+}
+
+// explainCandidates prints the instance families the runner can launch
+func explainCandidates(runner map[string]any, arch string) {
+	fmt.Printf("\nInstance candidates:\n")
+	if cpu, ok := runner["cpu"]; ok {
+		fmt.Printf("  CPU:      %s\n", joinValue(cpu))
+	}
+	if ram, ok := runner["ram"]; ok {
+		fmt.Printf("  RAM (GB): %s\n", joinValue(ram))
+	}
+
+	families, _ := runner["family"].([]any)
+	if len(families) == 0 {
+		fmt.Printf("  Family not set; RunsOn picks any family matching cpu and ram\n")
+		return
+	}
+	for _, item := range families {
+		pattern := fmt.Sprintf("%v", item)
+		names, err := catalog.Expand(pattern)
+		switch {
+		case err != nil:
+			fmt.Printf("  %-12s invalid: %v\n", pattern, err)
+			continue
+		case len(names) == 0:
+			fmt.Printf("  %-12s not in the instance catalog; passed to RunsOn as is\n", pattern)
+			continue
+		}
+
+		var mismatched []string
+		for _, name := range names {
+			if family, ok := catalog.Lookup(name); ok && arch != "" && family.Arch() != arch {
+				mismatched = append(mismatched, name)
+			}
+		}
+		fmt.Printf("  %-12s %s\n", pattern, strings.Join(names, ", "))
+		if len(mismatched) > 0 {
+			fmt.Printf("  %-12s not %s like the image: %s\n", "", arch, strings.Join(mismatched, ", "))
+		}
+	}
+}
+
+// explainBoot prints the preinstall scripts run on every boot and a rough
+// estimate of the time their slow steps add
+func explainBoot(runner, image map[string]any) {
+	fmt.Printf("\nBoot additions:\n")
+	scripts := []struct {
+		label  string
+		script any
+	}{
+		{"image preinstall", image["preinstall"]},
+		{"runner preinstall", runner["preinstall"]},
+	}
+
+	total, found := 0, false
+	for _, s := range scripts {
+		lines := scriptLines(s.script)
+		if len(lines) == 0 {
+			continue
+		}
+		found = true
+		fmt.Printf("  %s: %d lines\n", s.label, len(lines))
+		for _, line := range lines {
+			for _, step := range bootSteps {
+				if strings.Contains(line, step.command) {
+					fmt.Printf("    %s (%s, ~%ds)\n", strings.TrimSpace(line), step.description, step.seconds)
+					total += step.seconds
+					break
+				}
+			}
+		}
+	}
+	if !found {
+		fmt.Printf("  None\n")
+		return
+	}
+	fmt.Printf("  Estimated boot addition: ~%ds (rough, depends on network and package sizes)\n", total)
+}
+
+// explainWarnings validates the file defining the runner and prints the
+// diagnostics that apply to it
+func explainWarnings(path, name string, key, node *yaml.Node, sources map[string]fieldSource) error {
+	diags, err := validate.ValidateFile(context.Background(), path)
+	if err != nil {
+		return err
+	}
+
+	// Diagnostics apply if they point into the runner, at a field merged
+	// into it, or name the runner
+	lines := map[int]bool{}
+	for line := key.Line; line <= lastLine(node); line++ {
+		lines[line] = true
+	}
+	for _, source := range sources {
+		if source.path == path {
+			lines[source.line] = true
+		}
+	}
+	mentions := []string{"runners." + name + ".", "runners." + name + ":", "runner '" + name + "'"}
+
+	fmt.Printf("\nDiagnostics:\n")
+	found := false
+	for _, diag := range diags {
+		applies := diag.Line > 0 && lines[diag.Line]
+		for _, mention := range mentions {
+			applies = applies || strings.Contains(diag.Message, mention)
+		}
+		if !applies {
+			continue
+		}
+		found = true
+		fmt.Printf("  %s: %s: %s [%s]\n", formatLocation(diag), diag.Severity, diag.Message, diag.RuleID)
+	}
+	if !found {
+		fmt.Printf("  None\n")
+	}
+	return nil
+}
+
+// findEntry returns the first of the source files defining an entry of a
+// section, with the entry's key and value nodes. Entries of an extending file
+// replace those of the extended file, so this is the definition in effect.
+func findEntry(sources []string, section, name string) (string, *yaml.Node, *yaml.Node, error) {
+	for _, source := range sources {
+		data, err := os.ReadFile(source)
+		if err != nil {
+			return "", nil, nil, fmt.Errorf("failed to read %s: %w", source, err)
+		}
+		var doc yaml.Node
+		if err := yaml.Unmarshal(data, &doc); err != nil {
+			return "", nil, nil, fmt.Errorf("%s: YAML parse error: %w", source, err)
+		}
+		if len(doc.Content) == 0 {
+			continue
+		}
+		_, entries := mappingEntry(doc.Content[0], section)
+		if key, value := mappingEntry(resolveAlias(entries), name); key != nil {
+			return source, key, resolveAlias(value), nil
+		}
+	}
+	return "", nil, nil, fmt.Errorf("%s %q not found in %s", section, name, strings.Join(sources, ", "))
+}
+
+// fieldSources returns where each field of a mapping node is set. Fields set
+// directly take precedence over fields merged with <<, and earlier merged
+// mappings over later ones, as in YAML merge keys.
+func fieldSources(path string, node *yaml.Node) map[string]fieldSource {
+	sources := map[string]fieldSource{}
+	if node == nil || node.Kind != yaml.MappingNode {
+		return sources
+	}
+
+	var merges []*yaml.Node
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+		if key.Value == "<<" {
+			if value.Kind == yaml.SequenceNode {
+				merges = append(merges, value.Content...)
+			} else {
+				merges = append(merges, value)
+			}
+			continue
+		}
+		sources[key.Value] = fieldSource{path: path, line: key.Line}
+	}
+
+	for _, merge := range merges {
+		anchor := ""
+		if merge.Kind == yaml.AliasNode && merge.Alias != nil {
+			anchor = merge.Alias.Anchor
+		}
+		for key, source := range fieldSources(path, resolveAlias(merge)) {
+			if _, ok := sources[key]; ok {
+				continue
+			}
+			if source.anchor == "" {
+				source.anchor = anchor
+			}
+			sources[key] = source
+		}
+	}
+	return sources
+}
+
+// sortedRunnerFields returns the fields of a runner in documentation order
+func sortedRunnerFields(runner map[string]any) []string {
+	var known, other []string
+	for _, key := range runnerFieldOrder {
+		if _, ok := runner[key]; ok {
+			known = append(known, key)
+		}
+	}
+	for key := range runner {
+		if !slices.Contains(runnerFieldOrder, key) {
+			other = append(other, key)
+		}
+	}
+	sort.Strings(other)
+	return append(known, other...)
+}
+
+// scriptLines returns the non-empty, non-comment lines of a script field
+func scriptLines(value any) []string {
+	script, _ := value.(string)
+	var lines []string
+	for _, line := range strings.Split(script, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		lines = append(lines, trimmed)
+	}
+	return lines
+}
+
+// mappingEntry returns the key and value nodes for key in a mapping node
+func mappingEntry(n *yaml.Node, key string) (*yaml.Node, *yaml.Node) {
+	if n == nil || n.Kind != yaml.MappingNode {
+		return nil, nil
+	}
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if n.Content[i].Value == key {
+			return n.Content[i], n.Content[i+1]
+		}
+	}
+	return nil, nil
+}
+
+func resolveAlias(n *yaml.Node) *yaml.Node {
+	for n != nil && n.Kind == yaml.AliasNode {
+		n = n.Alias
+	}
+	return n
+}
+
+// lastLine returns the last line spanned by a node, not following aliases
+func lastLine(n *yaml.Node) int {
+	if n == nil {
+		return 0
+	}
+	last := n.Line + strings.Count(strings.TrimRight(n.Value, "\n"), "\n")
+	if n.Style&(yaml.LiteralStyle|yaml.FoldedStyle) != 0 {
+		// Block scalar content starts on the line after the indicator
+		last++
+	}
+	for _, child := range n.Content {
+		last = max(last, lastLine(child))
+	}
+	return last
+}
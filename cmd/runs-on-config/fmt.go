@@ -0,0 +1,100 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/runs-on/config/pkg/format"
+)
+
+func runFmt(args []string) int {
+	flags := flag.NewFlagSet("fmt", flag.ContinueOnError)
+	var (
+		write    = flags.Bool("w", false, "Write result to the source file instead of stdout")
+		check    = flags.Bool("check", false, "Exit with status 1 and list files that are not formatted")
+		filename = flags.String("filename", "", "Path to report for stdin input, e.g. .github/runs-on.yml")
+	)
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: runs-on-config fmt [flags] [files...]\n")
+		fmt.Fprintf(os.Stderr, "\nReads from stdin when no file is given.\n")
+		fmt.Fprintf(os.Stderr, "\nFlags:\n")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	if flags.NArg() == 0 {
+		if *write {
+			fmt.Fprintf(os.Stderr, "Error: cannot use -w with stdin\n")
+			return 2
+		}
+		src, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: failed to read stdin: %v\n", err)
+			return 1
+		}
+		path := "<stdin>"
+		if *filename != "" {
+			path = *filename
+		}
+		return fmtSource(path, src, *check, false)
+	}
+	if *filename != "" {
+		fmt.Fprintf(os.Stderr, "Error: -filename can only be used with stdin\n")
+		return 2
+	}
+
+	exitCode := 0
+	for _, path := range flags.Args() {
+		src, err := os.ReadFile(path)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			exitCode = 1
+			continue
+		}
+		if code := fmtSource(path, src, *check, *write); code > exitCode {
+			exitCode = code
+		}
+	}
+	return exitCode
+}
+
+// fmtSource formats a single source and reports, writes or prints the result
+func fmtSource(path string, src []byte, check, write bool) int {
+	formatted, err := format.Format(src)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s: %v\n", path, err)
+		return 1
+	}
+
+	changed := !bytes.Equal(src, formatted)
+	switch {
+	case check:
+		if changed {
+			fmt.Println(path)
+			return 1
+		}
+	case write:
+		if changed {
+			info, err := os.Stat(path)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				return 1
+			}
+			if err := os.WriteFile(path, formatted, info.Mode().Perm()); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				return 1
+			}
+		}
+	default:
+		if _, err := os.Stdout.Write(formatted); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return 1
+		}
+	}
+	return 0
+}
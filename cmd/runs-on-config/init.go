@@ -0,0 +1,244 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/runs-on/config/pkg/format"
+	"github.com/runs-on/config/pkg/validate"
+	"gopkg.in/yaml.v3"
+)
+
+// initOptions holds the values used to scaffold a starter config
+type initOptions struct {
+	runner  string
+	cpu     int
+	ram     int
+	family  string
+	image   string
+	pool    string
+	hot     int
+	stopped int
+	admins  string
+}
+
+func runInit(args []string) int {
+	flags := flag.NewFlagSet("init", flag.ContinueOnError)
+	var opts initOptions
+	flags.StringVar(&opts.runner, "runner", "default", "Runner name")
+	flags.IntVar(&opts.cpu, "cpu", 2, "Runner CPU count")
+	flags.IntVar(&opts.ram, "ram", 8, "Runner RAM in GB")
+	flags.StringVar(&opts.family, "family", "m7a+c7a", "Instance families, separated by '+'")
+	flags.StringVar(&opts.image, "image", "ubuntu24-full-x64", "Runner image")
+	flags.StringVar(&opts.pool, "pool", "", "Pool name (no pool when empty)")
+	flags.IntVar(&opts.hot, "hot", 1, "Hot instances in the pool")
+	flags.IntVar(&opts.stopped, "stopped", 2, "Stopped instances in the pool")
+	flags.StringVar(&opts.admins, "admins", "", "Comma-separated GitHub usernames of admins")
+	var (
+		output      = flags.String("o", "", "Write to file instead of stdout")
+		force       = flags.Bool("force", false, "Overwrite the output file if it exists")
+		interactive = flags.Bool("i", false, "Prompt for each value")
+	)
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: runs-on-config init [flags]\n")
+		fmt.Fprintf(os.Stderr, "\nGenerates a starter runs-on.yml that passes validation.\n")
+		fmt.Fprintf(os.Stderr, "\nFlags:\n")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	if *interactive {
+		if err := promptInitOptions(os.Stdin, os.Stderr, &opts); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return 1
+		}
+	}
+
+	data, err := scaffoldConfig(opts)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	if *output == "" {
+		if _, err := os.Stdout.Write(data); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return 1
+		}
+		return 0
+	}
+
+	if !*force {
+		if _, err := os.Stat(*output); err == nil {
+			fmt.Fprintf(os.Stderr, "Error: %s already exists (use -force to overwrite)\n", *output)
+			return 1
+		}
+	}
+	if err := os.WriteFile(*output, data, 0o644); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	fmt.Fprintf(os.Stderr, "Wrote %s\n", *output)
+	return 0
+}
+
+// scaffoldConfig renders a starter config and checks that it validates
+func scaffoldConfig(opts initOptions) ([]byte, error) {
+	if opts.runner == "" {
+		return nil, fmt.Errorf("runner name cannot be empty")
+	}
+
+	family := flowSeq()
+	for _, name := range splitList(opts.family, "+") {
+		family.Content = append(family.Content, scalar(name))
+	}
+	runner := mapping(
+		scalar("cpu"), flowSeq(intScalar(opts.cpu)),
+		scalar("ram"), flowSeq(intScalar(opts.ram)),
+		scalar("family"), family,
+		scalar("image"), scalar(opts.image),
+	)
+	root := mapping(scalar("runners"), mapping(scalar(opts.runner), runner))
+	root.Content[0].HeadComment = "Runner definitions, usable in workflows with runs-on: runs-on=${{ github.run_id }}/runner=" + opts.runner
+
+	if opts.pool != "" {
+		schedule := mapping(
+			scalar("name"), scalar("default"),
+			scalar("hot"), intScalar(opts.hot),
+			scalar("stopped"), intScalar(opts.stopped),
+		)
+		pool := mapping(
+			scalar("runner"), scalar(opts.runner),
+			scalar("schedule"), &yaml.Node{Kind: yaml.SequenceNode, Content: []*yaml.Node{schedule}},
+		)
+		root.Content = append(root.Content, scalar("pools"), mapping(scalar(opts.pool), pool))
+	}
+
+	if admins := splitList(opts.admins, ","); len(admins) > 0 {
+		list := &yaml.Node{Kind: yaml.SequenceNode}
+		for _, admin := range admins {
+			list.Content = append(list.Content, scalar(admin))
+		}
+		root.Content = append(root.Content, scalar("admins"), list)
+	}
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{root}}); err != nil {
+		return nil, fmt.Errorf("failed to encode config: %w", err)
+	}
+	data, err := format.Format(buf.Bytes())
+	if err != nil {
+		return nil, err
+	}
+
+	diags, err := validate.ValidateBytes(context.Background(), data, "runs-on.yml")
+	if err != nil {
+		return nil, err
+	}
+	for _, diag := range diags {
+		if diag.Severity == validate.SeverityError {
+			return nil, fmt.Errorf("generated config is invalid: %s", diag.Message)
+		}
+	}
+	return data, nil
+}
+
+// promptInitOptions asks for each option on out, reading answers from in.
+// Empty answers keep the current value.
+func promptInitOptions(in io.Reader, out io.Writer, opts *initOptions) error {
+	reader := bufio.NewReader(in)
+	ask := func(question, current string) (string, error) {
+		fmt.Fprintf(out, "%s [%s]: ", question, current)
+		line, err := reader.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return "", err
+		}
+		if answer := strings.TrimSpace(line); answer != "" {
+			return answer, nil
+		}
+		return current, nil
+	}
+	askInt := func(question string, current int) (int, error) {
+		for {
+			answer, err := ask(question, strconv.Itoa(current))
+			if err != nil {
+				return 0, err
+			}
+			n, err := strconv.Atoi(answer)
+			if err == nil && n >= 0 {
+				return n, nil
+			}
+			fmt.Fprintf(out, "Please enter a non-negative number\n")
+		}
+	}
+
+	var err error
+	if opts.runner, err = ask("Runner name", opts.runner); err != nil {
+		return err
+	}
+	if opts.cpu, err = askInt("CPU count", opts.cpu); err != nil {
+		return err
+	}
+	if opts.ram, err = askInt("RAM in GB", opts.ram); err != nil {
+		return err
+	}
+	if opts.family, err = ask("Instance families (e.g. m7a+c7a)", opts.family); err != nil {
+		return err
+	}
+	if opts.image, err = ask("Image", opts.image); err != nil {
+		return err
+	}
+	if opts.pool, err = ask("Pool name (empty for none)", opts.pool); err != nil {
+		return err
+	}
+	if opts.pool != "" {
+		if opts.hot, err = askInt("Hot instances", opts.hot); err != nil {
+			return err
+		}
+		if opts.stopped, err = askInt("Stopped instances", opts.stopped); err != nil {
+			return err
+		}
+	}
+	if opts.admins, err = ask("Admins (comma-separated GitHub usernames)", opts.admins); err != nil {
+		return err
+	}
+	return nil
+}
+
+func splitList(value, sep string) []string {
+	var items []string
+	for _, item := range strings.Split(value, sep) {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func scalar(value string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value}
+}
+
+func intScalar(value int) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!int", Value: strconv.Itoa(value)}
+}
+
+func flowSeq(items ...*yaml.Node) *yaml.Node {
+	return &yaml.Node{Kind: yaml.SequenceNode, Style: yaml.FlowStyle, Content: items}
+}
+
+// mapping builds a mapping node from alternating key and value nodes
+func mapping(pairs ...*yaml.Node) *yaml.Node {
+	return &yaml.Node{Kind: yaml.MappingNode, Content: pairs}
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/runs-on/config/pkg/config"
+	"github.com/runs-on/config/pkg/labels"
+	"gopkg.in/yaml.v3"
+)
+
+func runLabel(args []string) int {
+	flags := flag.NewFlagSet("label", flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: runs-on-config label [flags] <file> <label>\n")
+		fmt.Fprintf(os.Stderr, "\nPrints the runner a job label gets: the configured runner it selects, overridden by the label settings, with defaults for unset fields.\n")
+		fmt.Fprintf(os.Stderr, "\nExample: runs-on-config label .github/runs-on.yml 'runs-on=${{ github.run_id }}/runner=small/cpu=8'\n")
+		fmt.Fprintf(os.Stderr, "\nFlags:\n")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() != 2 {
+		fmt.Fprintf(os.Stderr, "Error: expected a file and a label\n")
+		flags.Usage()
+		return 2
+	}
+
+	if _, diags := labels.Parse(flags.Arg(1)); len(diags) > 0 {
+		for _, diag := range diags {
+			fmt.Fprintf(os.Stderr, "Error: %s\n", diag.Message)
+		}
+		return 1
+	}
+
+	cfg, err := config.Load(flags.Arg(0))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	resolution, err := labels.Resolve(cfg, flags.Arg(1))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+
+	switch {
+	case resolution.Pool != "":
+		fmt.Printf("Runner %s (pool %s)\n", resolution.Name, resolution.Pool)
+	case resolution.Name != "":
+		fmt.Printf("Runner %s\n", resolution.Name)
+	default:
+		fmt.Printf("Runner defined by the label\n")
+	}
+	if len(resolution.Overrides) > 0 {
+		fmt.Printf("Overridden by the label: %s\n", strings.Join(resolution.Overrides, ", "))
+	}
+	fmt.Println()
+	out, err := yaml.Marshal(resolution.Runner)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	os.Stdout.Write(out)
+	return 0
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/runs-on/config/internal/lsp"
+)
+
+func runLSP(args []string) int {
+	flags := flag.NewFlagSet("lsp", flag.ContinueOnError)
+	allFiles := flags.Bool("all-files", false, "Validate every opened document, not only runs-on.yml and runs-on.yaml")
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: runs-on-config lsp [flags]\n")
+		fmt.Fprintf(os.Stderr, "\nRuns a Language Server Protocol server over stdio that publishes diagnostics as runs-on.yml files are edited.\n")
+		fmt.Fprintf(os.Stderr, "\nFlags:\n")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	server := lsp.New(os.Stdin, os.Stdout, lsp.Options{AllFiles: *allFiles})
+	if err := server.Run(context.Background()); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	return 0
+}
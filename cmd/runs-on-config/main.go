@@ -0,0 +1,35 @@
+package main
+
+import (
+	"os"
+
+	"github.com/runs-on/config/internal/cli"
+)
+
+var root = &cli.Command{
+	Name: "runs-on-config",
+	Commands: []*cli.Command{
+		{Name: "bisect", Summary: "Find the commit that introduced a config violation", Run: runBisect},
+		{Name: "capabilities", Summary: "List supported schema versions, rules, formats and fixes", Run: runCapabilities},
+		{Name: "cost", Summary: "Estimate the monthly cost of the pools in a config", Run: runCost},
+		{Name: "diff", Summary: "Compare two configs semantically", Run: runDiff},
+		{Name: "docs", Summary: "Generate Markdown or HTML documentation for a config", Run: runDocs},
+		{Name: "explain", Summary: "Describe a validation rule", Run: runExplain},
+		{Name: "explain-runner", Summary: "Show everything resolved for one runner", Run: runExplainRunner},
+		{Name: "fmt", Summary: "Reformat runs-on.yml files into canonical style", Run: runFmt},
+		{Name: "init", Summary: "Generate a starter runs-on.yml", Run: runInit},
+		{Name: "label", Summary: "Show the runner a job label resolves to", Run: runLabel},
+		cli.Lint("runs-on-config lint"),
+		{Name: "lsp", Summary: "Run a language server publishing diagnostics over stdio", Run: runLSP},
+		{Name: "migrate", Summary: "Upgrade a config across breaking schema changes", Run: runMigrate},
+		{Name: "paths", Summary: "List every field set in configs with its type and line", Run: runPaths},
+		{Name: "resolve", Summary: "Print the effective config after anchors, defaults and normalization", Run: runResolve},
+		{Name: "schema", Summary: "Print the embedded schema", Run: runSchema},
+		{Name: "serve", Summary: "Serve an HTTP validation API", Run: runServe},
+		{Name: "tui", Summary: "Browse and edit a config interactively", Run: runTUI},
+	},
+}
+
+func main() {
+	os.Exit(root.Execute(os.Args[1:]))
+}
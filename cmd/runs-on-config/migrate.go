@@ -0,0 +1,165 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/runs-on/config/pkg/migrate"
+)
+
+func runMigrate(args []string) int {
+	flags := flag.NewFlagSet("migrate", flag.ContinueOnError)
+	var (
+		write = flags.Bool("w", false, "Write the migrated config back to the file instead of stdout")
+		from  = flags.Int("from", 0, "Schema version the config is at (0 applies every migration)")
+		list  = flags.Bool("list", false, "List available migrations and exit")
+	)
+	var interactive bool
+	flags.BoolVar(&interactive, "interactive", false, "Show each change and ask whether to apply it")
+	flags.BoolVar(&interactive, "i", false, "Shorthand for -interactive")
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: runs-on-config migrate [flags] <file>\n")
+		fmt.Fprintf(os.Stderr, "\nUpgrades a config to schema version %d, reporting each change on stderr.\n", migrate.LatestVersion())
+		fmt.Fprintf(os.Stderr, "With -interactive, each change is shown and applied only if confirmed.\n")
+		fmt.Fprintf(os.Stderr, "\nFlags:\n")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	if *list {
+		for _, migration := range migrate.Migrations() {
+			fmt.Printf("v%d  %-26s %s\n", migration.Version, migration.ID, migration.Description)
+		}
+		return 0
+	}
+
+	if flags.NArg() != 1 {
+		fmt.Fprintf(os.Stderr, "Error: expected one file\n")
+		flags.Usage()
+		return 2
+	}
+	path := flags.Arg(0)
+
+	src, err := os.ReadFile(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	var prompt *changePrompt
+	var accept func(migrate.Change) bool
+	if interactive {
+		prompt = newChangePrompt(os.Stdin, os.Stderr, path)
+		accept = prompt.accept
+	}
+	out, changes, err := migrate.MigrateFunc(src, *from, accept)
+	if err == nil && prompt != nil {
+		err = prompt.err
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %s: %v\n", path, err)
+		return 1
+	}
+
+	switch {
+	case prompt != nil && prompt.proposed > 0:
+		fmt.Fprintf(os.Stderr, "Applied %d of %d change(s)\n", len(changes), prompt.proposed)
+	case prompt == nil:
+		for _, change := range changes {
+			fmt.Fprintf(os.Stderr, "%s:%d: [%s] %s\n", path, change.Line, change.Migration, change.Message)
+		}
+	}
+	if len(changes) == 0 && (prompt == nil || prompt.proposed == 0) {
+		fmt.Fprintf(os.Stderr, "%s is up to date\n", path)
+	}
+
+	if !*write {
+		if _, err := os.Stdout.Write(out); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return 1
+		}
+		return 0
+	}
+	if len(changes) == 0 {
+		return 0
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	if err := os.WriteFile(path, out, info.Mode().Perm()); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// changePrompt asks whether to apply each proposed change on out, reading
+// answers from in. Answering "all" or "quit" applies or skips the remaining
+// changes without asking.
+type changePrompt struct {
+	reader *bufio.Reader
+	out    io.Writer
+	path   string
+	// remaining is the answer for the remaining changes, once given
+	remaining *bool
+	proposed  int
+	// err is the first error reading an answer; later changes are skipped
+	err error
+}
+
+func newChangePrompt(in io.Reader, out io.Writer, path string) *changePrompt {
+	return &changePrompt{reader: bufio.NewReader(in), out: out, path: path}
+}
+
+func (p *changePrompt) accept(change migrate.Change) bool {
+	p.proposed++
+	if p.err != nil {
+		return false
+	}
+	if p.remaining != nil {
+		return *p.remaining
+	}
+
+	fmt.Fprintf(p.out, "\n%s:%d: [%s] %s\n", p.path, change.Line, change.Migration, change.Message)
+	for _, line := range strings.Split(change.Before, "\n") {
+		fmt.Fprintf(p.out, "  - %s\n", line)
+	}
+	if change.After != "" {
+		for _, line := range strings.Split(change.After, "\n") {
+			fmt.Fprintf(p.out, "  + %s\n", line)
+		}
+	}
+	for {
+		fmt.Fprintf(p.out, "Apply this change? [y]es, [n]o, [a]ll, [q]uit: ")
+		line, err := p.reader.ReadString('\n')
+		if err != nil && (err != io.EOF || line == "") {
+			if err == io.EOF {
+				err = fmt.Errorf("no answer for change at line %d", change.Line)
+			}
+			p.err = err
+			return false
+		}
+		switch strings.ToLower(strings.TrimSpace(line)) {
+		case "y", "yes":
+			return true
+		case "n", "no":
+			return false
+		case "a", "all":
+			answer := true
+			p.remaining = &answer
+			return true
+		case "q", "quit":
+			answer := false
+			p.remaining = &answer
+			return false
+		}
+		fmt.Fprintf(p.out, "Please answer y, n, a or q\n")
+	}
+}
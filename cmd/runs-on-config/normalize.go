@@ -0,0 +1,18 @@
+package main
+
+import (
+	"github.com/runs-on/config/pkg/config"
+	"github.com/runs-on/config/pkg/extends"
+)
+
+// loadNormalized reads a config file, merges local _extends, expands
+// anchors and normalizes flexible fields so that equivalent spellings compare
+// equal
+func loadNormalized(path string) (map[string]any, error) {
+	doc, err := extends.Load(path, extends.Options{})
+	if err != nil {
+		return nil, err
+	}
+	config.Normalize(doc.Data)
+	return doc.Data, nil
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// patchOperation is a single RFC 6902 JSON Patch operation
+type patchOperation struct {
+	Op    string
+	Path  string
+	Value any
+}
+
+// MarshalJSON omits the value of remove operations only, since add and
+// replace values may legitimately be false, 0 or null
+func (op patchOperation) MarshalJSON() ([]byte, error) {
+	if op.Op == "remove" {
+		return json.Marshal(struct {
+			Op   string `json:"op"`
+			Path string `json:"path"`
+		}{op.Op, op.Path})
+	}
+	return json.Marshal(struct {
+		Op    string `json:"op"`
+		Path  string `json:"path"`
+		Value any    `json:"value"`
+	}{op.Op, op.Path, op.Value})
+}
+
+// comparedKeys are the top-level fields config.DiffData compares
+var comparedKeys = []string{"_extends", "admins", "runners", "images", "pools"}
+
+// comparedFields returns the part of a normalized config that diff compares
+func comparedFields(doc map[string]any) map[string]any {
+	result := make(map[string]any)
+	for _, key := range comparedKeys {
+		if value, ok := doc[key]; ok {
+			result[key] = value
+		}
+	}
+	return result
+}
+
+// jsonPatch returns the RFC 6902 operations turning oldValue into newValue.
+// Maps are patched key by key; lists and scalars are replaced as a whole.
+func jsonPatch(pointer string, oldValue, newValue any) []patchOperation {
+	if reflect.DeepEqual(oldValue, newValue) {
+		return nil
+	}
+
+	oldMap, oldIsMap := oldValue.(map[string]any)
+	newMap, newIsMap := newValue.(map[string]any)
+	if !oldIsMap || !newIsMap {
+		return []patchOperation{{Op: "replace", Path: pointer, Value: newValue}}
+	}
+
+	var ops []patchOperation
+	for _, key := range unionKeys(oldMap, newMap) {
+		oldChild, inOld := oldMap[key]
+		newChild, inNew := newMap[key]
+		childPointer := pointer + "/" + escapePointer(key)
+		switch {
+		case !inOld:
+			ops = append(ops, patchOperation{Op: "add", Path: childPointer, Value: newChild})
+		case !inNew:
+			ops = append(ops, patchOperation{Op: "remove", Path: childPointer})
+		default:
+			ops = append(ops, jsonPatch(childPointer, oldChild, newChild)...)
+		}
+	}
+	return ops
+}
+
+// mergePatch returns the RFC 7386 JSON Merge Patch turning oldValue into
+// newValue. Removed keys are set to null.
+func mergePatch(oldValue, newValue any) any {
+	oldMap, oldIsMap := oldValue.(map[string]any)
+	newMap, newIsMap := newValue.(map[string]any)
+	if !oldIsMap || !newIsMap {
+		return newValue
+	}
+
+	patch := make(map[string]any)
+	for _, key := range unionKeys(oldMap, newMap) {
+		oldChild, inOld := oldMap[key]
+		newChild, inNew := newMap[key]
+		switch {
+		case !inNew:
+			patch[key] = nil
+		case !inOld:
+			patch[key] = newChild
+		case !reflect.DeepEqual(oldChild, newChild):
+			patch[key] = mergePatch(oldChild, newChild)
+		}
+	}
+	return patch
+}
+
+// escapePointer escapes a key for use as an RFC 6901 JSON Pointer token
+func escapePointer(key string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(key, "~", "~0"), "/", "~1")
+}
+
+func unionKeys(a, b map[string]any) []string {
+	seen := make(map[string]bool)
+	var keys []string
+	for _, m := range []map[string]any{a, b} {
+		for key := range m {
+			if !seen[key] {
+				seen[key] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
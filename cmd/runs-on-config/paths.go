@@ -0,0 +1,71 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/runs-on/config/pkg/config"
+)
+
+func runPaths(args []string) int {
+	flags := flag.NewFlagSet("paths", flag.ContinueOnError)
+	outputFormat := flags.String("format", "text", "Output format: text or json")
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: runs-on-config paths [flags] <file>...\n")
+		fmt.Fprintf(os.Stderr, "\nLists every field set in each file, with anchors expanded, as\n")
+		fmt.Fprintf(os.Stderr, "<file>:<line><TAB><path><TAB><type>, e.g. to find configs still setting a field:\n")
+		fmt.Fprintf(os.Stderr, "\n  runs-on-config paths */.github/runs-on.yml | grep -P '\\trunners\\.[^.]+\\.disk\\t'\n")
+		fmt.Fprintf(os.Stderr, "\nFlags:\n")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if *outputFormat != "text" && *outputFormat != "json" {
+		fmt.Fprintf(os.Stderr, "Error: invalid format %q (valid: text, json)\n", *outputFormat)
+		return 2
+	}
+	if flags.NArg() == 0 {
+		fmt.Fprintf(os.Stderr, "Error: no file specified\n")
+		flags.Usage()
+		return 2
+	}
+
+	type filePath struct {
+		File string `json:"file"`
+		config.FieldPath
+	}
+	all := []filePath{}
+	status := 0
+	for _, path := range flags.Args() {
+		src, err := os.ReadFile(path)
+		if err == nil {
+			var paths []config.FieldPath
+			if paths, err = config.FieldPaths(src); err == nil {
+				for _, p := range paths {
+					all = append(all, filePath{File: path, FieldPath: p})
+				}
+			}
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %s: %v\n", path, err)
+			status = 1
+		}
+	}
+
+	if *outputFormat == "json" {
+		out, err := json.MarshalIndent(all, "", "  ")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return 1
+		}
+		fmt.Println(string(out))
+		return status
+	}
+	for _, p := range all {
+		fmt.Printf("%s:%d\t%s\t%s\n", p.File, p.Line, p.Path, p.Type)
+	}
+	return status
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/runs-on/config/pkg/config"
+	"github.com/runs-on/config/pkg/format"
+	"gopkg.in/yaml.v3"
+)
+
+func runResolve(args []string) int {
+	flags := flag.NewFlagSet("resolve", flag.ContinueOnError)
+	outputFormat := flags.String("format", "yaml", "Output format: yaml or json")
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: runs-on-config resolve [flags] <file>\n")
+		fmt.Fprintf(os.Stderr, "\nPrints the effective config: local _extends merged, anchors expanded, defaults applied and flexible fields normalized.\n")
+		fmt.Fprintf(os.Stderr, "\nFlags:\n")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() != 1 {
+		fmt.Fprintf(os.Stderr, "Error: expected one file\n")
+		flags.Usage()
+		return 2
+	}
+
+	doc, err := loadNormalized(flags.Arg(0))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	applyDefaults(doc)
+
+	var out []byte
+	switch *outputFormat {
+	case "yaml":
+		out, err = resolvedYAML(doc)
+	case "json":
+		out, err = json.MarshalIndent(doc, "", "  ")
+		out = append(out, '\n')
+	default:
+		fmt.Fprintf(os.Stderr, "Error: invalid format %q (valid: yaml, json)\n", *outputFormat)
+		return 2
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	if _, err := os.Stdout.Write(out); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// applyDefaults fills in pool fields that RunsOn defaults when unset. The
+// deprecated 'environment' field is folded into 'env'.
+func applyDefaults(doc map[string]any) {
+	pools, ok := doc["pools"].(map[string]any)
+	if !ok {
+		return
+	}
+	for _, value := range pools {
+		pool, ok := value.(map[string]any)
+		if !ok {
+			continue
+		}
+		if env, ok := pool["environment"]; ok {
+			if _, hasEnv := pool["env"]; !hasEnv {
+				pool["env"] = env
+			}
+			delete(pool, "environment")
+		}
+		if _, ok := pool["env"]; !ok {
+			pool["env"] = config.DefaultPoolEnv
+		}
+		if _, ok := pool["timezone"]; !ok {
+			pool["timezone"] = config.DefaultTimezone
+		}
+	}
+}
+
+// resolvedYAML encodes a resolved config as canonically formatted YAML, with
+// lists of scalars in flow style as in the documentation examples
+func resolvedYAML(doc map[string]any) ([]byte, error) {
+	var root yaml.Node
+	if err := root.Encode(doc); err != nil {
+		return nil, fmt.Errorf("failed to encode config: %w", err)
+	}
+	flowScalarLists(&root)
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&root); err != nil {
+		return nil, fmt.Errorf("failed to encode config: %w", err)
+	}
+	return format.Format(buf.Bytes())
+}
+
+func flowScalarLists(node *yaml.Node) {
+	if node.Kind == yaml.SequenceNode {
+		flow := true
+		for _, item := range node.Content {
+			if item.Kind != yaml.ScalarNode {
+				flow = false
+			}
+		}
+		if flow {
+			node.Style = yaml.FlowStyle
+		}
+	}
+	for _, child := range node.Content {
+		flowScalarLists(child)
+	}
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/runs-on/config/pkg/schemajson"
+	"github.com/runs-on/config/pkg/validate"
+)
+
+func runSchema(args []string) int {
+	flags := flag.NewFlagSet("schema", flag.ContinueOnError)
+	format := flags.String("format", "json", "Schema format: json or cue")
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: runs-on-config schema [flags]\n")
+		fmt.Fprintf(os.Stderr, "\nPrints the schema embedded in this binary.\n")
+		fmt.Fprintf(os.Stderr, "\nFlags:\n")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	var data []byte
+	switch *format {
+	case "json":
+		data = schemajson.Schema()
+	case "cue":
+		data = validate.CUESchema()
+	default:
+		fmt.Fprintf(os.Stderr, "Error: invalid format %q (valid: json, cue)\n", *format)
+		return 2
+	}
+
+	if _, err := os.Stdout.Write(data); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/runs-on/config/internal/server"
+)
+
+// adminTokenEnv names the environment variable holding the admin API token.
+// It is not a flag so that it does not show up in process listings.
+const adminTokenEnv = "RUNS_ON_CONFIG_ADMIN_TOKEN"
+
+func runServe(args []string) int {
+	flags := flag.NewFlagSet("serve", flag.ContinueOnError)
+	var (
+		addr        = flags.String("addr", ":8080", "Address to listen on")
+		maxBodySize = flags.Int64("max-body-size", server.DefaultMaxBodySize, "Maximum size of submitted configs in bytes")
+		schemaPath  = flags.String("schema", "", "CUE or JSON schema file, or schema version (e.g. v2), to use instead of the embedded schema (reloaded on SIGHUP)")
+		timeout     = flags.Duration("timeout", 10*time.Second, "Maximum time spent validating a request (0 for no limit)")
+	)
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: runs-on-config serve [flags]\n")
+		fmt.Fprintf(os.Stderr, "\nServes an HTTP validation API:\n")
+		fmt.Fprintf(os.Stderr, "  POST /validate   validate the YAML request body, returns JSON diagnostics\n")
+		fmt.Fprintf(os.Stderr, "  GET  /healthz    liveness check\n")
+		fmt.Fprintf(os.Stderr, "  POST /admin/reload  reload the schema, enabled by setting %s\n", adminTokenEnv)
+		fmt.Fprintf(os.Stderr, "\nSending SIGHUP also reloads the schema.\n")
+		fmt.Fprintf(os.Stderr, "\nFlags:\n")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	handler, err := server.New(server.Options{
+		MaxBodySize: *maxBodySize,
+		SchemaPath:  *schemaPath,
+		AdminToken:  os.Getenv(adminTokenEnv),
+		Timeout:     *timeout,
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	srv := &http.Server{
+		Addr:              *addr,
+		Handler:           handler,
+		ReadHeaderTimeout: 10 * time.Second,
+		ReadTimeout:       30 * time.Second,
+		WriteTimeout:      30 * time.Second,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+	go func() {
+		for range hup {
+			if response, err := handler.Reload(); err != nil {
+				fmt.Fprintf(os.Stderr, "Reload failed, keeping the previous schema: %v\n", err)
+			} else {
+				fmt.Fprintf(os.Stderr, "Reloaded schema %s\n", response.Schema)
+			}
+		}
+	}()
+
+	errc := make(chan error, 1)
+	go func() {
+		fmt.Fprintf(os.Stderr, "Listening on %s\n", *addr)
+		errc <- srv.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errc:
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/runs-on/config/internal/symbols"
+	"github.com/runs-on/config/internal/tui"
+)
+
+func runTUI(args []string) int {
+	flags := flag.NewFlagSet("tui", flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: runs-on-config tui [flags] <file>\n")
+		fmt.Fprintf(os.Stderr, "\nBrowses the runners, images and pools of a config with their validation\n")
+		fmt.Fprintf(os.Stderr, "errors, and edits fields interactively. Fields with a fixed set of values\n")
+		fmt.Fprintf(os.Stderr, "in the schema are picked from a list. Changes are written on save.\n")
+		fmt.Fprintf(os.Stderr, "\nFlags:\n")
+		flags.PrintDefaults()
+	}
+	symbolSet := flags.String("symbols", symbols.Unicode.Name, "Status markers: "+strings.Join(symbols.Names(), ", ")+" (ascii or none for terminals and screen readers that mangle Unicode)")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	set, err := symbols.Parse(*symbolSet)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 2
+	}
+	if flags.NArg() != 1 {
+		fmt.Fprintf(os.Stderr, "Error: expected one file\n")
+		flags.Usage()
+		return 2
+	}
+
+	session, err := tui.New(context.Background(), flags.Arg(0), os.Stdin, os.Stdout, tui.Options{Symbols: set})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	if err := session.Run(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	return 0
+}
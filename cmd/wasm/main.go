@@ -0,0 +1,62 @@
+//go:build js && wasm
+
+// Command wasm exposes the validator to JavaScript when compiled to
+// WebAssembly, for in-browser validation such as the playground of the
+// documentation site:
+//
+//	GOOS=js GOARCH=wasm go build -o runs-on-config.wasm ./cmd/wasm
+//
+// Once the module runs (with wasm_exec.js from the Go distribution), the
+// global function ValidateYAML(source[, options]) returns the validation
+// result as JSON, in the format of the HTTP API's POST /validate response.
+// options may set name (the file name in diagnostics, runs-on.yml by
+// default), locale (e.g. "fr") and strict.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"syscall/js"
+
+	"github.com/runs-on/config/internal/server"
+	"github.com/runs-on/config/pkg/validate"
+)
+
+func main() {
+	js.Global().Set("ValidateYAML", js.FuncOf(validateYAML))
+	// Keep the functions available until the page is closed
+	select {}
+}
+
+func validateYAML(_ js.Value, args []js.Value) any {
+	if len(args) == 0 || args[0].Type() != js.TypeString {
+		return encode(server.ErrorResponse{Error: "ValidateYAML expects the YAML source as a string", Code: validate.CodeInput})
+	}
+
+	name := "runs-on.yml"
+	// There are no local files to extend in a browser
+	opts := validate.Options{DisableLocalExtends: true}
+	if len(args) > 1 && args[1].Type() == js.TypeObject {
+		if value := args[1].Get("name"); value.Type() == js.TypeString {
+			name = value.String()
+		}
+		if value := args[1].Get("locale"); value.Type() == js.TypeString {
+			opts.Locale = value.String()
+		}
+		opts.Strict = args[1].Get("strict").Truthy()
+	}
+
+	diags, err := validate.ValidateBytesWithOptions(context.Background(), []byte(args[0].String()), name, opts)
+	if err != nil {
+		return encode(server.ErrorResponse{Error: err.Error(), Code: validate.CodeOf(err)})
+	}
+	return encode(server.NewValidateResponse(diags))
+}
+
+func encode(value any) string {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return `{"error":"failed to encode the result"}`
+	}
+	return string(data)
+}
@@ -0,0 +1,74 @@
+// Package capabilities describes what this build of the config tools
+// supports, so that orchestrating tools can feature-detect instead of parsing
+// version strings
+package capabilities
+
+import (
+	"github.com/runs-on/config/internal/cli"
+	appversion "github.com/runs-on/config/internal/version"
+	"github.com/runs-on/config/pkg/advisory"
+	"github.com/runs-on/config/pkg/validate"
+)
+
+// Capabilities is the machine-readable description of a build
+type Capabilities struct {
+	Version        string          `json:"version"`
+	Build          Build           `json:"build"`
+	SchemaVersions []SchemaVersion `json:"schema_versions"`
+	// SchemaSources lists the kinds of schema accepted by -schema besides
+	// named versions
+	SchemaSources []string `json:"schema_sources"`
+	Rules         []Rule   `json:"rules"`
+	OutputFormats []string `json:"output_formats"`
+	// Fixes lists the rule IDs whose diagnostics -fix can resolve
+	Fixes []string `json:"fixes"`
+	// AdvisoriesUpdated is the date of the embedded advisory database
+	AdvisoriesUpdated string `json:"advisories_updated"`
+}
+
+// Build describes how the binary was built, including the digest of the
+// embedded schema
+type Build = validate.Build
+
+// SchemaVersion is a named schema version accepted by -schema
+type SchemaVersion struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// Rule is a validation rule with its default severity
+type Rule struct {
+	ID       string `json:"id"`
+	Severity string `json:"severity"`
+	Summary  string `json:"summary"`
+	Fixable  bool   `json:"fixable"`
+	Group    string `json:"group,omitempty"`
+}
+
+// Get returns the capabilities of this build
+func Get() Capabilities {
+	c := Capabilities{
+		Version:           appversion.String(),
+		Build:             validate.BuildInfo(),
+		SchemaSources:     []string{"cue", "json"},
+		OutputFormats:     cli.OutputFormats,
+		AdvisoriesUpdated: advisory.Embedded().Updated,
+	}
+	for _, version := range validate.SchemaVersions() {
+		c.SchemaVersions = append(c.SchemaVersions, SchemaVersion{Name: version.Name, Description: version.Description})
+	}
+	c.SchemaVersions = append(c.SchemaVersions, SchemaVersion{Name: validate.LatestSchema, Description: "The embedded schema"})
+	for _, rule := range validate.Rules() {
+		c.Rules = append(c.Rules, Rule{
+			ID:       rule.ID,
+			Severity: string(rule.Severity),
+			Summary:  rule.Summary,
+			Fixable:  rule.Fixable,
+			Group:    rule.Group,
+		})
+		if rule.Fixable {
+			c.Fixes = append(c.Fixes, rule.ID)
+		}
+	}
+	return c
+}
@@ -0,0 +1,101 @@
+package cli
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/runs-on/config/internal/store"
+	appversion "github.com/runs-on/config/internal/version"
+	"github.com/runs-on/config/pkg/advisory"
+	"github.com/runs-on/config/pkg/extends"
+	"github.com/runs-on/config/pkg/validate"
+	"gopkg.in/yaml.v3"
+)
+
+// resultCache remembers configs that passed validation without any
+// diagnostic. Entries are empty artifacts named after a hash of the config and
+// of everything else the result depends on, so concurrent linters (as run by
+// pre-commit, or by CI jobs sharing a bucket) can share the cache without
+// locking.
+type resultCache struct {
+	store store.Store
+	salt  []byte
+}
+
+// openCache returns the cache for validations with opts, stored at location
+// (a directory or an s3://bucket/prefix URL), or in the user cache directory
+// if location is empty
+func openCache(ctx context.Context, location string, opts validate.Options) (*resultCache, error) {
+	if location == "" {
+		base, err := os.UserCacheDir()
+		if err != nil {
+			return nil, fmt.Errorf("failed to locate cache directory: %w", err)
+		}
+		location = filepath.Join(base, "runs-on-config", "lint")
+	}
+	artifacts, err := store.Open(ctx, location)
+	if err != nil {
+		return nil, err
+	}
+
+	advisories := opts.Advisories
+	if advisories == nil {
+		advisories = advisory.Embedded()
+	}
+	schema := opts.Schema
+	if schema == nil {
+		schema = validate.CUESchema()
+	}
+	salt, err := json.Marshal(struct {
+		Version       string
+		StrictAdmins  bool
+		ScopePath     string
+		Schema        []byte
+		Advisories    *advisory.Database
+		ShutdownDays  []string
+		UnusedRunners bool
+		LabelRunners  []string
+		UnusedImages  bool
+		Shellcheck    bool
+		MaxHot        int
+		Strict        bool
+		Rules         map[string]bool
+		Profile       string
+	}{appversion.String(), opts.StrictAdmins, opts.ScopePath, schema, advisories, opts.ShutdownDays, opts.UnusedRunners, opts.LabelRunners, opts.UnusedImages, opts.Shellcheck, opts.MaxHot, opts.Strict, opts.Rules, opts.Profile})
+	if err != nil {
+		return nil, err
+	}
+	return &resultCache{store: artifacts, salt: salt}, nil
+}
+
+func (c *resultCache) key(src []byte) string {
+	hash := sha256.New()
+	hash.Write(c.salt)
+	hash.Write([]byte{0})
+	hash.Write(src)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// passed reports whether src passed before. A cache that cannot be read is
+// treated as empty.
+func (c *resultCache) passed(ctx context.Context, src []byte) bool {
+	_, err := c.store.Get(ctx, c.key(src))
+	return err == nil
+}
+
+// remember records that src passed. Configs with a local _extends are not
+// cached, as their result also depends on the files they extend.
+func (c *resultCache) remember(ctx context.Context, src []byte) error {
+	var config struct {
+		Extends string `yaml:"_extends"`
+	}
+	if err := yaml.Unmarshal(src, &config); err != nil || extends.IsLocal(config.Extends) {
+		return nil
+	}
+	return c.store.Put(ctx, c.key(src), nil)
+}
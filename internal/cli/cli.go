@@ -0,0 +1,103 @@
+// Package cli implements the command tree shared by the runs-on-config
+// binaries. cmd/runs-on-config, cmd/lint and cmd/runs-on-config-lint are thin
+// wrappers around it.
+package cli
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	appversion "github.com/runs-on/config/internal/version"
+	"github.com/runs-on/config/pkg/validate"
+)
+
+// Exit codes shared by all commands
+const (
+	// ExitOK means success. Linted configs may still have warnings.
+	ExitOK = 0
+	// ExitFailure means the config has errors or the command failed
+	ExitFailure = 1
+	// ExitUsage means invalid flags or arguments
+	ExitUsage = 2
+)
+
+// Command is a node in a command tree. A command with subcommands dispatches
+// on its first argument; a leaf command passes its arguments to Run.
+type Command struct {
+	Name    string
+	Summary string
+	// Run parses the command's flags and arguments and returns an exit code
+	Run      func(args []string) int
+	Commands []*Command
+}
+
+// Execute runs the command selected by args and returns its exit code
+func (c *Command) Execute(args []string) int {
+	if len(c.Commands) == 0 {
+		return c.Run(args)
+	}
+	if len(args) == 0 {
+		c.usage()
+		return ExitUsage
+	}
+
+	name := args[0]
+	switch name {
+	case "-h", "-help", "--help", "help":
+		c.usage()
+		return ExitOK
+	case "-version", "--version", "version":
+		flags := flag.NewFlagSet(c.Name+" "+name, flag.ContinueOnError)
+		format := flags.String("format", "text", "Output format: text or json")
+		if err := flags.Parse(args[1:]); err != nil {
+			return ExitUsage
+		}
+		return printVersion(os.Stdout, c.Name, *format)
+	}
+
+	for _, sub := range c.Commands {
+		if sub.Name == name {
+			return sub.Execute(args[1:])
+		}
+	}
+
+	fmt.Fprintf(os.Stderr, "Error: unknown command %q\n\n", name)
+	c.usage()
+	return ExitUsage
+}
+
+func (c *Command) usage() {
+	fmt.Fprintf(os.Stderr, "Usage: %s <command> [flags] [args]\n", c.Name)
+	fmt.Fprintf(os.Stderr, "\nCommands:\n")
+	for _, sub := range c.Commands {
+		fmt.Fprintf(os.Stderr, "  %-15s %s\n", sub.Name, sub.Summary)
+	}
+	fmt.Fprintf(os.Stderr, "\nRun '%s <command> -h' for command flags.\n", c.Name)
+}
+
+// printVersion prints the version of a tool, with its build information in
+// the json format
+func printVersion(w io.Writer, name, format string) int {
+	switch format {
+	case "text":
+		fmt.Fprintf(w, "%s %s\n", name, appversion.String())
+	case "json":
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(struct {
+			Name    string         `json:"name"`
+			Version string         `json:"version"`
+			Build   validate.Build `json:"build"`
+		}{name, appversion.String(), validate.BuildInfo()}); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return ExitFailure
+		}
+	default:
+		fmt.Fprintf(os.Stderr, "Error: invalid format %q (valid: text, json)\n", format)
+		return ExitUsage
+	}
+	return ExitOK
+}
@@ -0,0 +1,52 @@
+package cli_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/runs-on/config/internal/cli"
+)
+
+func TestExecute(t *testing.T) {
+	var got []string
+	root := &cli.Command{
+		Name: "tool",
+		Commands: []*cli.Command{
+			{Name: "run", Run: func(args []string) int {
+				got = args
+				return 3
+			}},
+			{Name: "group", Commands: []*cli.Command{
+				{Name: "leaf", Run: func(args []string) int {
+					got = args
+					return cli.ExitOK
+				}},
+			}},
+		},
+	}
+
+	testCases := []struct {
+		name     string
+		args     []string
+		expected int
+		gotArgs  []string
+	}{
+		{name: "leaf", args: []string{"run", "-x", "file"}, expected: 3, gotArgs: []string{"-x", "file"}},
+		{name: "nested", args: []string{"group", "leaf", "a"}, expected: cli.ExitOK, gotArgs: []string{"a"}},
+		{name: "no command", args: nil, expected: cli.ExitUsage},
+		{name: "unknown command", args: []string{"missing"}, expected: cli.ExitUsage},
+		{name: "help", args: []string{"--help"}, expected: cli.ExitOK},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got = nil
+			if code := root.Execute(tc.args); code != tc.expected {
+				t.Errorf("Expected exit code %d, got %d", tc.expected, code)
+			}
+			if !slices.Equal(got, tc.gotArgs) {
+				t.Errorf("Expected args %v, got %v", tc.gotArgs, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,414 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/runs-on/config/internal/symbols"
+	"github.com/runs-on/config/pkg/advisory"
+	"github.com/runs-on/config/pkg/format"
+	"github.com/runs-on/config/pkg/validate"
+)
+
+// Lint returns the lint command. prog is the name shown in its usage, e.g.
+// "runs-on-config lint" or the name of a standalone binary.
+func Lint(prog string) *Command {
+	return &Command{
+		Name:    "lint",
+		Summary: "Validate a runs-on.yml file",
+		Run: func(args []string) int {
+			return runLint(prog, args, false)
+		},
+	}
+}
+
+// LegacyLint returns the lint command of the legacy runs-on-config-lint
+// binary, which exits with ExitFailure on warnings too.
+func LegacyLint(prog string) *Command {
+	return &Command{
+		Name:    "lint",
+		Summary: "Validate a runs-on.yml file",
+		Run: func(args []string) int {
+			return runLint(prog, args, true)
+		},
+	}
+}
+
+func runLint(prog string, args []string, failOnWarnings bool) int {
+	flags := flag.NewFlagSet(prog, flag.ContinueOnError)
+	var (
+		outputFormat  = flags.String("format", "text", "Output format: "+strings.Join(OutputFormats, ", "))
+		stdin         = flags.Bool("stdin", false, "Read from stdin instead of file")
+		filename      = flags.String("filename", "", "Path to report for stdin input, e.g. .github/runs-on.yml (also used to resolve local _extends)")
+		version       = flags.Bool("version", false, "Print version and exit")
+		summary       = flags.Bool("github-step-summary", false, "Append a Markdown report to $GITHUB_STEP_SUMMARY")
+		strict        = flags.Bool("strict-admins", false, "Also require admins to be sorted alphabetically")
+		fix           = flags.Bool("fix", false, "Fix the file before validating: remove duplicate admins (and sort them with -strict-admins), unused anchors, unused x-* blocks and deprecated fields, and move pool counts into a schedule")
+		schema        = flags.String("schema", "", "CUE or JSON schema file, or schema version (e.g. v2, latest), to validate against instead of the embedded schema")
+		scopePath     = flags.String("path", "", "Only validate the subtree at this dotted path, e.g. runners.gpu-runner (plus the entries it references)")
+		advisoryDB    = flags.String("advisory-db", "", "Advisory database file or URL to check instead of the embedded snapshot")
+		outputFile    = flags.String("output-file", "", "Write the report to this file and a human-readable report to stderr")
+		useCache      = flags.Bool("cache", false, "Skip files that passed before with the same content and flags, using a cache in the user cache directory")
+		symbolSet     = flags.String("symbols", symbols.Unicode.Name, "Status markers of text output: "+strings.Join(symbols.Names(), ", ")+" (ascii or none for terminals and screen readers that mangle Unicode)")
+		cacheLocation = flags.String("cache-location", "", "Directory or s3://bucket/prefix URL to keep the cache in instead of the user cache directory (implies -cache)")
+		settingsRef   = flags.String("config", "", "Lint settings file, http(s) URL, or - for stdin; flags given on the command line take precedence")
+		shutdownDays  = flags.String("shutdown-days", "", "Comma-separated weekdays without jobs, e.g. saturday,sunday, to warn about pool schedules keeping hot instances on them")
+		unusedRunners = flags.Bool("unused-runners", false, "Also warn about runners that no pool uses")
+		unusedImages  = flags.Bool("unused-images", false, "Also warn about images that no runner uses")
+		labelRunners  = flags.String("label-runners", "", "Comma-separated runner names or glob patterns, e.g. gpu-*, that jobs select with runner= labels only, not reported by -unused-runners")
+		shellcheck    = flags.Bool("shellcheck", false, "Also check preinstall scripts with shellcheck, if installed")
+		maxHot        = flags.Int("max-hot", validate.DefaultMaxHot, "Warn about pool schedule entries keeping more hot instances than this (negative: no limit)")
+		profile       = flags.String("profile", "", "Validation profile: "+strings.Join(validate.Profiles, ", ")+" (prod also warns about configs without schema-version)")
+	)
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s [flags] <file>...\n", prog)
+		fmt.Fprintf(os.Stderr, "\nValidates each file. With several files, text output is one line per file\n")
+		fmt.Fprintf(os.Stderr, "followed by its diagnostics, as expected by pre-commit.\n")
+		fmt.Fprintf(os.Stderr, "\nA .tar.gz or .tgz file is read as an archive of configs: each runs-on.yml or\n")
+		fmt.Fprintf(os.Stderr, "runs-on.yaml entry is validated and reported as archive:entry. Local _extends\n")
+		fmt.Fprintf(os.Stderr, "are not resolved inside archives.\n")
+		if failOnWarnings {
+			fmt.Fprintf(os.Stderr, "\nExits with %d when the config has no diagnostics, %d when it has errors or\n", ExitOK, ExitFailure)
+			fmt.Fprintf(os.Stderr, "warnings or cannot be read, and %d on invalid flags or arguments.\n", ExitUsage)
+		} else {
+			fmt.Fprintf(os.Stderr, "\nExits with %d when the config is valid (warnings allowed), %d when it has\n", ExitOK, ExitFailure)
+			fmt.Fprintf(os.Stderr, "errors or cannot be read, and %d on invalid flags or arguments.\n", ExitUsage)
+		}
+		fmt.Fprintf(os.Stderr, "\nFlags:\n")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return ExitOK
+		}
+		return ExitUsage
+	}
+
+	if *version {
+		if *outputFormat == "json" {
+			return printVersion(os.Stdout, toolName, "json")
+		}
+		return printVersion(os.Stdout, toolName, "text")
+	}
+
+	if !slices.Contains(OutputFormats, *outputFormat) {
+		fmt.Fprintf(os.Stderr, "Error: invalid format %q (valid: %s)\n", *outputFormat, strings.Join(OutputFormats, ", "))
+		return ExitUsage
+	}
+	set, err := symbols.Parse(*symbolSet)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return ExitUsage
+	}
+	if *settingsRef == "-" && *stdin {
+		fmt.Fprintf(os.Stderr, "Error: cannot read both -config and the config from stdin\n")
+		return ExitUsage
+	}
+	if *filename != "" && !*stdin {
+		fmt.Fprintf(os.Stderr, "Error: -filename can only be used with -stdin\n")
+		return ExitUsage
+	}
+	if *fix && *stdin {
+		fmt.Fprintf(os.Stderr, "Error: cannot use -fix with -stdin\n")
+		return ExitUsage
+	}
+	if *fix && slices.ContainsFunc(flags.Args(), isArchive) {
+		fmt.Fprintf(os.Stderr, "Error: cannot use -fix with archives\n")
+		return ExitUsage
+	}
+	if *stdin && flags.NArg() > 0 {
+		fmt.Fprintf(os.Stderr, "Error: cannot use files with -stdin\n")
+		return ExitUsage
+	}
+	if !*stdin && flags.NArg() == 0 {
+		fmt.Fprintf(os.Stderr, "Error: no file specified\n")
+		flags.Usage()
+		return ExitUsage
+	}
+
+	var diags []validate.Diagnostic
+	var files []string
+	ctx := context.Background()
+	opts := validate.Options{}
+	var suppressions []validate.Suppression
+	if *settingsRef != "" {
+		s, err := loadSettings(ctx, *settingsRef, os.Stdin)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return ExitFailure
+		}
+		// Settings fill in the flags that were not given
+		given := make(map[string]bool)
+		flags.Visit(func(f *flag.Flag) { given[f.Name] = true })
+		if s.StrictAdmins != nil && !given["strict-admins"] {
+			*strict = *s.StrictAdmins
+		}
+		if s.Schema != "" && !given["schema"] {
+			*schema = s.Schema
+		}
+		if s.AdvisoryDB != "" && !given["advisory-db"] {
+			*advisoryDB = s.AdvisoryDB
+		}
+		if len(s.ShutdownDays) > 0 && !given["shutdown-days"] {
+			*shutdownDays = strings.Join(s.ShutdownDays, ",")
+		}
+		if s.UnusedRunners != nil && !given["unused-runners"] {
+			*unusedRunners = *s.UnusedRunners
+		}
+		if s.UnusedImages != nil && !given["unused-images"] {
+			*unusedImages = *s.UnusedImages
+		}
+		if len(s.LabelRunners) > 0 && !given["label-runners"] {
+			*labelRunners = strings.Join(s.LabelRunners, ",")
+		}
+		if s.Shellcheck != nil && !given["shellcheck"] {
+			*shellcheck = *s.Shellcheck
+		}
+		if s.MaxHot != nil && !given["max-hot"] {
+			*maxHot = *s.MaxHot
+		}
+		if s.Profile != "" && !given["profile"] {
+			*profile = s.Profile
+		}
+		opts.Strict, opts.Rules, opts.MaxErrors = s.Strict, s.Rules, s.MaxErrors
+		suppressions = s.Suppress
+	}
+	opts.StrictAdmins, opts.ScopePath = *strict, *scopePath
+	if *shutdownDays != "" {
+		opts.ShutdownDays = strings.Split(*shutdownDays, ",")
+	}
+	opts.UnusedRunners, opts.UnusedImages = *unusedRunners, *unusedImages
+	if *labelRunners != "" {
+		opts.LabelRunners = strings.Split(*labelRunners, ",")
+	}
+	opts.Shellcheck = *shellcheck
+	opts.MaxHot = *maxHot
+	if *profile != "" && !slices.Contains(validate.Profiles, *profile) {
+		fmt.Fprintf(os.Stderr, "Error: invalid profile %q (valid: %s)\n", *profile, strings.Join(validate.Profiles, ", "))
+		return ExitUsage
+	}
+	opts.Profile = *profile
+	if *schema != "" {
+		if opts.Schema, err = validate.LoadSchema(*schema); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return ExitFailure
+		}
+	}
+
+	if *advisoryDB != "" {
+		if opts.Advisories, err = advisory.Load(ctx, *advisoryDB); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return ExitFailure
+		}
+	}
+
+	// failed is set when a file could not be checked; the other files are
+	// still checked and reported
+	failed := false
+	cached := make(map[string]bool)
+	if *stdin {
+		sourceName := "<stdin>"
+		if *filename != "" {
+			sourceName = *filename
+		}
+		files = []string{sourceName}
+		if diags, err = validate.ValidateReaderWithOptions(ctx, os.Stdin, sourceName, opts); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return ExitFailure
+		}
+	} else {
+		var cache *resultCache
+		if *useCache || *cacheLocation != "" {
+			if cache, err = openCache(ctx, *cacheLocation, opts); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				return ExitFailure
+			}
+		}
+		for _, filePath := range flags.Args() {
+			if isArchive(filePath) {
+				results, err := lintArchive(ctx, filePath, opts)
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "Error: %s: %v\n", filePath, err)
+					failed = true
+					continue
+				}
+				for _, result := range results {
+					if result.Err != nil {
+						fmt.Fprintf(os.Stderr, "Error: %s: %v\n", result.Path, result.Err)
+						failed = true
+						continue
+					}
+					files = append(files, result.Path)
+					diags = append(diags, result.Diagnostics...)
+				}
+				continue
+			}
+			if *fix {
+				if err := fixFile(ctx, filePath, *strict); err != nil {
+					fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+					failed = true
+					continue
+				}
+			}
+			fileDiags, hit, err := lintFile(ctx, filePath, opts, cache)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %s: %v\n", filePath, err)
+				failed = true
+				continue
+			}
+			files = append(files, filePath)
+			cached[filePath] = hit
+			diags = append(diags, fileDiags...)
+		}
+	}
+
+	if len(suppressions) > 0 {
+		diags = validate.Filter(diags, validate.Suppress(suppressions))
+	}
+
+	if *summary {
+		if err := appendStepSummary(diags, files); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		}
+	}
+
+	// Reports record the schema the configs were validated against
+	build := validate.BuildInfo()
+	if opts.Schema != nil {
+		build.SchemaDigest = validate.SchemaDigest(opts.Schema)
+	}
+	if *outputFile != "" {
+		if err := writeReportFile(*outputFile, *outputFormat, diags, build); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			return ExitFailure
+		}
+		writeTextReport(os.Stderr, files, cached, diags, set)
+	} else if *outputFormat == "text" {
+		writeTextReport(os.Stdout, files, cached, diags, set)
+	} else if err := writeReport(os.Stdout, *outputFormat, diags, build); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return ExitFailure
+	}
+
+	// Warnings don't cause failure, except for the legacy binary
+	if errorCount, _ := countSeverities(diags); failed || errorCount > 0 || (failOnWarnings && len(diags) > 0) {
+		return ExitFailure
+	}
+	return ExitOK
+}
+
+// lintFile validates a file. With a cache, a file whose content passed before
+// is not validated again (hit is set), and a file that passes is remembered.
+func lintFile(ctx context.Context, path string, opts validate.Options, cache *resultCache) (diags []validate.Diagnostic, hit bool, err error) {
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false, err
+	}
+	if cache != nil && cache.passed(ctx, src) {
+		return nil, true, nil
+	}
+	diags, err = validate.ValidateBytesWithOptions(ctx, src, path, opts)
+	if err != nil {
+		return nil, false, err
+	}
+	if cache != nil && len(diags) == 0 {
+		if err := cache.remember(ctx, src); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to update cache: %v\n", err)
+		}
+	}
+	return diags, false, nil
+}
+
+// isArchive reports whether a lint argument is a gzip-compressed tar archive
+// of configs rather than a config
+func isArchive(path string) bool {
+	return strings.HasSuffix(path, ".tar.gz") || strings.HasSuffix(path, ".tgz")
+}
+
+// lintArchive validates the configs of the archive at path. Results and
+// diagnostics are reported as path:entry, e.g.
+// export.tar.gz:acme/api/.github/runs-on.yml.
+func lintArchive(ctx context.Context, path string, opts validate.Options) ([]validate.FileResult, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	results, err := validate.ValidateArchive(ctx, f, opts)
+	if err != nil {
+		return nil, err
+	}
+	if len(results) == 0 {
+		return nil, errors.New("no runs-on.yml or runs-on.yaml file in archive")
+	}
+	for i := range results {
+		results[i].Path = path + ":" + results[i].Path
+		for j := range results[i].Diagnostics {
+			results[i].Diagnostics[j].Path = path + ":" + results[i].Diagnostics[j].Path
+		}
+	}
+	return results, nil
+}
+
+// writeTextReport writes the text report for one file, or the per-file
+// report when several files were checked
+func writeTextReport(w io.Writer, files []string, cached map[string]bool, diags []validate.Diagnostic, set symbols.Set) {
+	if len(files) > 1 {
+		writeFileResults(w, files, cached, diags, set)
+	} else {
+		writeText(w, diags, set)
+	}
+}
+
+// fixFile applies the admins and unused definitions autofixes and the
+// suggested fixes of fixable rules to a file, rewriting it if it changes
+func fixFile(ctx context.Context, path string, sorted bool) error {
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	fixed, err := format.FixAdmins(src, sorted)
+	if err == nil {
+		fixed, err = format.RemoveUnused(fixed)
+	}
+	if err == nil {
+		fixed, err = applySuggestedFixes(ctx, fixed, path)
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	if bytes.Equal(src, fixed) {
+		return nil
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, fixed, info.Mode().Perm()); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "Fixed %s\n", path)
+	return nil
+}
+
+// applySuggestedFixes applies the suggested fixes of the diagnostics of
+// fixable rules, e.g. removing deprecated fields
+func applySuggestedFixes(ctx context.Context, src []byte, path string) ([]byte, error) {
+	diags, err := validate.ValidateBytes(ctx, src, path)
+	if err != nil {
+		return nil, err
+	}
+	diags = slices.DeleteFunc(diags, func(diag validate.Diagnostic) bool {
+		rule, _ := validate.LookupRule(diag.RuleID)
+		return diag.Path != path || !rule.Fixable
+	})
+	fixed, _ := validate.ApplyFixes(src, diags)
+	return fixed, nil
+}
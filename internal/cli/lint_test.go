@@ -0,0 +1,163 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/runs-on/config/internal/symbols"
+	"github.com/runs-on/config/pkg/validate"
+)
+
+func TestWriteFileResults(t *testing.T) {
+	diags := []validate.Diagnostic{
+		{Path: "b/runs-on.yml", Line: 3, Column: 5, Message: "runner 'small' is undefined", Severity: validate.SeverityError, RuleID: "pool-runner-undefined"},
+		{Path: "c/runs-on.yml", Message: "admins are not sorted", Severity: validate.SeverityWarning, RuleID: "admins-order"},
+	}
+	var buf bytes.Buffer
+	writeFileResults(&buf, []string{"a/runs-on.yml", "b/runs-on.yml", "c/runs-on.yml", "d/runs-on.yml"}, map[string]bool{"d/runs-on.yml": true}, diags, symbols.Unicode)
+
+	expected := `✓ a/runs-on.yml: no issues
+✗ b/runs-on.yml: 1 error(s), 0 warning(s)
+    b/runs-on.yml:3:5: error: runner 'small' is undefined [pool-runner-undefined]
+⚠ c/runs-on.yml: 1 warning(s)
+    c/runs-on.yml: warning: admins are not sorted [admins-order]
+✓ d/runs-on.yml: no issues (cached)
+`
+	if buf.String() != expected {
+		t.Errorf("Unexpected output:\n%s", buf.String())
+	}
+}
+
+func TestWriteText_Symbols(t *testing.T) {
+	diags := []validate.Diagnostic{
+		{Path: "runs-on.yml", Line: 3, Column: 5, Message: "runner 'small' is undefined", Severity: validate.SeverityError, RuleID: "pool-runner-undefined"},
+	}
+	for _, tc := range []struct {
+		set      symbols.Set
+		expected string
+	}{
+		{symbols.ASCII, "[ERROR] Validation failed with 1 error(s)\n"},
+		{symbols.None, "Validation failed with 1 error(s)\n"},
+	} {
+		var buf bytes.Buffer
+		writeText(&buf, diags, tc.set)
+		if !strings.HasSuffix(buf.String(), tc.expected) || !isASCII(buf.String()) {
+			t.Errorf("Unexpected %s output:\n%s", tc.set.Name, buf.String())
+		}
+	}
+}
+
+// isASCII reports whether s only contains ASCII characters
+func isASCII(s string) bool {
+	for _, r := range s {
+		if r > 127 {
+			return false
+		}
+	}
+	return true
+}
+
+func TestResultCache(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+	ctx := context.Background()
+
+	cache, err := openCache(ctx, "", validate.Options{})
+	if err != nil {
+		t.Fatalf("openCache failed: %v", err)
+	}
+	src := []byte("runners:\n  small:\n    cpu: 2\n")
+	if cache.passed(ctx, src) {
+		t.Fatal("Expected an empty cache")
+	}
+	if err := cache.remember(ctx, src); err != nil {
+		t.Fatalf("remember failed: %v", err)
+	}
+	if !cache.passed(ctx, src) {
+		t.Error("Expected the config to be remembered")
+	}
+
+	// Other flags give other results
+	strict, err := openCache(ctx, "", validate.Options{StrictAdmins: true})
+	if err != nil {
+		t.Fatalf("openCache failed: %v", err)
+	}
+	if strict.passed(ctx, src) {
+		t.Error("Expected the cache to depend on the options")
+	}
+
+	// Configs extending local files depend on more than their content
+	extending := []byte("_extends: ./base.yml\nrunners: {}\n")
+	if err := cache.remember(ctx, extending); err != nil {
+		t.Fatalf("remember failed: %v", err)
+	}
+	if cache.passed(ctx, extending) {
+		t.Error("Expected configs with a local _extends not to be cached")
+	}
+}
+
+func TestLoadSettings(t *testing.T) {
+	ctx := context.Background()
+	content := "strict-admins: true\nshutdown-days: [saturday, sunday]\nunused-runners: true\nlabel-runners: [gpu-*]\nunused-images: true\nshellcheck: true\nmax-hot: 20\nprofile: prod\nrules:\n  public-ssh: false\nmax-errors: 5\n" +
+		"suppress:\n  - rule: burstable-capacity\n    field: runners.cheap\n    reason: accepted for nightly jobs\n"
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/policy.yml" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, content)
+	}))
+	defer server.Close()
+	path := filepath.Join(t.TempDir(), "lint.yml")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	for name, ref := range map[string]string{"file": path, "url": server.URL + "/policy.yml", "stdin": "-"} {
+		t.Run(name, func(t *testing.T) {
+			s, err := loadSettings(ctx, ref, strings.NewReader(content))
+			if err != nil {
+				t.Fatalf("loadSettings failed: %v", err)
+			}
+			if s.StrictAdmins == nil || !*s.StrictAdmins || !slices.Equal(s.ShutdownDays, []string{"saturday", "sunday"}) ||
+				s.UnusedRunners == nil || !*s.UnusedRunners || !slices.Equal(s.LabelRunners, []string{"gpu-*"}) || s.UnusedImages == nil || !*s.UnusedImages ||
+				s.Shellcheck == nil || !*s.Shellcheck || s.MaxHot == nil || *s.MaxHot != 20 || s.Profile != validate.ProfileProd ||
+				s.Rules["public-ssh"] || s.MaxErrors != 5 || len(s.Suppress) != 1 || s.Suppress[0].Field != "runners.cheap" {
+				t.Errorf("Unexpected settings: %+v", s)
+			}
+		})
+	}
+
+	if _, err := loadSettings(ctx, server.URL+"/missing.yml", nil); err == nil {
+		t.Error("Expected an error for a missing URL")
+	}
+	if _, err := loadSettings(ctx, "-", strings.NewReader("strict-admin: true\n")); err == nil {
+		t.Error("Expected an error for an unknown setting")
+	}
+	if s, err := loadSettings(ctx, "-", strings.NewReader("")); err != nil || s.StrictAdmins != nil {
+		t.Errorf("Expected empty settings for an empty file, got %+v, %v", s, err)
+	}
+}
+
+func TestLint_ExitCodes(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "runs-on.yml")
+	if err := os.WriteFile(path, []byte("runners:\n  small:\n    cpu: [2]\nadmins: [bob, alice]\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	args := []string{"-strict-admins", "-format", "json", path}
+	if code := Lint("lint").Execute(args); code != ExitOK {
+		t.Errorf("Expected lint to exit with %d on warnings, got %d", ExitOK, code)
+	}
+	if code := LegacyLint("runs-on-config-lint").Execute(args); code != ExitFailure {
+		t.Errorf("Expected the legacy lint to exit with %d on warnings, got %d", ExitFailure, code)
+	}
+}
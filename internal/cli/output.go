@@ -0,0 +1,430 @@
+package cli
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/runs-on/config/internal/symbols"
+	appversion "github.com/runs-on/config/internal/version"
+	"github.com/runs-on/config/pkg/validate"
+)
+
+// toolName identifies the linter in version and SARIF output
+const toolName = "runs-on-config-lint"
+
+// OutputFormats lists the report formats supported by WriteReport
+var OutputFormats = []string{"text", "plain", "json", "sarif"}
+
+// WriteReport writes diags to w in one of OutputFormats. JSON and SARIF
+// reports record the build of the validator (see validate.BuildInfo).
+func WriteReport(w io.Writer, format string, diags []validate.Diagnostic) error {
+	return writeReport(w, format, diags, validate.BuildInfo())
+}
+
+// writeReport is WriteReport for diags produced by build
+func writeReport(w io.Writer, format string, diags []validate.Diagnostic, build validate.Build) error {
+	switch format {
+	case "text":
+		writeText(w, diags, symbols.Unicode)
+		return nil
+	case "plain":
+		writePlain(w, diags)
+		return nil
+	case "json":
+		return writeJSON(w, diags, build)
+	case "sarif":
+		return writeSARIF(w, diags, build)
+	}
+	return fmt.Errorf("invalid format %q", format)
+}
+
+// writeReportFile writes diags to the file at path, replacing it
+func writeReportFile(path, format string, diags []validate.Diagnostic, build validate.Build) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create report: %w", err)
+	}
+	if err := writeReport(f, format, diags, build); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+	return nil
+}
+
+func writeText(w io.Writer, diags []validate.Diagnostic, set symbols.Set) {
+	if len(diags) == 0 {
+		fmt.Fprintln(w, symbols.Mark(set.OK, "No issues found"))
+		return
+	}
+
+	// Separate errors and warnings
+	var errors []validate.Diagnostic
+	var warnings []validate.Diagnostic
+
+	for _, diag := range diags {
+		if diag.Severity == validate.SeverityError {
+			errors = append(errors, diag)
+		} else {
+			warnings = append(warnings, diag)
+		}
+	}
+
+	// Print errors first
+	if len(errors) > 0 {
+		fmt.Fprintf(w, "\n%s\n\n", symbols.Mark(set.Error, fmt.Sprintf("Found %d error(s):", len(errors))))
+		for i, diag := range errors {
+			loc := formatLocation(diag)
+			fmt.Fprintf(w, "  %d. %s%s\n", i+1, loc, formatRule(diag))
+			fmt.Fprintf(w, "     %s\n", diag.Message)
+			if i < len(errors)-1 {
+				fmt.Fprintln(w)
+			}
+		}
+	}
+
+	// Print warnings
+	if len(warnings) > 0 {
+		if len(errors) > 0 {
+			fmt.Fprintln(w)
+		}
+		fmt.Fprintf(w, "%s\n\n", symbols.Mark(set.Warning, fmt.Sprintf("Found %d warning(s):", len(warnings))))
+		for i, diag := range warnings {
+			loc := formatLocation(diag)
+			fmt.Fprintf(w, "  %d. %s%s\n", i+1, loc, formatRule(diag))
+			fmt.Fprintf(w, "     %s\n", diag.Message)
+			if i < len(warnings)-1 {
+				fmt.Fprintln(w)
+			}
+		}
+	}
+
+	// Print summary
+	fmt.Fprintln(w)
+	if len(errors) > 0 {
+		fmt.Fprint(w, symbols.Mark(set.Error, fmt.Sprintf("Validation failed with %d error(s)", len(errors))))
+		if len(warnings) > 0 {
+			fmt.Fprintf(w, " and %d warning(s)", len(warnings))
+		}
+		fmt.Fprintln(w)
+	} else {
+		fmt.Fprintln(w, symbols.Mark(set.OK, fmt.Sprintf("Validation passed with %d warning(s)", len(warnings))))
+	}
+}
+
+// writeFileResults writes a compact report of several files, as run by
+// pre-commit: one line per file with its counts, followed by its diagnostics
+//
+//	✓ a/runs-on.yml: no issues
+//	✗ b/runs-on.yml: 1 error(s), 0 warning(s)
+//	    b/runs-on.yml:3:5: error: runner 'small' is undefined [pool-runner-undefined]
+//
+// Files marked as cached passed in an earlier run with the same content.
+func writeFileResults(w io.Writer, files []string, cached map[string]bool, diags []validate.Diagnostic, set symbols.Set) {
+	byFile := make(map[string][]validate.Diagnostic)
+	for _, diag := range diags {
+		byFile[diag.Path] = append(byFile[diag.Path], diag)
+	}
+	for _, file := range files {
+		fileDiags := byFile[file]
+		errors, warnings := countSeverities(fileDiags)
+		switch {
+		case errors > 0:
+			fmt.Fprintln(w, symbols.Mark(set.Error, fmt.Sprintf("%s: %d error(s), %d warning(s)", file, errors, warnings)))
+		case warnings > 0:
+			fmt.Fprintln(w, symbols.Mark(set.Warning, fmt.Sprintf("%s: %d warning(s)", file, warnings)))
+		case cached[file]:
+			fmt.Fprintln(w, symbols.Mark(set.OK, fmt.Sprintf("%s: no issues (cached)", file)))
+		default:
+			fmt.Fprintln(w, symbols.Mark(set.OK, fmt.Sprintf("%s: no issues", file)))
+		}
+		for _, diag := range fileDiags {
+			fmt.Fprintf(w, "    %s: %s: %s%s\n", formatLocation(diag), diag.Severity, diag.Message, formatRule(diag))
+		}
+	}
+}
+
+// writePlain writes one line per diagnostic and nothing else:
+//
+//	path:line:col:severity:code:message
+//
+// The format is a stable interface for line-based tools. Output is ASCII
+// only: control characters become spaces and other characters are escaped as
+// \uXXXX, as is ':' in paths so that the first five fields never contain
+// one. Line and column are 0 when unknown, and code is "config-validation"
+// for diagnostics without a rule ID.
+func writePlain(w io.Writer, diags []validate.Diagnostic) {
+	for _, diag := range diags {
+		code := diag.RuleID
+		if code == "" {
+			code = "config-validation"
+		}
+		fmt.Fprintf(w, "%s:%d:%d:%s:%s:%s\n",
+			plainField(diag.Path, ":"), diag.Line, diag.Column, diag.Severity, code, plainField(diag.Message, ""))
+	}
+}
+
+// plainField makes s ASCII-only and single-line, escaping the characters in
+// escape as well
+func plainField(s, escape string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r < 0x20 || r == 0x7f:
+			b.WriteByte(' ')
+		case r > 0x7f || strings.ContainsRune(escape, r):
+			if r > 0xffff {
+				fmt.Fprintf(&b, "\\U%08x", r)
+			} else {
+				fmt.Fprintf(&b, "\\u%04x", r)
+			}
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+func formatLocation(diag validate.Diagnostic) string {
+	if diag.Line > 0 {
+		return fmt.Sprintf("%s:%d:%d", diag.Path, diag.Line, diag.Column)
+	}
+	return diag.Path
+}
+
+func formatRule(diag validate.Diagnostic) string {
+	if diag.RuleID == "" {
+		return ""
+	}
+	return fmt.Sprintf(" [%s]", diag.RuleID)
+}
+
+func writeJSON(w io.Writer, diags []validate.Diagnostic, build validate.Build) error {
+	type jsonDiagnostic struct {
+		Path      string `json:"path"`
+		Line      int    `json:"line,omitempty"`
+		Column    int    `json:"column,omitempty"`
+		EndLine   int    `json:"endLine,omitempty"`
+		EndColumn int    `json:"endColumn,omitempty"`
+		Offset    int    `json:"offset,omitempty"`
+		EndOffset int    `json:"endOffset,omitempty"`
+		Message   string `json:"message"`
+		Severity  string `json:"severity"`
+		Rule      string `json:"rule,omitempty"`
+		FieldPath string `json:"fieldPath,omitempty"`
+	}
+
+	type jsonOutput struct {
+		Valid       bool             `json:"valid"`
+		Diagnostics []jsonDiagnostic `json:"diagnostics"`
+		Build       validate.Build   `json:"build"`
+	}
+
+	output := jsonOutput{
+		Valid:       len(diags) == 0,
+		Diagnostics: make([]jsonDiagnostic, len(diags)),
+		Build:       build,
+	}
+
+	for i, diag := range diags {
+		output.Diagnostics[i] = jsonDiagnostic{
+			Path:      diag.Path,
+			Line:      diag.Line,
+			Column:    diag.Column,
+			EndLine:   diag.EndLine,
+			EndColumn: diag.EndColumn,
+			Offset:    diag.Offset,
+			EndOffset: diag.EndOffset,
+			Message:   diag.Message,
+			Severity:  string(diag.Severity),
+			Rule:      diag.RuleID,
+			FieldPath: diag.FieldPath,
+		}
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(output); err != nil {
+		return fmt.Errorf("failed to encode JSON: %w", err)
+	}
+	return nil
+}
+
+type sarifLocation struct {
+	ArtifactLocation struct {
+		URI string `json:"uri"`
+	} `json:"artifactLocation"`
+	Region *sarifRegion `json:"region,omitempty"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn,omitempty"`
+	EndLine     int `json:"endLine,omitempty"`
+	EndColumn   int `json:"endColumn,omitempty"`
+}
+
+type sarifPhysicalLocation struct {
+	PhysicalLocation sarifLocation `json:"physicalLocation"`
+}
+
+type sarifText struct {
+	Text     string `json:"text"`
+	Markdown string `json:"markdown,omitempty"`
+}
+
+type sarifResult struct {
+	RuleID              string                  `json:"ruleId"`
+	RuleIndex           int                     `json:"ruleIndex"`
+	Level               string                  `json:"level"`
+	Message             sarifText               `json:"message"`
+	Locations           []sarifPhysicalLocation `json:"locations"`
+	PartialFingerprints map[string]string       `json:"partialFingerprints"`
+}
+
+type sarifRule struct {
+	ID                   string     `json:"id"`
+	ShortDescription     *sarifText `json:"shortDescription,omitempty"`
+	FullDescription      *sarifText `json:"fullDescription,omitempty"`
+	Help                 *sarifText `json:"help,omitempty"`
+	HelpURI              string     `json:"helpUri,omitempty"`
+	DefaultConfiguration struct {
+		Level string `json:"level"`
+	} `json:"defaultConfiguration"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	Version        string      `json:"version"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+	// Properties records the build, see validate.Build
+	Properties validate.Build `json:"properties"`
+}
+
+type sarifRun struct {
+	Tool struct {
+		Driver sarifDriver `json:"driver"`
+	} `json:"tool"`
+	ColumnKind string        `json:"columnKind"`
+	Results    []sarifResult `json:"results"`
+}
+
+type sarifOutput struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+// sarifFingerprintKey names the partial fingerprint of results. It hashes
+// the rule, file and message but not the line, so that results keep their
+// identity in code scanning when lines are added above them.
+const sarifFingerprintKey = "runsOnConfigDiagnostic/v1"
+
+func writeSARIF(w io.Writer, diags []validate.Diagnostic, build validate.Build) error {
+	var rules []sarifRule
+	ruleIndex := make(map[string]int)
+	occurrences := make(map[string]int)
+	results := make([]sarifResult, len(diags))
+	for i, diag := range diags {
+		level := "error"
+		if diag.Severity == validate.SeverityWarning {
+			level = "warning"
+		}
+
+		ruleID := diag.RuleID
+		if ruleID == "" {
+			ruleID = "config-validation"
+		}
+		if _, ok := ruleIndex[ruleID]; !ok {
+			ruleIndex[ruleID] = len(rules)
+			rules = append(rules, sarifRuleFor(ruleID, level))
+		}
+		result := sarifResult{
+			RuleID:    ruleID,
+			RuleIndex: ruleIndex[ruleID],
+			Level:     level,
+			Message:   sarifText{Text: diag.Message},
+		}
+
+		// Identical diagnostics in a file are told apart by their order
+		hash := sha256.Sum256([]byte(ruleID + "\x00" + diag.Path + "\x00" + diag.Message))
+		fingerprint := hex.EncodeToString(hash[:16])
+		occurrences[fingerprint]++
+		result.PartialFingerprints = map[string]string{
+			sarifFingerprintKey: fmt.Sprintf("%s:%d", fingerprint, occurrences[fingerprint]),
+		}
+
+		var loc sarifLocation
+		loc.ArtifactLocation.URI = diag.Path
+		if diag.Line > 0 {
+			loc.Region = &sarifRegion{
+				StartLine:   diag.Line,
+				StartColumn: diag.Column,
+				EndLine:     diag.EndLine,
+				EndColumn:   diag.EndColumn,
+			}
+		}
+		result.Locations = []sarifPhysicalLocation{{PhysicalLocation: loc}}
+
+		results[i] = result
+	}
+
+	run := sarifRun{ColumnKind: "unicodeCodePoints", Results: results}
+	run.Tool.Driver = sarifDriver{
+		Name:           toolName,
+		Version:        appversion.String(),
+		InformationURI: "https://github.com/runs-on/config",
+		Rules:          rules,
+		Properties:     build,
+	}
+	output := sarifOutput{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs:    []sarifRun{run},
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(output); err != nil {
+		return fmt.Errorf("failed to encode SARIF: %w", err)
+	}
+	return nil
+}
+
+// sarifRuleFor describes a rule from its documentation. Rules without
+// documentation, such as advisory IDs, only get their ID and level.
+func sarifRuleFor(id, level string) sarifRule {
+	rule := sarifRule{ID: id}
+	rule.DefaultConfiguration.Level = level
+	info, ok := validate.LookupRule(id)
+	if !ok {
+		return rule
+	}
+
+	rule.ShortDescription = &sarifText{Text: info.Summary}
+	rule.FullDescription = &sarifText{Text: info.Description}
+	rule.HelpURI = info.DocURL
+	var markdown strings.Builder
+	markdown.WriteString(info.Description)
+	if info.BadExample != "" {
+		fmt.Fprintf(&markdown, "\n\nIncorrect:\n\n```yaml\n%s\n```", info.BadExample)
+	}
+	if info.GoodExample != "" {
+		fmt.Fprintf(&markdown, "\n\nCorrect:\n\n```yaml\n%s\n```", info.GoodExample)
+	}
+	rule.Help = &sarifText{Text: info.Description, Markdown: markdown.String()}
+	if info.Severity == validate.SeverityWarning {
+		rule.DefaultConfiguration.Level = "warning"
+	} else {
+		rule.DefaultConfiguration.Level = "error"
+	}
+	return rule
+}
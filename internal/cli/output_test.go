@@ -0,0 +1,174 @@
+package cli_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/runs-on/config/internal/cli"
+	"github.com/runs-on/config/pkg/validate"
+)
+
+var reportDiags = []validate.Diagnostic{
+	{Path: "runs-on.yml", Line: 3, Column: 5, Message: "runner 'small' is undefined", Severity: validate.SeverityError, RuleID: "pool-runner-undefined"},
+	{Path: "runs-on.yml", Message: "admins are not sorted", Severity: validate.SeverityWarning, RuleID: "admins-order"},
+}
+
+func TestWriteReport_Text(t *testing.T) {
+	var buf bytes.Buffer
+	if err := cli.WriteReport(&buf, "text", reportDiags); err != nil {
+		t.Fatalf("WriteReport failed: %v", err)
+	}
+	for _, want := range []string{
+		"runs-on.yml:3:5 [pool-runner-undefined]",
+		"✗ Validation failed with 1 error(s) and 1 warning(s)",
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("Expected output to contain %q, got:\n%s", want, buf.String())
+		}
+	}
+
+	buf.Reset()
+	if err := cli.WriteReport(&buf, "text", nil); err != nil {
+		t.Fatalf("WriteReport failed: %v", err)
+	}
+	if buf.String() != "✓ No issues found\n" {
+		t.Errorf("Unexpected output for no diagnostics: %q", buf.String())
+	}
+}
+
+func TestWriteReport_JSON(t *testing.T) {
+	var buf bytes.Buffer
+	if err := cli.WriteReport(&buf, "json", reportDiags); err != nil {
+		t.Fatalf("WriteReport failed: %v", err)
+	}
+	var output struct {
+		Valid       bool `json:"valid"`
+		Diagnostics []struct {
+			Line int    `json:"line"`
+			Rule string `json:"rule"`
+		} `json:"diagnostics"`
+		Build validate.Build `json:"build"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &output); err != nil {
+		t.Fatalf("Invalid JSON: %v", err)
+	}
+	if output.Valid || len(output.Diagnostics) != 2 || output.Diagnostics[0].Line != 3 || output.Diagnostics[1].Rule != "admins-order" {
+		t.Errorf("Unexpected JSON output: %+v", output)
+	}
+	if output.Build != validate.BuildInfo() {
+		t.Errorf("build = %+v, want %+v", output.Build, validate.BuildInfo())
+	}
+}
+
+func TestWriteReport_SARIF(t *testing.T) {
+	diags := append(reportDiags, validate.Diagnostic{
+		Path: "runs-on.yml", Line: 9, Column: 5, EndLine: 9, EndColumn: 11,
+		Message: "runner 'small' is undefined", Severity: validate.SeverityError, RuleID: "pool-runner-undefined",
+	})
+	var buf bytes.Buffer
+	if err := cli.WriteReport(&buf, "sarif", diags); err != nil {
+		t.Fatalf("WriteReport failed: %v", err)
+	}
+	var output struct {
+		Version string `json:"version"`
+		Runs    []struct {
+			Tool struct {
+				Driver struct {
+					Rules []struct {
+						ID      string `json:"id"`
+						HelpURI string `json:"helpUri"`
+						Help    struct {
+							Markdown string `json:"markdown"`
+						} `json:"help"`
+					} `json:"rules"`
+				} `json:"driver"`
+			} `json:"tool"`
+			Results []struct {
+				RuleID              string            `json:"ruleId"`
+				RuleIndex           int               `json:"ruleIndex"`
+				Level               string            `json:"level"`
+				PartialFingerprints map[string]string `json:"partialFingerprints"`
+				Locations           []struct {
+					PhysicalLocation struct {
+						ArtifactLocation struct {
+							URI string `json:"uri"`
+						} `json:"artifactLocation"`
+						Region *struct {
+							StartLine int `json:"startLine"`
+							EndLine   int `json:"endLine"`
+							EndColumn int `json:"endColumn"`
+						} `json:"region"`
+					} `json:"physicalLocation"`
+				} `json:"locations"`
+			} `json:"results"`
+		} `json:"runs"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &output); err != nil {
+		t.Fatalf("Invalid SARIF: %v", err)
+	}
+	if output.Version != "2.1.0" || len(output.Runs) != 1 || len(output.Runs[0].Results) != 3 {
+		t.Fatalf("Unexpected SARIF output: %s", buf.String())
+	}
+	run := output.Runs[0]
+	if result := run.Results[1]; result.RuleID != "admins-order" || result.Level != "warning" || result.RuleIndex != 1 {
+		t.Errorf("Unexpected SARIF result: %+v", result)
+	}
+
+	// Each rule is described once, with its documentation
+	if len(run.Tool.Driver.Rules) != 2 || run.Tool.Driver.Rules[0].ID != "pool-runner-undefined" {
+		t.Fatalf("Unexpected SARIF rules: %+v", run.Tool.Driver.Rules)
+	}
+	if rule := run.Tool.Driver.Rules[0]; rule.HelpURI == "" || !strings.Contains(rule.Help.Markdown, "```yaml") {
+		t.Errorf("Expected rule documentation, got %+v", rule)
+	}
+
+	// Identical diagnostics get distinct fingerprints that do not depend on
+	// the line
+	first, second := run.Results[0].PartialFingerprints, run.Results[2].PartialFingerprints
+	if len(first) != 1 || len(second) != 1 {
+		t.Fatalf("Expected one partial fingerprint per result, got %v and %v", first, second)
+	}
+	for key, value := range first {
+		if prefix, _, _ := strings.Cut(value, ":"); !strings.HasPrefix(second[key], prefix+":") || second[key] == value {
+			t.Errorf("Expected fingerprints of identical diagnostics to differ by occurrence, got %q and %q", value, second[key])
+		}
+	}
+
+	location := run.Results[2].Locations[0].PhysicalLocation
+	if location.ArtifactLocation.URI != "runs-on.yml" || location.Region == nil || location.Region.StartLine != 9 ||
+		location.Region.EndLine != 9 || location.Region.EndColumn != 11 {
+		t.Errorf("Unexpected SARIF location: %+v", location)
+	}
+	if run.Results[1].Locations[0].PhysicalLocation.Region != nil {
+		t.Error("Expected no region for a diagnostic without a line")
+	}
+}
+
+func TestWriteReport_Plain(t *testing.T) {
+	diags := append(reportDiags, validate.Diagnostic{
+		Path:     "C:/runs-on.yml",
+		Line:     7,
+		Column:   1,
+		Message:  "unexpected “value”\nsee docs",
+		Severity: validate.SeverityError,
+	})
+	var buf bytes.Buffer
+	if err := cli.WriteReport(&buf, "plain", diags); err != nil {
+		t.Fatalf("WriteReport failed: %v", err)
+	}
+	expected := `runs-on.yml:3:5:error:pool-runner-undefined:runner 'small' is undefined
+runs-on.yml:0:0:warning:admins-order:admins are not sorted
+C\u003a/runs-on.yml:7:1:error:config-validation:unexpected \u201cvalue\u201d see docs
+`
+	if buf.String() != expected {
+		t.Errorf("Unexpected output:\n%s\nexpected:\n%s", buf.String(), expected)
+	}
+}
+
+func TestWriteReport_InvalidFormat(t *testing.T) {
+	if err := cli.WriteReport(&bytes.Buffer{}, "xml", nil); err == nil {
+		t.Error("Expected an error for an unknown format")
+	}
+}
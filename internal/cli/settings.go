@@ -0,0 +1,87 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/runs-on/config/pkg/validate"
+	"gopkg.in/yaml.v3"
+)
+
+// maxSettingsSize limits the size of a settings file read from a URL
+const maxSettingsSize = 1 << 20
+
+// settings are lint options read from a settings file (-config), so that an
+// organization can manage them centrally. Flags given on the command line
+// take precedence.
+type settings struct {
+	StrictAdmins  *bool           `yaml:"strict-admins"`
+	Schema        string          `yaml:"schema"`
+	AdvisoryDB    string          `yaml:"advisory-db"`
+	ShutdownDays  []string        `yaml:"shutdown-days"`
+	UnusedRunners *bool           `yaml:"unused-runners"`
+	LabelRunners  []string        `yaml:"label-runners"`
+	UnusedImages  *bool           `yaml:"unused-images"`
+	Shellcheck    *bool           `yaml:"shellcheck"`
+	MaxHot        *int            `yaml:"max-hot"`
+	Profile       string          `yaml:"profile"`
+	Strict        bool            `yaml:"strict"`
+	Rules         map[string]bool `yaml:"rules"`
+	MaxErrors     int             `yaml:"max-errors"`
+	// Suppress lists accepted diagnostics, which are not reported
+	Suppress []validate.Suppression `yaml:"suppress"`
+}
+
+// loadSettings reads a settings file from a path, an http(s) URL, or stdin
+// for "-". Unknown fields are rejected, so that typos do not silently turn
+// a policy off.
+func loadSettings(ctx context.Context, ref string, stdin io.Reader) (*settings, error) {
+	var data []byte
+	var err error
+	switch {
+	case ref == "-":
+		data, err = io.ReadAll(stdin)
+	case strings.HasPrefix(ref, "https://") || strings.HasPrefix(ref, "http://"):
+		data, err = downloadSettings(ctx, ref)
+	default:
+		data, err = os.ReadFile(ref)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read settings: %w", err)
+	}
+
+	var s settings
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&s); err != nil && err != io.EOF {
+		return nil, fmt.Errorf("invalid settings %s: %w", ref, err)
+	}
+	if s.MaxErrors < 0 {
+		return nil, fmt.Errorf("invalid settings %s: max-errors must not be negative", ref)
+	}
+	return &s, nil
+}
+
+func downloadSettings(ctx context.Context, url string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s: %s", url, resp.Status)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, maxSettingsSize))
+}
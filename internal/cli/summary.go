@@ -0,0 +1,149 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/runs-on/config/pkg/validate"
+)
+
+// maxSummaryRules is the number of most frequent rules listed in the step summary
+const maxSummaryRules = 5
+
+// appendStepSummary appends a Markdown report of diags to the file named by
+// $GITHUB_STEP_SUMMARY. It does nothing outside of GitHub Actions.
+func appendStepSummary(diags []validate.Diagnostic, files []string) error {
+	path := os.Getenv("GITHUB_STEP_SUMMARY")
+	if path == "" {
+		return nil
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open step summary: %w", err)
+	}
+	defer f.Close()
+	writeStepSummary(f, diags, files, blobURL())
+	return nil
+}
+
+// writeStepSummary renders the summary. When baseURL is set, diagnostic
+// locations link to the file at the commit being checked.
+func writeStepSummary(w io.Writer, diags []validate.Diagnostic, files []string, baseURL string) {
+	errors, warnings := countSeverities(diags)
+	switch {
+	case errors > 0:
+		fmt.Fprintf(w, "### ✗ runs-on.yml: %d error(s), %d warning(s)\n\n", errors, warnings)
+	case warnings > 0:
+		fmt.Fprintf(w, "### ⚠ runs-on.yml: %d warning(s)\n\n", warnings)
+	default:
+		fmt.Fprintf(w, "### ✓ runs-on.yml: no issues found\n\n")
+	}
+
+	fmt.Fprintf(w, "| File | Errors | Warnings |\n| --- | --- | --- |\n")
+	for _, file := range files {
+		var fileDiags []validate.Diagnostic
+		for _, diag := range diags {
+			if diag.Path == file {
+				fileDiags = append(fileDiags, diag)
+			}
+		}
+		fileErrors, fileWarnings := countSeverities(fileDiags)
+		fmt.Fprintf(w, "| %s | %d | %d |\n", markdownLink(file, fileLink(baseURL, file, 0)), fileErrors, fileWarnings)
+	}
+
+	if rules := topRules(diags); len(rules) > 0 {
+		fmt.Fprintf(w, "\n| Rule | Count |\n| --- | --- |\n")
+		for _, rule := range rules {
+			fmt.Fprintf(w, "| `%s` | %d |\n", rule.id, rule.count)
+		}
+	}
+
+	if len(diags) > 0 {
+		fmt.Fprintf(w, "\n| Severity | Location | Rule | Message |\n| --- | --- | --- | --- |\n")
+		for _, diag := range diags {
+			fmt.Fprintf(w, "| %s | %s | %s | %s |\n",
+				diag.Severity,
+				markdownLink(formatLocation(diag), fileLink(baseURL, diag.Path, diag.Line)),
+				diag.RuleID,
+				escapeCell(diag.Message))
+		}
+	}
+	fmt.Fprintln(w)
+}
+
+func countSeverities(diags []validate.Diagnostic) (errors, warnings int) {
+	for _, diag := range diags {
+		if diag.Severity == validate.SeverityError {
+			errors++
+		} else {
+			warnings++
+		}
+	}
+	return errors, warnings
+}
+
+type ruleCount struct {
+	id    string
+	count int
+}
+
+// topRules returns the most frequent rule IDs, most frequent first
+func topRules(diags []validate.Diagnostic) []ruleCount {
+	counts := make(map[string]int)
+	for _, diag := range diags {
+		if diag.RuleID != "" {
+			counts[diag.RuleID]++
+		}
+	}
+	rules := make([]ruleCount, 0, len(counts))
+	for id, count := range counts {
+		rules = append(rules, ruleCount{id: id, count: count})
+	}
+	sort.Slice(rules, func(i, j int) bool {
+		if rules[i].count != rules[j].count {
+			return rules[i].count > rules[j].count
+		}
+		return rules[i].id < rules[j].id
+	})
+	if len(rules) > maxSummaryRules {
+		rules = rules[:maxSummaryRules]
+	}
+	return rules
+}
+
+// blobURL returns the URL of the checked-out commit's tree on GitHub, from
+// the default environment variables of GitHub Actions
+func blobURL() string {
+	server, repo, sha := os.Getenv("GITHUB_SERVER_URL"), os.Getenv("GITHUB_REPOSITORY"), os.Getenv("GITHUB_SHA")
+	if server == "" || repo == "" || sha == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s/%s/blob/%s", server, repo, sha)
+}
+
+func fileLink(baseURL, path string, line int) string {
+	if baseURL == "" || path == "" || path == "<stdin>" || filepath.IsAbs(path) {
+		return ""
+	}
+	link := baseURL + "/" + filepath.ToSlash(filepath.Clean(path))
+	if line > 0 {
+		link += fmt.Sprintf("#L%d", line)
+	}
+	return link
+}
+
+func markdownLink(text, url string) string {
+	if url == "" {
+		return escapeCell(text)
+	}
+	return fmt.Sprintf("[%s](%s)", escapeCell(text), url)
+}
+
+func escapeCell(text string) string {
+	text = strings.ReplaceAll(text, "|", "\\|")
+	return strings.ReplaceAll(text, "\n", " ")
+}
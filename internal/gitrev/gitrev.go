@@ -0,0 +1,102 @@
+// Package gitrev reads config files at arbitrary git revisions by shelling
+// out to the git binary.
+package gitrev
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// ErrNotFound is returned when a path does not exist at the requested revision
+var ErrNotFound = errors.New("path not found at revision")
+
+// Commit identifies a single commit
+type Commit struct {
+	Hash    string
+	Subject string
+}
+
+// Repo is a git working tree on disk
+type Repo struct {
+	Dir string
+}
+
+// Open returns the repository containing dir
+func Open(ctx context.Context, dir string) (*Repo, error) {
+	out, err := run(ctx, dir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return nil, fmt.Errorf("not a git repository: %w", err)
+	}
+	return &Repo{Dir: strings.TrimSpace(string(out))}, nil
+}
+
+// ResolveCommit resolves a revision (branch, tag, sha, HEAD~2, ...) to a commit
+func (r *Repo) ResolveCommit(ctx context.Context, rev string) (Commit, error) {
+	out, err := run(ctx, r.Dir, "log", "-1", "--format=%H%x00%s", rev, "--")
+	if err != nil {
+		return Commit{}, fmt.Errorf("failed to resolve revision %q: %w", rev, err)
+	}
+	hash, subject, _ := strings.Cut(strings.TrimSpace(string(out)), "\x00")
+	return Commit{Hash: hash, Subject: subject}, nil
+}
+
+// ReadFile returns the content of path (relative to the repository root) at rev
+func (r *Repo) ReadFile(ctx context.Context, rev, path string) ([]byte, error) {
+	out, err := run(ctx, r.Dir, "show", rev+":"+path)
+	if err != nil {
+		if strings.Contains(err.Error(), "does not exist") || strings.Contains(err.Error(), "exists on disk, but not in") {
+			return nil, fmt.Errorf("%s at %s: %w", path, rev, ErrNotFound)
+		}
+		return nil, fmt.Errorf("failed to read %s at %s: %w", path, rev, err)
+	}
+	return out, nil
+}
+
+// CommitsBetween lists the commits that modify any of paths and are
+// descendants of good and ancestors of bad (inclusive), oldest first
+func (r *Repo) CommitsBetween(ctx context.Context, good, bad string, paths ...string) ([]Commit, error) {
+	args := append([]string{"log", "--reverse", "--ancestry-path", "--format=%H%x00%s", good + ".." + bad, "--"}, paths...)
+	out, err := run(ctx, r.Dir, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list commits between %s and %s: %w", good, bad, err)
+	}
+
+	var commits []Commit
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		if line == "" {
+			continue
+		}
+		hash, subject, _ := strings.Cut(line, "\x00")
+		commits = append(commits, Commit{Hash: hash, Subject: subject})
+	}
+	return commits, nil
+}
+
+// Diff returns the patch that commit applied to paths
+func (r *Repo) Diff(ctx context.Context, commit string, paths ...string) (string, error) {
+	args := append([]string{"show", "--format=", "--no-color", commit, "--"}, paths...)
+	out, err := run(ctx, r.Dir, args...)
+	if err != nil {
+		return "", fmt.Errorf("failed to diff %s at %s: %w", strings.Join(paths, ", "), commit, err)
+	}
+	return string(out), nil
+}
+
+func run(ctx context.Context, dir string, args ...string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("git %s: %s", args[0], msg)
+		}
+		return nil, fmt.Errorf("git %s: %w", args[0], err)
+	}
+	return out, nil
+}
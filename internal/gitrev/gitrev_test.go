@@ -0,0 +1,95 @@
+package gitrev_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/runs-on/config/internal/gitrev"
+)
+
+func TestRepo(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+
+	dir := t.TempDir()
+	git := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(),
+			"GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com",
+			"GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com",
+		)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v failed: %v\n%s", args, err, out)
+		}
+	}
+	write := func(name, content string) {
+		t.Helper()
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("Failed to create directory: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("Failed to write file: %v", err)
+		}
+	}
+
+	git("init", "-q")
+	write("README", "hello\n")
+	git("add", "-A")
+	git("commit", "-q", "-m", "initial")
+	write(".github/runs-on.yml", "admins: [one]\n")
+	git("add", "-A")
+	git("commit", "-q", "-m", "add config")
+	write("README", "hello again\n")
+	git("commit", "-q", "-am", "unrelated")
+	write(".github/runs-on.yml", "admins: [one, two]\n")
+	git("commit", "-q", "-am", "add admin")
+	write(".github/base.yml", "admins: [three]\n")
+	git("add", "-A")
+	git("commit", "-q", "-m", "add base")
+
+	ctx := context.Background()
+	repo, err := gitrev.Open(ctx, dir)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+
+	data, err := repo.ReadFile(ctx, "HEAD~2", ".github/runs-on.yml")
+	if err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+	if string(data) != "admins: [one]\n" {
+		t.Errorf("Unexpected content at HEAD~2: %q", data)
+	}
+
+	if _, err := repo.ReadFile(ctx, "HEAD~4", ".github/runs-on.yml"); !errors.Is(err, gitrev.ErrNotFound) {
+		t.Errorf("Expected ErrNotFound before the file existed, got %v", err)
+	}
+
+	commits, err := repo.CommitsBetween(ctx, "HEAD~4", "HEAD", ".github/runs-on.yml")
+	if err != nil {
+		t.Fatalf("CommitsBetween failed: %v", err)
+	}
+	if len(commits) != 2 || commits[0].Subject != "add config" || commits[1].Subject != "add admin" {
+		t.Errorf("Expected the two commits touching the config, oldest first, got %+v", commits)
+	}
+	if commits, err := repo.CommitsBetween(ctx, "HEAD~4", "HEAD", ".github/runs-on.yml", ".github/base.yml"); err != nil || len(commits) != 3 {
+		t.Errorf("Expected the three commits touching either file, got %+v, %v", commits, err)
+	}
+
+	diff, err := repo.Diff(ctx, commits[1].Hash, ".github/runs-on.yml")
+	if err != nil {
+		t.Fatalf("Diff failed: %v", err)
+	}
+	if want := "+admins: [one, two]"; !strings.Contains(diff, want) {
+		t.Errorf("Expected diff to contain %q, got:\n%s", want, diff)
+	}
+}
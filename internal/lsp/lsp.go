@@ -0,0 +1,409 @@
+// Package lsp implements a Language Server Protocol server that publishes
+// validation diagnostics for runs-on.yml files. Only the parts of the
+// protocol needed for diagnostics and their quick fixes are supported:
+// documents are synced in full and validated on every change.
+package lsp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	appversion "github.com/runs-on/config/internal/version"
+	"github.com/runs-on/config/pkg/validate"
+)
+
+// JSON-RPC error codes
+const (
+	codeParseError     = -32700
+	codeMethodNotFound = -32601
+	codeInvalidParams  = -32602
+)
+
+// LSP diagnostic severities
+const (
+	severityError   = 1
+	severityWarning = 2
+)
+
+// Options configures the server
+type Options struct {
+	// AllFiles validates every document the client opens. By default only
+	// files named runs-on.yml or runs-on.yaml are validated, so that the
+	// server can be attached to all YAML files.
+	AllFiles bool
+}
+
+// Server is a language server communicating over a pair of streams
+type Server struct {
+	opts     Options
+	in       *bufio.Reader
+	out      io.Writer
+	outMu    sync.Mutex
+	shutdown bool
+}
+
+// New returns a server reading requests from in and writing to out
+func New(in io.Reader, out io.Writer, opts Options) *Server {
+	return &Server{opts: opts, in: bufio.NewReader(in), out: out}
+}
+
+type message struct {
+	JSONRPC string           `json:"jsonrpc"`
+	ID      *json.RawMessage `json:"id,omitempty"`
+	Method  string           `json:"method,omitempty"`
+	Params  json.RawMessage  `json:"params,omitempty"`
+	Result  any              `json:"result,omitempty"`
+	Error   *responseError   `json:"error,omitempty"`
+}
+
+type responseError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+type textDocumentItem struct {
+	URI  string `json:"uri"`
+	Text string `json:"text"`
+}
+
+type documentParams struct {
+	TextDocument   textDocumentItem `json:"textDocument"`
+	ContentChanges []struct {
+		Text string `json:"text"`
+	} `json:"contentChanges"`
+	Text *string `json:"text"`
+}
+
+type position struct {
+	Line      int `json:"line"`
+	Character int `json:"character"`
+}
+
+type diagnostic struct {
+	Range struct {
+		Start position `json:"start"`
+		End   position `json:"end"`
+	} `json:"range"`
+	Severity        int              `json:"severity"`
+	Code            string           `json:"code,omitempty"`
+	CodeDescription *codeDescription `json:"codeDescription,omitempty"`
+	Source          string           `json:"source"`
+	Message         string           `json:"message"`
+	// Data holds the suggested fix, which the client sends back with code
+	// action requests
+	Data *quickFix `json:"data,omitempty"`
+}
+
+type textRange struct {
+	Start position `json:"start"`
+	End   position `json:"end"`
+}
+
+type textEdit struct {
+	Range   textRange `json:"range"`
+	NewText string    `json:"newText"`
+}
+
+type quickFix struct {
+	Title string     `json:"title"`
+	Edits []textEdit `json:"edits"`
+}
+
+type codeActionParams struct {
+	TextDocument textDocumentItem `json:"textDocument"`
+	Context      struct {
+		Diagnostics []json.RawMessage `json:"diagnostics"`
+	} `json:"context"`
+}
+
+type codeAction struct {
+	Title       string            `json:"title"`
+	Kind        string            `json:"kind"`
+	Diagnostics []json.RawMessage `json:"diagnostics"`
+	Edit        workspaceEdit     `json:"edit"`
+}
+
+type workspaceEdit struct {
+	Changes map[string][]textEdit `json:"changes"`
+}
+
+type codeDescription struct {
+	Href string `json:"href"`
+}
+
+type publishDiagnosticsParams struct {
+	URI         string       `json:"uri"`
+	Diagnostics []diagnostic `json:"diagnostics"`
+}
+
+// Run serves requests until the client sends exit or closes the input.
+// It returns an error if the client exits without a shutdown request.
+func (s *Server) Run(ctx context.Context) error {
+	for {
+		body, err := s.readMessage()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		var msg message
+		if err := json.Unmarshal(body, &msg); err != nil {
+			s.reply(nil, nil, &responseError{Code: codeParseError, Message: err.Error()})
+			continue
+		}
+		if msg.Method == "exit" {
+			if !s.shutdown {
+				return fmt.Errorf("exit received before shutdown")
+			}
+			return nil
+		}
+		s.handle(ctx, msg)
+	}
+}
+
+func (s *Server) handle(ctx context.Context, msg message) {
+	switch msg.Method {
+	case "initialize":
+		s.reply(msg.ID, map[string]any{
+			"capabilities": map[string]any{
+				"codeActionProvider": map[string]any{"codeActionKinds": []string{"quickfix"}},
+				"textDocumentSync": map[string]any{
+					"openClose": true,
+					// Full document sync
+					"change": 1,
+					"save":   map[string]any{"includeText": true},
+				},
+			},
+			"serverInfo": map[string]any{
+				"name":    "runs-on-config",
+				"version": appversion.String(),
+			},
+		}, nil)
+	case "shutdown":
+		s.shutdown = true
+		s.reply(msg.ID, nil, nil)
+	case "textDocument/didOpen", "textDocument/didChange", "textDocument/didSave":
+		var params documentParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return
+		}
+		text := params.TextDocument.Text
+		if n := len(params.ContentChanges); n > 0 {
+			text = params.ContentChanges[n-1].Text
+		} else if params.Text != nil {
+			text = *params.Text
+		} else if msg.Method != "textDocument/didOpen" {
+			return
+		}
+		s.publish(ctx, params.TextDocument.URI, text)
+	case "textDocument/codeAction":
+		var params codeActionParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			s.reply(msg.ID, nil, &responseError{Code: codeInvalidParams, Message: err.Error()})
+			return
+		}
+		s.reply(msg.ID, codeActions(params), nil)
+	case "textDocument/didClose":
+		var params documentParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return
+		}
+		s.notify("textDocument/publishDiagnostics", publishDiagnosticsParams{
+			URI:         params.TextDocument.URI,
+			Diagnostics: []diagnostic{},
+		})
+	default:
+		// Requests need an answer; notifications we do not handle are ignored
+		if msg.ID != nil {
+			s.reply(msg.ID, nil, &responseError{Code: codeMethodNotFound, Message: "method not supported: " + msg.Method})
+		}
+	}
+}
+
+// publish validates a document and sends its diagnostics to the client
+func (s *Server) publish(ctx context.Context, uri, text string) {
+	path := uriPath(uri)
+	if !s.opts.AllFiles && !isConfigFile(path) {
+		return
+	}
+
+	diags, err := validate.ValidateReader(ctx, strings.NewReader(text), path)
+	if validate.CodeOf(err) == validate.CodeCanceled {
+		// The server is shutting down
+		return
+	}
+	if err != nil {
+		diags = []validate.Diagnostic{{
+			Path:     path,
+			Line:     0,
+			Column:   0,
+			Message:  err.Error(),
+			Severity: validate.SeverityError,
+		}}
+	}
+
+	result := make([]diagnostic, 0, len(diags))
+	for _, diag := range diags {
+		d := diagnostic{Source: "runs-on-config", Message: diag.Message, Code: diag.RuleID}
+		d.Severity = severityError
+		if diag.Severity == validate.SeverityWarning {
+			d.Severity = severityWarning
+		}
+		if diag.Line > 0 {
+			d.Range.Start = position{Line: diag.Line - 1, Character: max(diag.Column-1, 0)}
+			d.Range.End = position{Line: diag.Line - 1, Character: lineLength(text, diag.Line-1)}
+			if diag.EndLine > 0 && diag.EndColumn > 0 {
+				d.Range.End = position{Line: diag.EndLine - 1, Character: diag.EndColumn - 1}
+			}
+		}
+		if diag.Fix != nil && diag.Path == path {
+			d.Data = &quickFix{Title: diag.Fix.Description}
+			for _, edit := range diag.Fix.Edits {
+				d.Data.Edits = append(d.Data.Edits, textEdit{
+					Range:   textRange{Start: offsetPosition(text, edit.Offset), End: offsetPosition(text, edit.EndOffset)},
+					NewText: edit.NewText,
+				})
+			}
+		}
+		if rule, ok := validate.LookupRule(diag.RuleID); ok && rule.DocURL != "" {
+			d.CodeDescription = &codeDescription{Href: rule.DocURL}
+		}
+		result = append(result, d)
+	}
+	s.notify("textDocument/publishDiagnostics", publishDiagnosticsParams{URI: uri, Diagnostics: result})
+}
+
+// codeActions returns a quick fix for each diagnostic of the request that
+// was published with a suggested fix
+func codeActions(params codeActionParams) []codeAction {
+	actions := []codeAction{}
+	for _, raw := range params.Context.Diagnostics {
+		var d diagnostic
+		if json.Unmarshal(raw, &d) != nil || d.Data == nil || len(d.Data.Edits) == 0 {
+			continue
+		}
+		actions = append(actions, codeAction{
+			Title:       d.Data.Title,
+			Kind:        "quickfix",
+			Diagnostics: []json.RawMessage{raw},
+			Edit:        workspaceEdit{Changes: map[string][]textEdit{params.TextDocument.URI: d.Data.Edits}},
+		})
+	}
+	return actions
+}
+
+// offsetPosition returns the position of a byte offset in text, counting
+// characters like diagnostic columns
+func offsetPosition(text string, offset int) position {
+	offset = min(max(offset, 0), len(text))
+	lineStart := strings.LastIndexByte(text[:offset], '\n') + 1
+	return position{Line: strings.Count(text[:offset], "\n"), Character: utf8.RuneCountInString(text[lineStart:offset])}
+}
+
+func (s *Server) reply(id *json.RawMessage, result any, respErr *responseError) {
+	if id == nil && respErr == nil {
+		return
+	}
+	msg := message{JSONRPC: "2.0", ID: id, Error: respErr}
+	if respErr == nil {
+		// A null result must still be sent for requests such as shutdown
+		msg.Result = json.RawMessage("null")
+		if result != nil {
+			msg.Result = result
+		}
+	}
+	if id == nil {
+		null := json.RawMessage("null")
+		msg.ID = &null
+	}
+	s.write(msg)
+}
+
+func (s *Server) notify(method string, params any) {
+	data, err := json.Marshal(params)
+	if err != nil {
+		return
+	}
+	s.write(message{JSONRPC: "2.0", Method: method, Params: data})
+}
+
+func (s *Server) write(msg message) {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return
+	}
+	s.outMu.Lock()
+	defer s.outMu.Unlock()
+	fmt.Fprintf(s.out, "Content-Length: %d\r\n\r\n", len(data))
+	//nolint:errcheck // Write failures surface as EOF on the next read
+	_, _ = s.out.Write(data)
+}
+
+// readMessage reads one message framed by LSP base protocol headers
+func (s *Server) readMessage() ([]byte, error) {
+	length := -1
+	for {
+		line, err := s.in.ReadString('\n')
+		if err != nil {
+			if err == io.EOF && line == "" && length == -1 {
+				return nil, io.EOF
+			}
+			return nil, fmt.Errorf("failed to read header: %w", err)
+		}
+		line = strings.TrimRight(line, "\r\n")
+		if line == "" {
+			break
+		}
+		name, value, ok := strings.Cut(line, ":")
+		if ok && strings.EqualFold(strings.TrimSpace(name), "Content-Length") {
+			length, err = strconv.Atoi(strings.TrimSpace(value))
+			if err != nil {
+				return nil, fmt.Errorf("invalid Content-Length: %w", err)
+			}
+		}
+	}
+	if length < 0 {
+		return nil, fmt.Errorf("missing Content-Length header")
+	}
+
+	body := make([]byte, length)
+	if _, err := io.ReadFull(s.in, body); err != nil {
+		return nil, fmt.Errorf("failed to read body: %w", err)
+	}
+	return body, nil
+}
+
+// uriPath converts a file:// URI to a local path, falling back to the URI
+func uriPath(uri string) string {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != "file" {
+		return uri
+	}
+	return filepath.FromSlash(u.Path)
+}
+
+func isConfigFile(path string) bool {
+	name := filepath.Base(path)
+	return name == "runs-on.yml" || name == "runs-on.yaml"
+}
+
+// lineLength returns the length of a 0-based line of text, used to underline
+// the rest of the line from the diagnostic position
+func lineLength(text string, line int) int {
+	lines := strings.Split(text, "\n")
+	if line >= len(lines) {
+		return 0
+	}
+	return len(strings.TrimRight(lines[line], "\r"))
+}
@@ -0,0 +1,226 @@
+package lsp_test
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/runs-on/config/internal/lsp"
+)
+
+type rpcMessage struct {
+	ID     *int            `json:"id"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params"`
+	Result json.RawMessage `json:"result"`
+	Error  *struct {
+		Code int `json:"code"`
+	} `json:"error"`
+}
+
+type publishedDiagnostics struct {
+	URI         string `json:"uri"`
+	Diagnostics []struct {
+		Range struct {
+			Start struct {
+				Line      int `json:"line"`
+				Character int `json:"character"`
+			} `json:"start"`
+		} `json:"range"`
+		Severity int    `json:"severity"`
+		Code     string `json:"code"`
+		Message  string `json:"message"`
+	} `json:"diagnostics"`
+}
+
+func frame(t *testing.T, messages ...map[string]any) io.Reader {
+	t.Helper()
+	var buf bytes.Buffer
+	for _, msg := range messages {
+		msg["jsonrpc"] = "2.0"
+		data, err := json.Marshal(msg)
+		if err != nil {
+			t.Fatalf("Failed to encode message: %v", err)
+		}
+		fmt.Fprintf(&buf, "Content-Length: %d\r\n\r\n%s", len(data), data)
+	}
+	return &buf
+}
+
+func readAll(t *testing.T, out *bytes.Buffer) []rpcMessage {
+	t.Helper()
+	var messages []rpcMessage
+	reader := bufio.NewReader(out)
+	for {
+		header, err := reader.ReadString('\n')
+		if err == io.EOF {
+			return messages
+		}
+		length, err := strconv.Atoi(strings.TrimSpace(strings.TrimPrefix(header, "Content-Length:")))
+		if err != nil {
+			t.Fatalf("Invalid header %q", header)
+		}
+		if _, err := reader.ReadString('\n'); err != nil {
+			t.Fatalf("Missing header terminator: %v", err)
+		}
+		body := make([]byte, length)
+		if _, err := io.ReadFull(reader, body); err != nil {
+			t.Fatalf("Failed to read body: %v", err)
+		}
+		var msg rpcMessage
+		if err := json.Unmarshal(body, &msg); err != nil {
+			t.Fatalf("Invalid message %s: %v", body, err)
+		}
+		messages = append(messages, msg)
+	}
+}
+
+func published(t *testing.T, messages []rpcMessage) []publishedDiagnostics {
+	t.Helper()
+	var result []publishedDiagnostics
+	for _, msg := range messages {
+		if msg.Method != "textDocument/publishDiagnostics" {
+			continue
+		}
+		var params publishedDiagnostics
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			t.Fatalf("Invalid diagnostics: %v", err)
+		}
+		result = append(result, params)
+	}
+	return result
+}
+
+func TestSession(t *testing.T) {
+	const uri = "file:///repo/.github/runs-on.yml"
+	invalid := "runners:\n  small:\n    ssh: true\n"
+	valid := "runners:\n  small:\n    cpu: [2]\n"
+
+	in := frame(t,
+		map[string]any{"id": 1, "method": "initialize", "params": map[string]any{}},
+		map[string]any{"method": "initialized", "params": map[string]any{}},
+		map[string]any{"method": "textDocument/didOpen", "params": map[string]any{
+			"textDocument": map[string]any{"uri": uri, "languageId": "yaml", "version": 1, "text": invalid},
+		}},
+		map[string]any{"method": "textDocument/didChange", "params": map[string]any{
+			"textDocument":   map[string]any{"uri": uri, "version": 2},
+			"contentChanges": []map[string]any{{"text": valid}},
+		}},
+		map[string]any{"method": "textDocument/didOpen", "params": map[string]any{
+			"textDocument": map[string]any{"uri": "file:///repo/other.yml", "languageId": "yaml", "version": 1, "text": "a: ["},
+		}},
+		map[string]any{"method": "textDocument/didClose", "params": map[string]any{
+			"textDocument": map[string]any{"uri": uri},
+		}},
+		map[string]any{"id": 2, "method": "textDocument/hover", "params": map[string]any{}},
+		map[string]any{"id": 3, "method": "shutdown"},
+		map[string]any{"method": "exit"},
+	)
+	var out bytes.Buffer
+	if err := lsp.New(in, &out, lsp.Options{}).Run(context.Background()); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	messages := readAll(t, &out)
+
+	if len(messages) == 0 || messages[0].ID == nil || *messages[0].ID != 1 || !strings.Contains(string(messages[0].Result), "textDocumentSync") {
+		t.Fatalf("Expected an initialize response first, got %+v", messages)
+	}
+
+	diagnostics := published(t, messages)
+	if len(diagnostics) != 3 {
+		t.Fatalf("Expected diagnostics for open, change and close of runs-on.yml only, got %+v", diagnostics)
+	}
+	opened := diagnostics[0]
+	if opened.URI != uri || len(opened.Diagnostics) != 1 {
+		t.Fatalf("Expected one diagnostic on open, got %+v", opened)
+	}
+	if d := opened.Diagnostics[0]; d.Severity != 2 || d.Code != "public-ssh" || d.Range.Start.Line != 2 || d.Range.Start.Character != 4 {
+		t.Errorf("Unexpected diagnostic: %+v", d)
+	}
+	if len(diagnostics[1].Diagnostics) != 0 || len(diagnostics[2].Diagnostics) != 0 {
+		t.Errorf("Expected diagnostics to be cleared after the fix and on close, got %+v", diagnostics[1:])
+	}
+
+	var hover, shutdown *rpcMessage
+	for i, msg := range messages {
+		if msg.ID != nil && *msg.ID == 2 {
+			hover = &messages[i]
+		}
+		if msg.ID != nil && *msg.ID == 3 {
+			shutdown = &messages[i]
+		}
+	}
+	if hover == nil || hover.Error == nil || hover.Error.Code != -32601 {
+		t.Errorf("Expected method not found for hover, got %+v", hover)
+	}
+	if shutdown == nil || shutdown.Error != nil {
+		t.Errorf("Expected a shutdown response, got %+v", shutdown)
+	}
+}
+
+func TestCodeAction(t *testing.T) {
+	const uri = "file:///repo/.github/runs-on.yml"
+	text := "runners:\n  small:\n    cpu: [2]\n    disk: default\n"
+	in := frame(t,
+		map[string]any{"method": "textDocument/didOpen", "params": map[string]any{
+			"textDocument": map[string]any{"uri": uri, "languageId": "yaml", "version": 1, "text": text},
+		}},
+	)
+	var out bytes.Buffer
+	if err := lsp.New(in, &out, lsp.Options{}).Run(context.Background()); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	var params struct {
+		Diagnostics []json.RawMessage `json:"diagnostics"`
+	}
+	if messages := readAll(t, &out); len(messages) != 1 || json.Unmarshal(messages[0].Params, &params) != nil || len(params.Diagnostics) != 1 {
+		t.Fatalf("Expected one published diagnostic, got %+v", messages)
+	}
+
+	// The client sends the diagnostic back, with its data, to get the fix
+	in = frame(t,
+		map[string]any{"id": 1, "method": "textDocument/codeAction", "params": map[string]any{
+			"textDocument": map[string]any{"uri": uri},
+			"range":        map[string]any{"start": map[string]any{"line": 3, "character": 4}, "end": map[string]any{"line": 3, "character": 4}},
+			"context":      map[string]any{"diagnostics": params.Diagnostics},
+		}},
+	)
+	out.Reset()
+	if err := lsp.New(in, &out, lsp.Options{}).Run(context.Background()); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	var actions []struct {
+		Title string `json:"title"`
+		Kind  string `json:"kind"`
+		Edit  struct {
+			Changes map[string][]struct {
+				Range struct {
+					Start, End struct{ Line, Character int }
+				} `json:"range"`
+				NewText string `json:"newText"`
+			} `json:"changes"`
+		} `json:"edit"`
+	}
+	messages := readAll(t, &out)
+	if len(messages) != 1 || json.Unmarshal(messages[0].Result, &actions) != nil || len(actions) != 1 {
+		t.Fatalf("Expected one code action, got %+v", messages)
+	}
+	edits := actions[0].Edit.Changes[uri]
+	if actions[0].Kind != "quickfix" || len(edits) != 1 || edits[0].NewText != "" ||
+		edits[0].Range.Start.Line != 3 || edits[0].Range.Start.Character != 0 || edits[0].Range.End.Line != 4 || edits[0].Range.End.Character != 0 {
+		t.Errorf("Expected a quick fix removing the disk line, got %+v", actions)
+	}
+}
+
+func TestExitWithoutShutdown(t *testing.T) {
+	in := frame(t, map[string]any{"method": "exit"})
+	if err := lsp.New(in, io.Discard, lsp.Options{}).Run(context.Background()); err == nil {
+		t.Error("Expected an error when exiting without shutdown")
+	}
+}
@@ -0,0 +1,249 @@
+// Package server exposes the validator over HTTP
+package server
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/runs-on/config/internal/capabilities"
+	"github.com/runs-on/config/pkg/validate"
+)
+
+// DefaultMaxBodySize is the default limit on the size of submitted configs
+const DefaultMaxBodySize = 1 << 20
+
+// Options configures the HTTP handler
+type Options struct {
+	// MaxBodySize limits the size of request bodies in bytes. Zero means
+	// DefaultMaxBodySize.
+	MaxBodySize int64
+	// SchemaPath is a CUE or JSON schema file, or a named schema version, to
+	// validate against instead of the embedded schema (see
+	// validate.LoadSchema). It is read again on every Reload.
+	SchemaPath string
+	// AdminToken enables POST /admin/reload for requests carrying it as a
+	// bearer token. The endpoint is disabled when empty.
+	AdminToken string
+	// Timeout limits the time spent validating a request. Zero means no
+	// limit other than the request's own context.
+	Timeout time.Duration
+}
+
+// Server serves the validation API. Its schema can be reloaded while it is
+// serving requests.
+type Server struct {
+	opts    Options
+	handler http.Handler
+	schema  atomic.Pointer[loadedSchema]
+}
+
+// loadedSchema is the schema in use, swapped atomically on reload
+type loadedSchema struct {
+	validator *validate.Validator
+	path      string
+	loadedAt  time.Time
+}
+
+// ReloadResponse is the body returned by POST /admin/reload
+type ReloadResponse struct {
+	Schema   string    `json:"schema"`
+	LoadedAt time.Time `json:"loaded_at"`
+}
+
+// Diagnostic is the JSON form of a validate.Diagnostic
+type Diagnostic struct {
+	Path      string `json:"path"`
+	Line      int    `json:"line,omitempty"`
+	Column    int    `json:"column,omitempty"`
+	EndLine   int    `json:"endLine,omitempty"`
+	EndColumn int    `json:"endColumn,omitempty"`
+	Offset    int    `json:"offset,omitempty"`
+	EndOffset int    `json:"endOffset,omitempty"`
+	Message   string `json:"message"`
+	Severity  string `json:"severity"`
+	Rule      string `json:"rule,omitempty"`
+	FieldPath string `json:"fieldPath,omitempty"`
+}
+
+// ValidateResponse is the body returned by POST /validate. Valid is false
+// when there is at least one error; warnings do not make a config invalid.
+type ValidateResponse struct {
+	Valid       bool         `json:"valid"`
+	Diagnostics []Diagnostic `json:"diagnostics"`
+}
+
+// ErrorResponse is the body returned when a request fails
+type ErrorResponse struct {
+	Error string `json:"error"`
+	// Code classifies validation failures, see validate.ErrorCode
+	Code validate.ErrorCode `json:"code,omitempty"`
+}
+
+// New returns a server for the validation API:
+//
+//	POST /validate       validate the YAML request body (?name= sets the file name in diagnostics)
+//	GET  /healthz        liveness check
+//	GET  /capabilities   supported schema versions, rules, formats and build info
+//	POST /admin/reload   reload the schema (only when Options.AdminToken is set)
+//
+// It fails if the schema cannot be loaded.
+func New(opts Options) (*Server, error) {
+	if opts.MaxBodySize <= 0 {
+		opts.MaxBodySize = DefaultMaxBodySize
+	}
+	s := &Server{opts: opts}
+	if _, err := s.Reload(); err != nil {
+		return nil, err
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /validate", s.handleValidate)
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		fmt.Fprintln(w, "ok")
+	})
+	mux.HandleFunc("GET /capabilities", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, capabilities.Get())
+	})
+	if opts.AdminToken != "" {
+		mux.HandleFunc("POST /admin/reload", s.handleReload)
+	}
+	s.handler = mux
+	return s, nil
+}
+
+// ServeHTTP implements http.Handler
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.handler.ServeHTTP(w, r)
+}
+
+// Reload reads and compiles the schema, then swaps it in for new requests.
+// Requests in flight finish with the previous schema. If the new schema is
+// invalid, the previous one stays in use and an error is returned.
+func (s *Server) Reload() (ReloadResponse, error) {
+	loaded := &loadedSchema{path: "embedded", loadedAt: time.Now().UTC()}
+	// Submitted configs are untrusted: never read local files on their behalf
+	opts := validate.Options{DisableLocalExtends: true}
+	if s.opts.SchemaPath != "" {
+		source, err := validate.LoadSchema(s.opts.SchemaPath)
+		if err != nil {
+			return ReloadResponse{}, err
+		}
+		opts.Schema = source
+		loaded.path = s.opts.SchemaPath
+	}
+	validator, err := validate.NewWithOptions(opts)
+	if err != nil {
+		return ReloadResponse{}, err
+	}
+	loaded.validator = validator
+	s.schema.Store(loaded)
+	return ReloadResponse{Schema: loaded.path, LoadedAt: loaded.loadedAt}, nil
+}
+
+func (s *Server) handleReload(w http.ResponseWriter, r *http.Request) {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(s.opts.AdminToken)) != 1 {
+		writeJSON(w, http.StatusUnauthorized, ErrorResponse{Error: "invalid admin token"})
+		return
+	}
+	response, err := s.Reload()
+	if err != nil {
+		writeJSON(w, http.StatusUnprocessableEntity, ErrorResponse{Error: err.Error(), Code: validate.CodeOf(err)})
+		return
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+func (s *Server) handleValidate(w http.ResponseWriter, r *http.Request) {
+	name := r.URL.Query().Get("name")
+	if name == "" {
+		name = "runs-on.yml"
+	}
+
+	ctx := r.Context()
+	if s.opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.opts.Timeout)
+		defer cancel()
+	}
+	body := http.MaxBytesReader(w, r.Body, s.opts.MaxBodySize)
+	diags, err := s.schema.Load().validator.ValidateReader(ctx, body, name)
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			writeJSON(w, http.StatusRequestEntityTooLarge, ErrorResponse{
+				Error: fmt.Sprintf("request body exceeds %d bytes", maxBytesErr.Limit),
+			})
+			return
+		}
+		if errors.Is(err, context.DeadlineExceeded) {
+			writeJSON(w, http.StatusServiceUnavailable, ErrorResponse{Error: err.Error(), Code: validate.CodeOf(err)})
+			return
+		}
+		writeJSON(w, http.StatusInternalServerError, ErrorResponse{Error: err.Error(), Code: validate.CodeOf(err)})
+		return
+	}
+
+	if catalog := requestCatalog(r); catalog != nil {
+		diags = validate.Localize(diags, catalog)
+	}
+
+	writeJSON(w, http.StatusOK, NewValidateResponse(diags))
+}
+
+// NewValidateResponse returns the response body for the diagnostics of a
+// config
+func NewValidateResponse(diags []validate.Diagnostic) ValidateResponse {
+	response := ValidateResponse{Valid: true, Diagnostics: make([]Diagnostic, len(diags))}
+	for i, diag := range diags {
+		if diag.Severity == validate.SeverityError {
+			response.Valid = false
+		}
+		response.Diagnostics[i] = Diagnostic{
+			Path:      diag.Path,
+			Line:      diag.Line,
+			Column:    diag.Column,
+			EndLine:   diag.EndLine,
+			EndColumn: diag.EndColumn,
+			Offset:    diag.Offset,
+			EndOffset: diag.EndOffset,
+			Message:   diag.Message,
+			Severity:  string(diag.Severity),
+			Rule:      diag.RuleID,
+			FieldPath: diag.FieldPath,
+		}
+	}
+	return response
+}
+
+// requestCatalog returns the built-in catalog for the locale query parameter
+// of a request, or else for the first language of its Accept-Language header
+// that has one, or nil
+func requestCatalog(r *http.Request) validate.Catalog {
+	if locale := r.URL.Query().Get("locale"); locale != "" {
+		catalog, _ := validate.CatalogFor(locale)
+		return catalog
+	}
+	for language := range strings.SplitSeq(r.Header.Get("Accept-Language"), ",") {
+		tag, _, _ := strings.Cut(strings.TrimSpace(language), ";")
+		if catalog, ok := validate.CatalogFor(tag); ok {
+			return catalog
+		}
+	}
+	return nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	//nolint:errcheck // The client has gone away if the response cannot be written
+	_ = json.NewEncoder(w).Encode(value)
+}
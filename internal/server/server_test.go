@@ -0,0 +1,213 @@
+package server_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/runs-on/config/internal/capabilities"
+	"github.com/runs-on/config/internal/server"
+)
+
+func newServer(t *testing.T, opts server.Options) *server.Server {
+	t.Helper()
+	srv, err := server.New(opts)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	return srv
+}
+
+func post(t *testing.T, handler http.Handler, target, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, target, strings.NewReader(body))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec
+}
+
+func decode(t *testing.T, rec *httptest.ResponseRecorder) server.ValidateResponse {
+	t.Helper()
+	var response server.ValidateResponse
+	if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	return response
+}
+
+func TestValidate(t *testing.T) {
+	handler := newServer(t, server.Options{})
+
+	rec := post(t, handler, "/validate", "runners:\n  small:\n    cpu: [2]\n")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", rec.Code, rec.Body)
+	}
+	if response := decode(t, rec); !response.Valid || len(response.Diagnostics) != 0 {
+		t.Errorf("Expected a valid config, got %+v", response)
+	}
+
+	rec = post(t, handler, "/validate?name=.github/runs-on.yml", `pools:
+  main:
+    runner: missing
+    schedule:
+      - name: default
+        hot: 1
+        stopped: 1
+runners: {}
+`)
+	response := decode(t, rec)
+	if response.Valid || len(response.Diagnostics) == 0 {
+		t.Fatalf("Expected an invalid config, got %+v", response)
+	}
+	diag := response.Diagnostics[0]
+	if diag.Path != ".github/runs-on.yml" || diag.Severity != "error" || diag.Rule == "" {
+		t.Errorf("Unexpected diagnostic: %+v", diag)
+	}
+}
+
+func TestValidate_WarningsOnly(t *testing.T) {
+	rec := post(t, newServer(t, server.Options{}), "/validate", "runners:\n  small:\n    ssh: true\n")
+	response := decode(t, rec)
+	if !response.Valid || len(response.Diagnostics) != 1 || response.Diagnostics[0].Severity != "warning" {
+		t.Errorf("Expected a valid config with one warning, got %+v", response)
+	}
+}
+
+func TestValidate_Locale(t *testing.T) {
+	handler := newServer(t, server.Options{})
+	const config = "runners:\n  small:\n    ssh: true\n"
+	const french = "le runner 'small' active ssh sur une adresse IP publique ; définissez 'private: true' ou désactivez ssh"
+
+	rec := post(t, handler, "/validate?locale=fr", config)
+	if response := decode(t, rec); len(response.Diagnostics) != 1 || response.Diagnostics[0].Message != french || response.Diagnostics[0].Rule != "public-ssh" {
+		t.Errorf("Expected a French message, got %+v", response)
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/validate", strings.NewReader(config))
+	req.Header.Set("Accept-Language", "xx, fr-CH;q=0.9, en;q=0.8")
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if response := decode(t, rec); len(response.Diagnostics) != 1 || response.Diagnostics[0].Message != french {
+		t.Errorf("Expected a French message from Accept-Language, got %+v", response)
+	}
+
+	rec = post(t, handler, "/validate?locale=xx", config)
+	if response := decode(t, rec); len(response.Diagnostics) != 1 || !strings.Contains(response.Diagnostics[0].Message, "enables ssh") {
+		t.Errorf("Expected an English message for an unknown locale, got %+v", response)
+	}
+}
+
+func TestValidate_NoLocalFileAccess(t *testing.T) {
+	rec := post(t, newServer(t, server.Options{}), "/validate?name=../../schema/testdata/valid/runs-on.yml",
+		"_extends: ./shared/base-runners.yml\n")
+	response := decode(t, rec)
+	if !response.Valid || len(response.Diagnostics) != 0 {
+		t.Errorf("Expected local _extends to be skipped, got %+v", response)
+	}
+}
+
+func TestValidate_BodyTooLarge(t *testing.T) {
+	handler := newServer(t, server.Options{MaxBodySize: 16})
+	rec := post(t, handler, "/validate", "runners:\n  small:\n    cpu: [2]\n")
+	if rec.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("Expected 413, got %d: %s", rec.Code, rec.Body)
+	}
+}
+
+func TestRouting(t *testing.T) {
+	handler := newServer(t, server.Options{})
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/validate", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("Expected 405 for GET /validate, got %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("Expected 200 for /healthz, got %d", rec.Code)
+	}
+}
+
+func TestCapabilities(t *testing.T) {
+	handler := newServer(t, server.Options{})
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/capabilities", nil))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d: %s", rec.Code, rec.Body)
+	}
+	var response capabilities.Capabilities
+	if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if len(response.Rules) == 0 || len(response.SchemaVersions) == 0 || len(response.OutputFormats) == 0 {
+		t.Errorf("Expected rules, schema versions and output formats, got %+v", response)
+	}
+	if !slices.Contains(response.Fixes, "admins-order") {
+		t.Errorf("Expected admins-order to be fixable, got %v", response.Fixes)
+	}
+}
+
+func TestReload(t *testing.T) {
+	schemaPath := filepath.Join(t.TempDir(), "schema.cue")
+	writeSchema := func(src string) {
+		if err := os.WriteFile(schemaPath, []byte(src), 0o644); err != nil {
+			t.Fatalf("Failed to write schema: %v", err)
+		}
+	}
+	writeSchema("#Config: {...}\n")
+	srv := newServer(t, server.Options{SchemaPath: schemaPath, AdminToken: "secret"})
+	config := "runners:\n  small:\n    cpu: [2]\n"
+
+	if response := decode(t, post(t, srv, "/validate", config)); !response.Valid {
+		t.Fatalf("Expected the permissive schema to accept the config, got %+v", response)
+	}
+
+	// Swap in a schema that rejects runners
+	writeSchema("#Config: close({admins?: [...string]})\n")
+	reload := func(token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/admin/reload", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		srv.ServeHTTP(rec, req)
+		return rec
+	}
+	if rec := reload("wrong"); rec.Code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 for a wrong token, got %d", rec.Code)
+	}
+	if rec := reload("secret"); rec.Code != http.StatusOK {
+		t.Fatalf("Expected reload to succeed, got %d: %s", rec.Code, rec.Body)
+	}
+	if response := decode(t, post(t, srv, "/validate", config)); response.Valid {
+		t.Error("Expected the reloaded schema to reject runners")
+	}
+
+	// A broken schema is rejected and the previous one stays in use
+	writeSchema("#Config: {")
+	if rec := reload("secret"); rec.Code != http.StatusUnprocessableEntity {
+		t.Errorf("Expected 422 for a broken schema, got %d", rec.Code)
+	}
+	if response := decode(t, post(t, srv, "/validate", config)); response.Valid {
+		t.Error("Expected the previous schema to stay in use")
+	}
+}
+
+func TestReload_DisabledWithoutToken(t *testing.T) {
+	rec := post(t, newServer(t, server.Options{}), "/admin/reload", "")
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 without an admin token, got %d", rec.Code)
+	}
+}
+
+func TestNew_InvalidSchema(t *testing.T) {
+	if _, err := server.New(server.Options{SchemaPath: filepath.Join(t.TempDir(), "missing.cue")}); err == nil {
+		t.Error("Expected an error for a missing schema")
+	}
+}
@@ -0,0 +1,76 @@
+//go:build s3
+
+package store
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// S3 stores artifacts as objects in an S3 bucket, under an optional key
+// prefix
+type S3 struct {
+	client *s3.Client
+	bucket string
+	prefix string
+}
+
+// NewS3 returns a store for the bucket, using the default AWS credential
+// chain (environment, shared config, instance role)
+func NewS3(ctx context.Context, bucket, prefix string) (*S3, error) {
+	cfg, err := config.LoadDefaultConfig(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load AWS config: %w", err)
+	}
+	return &S3{client: s3.NewFromConfig(cfg), bucket: bucket, prefix: prefix}, nil
+}
+
+func openS3(ctx context.Context, bucket, prefix string) (Store, error) {
+	return NewS3(ctx, bucket, prefix)
+}
+
+// Get reads the object stored under key
+func (s *S3) Get(ctx context.Context, key string) ([]byte, error) {
+	out, err := s.client.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(s.key(key)),
+	})
+	if err != nil {
+		var noSuchKey *types.NoSuchKey
+		if errors.As(err, &noSuchKey) {
+			return nil, ErrNotFound
+		}
+		return nil, fmt.Errorf("failed to get s3://%s/%s: %w", s.bucket, s.key(key), err)
+	}
+	defer func() {
+		//nolint:errcheck // Close errors on response bodies are safe to ignore
+		_ = out.Body.Close()
+	}()
+	return io.ReadAll(out.Body)
+}
+
+// Put stores data under key
+func (s *S3) Put(ctx context.Context, key string, data []byte) error {
+	_, err := s.client.PutObject(ctx, &s3.PutObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(s.key(key)),
+		Body:   bytes.NewReader(data),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to put s3://%s/%s: %w", s.bucket, s.key(key), err)
+	}
+	return nil
+}
+
+func (s *S3) key(key string) string {
+	return path.Join(s.prefix, key)
+}
@@ -0,0 +1,12 @@
+//go:build !s3
+
+package store
+
+import (
+	"context"
+	"fmt"
+)
+
+func openS3(_ context.Context, bucket, prefix string) (Store, error) {
+	return nil, fmt.Errorf("cannot open s3://%s/%s: built without S3 support (build with -tags s3)", bucket, prefix)
+}
@@ -0,0 +1,16 @@
+//go:build !s3
+
+package store_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/runs-on/config/internal/store"
+)
+
+func TestOpen_S3Disabled(t *testing.T) {
+	if _, err := store.Open(context.Background(), "s3://bucket/prefix"); err == nil {
+		t.Error("Expected an error for an S3 location without the s3 build tag")
+	}
+}
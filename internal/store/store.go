@@ -0,0 +1,38 @@
+// Package store persists artifacts, such as lint cache entries, in a local
+// directory or in an S3 bucket, so that ephemeral CI runners can share them.
+// S3 support is only built with the s3 build tag, so that programs importing
+// this module do not link the AWS SDK.
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrNotFound is returned by Get when no artifact is stored under the key
+var ErrNotFound = errors.New("artifact not found")
+
+// Store reads and writes artifacts by key. Keys are slash-separated relative
+// paths.
+type Store interface {
+	Get(ctx context.Context, key string) ([]byte, error)
+	Put(ctx context.Context, key string, data []byte) error
+}
+
+// Open returns the store at location: an s3://bucket/prefix URL, or a local
+// directory that is created when the first artifact is written
+func Open(ctx context.Context, location string) (Store, error) {
+	if location == "" {
+		return nil, errors.New("empty store location")
+	}
+	if rest, ok := strings.CutPrefix(location, "s3://"); ok {
+		bucket, prefix, _ := strings.Cut(rest, "/")
+		if bucket == "" {
+			return nil, fmt.Errorf("invalid S3 location %q: missing bucket", location)
+		}
+		return openS3(ctx, bucket, strings.Trim(prefix, "/"))
+	}
+	return Dir(location), nil
+}
@@ -0,0 +1,39 @@
+package store_test
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/runs-on/config/internal/store"
+)
+
+func TestDir(t *testing.T) {
+	ctx := context.Background()
+	dir := filepath.Join(t.TempDir(), "artifacts")
+
+	s, err := store.Open(ctx, dir)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	if _, err := s.Get(ctx, "lint/abc"); !errors.Is(err, store.ErrNotFound) {
+		t.Fatalf("Expected ErrNotFound, got %v", err)
+	}
+	if err := s.Put(ctx, "lint/abc", []byte("ok")); err != nil {
+		t.Fatalf("Put failed: %v", err)
+	}
+	data, err := s.Get(ctx, "lint/abc")
+	if err != nil || string(data) != "ok" {
+		t.Errorf("Expected the stored artifact, got %q, %v", data, err)
+	}
+}
+
+func TestOpen_InvalidS3(t *testing.T) {
+	if _, err := store.Open(context.Background(), "s3:///prefix"); err == nil {
+		t.Error("Expected an error for an S3 location without a bucket")
+	}
+	if _, err := store.Open(context.Background(), ""); err == nil {
+		t.Error("Expected an error for an empty location")
+	}
+}
@@ -0,0 +1,55 @@
+// Package symbols provides the status markers of text output. Besides the
+// default Unicode glyphs, there are ASCII markers for terminals and tools that
+// mangle them, and no markers at all for screen readers. Output never relies
+// on the markers alone: the text next to them states the status too.
+package symbols
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Set is a set of status markers
+type Set struct {
+	Name    string
+	OK      string
+	Error   string
+	Warning string
+}
+
+var (
+	Unicode = Set{Name: "unicode", OK: "✓", Error: "✗", Warning: "⚠"}
+	ASCII   = Set{Name: "ascii", OK: "[OK]", Error: "[ERROR]", Warning: "[WARN]"}
+	None    = Set{Name: "none"}
+)
+
+// Sets lists the available sets, default first
+var Sets = []Set{Unicode, ASCII, None}
+
+// Names returns the names of Sets
+func Names() []string {
+	names := make([]string, len(Sets))
+	for i, set := range Sets {
+		names[i] = set.Name
+	}
+	return names
+}
+
+// Parse returns the set with the given name
+func Parse(name string) (Set, error) {
+	for _, set := range Sets {
+		if set.Name == name {
+			return set, nil
+		}
+	}
+	return Set{}, fmt.Errorf("invalid symbols %q (valid: %s)", name, strings.Join(Names(), ", "))
+}
+
+// Mark prefixes text with marker and a space, or returns text unchanged if
+// marker is empty
+func Mark(marker, text string) string {
+	if marker == "" {
+		return text
+	}
+	return marker + " " + text
+}
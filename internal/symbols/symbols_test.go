@@ -0,0 +1,28 @@
+package symbols_test
+
+import (
+	"testing"
+
+	"github.com/runs-on/config/internal/symbols"
+)
+
+func TestParse(t *testing.T) {
+	for _, set := range symbols.Sets {
+		parsed, err := symbols.Parse(set.Name)
+		if err != nil || parsed != set {
+			t.Errorf("Expected %q to parse as %+v, got %+v, %v", set.Name, set, parsed, err)
+		}
+	}
+	if _, err := symbols.Parse("emoji"); err == nil {
+		t.Error("Expected an error for an unknown set")
+	}
+}
+
+func TestMark(t *testing.T) {
+	if got := symbols.Mark(symbols.ASCII.Error, "Found 1 error(s)"); got != "[ERROR] Found 1 error(s)" {
+		t.Errorf("Unexpected marked text %q", got)
+	}
+	if got := symbols.Mark(symbols.None.Error, "Found 1 error(s)"); got != "Found 1 error(s)" {
+		t.Errorf("Expected no marker, got %q", got)
+	}
+}
@@ -0,0 +1,153 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/runs-on/config/pkg/validate"
+	"gopkg.in/yaml.v3"
+)
+
+// field is a key and value of a mapping node
+type field struct {
+	key   *yaml.Node
+	value *yaml.Node
+}
+
+// rootMapping returns the top-level mapping of a parsed document, or nil
+func rootMapping(doc *yaml.Node) *yaml.Node {
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return nil
+	}
+	return doc.Content[0]
+}
+
+// mappingFields returns the fields of a mapping node in document order
+func mappingFields(n *yaml.Node) []field {
+	if n == nil || n.Kind != yaml.MappingNode {
+		return nil
+	}
+	fields := make([]field, 0, len(n.Content)/2)
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		fields = append(fields, field{key: n.Content[i], value: n.Content[i+1]})
+	}
+	return fields
+}
+
+// entryNames returns the names of the entries of a section, skipping hidden
+// keys such as x-defaults
+func entryNames(root *yaml.Node, section string) []string {
+	_, value := lookup(root, section)
+	var names []string
+	for _, field := range mappingFields(resolveAlias(value)) {
+		if !strings.HasPrefix(field.key.Value, "x-") {
+			names = append(names, field.key.Value)
+		}
+	}
+	return names
+}
+
+// entryNodes returns the key and value nodes of a section entry, or nils
+func entryNodes(root *yaml.Node, section, name string) (*yaml.Node, *yaml.Node) {
+	_, value := lookup(root, section)
+	return lookup(resolveAlias(value), name)
+}
+
+func lookup(n *yaml.Node, key string) (*yaml.Node, *yaml.Node) {
+	for _, field := range mappingFields(n) {
+		if field.key.Value == key {
+			return field.key, field.value
+		}
+	}
+	return nil, nil
+}
+
+// setField sets or adds a field of a mapping node
+func setField(n *yaml.Node, key string, value *yaml.Node) {
+	if k, _ := lookup(n, key); k != nil {
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			if n.Content[i] == k {
+				n.Content[i+1] = value
+			}
+		}
+		return
+	}
+	n.Content = append(n.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, value)
+}
+
+// deleteField removes a field of a mapping node
+func deleteField(n *yaml.Node, key string) {
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if n.Content[i].Value == key {
+			n.Content = append(n.Content[:i], n.Content[i+2:]...)
+			return
+		}
+	}
+}
+
+// belongsTo reports whether a diagnostic is about a section entry: it falls
+// within the entry's lines or, without a line, names the entry
+func belongsTo(diag validate.Diagnostic, section, name string, key, value *yaml.Node) bool {
+	if key == nil {
+		return false
+	}
+	if diag.Line > 0 {
+		return diag.Line >= key.Line && diag.Line <= lastLine(value)
+	}
+	// Schema errors name the path; other checks name the entry, e.g.
+	// "runner 'small' ..."
+	path := section + "." + name
+	entry := fmt.Sprintf("%s '%s'", strings.TrimSuffix(section, "s"), name)
+	return strings.Contains(diag.Message, path+".") || strings.Contains(diag.Message, path+":") ||
+		strings.Contains(diag.Message, entry)
+}
+
+// summarize renders a value on one line
+func summarize(n *yaml.Node) string {
+	switch n.Kind {
+	case yaml.AliasNode:
+		return "*" + n.Value
+	case yaml.ScalarNode:
+		value, _, multiline := strings.Cut(n.Value, "\n")
+		if n.Tag == "!!str" && (value == "" || strings.ContainsAny(value, ":#") || value != strings.TrimSpace(value)) {
+			value = fmt.Sprintf("%q", value)
+		}
+		if multiline {
+			value += " …"
+		}
+		return value
+	case yaml.SequenceNode:
+		items := make([]string, len(n.Content))
+		for i, item := range n.Content {
+			if item.Kind != yaml.ScalarNode && item.Kind != yaml.AliasNode {
+				return fmt.Sprintf("(%d items)", len(n.Content))
+			}
+			items[i] = summarize(item)
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	case yaml.MappingNode:
+		return fmt.Sprintf("(%d fields)", len(n.Content)/2)
+	}
+	return ""
+}
+
+// resolveAlias returns the node an alias refers to, or n itself
+func resolveAlias(n *yaml.Node) *yaml.Node {
+	for n != nil && n.Kind == yaml.AliasNode && n.Alias != nil {
+		n = n.Alias
+	}
+	return n
+}
+
+// lastLine returns the last line spanned by a node
+func lastLine(n *yaml.Node) int {
+	last := n.Line + strings.Count(strings.TrimRight(n.Value, "\n"), "\n")
+	if n.Style&(yaml.LiteralStyle|yaml.FoldedStyle) != 0 {
+		// Block scalar content starts on the line after the indicator
+		last++
+	}
+	for _, child := range n.Content {
+		last = max(last, lastLine(child))
+	}
+	return last
+}
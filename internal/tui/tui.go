@@ -0,0 +1,467 @@
+// Package tui implements an interactive terminal editor for runs-on.yml
+// files: browse runners, images and pools, see their diagnostics inline and
+// edit fields, picking values from the schema where it lists them.
+//
+// The interface is line-based (numbered menus read from the input), so it
+// works in any terminal and can be scripted.
+package tui
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/runs-on/config/internal/symbols"
+	"github.com/runs-on/config/pkg/format"
+	"github.com/runs-on/config/pkg/validate"
+	"gopkg.in/yaml.v3"
+)
+
+// sections are the config sections browsed, in display order
+var sections = []string{"runners", "images", "pools"}
+
+// errQuit ends a session when the input is exhausted
+var errQuit = errors.New("quit")
+
+// Session is an editing session for one config file
+type Session struct {
+	path  string
+	src   []byte
+	diags []validate.Diagnostic
+	specs map[string][]validate.FieldSpec
+	dirty bool
+
+	symbols symbols.Set
+
+	ctx context.Context
+	in  *bufio.Scanner
+	out io.Writer
+}
+
+// Options configures a session
+type Options struct {
+	// Symbols are the status markers shown next to diagnostics. The zero
+	// value means symbols.Unicode.
+	Symbols symbols.Set
+}
+
+// entryRef identifies an entry of a section
+type entryRef struct {
+	section string
+	name    string
+}
+
+// New reads the config at path and validates it. Commands are read from in,
+// one per line, and the interface is written to out.
+func New(ctx context.Context, path string, in io.Reader, out io.Writer, opts Options) (*Session, error) {
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if opts.Symbols.Name == "" {
+		opts.Symbols = symbols.Unicode
+	}
+	s := &Session{
+		path:    path,
+		specs:   make(map[string][]validate.FieldSpec),
+		symbols: opts.Symbols,
+		ctx:     ctx,
+		in:      bufio.NewScanner(in),
+		out:     out,
+	}
+	for _, section := range sections {
+		if s.specs[section], err = validate.FieldSpecs(section); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.update(src); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Run shows the main menu until the user quits or the input ends
+func (s *Session) Run() error {
+	err := s.mainMenu()
+	if errors.Is(err, errQuit) {
+		if s.dirty {
+			fmt.Fprintf(s.out, "\nInput ended: unsaved changes to %s were discarded.\n", s.path)
+		}
+		return nil
+	}
+	return err
+}
+
+func (s *Session) mainMenu() error {
+	for {
+		doc, err := s.parse()
+		if err != nil {
+			return err
+		}
+		root := rootMapping(doc)
+
+		errorCount, warningCount := 0, 0
+		for _, diag := range s.diags {
+			if diag.Severity == validate.SeverityError {
+				errorCount++
+			} else {
+				warningCount++
+			}
+		}
+		modified := ""
+		if s.dirty {
+			modified = " (modified)"
+		}
+		fmt.Fprintf(s.out, "\n%s%s: %d error(s), %d warning(s)\n", s.path, modified, errorCount, warningCount)
+
+		var entries []entryRef
+		attached := make(map[int]bool)
+		for _, section := range sections {
+			names := entryNames(root, section)
+			if len(names) == 0 {
+				continue
+			}
+			fmt.Fprintf(s.out, "\n%s\n", strings.ToUpper(section[:1])+section[1:])
+			for _, name := range names {
+				entries = append(entries, entryRef{section, name})
+				key, value := entryNodes(root, section, name)
+				errs, warnings := 0, 0
+				for i, diag := range s.diags {
+					if belongsTo(diag, section, name, key, value) {
+						attached[i] = true
+						if diag.Severity == validate.SeverityError {
+							errs++
+						} else {
+							warnings++
+						}
+					}
+				}
+				fmt.Fprintf(s.out, "  %3d. %-24s %s\n", len(entries), name, s.counts(errs, warnings))
+			}
+		}
+
+		var other []validate.Diagnostic
+		for i, diag := range s.diags {
+			if !attached[i] {
+				other = append(other, diag)
+			}
+		}
+		if len(other) > 0 {
+			fmt.Fprintf(s.out, "\nOther diagnostics:\n")
+			for _, diag := range other {
+				fmt.Fprintf(s.out, "  %s\n", s.formatDiagnostic(diag))
+			}
+		}
+
+		fmt.Fprintf(s.out, "\n[number] open entry  [s] save  [q] quit\n")
+		input, err := s.prompt("> ")
+		if err != nil {
+			return err
+		}
+		switch input {
+		case "":
+		case "s":
+			if err := s.save(); err != nil {
+				fmt.Fprintf(s.out, "Error: %v\n", err)
+			}
+		case "q":
+			if !s.dirty || s.confirm("Discard unsaved changes? [y/N] ") {
+				return nil
+			}
+		default:
+			n, err := strconv.Atoi(input)
+			if err != nil || n < 1 || n > len(entries) {
+				fmt.Fprintf(s.out, "Unknown command %q\n", input)
+				continue
+			}
+			if err := s.entryMenu(entries[n-1]); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (s *Session) entryMenu(ref entryRef) error {
+	for {
+		doc, err := s.parse()
+		if err != nil {
+			return err
+		}
+		key, value := entryNodes(rootMapping(doc), ref.section, ref.name)
+		if key == nil {
+			// The entry is gone, e.g. after an edit renamed it
+			return nil
+		}
+		value = resolveAlias(value)
+
+		fmt.Fprintf(s.out, "\n%s.%s (line %d)\n", ref.section, ref.name, key.Line)
+		fields := mappingFields(value)
+		shown := make(map[int]bool)
+		for i, field := range fields {
+			fmt.Fprintf(s.out, "  %3d. %s: %s\n", i+1, field.key.Value, summarize(field.value))
+			for j, diag := range s.diags {
+				if diag.Line >= field.key.Line && diag.Line <= lastLine(field.value) {
+					shown[j] = true
+					fmt.Fprintf(s.out, "       %s\n", s.formatDiagnostic(diag))
+				}
+			}
+		}
+		for j, diag := range s.diags {
+			if !shown[j] && belongsTo(diag, ref.section, ref.name, key, value) {
+				fmt.Fprintf(s.out, "  %s\n", s.formatDiagnostic(diag))
+			}
+		}
+
+		fmt.Fprintf(s.out, "\n[number] edit field  [a] add field  [d number] delete field  [b] back\n")
+		input, err := s.prompt("> ")
+		if err != nil {
+			return err
+		}
+		switch {
+		case input == "":
+		case input == "b":
+			return nil
+		case input == "a":
+			if err := s.addField(ref, fields); err != nil {
+				return err
+			}
+		case strings.HasPrefix(input, "d "):
+			n, err := strconv.Atoi(strings.TrimSpace(input[2:]))
+			if err != nil || n < 1 || n > len(fields) {
+				fmt.Fprintf(s.out, "Unknown field %q\n", input[2:])
+				continue
+			}
+			s.edit(ref, func(entry *yaml.Node) error {
+				deleteField(entry, fields[n-1].key.Value)
+				return nil
+			})
+		default:
+			n, err := strconv.Atoi(input)
+			if err != nil || n < 1 || n > len(fields) {
+				fmt.Fprintf(s.out, "Unknown command %q\n", input)
+				continue
+			}
+			if err := s.editField(ref, fields[n-1].key.Value, fields[n-1].value); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// addField offers the schema fields the entry does not set yet
+func (s *Session) addField(ref entryRef, fields []field) error {
+	var available []validate.FieldSpec
+	for _, spec := range s.specs[ref.section] {
+		set := false
+		for _, field := range fields {
+			if field.key.Value == spec.Name {
+				set = true
+			}
+		}
+		if !set {
+			available = append(available, spec)
+		}
+	}
+	if len(available) == 0 {
+		fmt.Fprintf(s.out, "All schema fields are set.\n")
+		return nil
+	}
+
+	fmt.Fprintf(s.out, "\nFields:\n")
+	for i, spec := range available {
+		fmt.Fprintf(s.out, "  %3d. %-16s %s\n", i+1, spec.Name, firstLine(spec.Doc))
+	}
+	input, err := s.prompt("Field to add (empty cancels): ")
+	if err != nil || input == "" {
+		return err
+	}
+	n, err := strconv.Atoi(input)
+	if err != nil || n < 1 || n > len(available) {
+		fmt.Fprintf(s.out, "Unknown field %q\n", input)
+		return nil
+	}
+	return s.editField(ref, available[n-1].Name, nil)
+}
+
+// editField asks for a new value of a field, offering the schema values when
+// the field has a fixed set of them
+func (s *Session) editField(ref entryRef, name string, current *yaml.Node) error {
+	if name == "<<" {
+		fmt.Fprintf(s.out, "Merge keys cannot be edited here.\n")
+		return nil
+	}
+	var spec validate.FieldSpec
+	for _, candidate := range s.specs[ref.section] {
+		if candidate.Name == name {
+			spec = candidate
+		}
+	}
+
+	fmt.Fprintf(s.out, "\n%s", name)
+	if spec.Doc != "" {
+		fmt.Fprintf(s.out, ": %s", strings.ReplaceAll(spec.Doc, "\n", "\n  "))
+	}
+	fmt.Fprintln(s.out)
+	if current != nil {
+		fmt.Fprintf(s.out, "Current: %s\n", summarize(current))
+	}
+	for i, value := range spec.Enum {
+		fmt.Fprintf(s.out, "  %3d. %s\n", i+1, value)
+	}
+
+	message := "New value as YAML, e.g. 4 or [2, 4] (empty cancels): "
+	if len(spec.Enum) > 0 {
+		message = "Choice or value (empty cancels): "
+	}
+	input, err := s.prompt(message)
+	if err != nil || input == "" {
+		return err
+	}
+
+	var value yaml.Node
+	if n, err := strconv.Atoi(input); err == nil && n >= 1 && n <= len(spec.Enum) {
+		value = yaml.Node{Kind: yaml.ScalarNode, Value: spec.Enum[n-1]}
+	} else {
+		var doc yaml.Node
+		if err := yaml.Unmarshal([]byte(input), &doc); err != nil || len(doc.Content) == 0 {
+			fmt.Fprintf(s.out, "Invalid value: %v\n", err)
+			return nil
+		}
+		value = *doc.Content[0]
+		value.Style &^= yaml.FlowStyle
+		if value.Kind == yaml.SequenceNode {
+			value.Style |= yaml.FlowStyle
+		}
+	}
+	s.edit(ref, func(entry *yaml.Node) error {
+		setField(entry, name, &value)
+		return nil
+	})
+	return nil
+}
+
+// edit applies a change to an entry, then formats and revalidates the config
+func (s *Session) edit(ref entryRef, change func(entry *yaml.Node) error) {
+	doc, err := s.parse()
+	if err == nil {
+		_, entry := entryNodes(rootMapping(doc), ref.section, ref.name)
+		if entry == nil {
+			err = fmt.Errorf("%s.%s not found", ref.section, ref.name)
+		} else {
+			err = change(resolveAlias(entry))
+		}
+	}
+	var src []byte
+	if err == nil {
+		src, err = encode(doc)
+	}
+	if err == nil {
+		err = s.update(src)
+	}
+	if err != nil {
+		fmt.Fprintf(s.out, "Error: %v\n", err)
+		return
+	}
+	s.dirty = true
+}
+
+// update replaces the config source and revalidates it
+func (s *Session) update(src []byte) error {
+	diags, err := validate.ValidateBytes(s.ctx, src, s.path)
+	if err != nil {
+		return err
+	}
+	s.src, s.diags = src, diags
+	return nil
+}
+
+func (s *Session) save() error {
+	info, err := os.Stat(s.path)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(s.path, s.src, info.Mode().Perm()); err != nil {
+		return err
+	}
+	s.dirty = false
+	fmt.Fprintf(s.out, "Saved %s\n", s.path)
+	return nil
+}
+
+func (s *Session) parse() (*yaml.Node, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(s.src, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", s.path, err)
+	}
+	return &doc, nil
+}
+
+func (s *Session) prompt(message string) (string, error) {
+	fmt.Fprint(s.out, message)
+	if !s.in.Scan() {
+		if err := s.in.Err(); err != nil {
+			return "", err
+		}
+		return "", errQuit
+	}
+	return strings.TrimSpace(s.in.Text()), nil
+}
+
+func (s *Session) confirm(message string) bool {
+	input, err := s.prompt(message)
+	return err == nil && strings.EqualFold(input, "y")
+}
+
+// encode writes a document back in canonical style (see format.Format)
+func encode(doc *yaml.Node) ([]byte, error) {
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(doc); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return format.Format(buf.Bytes())
+}
+
+func (s *Session) counts(errs, warnings int) string {
+	var parts []string
+	if errs > 0 {
+		parts = append(parts, symbols.Mark(s.symbols.Error, fmt.Sprintf("%d error(s)", errs)))
+	}
+	if warnings > 0 {
+		parts = append(parts, symbols.Mark(s.symbols.Warning, fmt.Sprintf("%d warning(s)", warnings)))
+	}
+	return strings.Join(parts, "  ")
+}
+
+// formatDiagnostic describes a diagnostic, naming its severity so that it
+// does not rely on the marker
+func (s *Session) formatDiagnostic(diag validate.Diagnostic) string {
+	marker := s.symbols.Error
+	if diag.Severity != validate.SeverityError {
+		marker = s.symbols.Warning
+	}
+	location := ""
+	if diag.Line > 0 {
+		location = fmt.Sprintf("line %d: ", diag.Line)
+	}
+	rule := ""
+	if diag.RuleID != "" {
+		rule = fmt.Sprintf(" [%s]", diag.RuleID)
+	}
+	return symbols.Mark(marker, fmt.Sprintf("%s: %s%s%s", diag.Severity, location, diag.Message, rule))
+}
+
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(s, "\n")
+	return line
+}
@@ -0,0 +1,115 @@
+package tui_test
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/runs-on/config/internal/symbols"
+	"github.com/runs-on/config/internal/tui"
+)
+
+func TestSession(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "runs-on.yml")
+	config := `runners:
+  small:
+    cpu: 2
+    family: [c7a]
+    spot: sometimes
+pools:
+  main:
+    runner: small
+`
+	if err := os.WriteFile(path, []byte(config), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	// Open the runner, pick the fourth spot value (pco), delete family, go
+	// back, save and quit
+	input := strings.Join([]string{"1", "3", "4", "d 2", "b", "s", "q"}, "\n") + "\n"
+	var out bytes.Buffer
+	session, err := tui.New(context.Background(), path, strings.NewReader(input), &out, tui.Options{})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	if err := session.Run(); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	for _, expected := range []string{"10 error(s)", "runners.small (line 2)", "spot: sometimes", "1. false", "Saved " + path} {
+		if !strings.Contains(out.String(), expected) {
+			t.Errorf("Expected output to contain %q, got:\n%s", expected, out.String())
+		}
+	}
+
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := `runners:
+  small:
+    cpu: 2
+    spot: pco
+
+pools:
+  main:
+    runner: small
+`
+	if string(got) != expected {
+		t.Errorf("Unexpected saved config:\n%s", got)
+	}
+	if info, err := os.Stat(path); err != nil || info.Mode().Perm() != 0o600 {
+		t.Errorf("Expected file permissions to be kept, got %v", info.Mode())
+	}
+}
+
+func TestSession_DiscardOnEOF(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "runs-on.yml")
+	config := "runners:\n  small:\n    cpu: 2\n"
+	if err := os.WriteFile(path, []byte(config), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	session, err := tui.New(context.Background(), path, strings.NewReader("1\n1\n[4, 8]\n"), &out, tui.Options{})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	if err := session.Run(); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if !strings.Contains(out.String(), "cpu: [4, 8]") || !strings.Contains(out.String(), "unsaved changes") {
+		t.Errorf("Unexpected output:\n%s", out.String())
+	}
+	if got, _ := os.ReadFile(path); string(got) != config {
+		t.Errorf("Expected the file to be left untouched, got:\n%s", got)
+	}
+}
+
+func TestSession_ASCIISymbols(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "runs-on.yml")
+	config := "runners:\n  small:\n    cpu: 2\npools:\n  main:\n    runner: large\n"
+	if err := os.WriteFile(path, []byte(config), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	session, err := tui.New(context.Background(), path, strings.NewReader("2\nb\nq\n"), &out, tui.Options{Symbols: symbols.ASCII})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	if err := session.Run(); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if !strings.Contains(out.String(), "[ERROR] 1 error(s)") || !strings.Contains(out.String(), "[ERROR] error: ") {
+		t.Errorf("Expected ASCII markers and severities, got:\n%s", out.String())
+	}
+	for _, r := range out.String() {
+		if r > 127 {
+			t.Fatalf("Expected ASCII output, got %q in:\n%s", r, out.String())
+		}
+	}
+}
@@ -8,6 +8,14 @@ import "runtime/debug"
 //	go build -ldflags "-X github.com/runs-on/config/internal/version.Version=v2.12.0"
 var Version = "dev"
 
+// Commit and Date are the git commit and its time (RFC 3339) the binaries
+// were built from. They can be set at build time like Version; when unset,
+// the VCS information recorded by the Go toolchain is used.
+var (
+	Commit = ""
+	Date   = ""
+)
+
 func String() string {
 	if Version != "" && Version != "dev" {
 		return Version
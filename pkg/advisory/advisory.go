@@ -0,0 +1,247 @@
+// Package advisory loads advisories describing insecure config patterns
+// discovered after a release (e.g. a vulnerable image version or a dangerous
+// combination of extras) and matches configs against them. Advisories are
+// published as a JSON database, independently of releases of this module; a
+// snapshot is embedded at build time.
+package advisory
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxDatabaseSize limits the size of a downloaded database
+const maxDatabaseSize = 10 << 20
+
+//go:embed advisories.json
+var embeddedDatabase []byte
+
+// Database is a set of advisories
+type Database struct {
+	// Updated is the date the database was last published
+	Updated    string     `json:"updated"`
+	Advisories []Advisory `json:"advisories"`
+}
+
+// Advisory describes an insecure config pattern
+type Advisory struct {
+	// ID is the stable advisory identifier, e.g. "ROC-2026-0001"
+	ID      string `json:"id"`
+	Summary string `json:"summary"`
+	Details string `json:"details,omitempty"`
+	// Severity is "error" or "warning"
+	Severity  string `json:"severity"`
+	Published string `json:"published,omitempty"`
+	URL       string `json:"url,omitempty"`
+	Match     Match  `json:"match"`
+}
+
+// Match selects the config entries an advisory applies to. All conditions
+// must hold.
+type Match struct {
+	// Section is "runners", "images" or "pools"
+	Section string `json:"section"`
+	// Fields maps field names to glob patterns (path.Match syntax) matched
+	// against the field's value. For list and "+"-separated fields, any item
+	// may match.
+	Fields map[string]string `json:"fields,omitempty"`
+	// Extras lists runner extras that must all be enabled
+	Extras []string `json:"extras,omitempty"`
+}
+
+// Finding is an entry of a config matched by an advisory
+type Finding struct {
+	Advisory Advisory
+	Section  string
+	Entry    string
+	// Field is the field that best locates the finding, or "" for the entry
+	Field string
+}
+
+// Embedded returns the database snapshot embedded in this binary
+func Embedded() *Database {
+	db, err := Parse(embeddedDatabase)
+	if err != nil {
+		// The database is embedded at build time, so this cannot happen
+		panic(fmt.Sprintf("invalid embedded advisory database: %v", err))
+	}
+	return db
+}
+
+// Load reads a database from a local file or an http(s) URL
+func Load(ctx context.Context, ref string) (*Database, error) {
+	var data []byte
+	var err error
+	if strings.HasPrefix(ref, "https://") || strings.HasPrefix(ref, "http://") {
+		data, err = download(ctx, ref)
+	} else {
+		data, err = os.ReadFile(ref)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read advisory database: %w", err)
+	}
+	db, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid advisory database %s: %w", ref, err)
+	}
+	return db, nil
+}
+
+func download(ctx context.Context, url string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s: %s", url, resp.Status)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, maxDatabaseSize))
+}
+
+// Parse parses a database and checks its advisories
+func Parse(data []byte) (*Database, error) {
+	var db Database
+	if err := json.Unmarshal(data, &db); err != nil {
+		return nil, err
+	}
+	for _, advisory := range db.Advisories {
+		if advisory.ID == "" || advisory.Summary == "" {
+			return nil, fmt.Errorf("advisory %q needs an id and a summary", advisory.ID)
+		}
+		switch advisory.Severity {
+		case "error", "warning":
+		default:
+			return nil, fmt.Errorf("advisory %s: invalid severity %q (valid: error, warning)", advisory.ID, advisory.Severity)
+		}
+		switch advisory.Match.Section {
+		case "runners", "images", "pools":
+		default:
+			return nil, fmt.Errorf("advisory %s: invalid section %q (valid: runners, images, pools)", advisory.ID, advisory.Match.Section)
+		}
+		if len(advisory.Match.Fields) == 0 && len(advisory.Match.Extras) == 0 {
+			return nil, fmt.Errorf("advisory %s: match needs fields or extras", advisory.ID)
+		}
+		for field, pattern := range advisory.Match.Fields {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return nil, fmt.Errorf("advisory %s: invalid pattern for %s: %w", advisory.ID, field, err)
+			}
+		}
+	}
+	return &db, nil
+}
+
+// Check returns the entries of a decoded config matched by the advisories,
+// sorted by advisory ID, section and entry name
+func (db *Database) Check(config map[string]any) []Finding {
+	var findings []Finding
+	for _, advisory := range db.Advisories {
+		entries, _ := config[advisory.Match.Section].(map[string]any)
+		for name, value := range entries {
+			entry, ok := value.(map[string]any)
+			if !ok {
+				continue
+			}
+			if field, ok := advisory.Match.matches(entry); ok {
+				findings = append(findings, Finding{Advisory: advisory, Section: advisory.Match.Section, Entry: name, Field: field})
+			}
+		}
+	}
+	sort.Slice(findings, func(i, j int) bool {
+		a, b := findings[i], findings[j]
+		if a.Advisory.ID != b.Advisory.ID {
+			return a.Advisory.ID < b.Advisory.ID
+		}
+		if a.Section != b.Section {
+			return a.Section < b.Section
+		}
+		return a.Entry < b.Entry
+	})
+	return findings
+}
+
+// matches reports whether an entry meets all conditions, and returns the
+// field locating the match: the first matched field by name, or "extras"
+func (m Match) matches(entry map[string]any) (string, bool) {
+	fields := make([]string, 0, len(m.Fields))
+	for field := range m.Fields {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	for _, field := range fields {
+		matched := false
+		for _, value := range values(entry[field]) {
+			if ok, _ := path.Match(m.Fields[field], value); ok {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return "", false
+		}
+	}
+
+	enabled := make(map[string]bool)
+	for _, extra := range values(entry["extras"]) {
+		enabled[extra] = true
+	}
+	for _, extra := range m.Extras {
+		if !enabled[extra] {
+			return "", false
+		}
+	}
+
+	if len(fields) > 0 {
+		return fields[0], true
+	}
+	return "extras", true
+}
+
+// values returns the string forms of a field value. Lists yield one value per
+// item, and "+"-separated strings yield the whole string and each item.
+func values(value any) []string {
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case string:
+		result := []string{v}
+		if parts := strings.Split(v, "+"); len(parts) > 1 {
+			for _, part := range parts {
+				if part = strings.TrimSpace(part); part != "" {
+					result = append(result, part)
+				}
+			}
+		}
+		return result
+	case bool:
+		return []string{strconv.FormatBool(v)}
+	case int:
+		return []string{strconv.Itoa(v)}
+	case float64:
+		return []string{strconv.FormatFloat(v, 'f', -1, 64)}
+	case []any:
+		var result []string
+		for _, item := range v {
+			result = append(result, values(item)...)
+		}
+		return result
+	}
+	return []string{fmt.Sprint(value)}
+}
@@ -0,0 +1,111 @@
+package advisory_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/runs-on/config/pkg/advisory"
+	"gopkg.in/yaml.v3"
+)
+
+const testDatabase = `{
+  "updated": "2026-10-01",
+  "advisories": [
+    {
+      "id": "ROC-2026-0002",
+      "summary": "efs with tmpfs exposes cached secrets",
+      "severity": "error",
+      "match": {"section": "runners", "extras": ["efs", "tmpfs"]}
+    },
+    {
+      "id": "ROC-2026-0001",
+      "summary": "ubuntu22 images before 20260901 ship a vulnerable runner agent",
+      "severity": "warning",
+      "url": "https://example.com/ROC-2026-0001",
+      "match": {"section": "images", "fields": {"name": "runs-on-*-ubuntu22-*-20260[1-8]*"}}
+    }
+  ]
+}`
+
+func TestCheck(t *testing.T) {
+	db, err := advisory.Parse([]byte(testDatabase))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	var config map[string]any
+	if err := yaml.Unmarshal([]byte(`runners:
+  cached:
+    extras: efs+tmpfs+s3-cache
+  partial:
+    extras: [efs]
+images:
+  old:
+    name: runs-on-v2-ubuntu22-full-x64-20260515
+  new:
+    name: runs-on-v2-ubuntu22-full-x64-20260915
+`), &config); err != nil {
+		t.Fatalf("Failed to parse config: %v", err)
+	}
+
+	findings := db.Check(config)
+	if len(findings) != 2 {
+		t.Fatalf("Expected 2 findings, got %+v", findings)
+	}
+	if f := findings[0]; f.Advisory.ID != "ROC-2026-0001" || f.Section != "images" || f.Entry != "old" || f.Field != "name" {
+		t.Errorf("Unexpected finding: %+v", f)
+	}
+	if f := findings[1]; f.Advisory.ID != "ROC-2026-0002" || f.Entry != "cached" || f.Field != "extras" {
+		t.Errorf("Unexpected finding: %+v", f)
+	}
+}
+
+func TestParse_Invalid(t *testing.T) {
+	for _, src := range []string{
+		`not json`,
+		`{"advisories": [{"summary": "no id", "severity": "error", "match": {"section": "runners", "extras": ["efs"]}}]}`,
+		`{"advisories": [{"id": "A", "summary": "s", "severity": "info", "match": {"section": "runners", "extras": ["efs"]}}]}`,
+		`{"advisories": [{"id": "A", "summary": "s", "severity": "error", "match": {"section": "admins", "extras": ["efs"]}}]}`,
+		`{"advisories": [{"id": "A", "summary": "s", "severity": "error", "match": {"section": "runners"}}]}`,
+		`{"advisories": [{"id": "A", "summary": "s", "severity": "error", "match": {"section": "runners", "fields": {"image": "["}}}]}`,
+	} {
+		if _, err := advisory.Parse([]byte(src)); err == nil {
+			t.Errorf("Expected an error for %s", src)
+		}
+	}
+}
+
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "advisories.json")
+	if err := os.WriteFile(path, []byte(testDatabase), 0o644); err != nil {
+		t.Fatalf("Failed to write database: %v", err)
+	}
+	if db, err := advisory.Load(context.Background(), path); err != nil || len(db.Advisories) != 2 {
+		t.Errorf("Expected 2 advisories from a file, got %v, %v", db, err)
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/advisories.json" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(testDatabase))
+	}))
+	defer srv.Close()
+	if db, err := advisory.Load(context.Background(), srv.URL+"/advisories.json"); err != nil || db.Updated != "2026-10-01" {
+		t.Errorf("Expected the database from a URL, got %v, %v", db, err)
+	}
+	if _, err := advisory.Load(context.Background(), srv.URL+"/missing.json"); err == nil {
+		t.Error("Expected an error for a missing URL")
+	}
+}
+
+func TestEmbedded(t *testing.T) {
+	if db := advisory.Embedded(); db.Updated == "" {
+		t.Errorf("Expected the embedded database to have a date, got %+v", db)
+	}
+}
@@ -0,0 +1,142 @@
+// Package catalog lists the EC2 instance families RunsOn can launch and
+// expands the family patterns accepted in runner specs.
+package catalog
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// families lists known EC2 instance families. It is used to expand family
+// patterns, so it only needs to be complete enough for wildcards to match
+// what users expect.
+var families = []string{
+	// General purpose
+	"m5", "m5a", "m5ad", "m5d", "m5dn", "m5n", "m5zn",
+	"m6a", "m6g", "m6gd", "m6i", "m6id", "m6idn", "m6in",
+	"m7a", "m7g", "m7gd", "m7i", "m7i-flex",
+	"m8a", "m8g", "m8gd", "m8i",
+	"t3", "t3a", "t4g",
+	"mac1", "mac2",
+	// Compute optimized
+	"c5", "c5a", "c5ad", "c5d", "c5n",
+	"c6a", "c6g", "c6gd", "c6gn", "c6i", "c6id", "c6in",
+	"c7a", "c7g", "c7gd", "c7gn", "c7i", "c7i-flex",
+	"c8g", "c8gd", "c8gn", "c8i",
+	// Memory optimized
+	"r5", "r5a", "r5ad", "r5b", "r5d", "r5dn", "r5n",
+	"r6a", "r6g", "r6gd", "r6i", "r6id", "r6idn", "r6in",
+	"r7a", "r7g", "r7gd", "r7i", "r7iz",
+	"r8g", "r8gd", "r8i",
+	"x2gd", "x2idn", "x2iedn", "x8g", "z1d",
+	// Storage optimized
+	"d3", "d3en", "i3", "i3en", "i4g", "i4i", "i7ie", "im4gn", "is4gen",
+	// Accelerated computing
+	"g4ad", "g4dn", "g5", "g5g", "g6", "g6e", "gr6", "inf2", "p4d", "p5", "trn1",
+}
+
+// Family is an EC2 instance family such as c7gn, split into its parts
+type Family struct {
+	// Name is the full family name, e.g. "c7gn"
+	Name string
+	// Series is the instance class prefix, e.g. "c"
+	Series string
+	// Generation is the family generation, e.g. 7
+	Generation int
+	// Attributes are the suffixes after the generation, e.g. "gn"
+	Attributes string
+}
+
+// ParseFamily splits a family name into series, generation and attributes
+func ParseFamily(name string) (Family, bool) {
+	i := 0
+	for i < len(name) && name[i] >= 'a' && name[i] <= 'z' {
+		i++
+	}
+	j := i
+	for j < len(name) && name[j] >= '0' && name[j] <= '9' {
+		j++
+	}
+	if i == 0 || j == i {
+		return Family{}, false
+	}
+	generation, err := strconv.Atoi(name[i:j])
+	if err != nil {
+		return Family{}, false
+	}
+	return Family{Name: name, Series: name[:i], Generation: generation, Attributes: name[j:]}, true
+}
+
+// Arch returns the CPU architecture of the family's instances, "arm64" for
+// Graviton (and Apple silicon mac2) families and "x64" otherwise
+func (f Family) Arch() string {
+	if strings.Contains(f.Attributes, "g") || f.Name == "mac2" {
+		return "arm64"
+	}
+	return "x64"
+}
+
+// Burstable reports whether the family's instances are burstable (T series):
+// they run at a baseline CPU level and spend CPU credits to go above it
+func (f Family) Burstable() bool {
+	return f.Series == "t"
+}
+
+// Families returns all known families sorted by name
+func Families() []Family {
+	result := make([]Family, 0, len(families))
+	for _, name := range families {
+		if family, ok := ParseFamily(name); ok {
+			result = append(result, family)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result
+}
+
+// Lookup returns the known family with the given name
+func Lookup(name string) (Family, bool) {
+	for _, family := range Families() {
+		if family.Name == name {
+			return family, true
+		}
+	}
+	return Family{}, false
+}
+
+// IsPattern reports whether a family value is a wildcard (c7*) rather than
+// a family name or prefix
+func IsPattern(value string) bool {
+	return strings.ContainsAny(value, "*?[")
+}
+
+// Expand returns the names of the known families matching a pattern, sorted.
+//
+// Wildcards use shell glob syntax: "c7*" matches c7a, c7g, c7i, etc.
+func Expand(pattern string) ([]string, error) {
+	var match func(Family) bool
+	switch {
+	case strings.ContainsAny(pattern, "*?["):
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid wildcard %q: %w", pattern, err)
+		}
+		match = func(f Family) bool {
+			ok, _ := path.Match(pattern, f.Name)
+			return ok
+		}
+	default:
+		// Plain values are family names or prefixes
+		match = func(f Family) bool { return strings.HasPrefix(f.Name, pattern) }
+	}
+
+	var names []string
+	for _, family := range Families() {
+		if match(family) {
+			names = append(names, family.Name)
+		}
+	}
+	return names, nil
+}
@@ -0,0 +1,169 @@
+package catalog_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/runs-on/config/pkg/catalog"
+)
+
+func TestParseFamily(t *testing.T) {
+	family, ok := catalog.ParseFamily("c7gn")
+	if !ok {
+		t.Fatal("Expected c7gn to parse")
+	}
+	if family.Series != "c" || family.Generation != 7 || family.Attributes != "gn" {
+		t.Errorf("Unexpected parts: %+v", family)
+	}
+
+	for _, name := range []string{"", "7a", "c", "*"} {
+		if _, ok := catalog.ParseFamily(name); ok {
+			t.Errorf("Expected %q not to parse", name)
+		}
+	}
+}
+
+func TestFamilyArch(t *testing.T) {
+	testCases := map[string]string{
+		"c7a": "x64", "m7i-flex": "x64", "g4dn": "x64", "mac1": "x64",
+		"c7g": "arm64", "c7gn": "arm64", "g5g": "arm64", "im4gn": "arm64", "mac2": "arm64",
+	}
+	for name, want := range testCases {
+		family, ok := catalog.Lookup(name)
+		if !ok {
+			t.Fatalf("Expected %q to be known", name)
+		}
+		if got := family.Arch(); got != want {
+			t.Errorf("%s: expected %s, got %s", name, want, got)
+		}
+	}
+}
+
+func TestFamilyBurstable(t *testing.T) {
+	for name, want := range map[string]bool{"t3": true, "t3a": true, "t4g": true, "m7i-flex": false, "c7a": false, "trn1": false} {
+		family, ok := catalog.Lookup(name)
+		if !ok {
+			t.Fatalf("Expected %q to be known", name)
+		}
+		if got := family.Burstable(); got != want {
+			t.Errorf("%s: expected %v, got %v", name, want, got)
+		}
+	}
+}
+
+func TestExpand(t *testing.T) {
+	testCases := []struct {
+		pattern  string
+		contains []string
+		excludes []string
+	}{
+		{"c7*", []string{"c7a", "c7g", "c7gn", "c7i", "c7i-flex"}, []string{"c6a", "c8g", "m7a"}},
+		{"*7g", []string{"c7g", "m7g", "r7g"}, []string{"c7gd", "m6g"}},
+		{"m7", []string{"m7a", "m7g", "m7i-flex"}, []string{"m6a", "m8g"}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.pattern, func(t *testing.T) {
+			names, err := catalog.Expand(tc.pattern)
+			if err != nil {
+				t.Fatalf("Expand failed: %v", err)
+			}
+			for _, name := range tc.contains {
+				if !slices.Contains(names, name) {
+					t.Errorf("Expected %s to match %s, got %v", tc.pattern, name, names)
+				}
+			}
+			for _, name := range tc.excludes {
+				if slices.Contains(names, name) {
+					t.Errorf("Expected %s not to match %s", tc.pattern, name)
+				}
+			}
+		})
+	}
+}
+
+func TestExpand_NoMatchAndInvalid(t *testing.T) {
+	names, err := catalog.Expand("q9*")
+	if err != nil {
+		t.Fatalf("Expand failed: %v", err)
+	}
+	if len(names) != 0 {
+		t.Errorf("Expected no match, got %v", names)
+	}
+
+	for _, pattern := range []string{"c7[", "[c7"} {
+		if _, err := catalog.Expand(pattern); err == nil {
+			t.Errorf("Expected error for %q", pattern)
+		}
+	}
+}
+
+func TestIsPattern(t *testing.T) {
+	testCases := map[string]bool{
+		"c7a":  false,
+		"m7":   false,
+		"c7*":  true,
+		"m6*":  true,
+		"c7?":  true,
+		"c7a.": false,
+	}
+	for value, expected := range testCases {
+		if got := catalog.IsPattern(value); got != expected {
+			t.Errorf("IsPattern(%q) = %v, expected %v", value, got, expected)
+		}
+	}
+}
+
+func TestImages(t *testing.T) {
+	images := catalog.Images()
+	if !slices.IsSorted(images) || !slices.Contains(images, "ubuntu22-full-x64") {
+		t.Errorf("Expected sorted built-in images, got %v", images)
+	}
+	if !catalog.IsImage("ubuntu24-full-arm64") || catalog.IsImage("ubuntu24-full") {
+		t.Error("Unexpected IsImage result")
+	}
+}
+
+func TestFamilyMaxSize(t *testing.T) {
+	testCases := map[string]catalog.Size{
+		"c7a":  {VCPU: 192, MemoryGB: 384},
+		"r5":   {VCPU: 96, MemoryGB: 768},
+		"m8i":  {VCPU: 384, MemoryGB: 1536},
+		"t4g":  {VCPU: 8, MemoryGB: 32},
+		"c7gn": {VCPU: 192, MemoryGB: 384},
+	}
+	for name, want := range testCases {
+		family, ok := catalog.Lookup(name)
+		if !ok {
+			t.Fatalf("Expected %q to be known", name)
+		}
+		if got, ok := family.MaxSize(); !ok || got != want {
+			t.Errorf("%s: expected %+v, got %+v (%v)", name, want, got, ok)
+		}
+	}
+	for _, name := range []string{"g5", "mac2", "c5n", "i4i"} {
+		family, _ := catalog.Lookup(name)
+		if _, ok := family.MaxSize(); ok {
+			t.Errorf("Expected the sizes of %s to be unknown", name)
+		}
+	}
+}
+
+func TestInstanceSize(t *testing.T) {
+	testCases := map[string]catalog.Size{
+		"c7a.large":   {VCPU: 2, MemoryGB: 4},
+		"m7g.medium":  {VCPU: 1, MemoryGB: 4},
+		"r7i.xlarge":  {VCPU: 4, MemoryGB: 32},
+		"m7a.2xlarge": {VCPU: 8, MemoryGB: 32},
+		"t3.micro":    {VCPU: 2, MemoryGB: 1},
+	}
+	for instanceType, want := range testCases {
+		if got, ok := catalog.InstanceSize(instanceType); !ok || got != want {
+			t.Errorf("%s: expected %+v, got %+v (%v)", instanceType, want, got, ok)
+		}
+	}
+	for _, instanceType := range []string{"c7a", "c7a.metal", "g5.xlarge", "c7a.hugexlarge", "t3.huge"} {
+		if _, ok := catalog.InstanceSize(instanceType); ok {
+			t.Errorf("Expected the size of %s to be unknown", instanceType)
+		}
+	}
+}
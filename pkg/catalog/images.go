@@ -0,0 +1,23 @@
+package catalog
+
+import "slices"
+
+// images lists the images RunsOn provides, which runners may use without
+// defining them in the images section
+var images = []string{
+	"ubuntu22-full-x64", "ubuntu22-full-arm64",
+	"ubuntu22-base-x64", "ubuntu22-base-arm64",
+	"ubuntu24-full-x64", "ubuntu24-full-arm64",
+	"ubuntu24-base-x64", "ubuntu24-base-arm64",
+	"windows22-full-x64", "windows22-base-x64",
+}
+
+// Images returns the names of the built-in RunsOn images, sorted
+func Images() []string {
+	return slices.Sorted(slices.Values(images))
+}
+
+// IsImage reports whether name is a built-in RunsOn image
+func IsImage(name string) bool {
+	return slices.Contains(images, name)
+}
@@ -0,0 +1,95 @@
+package catalog
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Size is the number of vCPUs and the memory of an instance type
+type Size struct {
+	VCPU     float64
+	MemoryGB float64
+}
+
+// memoryPerVCPU is the memory in GB per vCPU of the instance types of a
+// series, for the series whose instance types all have the same ratio
+var memoryPerVCPU = map[string]float64{"c": 2, "m": 4, "r": 8}
+
+// burstableSizes are the sizes of the T series
+var burstableSizes = map[string]Size{
+	"nano":    {2, 0.5},
+	"micro":   {2, 1},
+	"small":   {2, 2},
+	"medium":  {2, 4},
+	"large":   {2, 8},
+	"xlarge":  {4, 16},
+	"2xlarge": {8, 32},
+}
+
+// irregularFamilies have instance types whose memory is not proportional to
+// their vCPUs
+var irregularFamilies = map[string]bool{"c5n": true}
+
+// MaxSize returns an upper bound of the size of the family's largest
+// instance type, if known. It is exact for the T series; for other series it
+// may exceed the largest instance type of the family, e.g. for Graviton
+// families, so it can tell that a size is out of reach but not that it
+// exists.
+func (f Family) MaxSize() (Size, bool) {
+	if f.Burstable() {
+		return burstableSizes["2xlarge"], true
+	}
+	ratio, ok := memoryPerVCPU[f.Series]
+	if !ok || irregularFamilies[f.Name] {
+		return Size{}, false
+	}
+	var vcpu float64
+	switch {
+	case f.Generation <= 5:
+		vcpu = 96
+	case f.Generation >= 8 && strings.HasPrefix(f.Attributes, "i"):
+		vcpu = 384
+	default:
+		vcpu = 192
+	}
+	return Size{VCPU: vcpu, MemoryGB: vcpu * ratio}, true
+}
+
+// InstanceSize returns the size of an instance type such as c7a.2xlarge, for
+// the series whose sizes are known (see Family.MaxSize). Metal instance
+// types are not known.
+func InstanceSize(instanceType string) (Size, bool) {
+	name, size, ok := strings.Cut(instanceType, ".")
+	family, known := Lookup(name)
+	if !ok || !known {
+		return Size{}, false
+	}
+	if _, known := family.MaxSize(); !known {
+		return Size{}, false
+	}
+	if family.Burstable() {
+		s, ok := burstableSizes[size]
+		return s, ok
+	}
+
+	var vcpu float64
+	switch {
+	case size == "medium":
+		vcpu = 1
+	case size == "large":
+		vcpu = 2
+	case strings.HasSuffix(size, "xlarge"):
+		multiple := 1
+		if prefix := strings.TrimSuffix(size, "xlarge"); prefix != "" {
+			n, err := strconv.Atoi(prefix)
+			if err != nil || n <= 0 {
+				return Size{}, false
+			}
+			multiple = n
+		}
+		vcpu = float64(4 * multiple)
+	default:
+		return Size{}, false
+	}
+	return Size{VCPU: vcpu, MemoryGB: vcpu * memoryPerVCPU[family.Series]}, true
+}
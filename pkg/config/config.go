@@ -0,0 +1,80 @@
+// Package config provides typed access to runs-on.yml configs. Flexible
+// fields (e.g. cpu: "2+4" or ssh: "true") are decoded into their canonical
+// list and boolean forms. Document edits a config while keeping its comments
+// and anchors.
+package config
+
+// Runner is a runner specification from the runners section
+type Runner struct {
+	ID         string     `yaml:"id,omitempty"`
+	CPU        NumberList `yaml:"cpu,omitempty"`
+	RAM        NumberList `yaml:"ram,omitempty"`
+	Family     StringList `yaml:"family,omitempty"`
+	Image      string     `yaml:"image,omitempty"`
+	Spot       Spot       `yaml:"spot,omitempty"`
+	SSH        *Bool      `yaml:"ssh,omitempty"`
+	NestedVirt *Bool      `yaml:"nested-virt,omitempty"`
+	Private    *Bool      `yaml:"private,omitempty"`
+	Volume     string     `yaml:"volume,omitempty"`
+	// Disk is deprecated and ignored by RunsOn
+	Disk       string     `yaml:"disk,omitempty"`
+	Retry      StringList `yaml:"retry,omitempty"`
+	Extras     StringList `yaml:"extras,omitempty"`
+	Tags       StringList `yaml:"tags,omitempty"`
+	Debug      *Bool      `yaml:"debug,omitempty"`
+	Preinstall string     `yaml:"preinstall,omitempty"`
+	Prerun     string     `yaml:"prerun,omitempty"`
+}
+
+// Config is a parsed runs-on.yml file. Fields that are not part of the
+// schema (e.g. x- anchors) are ignored.
+type Config struct {
+	// Extends is the _extends reference, either a repository or a local path
+	Extends string `yaml:"_extends,omitempty"`
+	// SchemaVersion is the schema version the config is written for, e.g. v3
+	SchemaVersion string            `yaml:"schema-version,omitempty"`
+	Runners       map[string]Runner `yaml:"runners,omitempty"`
+	Images        map[string]Image  `yaml:"images,omitempty"`
+	Pools         map[string]Pool   `yaml:"pools,omitempty"`
+	Admins        []string          `yaml:"admins,omitempty"`
+}
+
+// Image is an image specification from the images section
+type Image struct {
+	ID             string            `yaml:"id,omitempty"`
+	AMI            string            `yaml:"ami,omitempty"`
+	Platform       string            `yaml:"platform,omitempty"`
+	Arch           string            `yaml:"arch,omitempty"`
+	Name           string            `yaml:"name,omitempty"`
+	Owner          string            `yaml:"owner,omitempty"`
+	MainDiskSize   int               `yaml:"main_disk_size,omitempty"`
+	RootDeviceName string            `yaml:"root_device_name,omitempty"`
+	Tags           map[string]string `yaml:"tags,omitempty"`
+	Preinstall     string            `yaml:"preinstall,omitempty"`
+	Prerun         string            `yaml:"prerun,omitempty"`
+}
+
+// Pool is a pool specification from the pools section
+type Pool struct {
+	Runner string `yaml:"runner"`
+	Env    string `yaml:"env,omitempty"`
+	// Environment is deprecated in favor of Env
+	Environment string     `yaml:"environment,omitempty"`
+	Version     string     `yaml:"version,omitempty"`
+	Timezone    string     `yaml:"timezone,omitempty"`
+	Schedule    []Schedule `yaml:"schedule,omitempty"`
+}
+
+// Schedule is an entry of a pool schedule
+type Schedule struct {
+	Name    string         `yaml:"name"`
+	Hot     int            `yaml:"hot"`
+	Stopped int            `yaml:"stopped"`
+	Match   *ScheduleMatch `yaml:"match,omitempty"`
+}
+
+// ScheduleMatch restricts a schedule entry to days and times
+type ScheduleMatch struct {
+	Day  []string `yaml:"day,omitempty"`
+	Time []string `yaml:"time,omitempty"`
+}
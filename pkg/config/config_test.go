@@ -0,0 +1,488 @@
+package config_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/runs-on/config/pkg/config"
+	"gopkg.in/yaml.v3"
+)
+
+func TestRunner_FlexibleFields(t *testing.T) {
+	testCases := map[string]string{
+		"strings": `
+cpu: "2+4"
+ram: "16+32"
+family: c7a+m7*
+extras: s3-cache+tmpfs
+ssh: "true"
+spot: false
+`,
+		"lists": `
+cpu: [2, 4]
+ram: [16, 32]
+family: [c7a, m7*]
+extras: [s3-cache, tmpfs]
+ssh: true
+spot: "false"
+`,
+	}
+
+	for name, src := range testCases {
+		t.Run(name, func(t *testing.T) {
+			var runner config.Runner
+			if err := yaml.Unmarshal([]byte(src), &runner); err != nil {
+				t.Fatalf("Unmarshal failed: %v", err)
+			}
+			if !slices.Equal(runner.CPU, config.NumberList{2, 4}) || !slices.Equal(runner.RAM, config.NumberList{16, 32}) {
+				t.Errorf("Unexpected cpu/ram: %v %v", runner.CPU, runner.RAM)
+			}
+			if !slices.Equal(runner.Family, config.StringList{"c7a", "m7*"}) {
+				t.Errorf("Unexpected family: %v", runner.Family)
+			}
+			if !slices.Equal(runner.Extras, config.StringList{"s3-cache", "tmpfs"}) {
+				t.Errorf("Unexpected extras: %v", runner.Extras)
+			}
+			if !runner.SSH.IsTrue() || runner.Private.IsTrue() {
+				t.Errorf("Unexpected flags: ssh=%v private=%v", runner.SSH, runner.Private)
+			}
+			if runner.Spot != "false" {
+				t.Errorf("Unexpected spot: %q", runner.Spot)
+			}
+		})
+	}
+}
+
+func TestRunner_Invalid(t *testing.T) {
+	for _, src := range []string{"cpu: lots\n", "ssh: maybe\n", "family: {a: b}\n"} {
+		var runner config.Runner
+		if err := yaml.Unmarshal([]byte(src), &runner); err == nil {
+			t.Errorf("Expected error for %q", src)
+		}
+	}
+}
+
+func TestSpot_Canonical(t *testing.T) {
+	testCases := map[config.Spot]string{
+		"never":                    "false",
+		"false":                    "false",
+		"price-capacity-optimized": "pco",
+		"lowest-price":             "lp",
+		"co":                       "co",
+	}
+	for spot, expected := range testCases {
+		if got := spot.Canonical(); got != expected {
+			t.Errorf("Spot(%q).Canonical() = %q, expected %q", spot, got, expected)
+		}
+	}
+}
+
+func TestFieldPaths(t *testing.T) {
+	src := `x-defaults: &defaults
+  cpu: 2
+  disk: default
+runners:
+  small:
+    <<: *defaults
+    cpu: [4, 8]
+    ssh: true
+pools:
+  main:
+    runner: small
+    schedule:
+      - name: default
+        hot: 1
+`
+	paths, err := config.FieldPaths([]byte(src))
+	if err != nil {
+		t.Fatalf("FieldPaths failed: %v", err)
+	}
+	var got []string
+	for _, p := range paths {
+		got = append(got, fmt.Sprintf("%s %s %d", p.Path, p.Type, p.Line))
+	}
+	want := []string{
+		"x-defaults map 1",
+		"x-defaults.cpu int 2",
+		"x-defaults.disk string 3",
+		"runners map 4",
+		"runners.small map 5",
+		"runners.small.cpu list 7",
+		"runners.small.cpu.0 int 7",
+		"runners.small.cpu.1 int 7",
+		"runners.small.ssh bool 8",
+		"runners.small.disk string 3",
+		"pools map 9",
+		"pools.main map 10",
+		"pools.main.runner string 11",
+		"pools.main.schedule list 12",
+		"pools.main.schedule.0 map 13",
+		"pools.main.schedule.0.name string 13",
+		"pools.main.schedule.0.hot int 14",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("Unexpected paths:\ngot  %q\nwant %q", got, want)
+	}
+
+	if _, err := config.FieldPaths([]byte("runners: [")); err == nil {
+		t.Error("Expected an error for invalid YAML")
+	}
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"base.yml":    "runners:\n  small:\n    cpu: 2\n    ssh: \"true\"\n",
+		"runs-on.yml": "_extends: ./base.yml\npools:\n  main:\n    runner: small\n    schedule:\n      - name: default\n        hot: 1\n        stopped: 2\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cfg, err := config.Load(filepath.Join(dir, "runs-on.yml"))
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	runner, ok := cfg.Runners["small"]
+	if !ok || !slices.Equal(runner.CPU, config.NumberList{2}) || !runner.SSH.IsTrue() {
+		t.Errorf("Expected the extended runner, got %+v", cfg.Runners)
+	}
+	if pool := cfg.Pools["main"]; pool.Runner != "small" || len(pool.Schedule) != 1 || pool.Schedule[0].Stopped != 2 {
+		t.Errorf("Unexpected pool %+v", pool)
+	}
+
+	if _, err := config.Load(filepath.Join(dir, "missing.yml")); err == nil {
+		t.Error("Expected an error for a missing file")
+	}
+	if _, err := config.Parse([]byte("runners:\n  small:\n    cpu: lots\n")); err == nil {
+		t.Error("Expected an error for an invalid cpu")
+	}
+}
+
+func TestDocument(t *testing.T) {
+	src := `# Shared defaults
+x-defaults: &defaults
+  cpu: 2 # small
+runners:
+  small:
+    <<: *defaults
+  big: # for builds
+    cpu: 16
+pools:
+  main:
+    runner: small
+    schedule:
+      # Business hours
+      - name: default
+        hot: 1
+        stopped: 2
+  other:
+    runner: big
+`
+	doc, err := config.ParseDocument([]byte(src))
+	if err != nil {
+		t.Fatalf("ParseDocument failed: %v", err)
+	}
+	if err := doc.RenameRunner("small", "medium"); err != nil {
+		t.Fatalf("RenameRunner failed: %v", err)
+	}
+	if err := doc.Set("pools.main.schedule.0.hot", 3); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	if err := doc.Set("x-defaults.cpu", 4); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	if err := doc.Set("runners.big.tags", []string{"ci"}); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	if deleted, err := doc.Delete("pools.other"); err != nil || !deleted {
+		t.Fatalf("Expected pools.other to be deleted, got %v, %v", deleted, err)
+	}
+
+	got, err := doc.Bytes()
+	if err != nil {
+		t.Fatalf("Bytes failed: %v", err)
+	}
+	want := `# Shared defaults
+x-defaults: &defaults
+  cpu: 4 # small
+runners:
+  medium:
+    <<: *defaults
+  big: # for builds
+    cpu: 16
+    tags:
+      - ci
+pools:
+  main:
+    runner: medium
+    schedule:
+      # Business hours
+      - name: default
+        hot: 3
+        stopped: 2
+`
+	if string(got) != want {
+		t.Errorf("Unexpected document:\n%s\nwant:\n%s", got, want)
+	}
+
+	cfg, err := doc.Config()
+	if err != nil {
+		t.Fatalf("Config failed: %v", err)
+	}
+	if runner := cfg.Runners["medium"]; !slices.Equal(runner.CPU, config.NumberList{4}) {
+		t.Errorf("Expected the merged cpu to follow the anchor, got %+v", cfg.Runners)
+	}
+
+	// Edits through aliases would change every use of the anchor
+	if err := doc.Set("runners.medium.<<.cpu", 8); err == nil {
+		t.Error("Expected an error when editing through an alias")
+	}
+	if err := doc.RenameRunner("missing", "other"); err == nil {
+		t.Error("Expected an error for an undefined runner")
+	}
+	if err := doc.RenameRunner("medium", "big"); err == nil {
+		t.Error("Expected an error for an existing runner")
+	}
+	if deleted, err := doc.Delete("pools.missing.runner"); err != nil || deleted {
+		t.Errorf("Expected nothing to delete, got %v, %v", deleted, err)
+	}
+}
+
+func TestConfig_ApplyDefaults(t *testing.T) {
+	cfg, err := config.Parse([]byte(`runners:
+  small: {}
+  big:
+    cpu: 16
+    image: custom
+    spot: false
+    private: true
+pools:
+  main:
+    runner: small
+    environment: staging
+  other:
+    runner: big
+    env: dev
+    timezone: Europe/Paris
+    schedule:
+      - name: day
+        hot: 2
+`))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	cfg.ApplyDefaults()
+
+	small := cfg.Runners["small"]
+	if !slices.Equal(small.CPU, config.NumberList{config.DefaultRunnerCPU}) || small.Image != config.DefaultImage || small.Spot != config.DefaultSpot || small.Volume != config.DefaultVolume {
+		t.Errorf("Expected defaults, got %+v", small)
+	}
+	if small.Private == nil || small.Private.IsTrue() || small.SSH != nil {
+		t.Errorf("Expected private to default to false and ssh to stay unset, got %+v", small)
+	}
+	big := cfg.Runners["big"]
+	if !slices.Equal(big.CPU, config.NumberList{16}) || big.Image != "custom" || big.Spot != "false" || !big.Private.IsTrue() {
+		t.Errorf("Expected set fields to be kept, got %+v", big)
+	}
+
+	main := cfg.Pools["main"]
+	if main.Env != "staging" || main.Environment != "" || main.Timezone != config.DefaultTimezone {
+		t.Errorf("Unexpected pool %+v", main)
+	}
+	if len(main.Schedule) != 1 || main.Schedule[0] != (config.Schedule{Name: config.DefaultScheduleName}) {
+		t.Errorf("Expected an empty default schedule entry, got %+v", main.Schedule)
+	}
+	other := cfg.Pools["other"]
+	if other.Env != "dev" || other.Timezone != "Europe/Paris" || len(other.Schedule) != 1 || other.Schedule[0].Hot != 2 {
+		t.Errorf("Expected set fields to be kept, got %+v", other)
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	doc := map[string]any{
+		"runners": map[string]any{
+			"small": map[string]any{
+				"cpu":     "2+4",
+				"ram":     16,
+				"family":  "c7a+m7a",
+				"retry":   "when-interrupted+on-failure",
+				"extras":  []any{"s3-cache"},
+				"tags":    "ci",
+				"ssh":     "false",
+				"private": true,
+				"spot":    false,
+			},
+			"invalid": map[string]any{"cpu": "lots", "debug": "maybe"},
+		},
+		"images": map[string]any{"ubuntu": map[string]any{"owner": 123456789012}},
+	}
+	config.Normalize(doc)
+
+	want := map[string]any{
+		"runners": map[string]any{
+			"small": map[string]any{
+				"cpu":     []any{2, 4},
+				"ram":     []any{16},
+				"family":  []any{"c7a", "m7a"},
+				"retry":   []any{"when-interrupted", "on-failure"},
+				"extras":  []any{"s3-cache"},
+				"tags":    []any{"ci"},
+				"ssh":     false,
+				"private": true,
+				"spot":    "false",
+			},
+			"invalid": map[string]any{"cpu": "lots", "debug": "maybe"},
+		},
+		"images": map[string]any{"ubuntu": map[string]any{"owner": "123456789012"}},
+	}
+	if fmt.Sprint(doc) != fmt.Sprint(want) {
+		t.Errorf("Expected %v, got %v", want, doc)
+	}
+
+	if numbers, err := config.Numbers([]any{2, "4+8.5"}); err != nil || !slices.Equal(numbers, config.NumberList{2, 4, 8.5}) {
+		t.Errorf("Unexpected numbers %v, %v", numbers, err)
+	}
+	if b, err := config.ParseBool("true"); err != nil || !b {
+		t.Errorf("Expected true, got %v, %v", b, err)
+	}
+	if _, err := config.ParseBool(1); err == nil {
+		t.Error("Expected an error for a number")
+	}
+}
+
+func TestDiff(t *testing.T) {
+	a, err := config.Parse([]byte(`runners:
+  small:
+    cpu: "2+4"
+    ssh: "true"
+  old:
+    cpu: 2
+pools:
+  main:
+    runner: small
+    schedule:
+      - name: day
+        hot: 2
+admins: [alice, bob]
+`))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	b, err := config.Parse([]byte(`runners:
+  small:
+    cpu: [2, 4]
+    ssh: true
+    spot: false
+pools:
+  main:
+    runner: small
+    schedule:
+      - name: day
+        hot: 4
+admins: [bob, carol]
+`))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	var got []string
+	for _, change := range config.Diff(a, b) {
+		got = append(got, fmt.Sprintf("%s %s %s %s %v %v", change.Kind, change.Section, change.Name, change.Path, change.Old, change.New))
+	}
+	want := []string{
+		"added admins   <nil> carol",
+		"removed admins   alice <nil>",
+		"removed runners old  map[cpu:[2]] <nil>",
+		"added runners small spot <nil> false",
+		"changed pools main schedule[day].hot 2 4",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("Expected changes\n%q\ngot\n%q", want, got)
+	}
+
+	if changes := config.Diff(a, a); len(changes) != 0 {
+		t.Errorf("Expected no changes, got %+v", changes)
+	}
+}
+
+func TestPool_Timeline(t *testing.T) {
+	cfg, err := config.Parse([]byte(`pools:
+  main:
+    runner: small
+    schedule:
+      - name: default
+        hot: 1
+      - name: weekend
+        stopped: 1
+        match:
+          day: [Saturday, sunday]
+      - name: nights
+        match:
+          time: ["22:00", "06:00"]
+`))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	timeline, err := cfg.Pools["main"].Timeline()
+	if err != nil {
+		t.Fatalf("Timeline failed: %v", err)
+	}
+
+	var got []string
+	total := make(map[string]time.Duration)
+	for i, interval := range timeline {
+		if i < 3 {
+			got = append(got, fmt.Sprintf("%s-%s %s", interval.Start, interval.End, interval.Name))
+		}
+		total[interval.Name] += interval.Duration()
+	}
+	want := []string{"0s-6h0m0s nights", "6h0m0s-22h0m0s default", "22h0m0s-30h0m0s nights"}
+	if !slices.Equal(got, want) {
+		t.Errorf("Expected timeline to start with %q, got %q", want, got)
+	}
+	if last := timeline[len(timeline)-1]; last.End != config.Week || last.Name != "weekend" || last.Stopped != 1 {
+		t.Errorf("Expected the week to end on the weekend entry, got %+v", last)
+	}
+	// Weekend days are matched all day by the earlier entry
+	if total["weekend"] != 48*time.Hour || total["nights"] != 5*8*time.Hour || total["default"] != 5*16*time.Hour {
+		t.Errorf("Unexpected totals %v", total)
+	}
+
+	for _, match := range []string{"day: [someday]", `time: ["25:00", "06:00"]`, `time: ["08:00"]`} {
+		cfg, err := config.Parse([]byte("pools:\n  main:\n    runner: small\n    schedule:\n      - name: bad\n        match: {" + match + "}\n"))
+		if err != nil {
+			t.Fatalf("Parse failed: %v", err)
+		}
+		if _, err := cfg.Pools["main"].Timeline(); err == nil {
+			t.Errorf("Expected an error for %s", match)
+		}
+	}
+
+	if timeline, err := (config.Pool{}).Timeline(); err != nil || timeline != nil {
+		t.Errorf("Expected an empty timeline without schedule, got %v, %v", timeline, err)
+	}
+}
+
+func TestParseVolume(t *testing.T) {
+	var got []string
+	for _, component := range config.ParseVolume("gp3:500GB::125mbps:8x") {
+		got = append(got, fmt.Sprintf("%d:%d:%q:%d:%s", component.Kind, component.Offset, component.Text, component.Value, component.Name))
+	}
+	want := []string{`1:0:"gp3":0:gp3`, `0:4:"500GB":500:`, `4:10:"":0:`, `2:11:"125mbps":125:`, `4:19:"8x":0:`}
+	if !slices.Equal(got, want) {
+		t.Errorf("Expected components %q, got %q", want, got)
+	}
+	if size, ok := config.VolumeSizeGB("gp3:3000iops:80gb"); !ok || size != 80 {
+		t.Errorf("Expected a size of 80GB, got %d, %v", size, ok)
+	}
+	if _, ok := config.VolumeSizeGB("gp3"); ok {
+		t.Error("Expected no size for a volume type only")
+	}
+}
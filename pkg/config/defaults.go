@@ -0,0 +1,66 @@
+package config
+
+// Values RunsOn uses for fields that are not set
+const (
+	DefaultRunnerCPU = 2
+	DefaultImage     = "ubuntu24-full-x64"
+	// DefaultSpot is the spot strategy, price-capacity-optimized
+	DefaultSpot     = Spot("pco")
+	DefaultVolume   = "40gb:gp3:125mbs:3000iops"
+	DefaultPoolEnv  = "production"
+	DefaultTimezone = "UTC"
+	// DefaultScheduleName is the name of the schedule entry a pool without
+	// schedule gets: it keeps no instances
+	DefaultScheduleName = "default"
+)
+
+// ApplyDefaults fills in the fields of runners and pools that RunsOn
+// defaults when they are not set, so that consumers see the effective
+// values. The deprecated pool field Environment is folded into Env.
+// Fields without a documented default, such as RAM, Family or SSH, are left
+// as they are.
+func (c *Config) ApplyDefaults() {
+	for name, runner := range c.Runners {
+		runner.ApplyDefaults()
+		c.Runners[name] = runner
+	}
+
+	for name, pool := range c.Pools {
+		if pool.Env == "" {
+			pool.Env = pool.Environment
+		}
+		pool.Environment = ""
+		if pool.Env == "" {
+			pool.Env = DefaultPoolEnv
+		}
+		if pool.Timezone == "" {
+			pool.Timezone = DefaultTimezone
+		}
+		if len(pool.Schedule) == 0 {
+			pool.Schedule = []Schedule{{Name: DefaultScheduleName}}
+		}
+		c.Pools[name] = pool
+	}
+}
+
+// ApplyDefaults fills in the fields of a runner that RunsOn defaults, as
+// Config.ApplyDefaults does for every runner
+func (r *Runner) ApplyDefaults() {
+	if len(r.CPU) == 0 {
+		r.CPU = NumberList{DefaultRunnerCPU}
+	}
+	if r.Image == "" {
+		r.Image = DefaultImage
+	}
+	if r.Spot == "" {
+		r.Spot = DefaultSpot
+	}
+	if r.Volume == "" {
+		r.Volume = DefaultVolume
+	}
+	for _, field := range []**Bool{&r.Private, &r.NestedVirt, &r.Debug} {
+		if *field == nil {
+			*field = new(Bool)
+		}
+	}
+}
@@ -0,0 +1,212 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ChangeKind is the kind of a Change
+type ChangeKind string
+
+// Change kinds
+const (
+	ChangeAdded   ChangeKind = "added"
+	ChangeRemoved ChangeKind = "removed"
+	ChangeChanged ChangeKind = "changed"
+)
+
+// Sections compared entry by entry; other compared top-level fields are
+// compared as a whole. Custom top-level fields only matter through the
+// anchors they define, which are already expanded.
+var (
+	diffSections = []string{"runners", "images", "pools"}
+	diffFields   = []string{"_extends", "admins"}
+)
+
+// Change is a single semantic difference between two configs
+type Change struct {
+	Kind ChangeKind `json:"kind"`
+	// Section is the top-level field, e.g. runners or admins
+	Section string `json:"section"`
+	// Name is the runner, image or pool, or empty for other sections
+	Name string `json:"name,omitempty"`
+	// Path is the field within the entry, e.g. cpu or schedule[default].hot,
+	// or empty when the entry or section changed as a whole
+	Path string `json:"path,omitempty"`
+	// Old and New are the decoded values, nil when the value is added or
+	// removed
+	Old any `json:"old"`
+	New any `json:"new"`
+}
+
+// Diff returns the semantic changes from a to b, by section (_extends,
+// admins, runners, images, pools), then by name and path. Flexible fields
+// are compared in their canonical forms, so cpu: "2+4" equals cpu: [2, 4].
+func Diff(a, b *Config) []Change {
+	return DiffData(diffData(a), diffData(b))
+}
+
+// DiffData is Diff for decoded configs, which should be normalized first
+// (see Normalize). Admins are compared as a set and pool schedules are
+// matched by name.
+func DiffData(a, b map[string]any) []Change {
+	var changes []Change
+
+	for _, field := range diffFields {
+		oldValue, newValue := a[field], b[field]
+		if field == "admins" {
+			changes = append(changes, diffSet(field, oldValue, newValue)...)
+			continue
+		}
+		changes = append(changes, diffValue(field, "", "", oldValue, newValue)...)
+	}
+
+	for _, section := range diffSections {
+		oldEntries, _ := a[section].(map[string]any)
+		newEntries, _ := b[section].(map[string]any)
+		for _, name := range unionKeys(oldEntries, newEntries) {
+			oldEntry, inOld := oldEntries[name]
+			newEntry, inNew := newEntries[name]
+			switch {
+			case !inOld:
+				changes = append(changes, Change{Kind: ChangeAdded, Section: section, Name: name, New: newEntry})
+			case !inNew:
+				changes = append(changes, Change{Kind: ChangeRemoved, Section: section, Name: name, Old: oldEntry})
+			default:
+				changes = append(changes, diffValue(section, name, "", oldEntry, newEntry)...)
+			}
+		}
+	}
+
+	return changes
+}
+
+// diffData returns the decoded, normalized form of a typed config
+func diffData(cfg *Config) map[string]any {
+	data := make(map[string]any)
+	if cfg == nil {
+		return data
+	}
+	// The typed fields always encode into a mapping
+	src, err := yaml.Marshal(cfg)
+	if err == nil {
+		err = yaml.Unmarshal(src, &data)
+	}
+	if err != nil {
+		panic(fmt.Sprintf("config: encoding config: %v", err))
+	}
+	Normalize(data)
+	return data
+}
+
+// diffValue recursively compares two values. Maps are compared key by key and
+// lists of named entries (pool schedules) are matched by name.
+func diffValue(section, name, path string, oldValue, newValue any) []Change {
+	if reflect.DeepEqual(oldValue, newValue) {
+		return nil
+	}
+	switch {
+	case oldValue == nil:
+		return []Change{{Kind: ChangeAdded, Section: section, Name: name, Path: path, New: newValue}}
+	case newValue == nil:
+		return []Change{{Kind: ChangeRemoved, Section: section, Name: name, Path: path, Old: oldValue}}
+	}
+
+	oldMap, oldIsMap := oldValue.(map[string]any)
+	newMap, newIsMap := newValue.(map[string]any)
+	if oldIsMap && newIsMap {
+		var changes []Change
+		for _, key := range unionKeys(oldMap, newMap) {
+			changes = append(changes, diffValue(section, name, joinPath(path, key), oldMap[key], newMap[key])...)
+		}
+		return changes
+	}
+
+	oldNamed, oldOK := namedEntries(oldValue)
+	newNamed, newOK := namedEntries(newValue)
+	if oldOK && newOK {
+		var changes []Change
+		for _, key := range unionKeys(oldNamed, newNamed) {
+			changes = append(changes, diffValue(section, name, fmt.Sprintf("%s[%s]", path, key), oldNamed[key], newNamed[key])...)
+		}
+		return changes
+	}
+
+	return []Change{{Kind: ChangeChanged, Section: section, Name: name, Path: path, Old: oldValue, New: newValue}}
+}
+
+// diffSet compares two lists as unordered sets of scalars
+func diffSet(section string, oldValue, newValue any) []Change {
+	oldItems, _ := oldValue.([]any)
+	newItems, _ := newValue.([]any)
+	var changes []Change
+	for _, item := range newItems {
+		if !containsValue(oldItems, item) {
+			changes = append(changes, Change{Kind: ChangeAdded, Section: section, New: item})
+		}
+	}
+	for _, item := range oldItems {
+		if !containsValue(newItems, item) {
+			changes = append(changes, Change{Kind: ChangeRemoved, Section: section, Old: item})
+		}
+	}
+	return changes
+}
+
+// namedEntries indexes a list of maps by their unique "name" field
+func namedEntries(value any) (map[string]any, bool) {
+	items, ok := value.([]any)
+	if !ok {
+		return nil, false
+	}
+	result := make(map[string]any, len(items))
+	for _, item := range items {
+		entry, ok := item.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		name, ok := entry["name"].(string)
+		if !ok {
+			return nil, false
+		}
+		if _, duplicate := result[name]; duplicate {
+			return nil, false
+		}
+		result[name] = entry
+	}
+	return result, true
+}
+
+func unionKeys(a, b map[string]any) []string {
+	seen := make(map[string]bool)
+	var keys []string
+	for _, m := range []map[string]any{a, b} {
+		for key := range m {
+			if !seen[key] {
+				seen[key] = true
+				keys = append(keys, key)
+			}
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func containsValue(items []any, value any) bool {
+	for _, item := range items {
+		if reflect.DeepEqual(item, value) {
+			return true
+		}
+	}
+	return false
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
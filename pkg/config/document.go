@@ -0,0 +1,259 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Document is a config backed by its YAML node tree, for tools editing
+// configs: comments, anchors, aliases and key order are kept when it is
+// written back with Bytes. Fields are addressed by dotted paths, with list
+// indexes as segments, e.g. "pools.main.schedule.0.hot".
+type Document struct {
+	node yaml.Node
+}
+
+// LoadDocument reads the config file at path for editing. Local _extends are
+// not merged, so that the file is written back as it is.
+func LoadDocument(path string) (*Document, error) {
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := ParseDocument(src)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return doc, nil
+}
+
+// ParseDocument parses a config for editing. An empty source is an empty
+// config.
+func ParseDocument(src []byte) (*Document, error) {
+	var doc Document
+	if err := yaml.Unmarshal(src, &doc.node); err != nil {
+		return nil, fmt.Errorf("failed to parse YAML: %w", err)
+	}
+	if len(doc.node.Content) == 0 {
+		doc.node = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}}
+	}
+	if doc.node.Content[0].Kind != yaml.MappingNode {
+		return nil, errors.New("config is not a mapping")
+	}
+	return &doc, nil
+}
+
+// Root returns the top-level mapping of the config, to edit it directly
+func (d *Document) Root() *yaml.Node {
+	return d.node.Content[0]
+}
+
+// Config decodes the current state of the document into its typed form
+func (d *Document) Config() (*Config, error) {
+	var cfg Config
+	if err := d.node.Decode(&cfg); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+// Lookup returns the value at a field path, with aliases resolved, or nil if
+// it is not set. Fields merged in with '<<' are not looked up.
+func (d *Document) Lookup(path string) *yaml.Node {
+	n := d.Root()
+	for _, segment := range strings.Split(path, ".") {
+		if n = child(resolveAlias(n), segment); n == nil {
+			return nil
+		}
+	}
+	return resolveAlias(n)
+}
+
+// Set sets the field at path to value, encoded as YAML. Missing mappings
+// along the path are created, and new fields are added after the existing
+// ones. A replaced value keeps its comments and anchor, so that aliases to
+// it see the new value. Set fails if the path goes through an alias, as the
+// edit would change every place the anchor is used.
+func (d *Document) Set(path string, value any) error {
+	var n yaml.Node
+	if err := n.Encode(value); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	parent, last, err := d.parent(path, true)
+	if err != nil {
+		return err
+	}
+	if parent == nil {
+		return fmt.Errorf("%s: index out of range", path)
+	}
+	old := child(parent, last)
+	if old == nil {
+		if parent.Kind != yaml.MappingNode {
+			return fmt.Errorf("%s: index out of range", path)
+		}
+		parent.Content = append(parent.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: last}, &n)
+		return nil
+	}
+	if old.Kind != yaml.AliasNode {
+		n.Anchor = old.Anchor
+	}
+	n.HeadComment, n.LineComment, n.FootComment = old.HeadComment, old.LineComment, old.FootComment
+	*old = n
+	return nil
+}
+
+// Delete removes the field at path, or the list item at an index, keeping
+// comments above it on the next entry. It reports whether the field was set,
+// and fails like Set if the path goes through an alias.
+func (d *Document) Delete(path string) (bool, error) {
+	parent, last, err := d.parent(path, false)
+	if err != nil || parent == nil {
+		return false, err
+	}
+	i := childIndex(parent, last)
+	if i < 0 {
+		return false, nil
+	}
+	width := 1
+	if parent.Kind == yaml.MappingNode {
+		width = 2
+	}
+	if comment := parent.Content[i].HeadComment; comment != "" && i+width < len(parent.Content) && parent.Content[i+width].HeadComment == "" {
+		parent.Content[i+width].HeadComment = comment
+	}
+	parent.Content = append(parent.Content[:i], parent.Content[i+width:]...)
+	return true, nil
+}
+
+// RenameRunner renames a runner and updates the pools referring to it by
+// name. It fails if there is no runner old or a runner new already exists.
+func (d *Document) RenameRunner(old, new string) error {
+	runners := d.Lookup("runners")
+	i := childIndex(runners, old)
+	if i < 0 || runners.Kind != yaml.MappingNode {
+		return fmt.Errorf("runner '%s' is not defined", old)
+	}
+	if childIndex(runners, new) >= 0 {
+		return fmt.Errorf("runner '%s' already exists", new)
+	}
+	runners.Content[i].Value = new
+
+	if pools := d.Lookup("pools"); pools != nil && pools.Kind == yaml.MappingNode {
+		for j := 1; j < len(pools.Content); j += 2 {
+			// Aliased references are left alone, as their anchor may be
+			// used elsewhere
+			if runner := child(pools.Content[j], "runner"); runner != nil && runner.Kind == yaml.ScalarNode && runner.Value == old {
+				runner.Value = new
+			}
+		}
+	}
+	return nil
+}
+
+// Bytes encodes the document as YAML, indented by two spaces
+func (d *Document) Bytes() ([]byte, error) {
+	clearMergeTags(&d.node)
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&d.node); err != nil {
+		return nil, fmt.Errorf("failed to encode YAML: %w", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, fmt.Errorf("failed to encode YAML: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// clearMergeTags untags merge keys, which yaml.v3 would otherwise emit as
+// "!!merge <<". Untagged "<<" keys are still merged when decoding.
+func clearMergeTags(n *yaml.Node) {
+	if n.Kind == yaml.ScalarNode && n.Tag == "!!merge" {
+		n.Tag = ""
+	}
+	for _, c := range n.Content {
+		clearMergeTags(c)
+	}
+}
+
+// parent returns the node holding the last segment of path and that segment.
+// With create, missing mappings are added; otherwise a nil parent is returned
+// when one is missing.
+func (d *Document) parent(path string, create bool) (*yaml.Node, string, error) {
+	segments := strings.Split(path, ".")
+	n := d.Root()
+	for i, segment := range segments[:len(segments)-1] {
+		next := child(n, segment)
+		if next == nil {
+			if !create || n.Kind != yaml.MappingNode {
+				return nil, "", nil
+			}
+			next = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+			n.Content = append(n.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: segment}, next)
+		}
+		if next.Kind == yaml.AliasNode {
+			return nil, "", fmt.Errorf("%s: %s is an alias; edit its anchor instead", path, strings.Join(segments[:i+1], "."))
+		}
+		if next.Kind != yaml.MappingNode && next.Kind != yaml.SequenceNode {
+			return nil, "", fmt.Errorf("%s: %s is not a mapping or a list", path, strings.Join(segments[:i+1], "."))
+		}
+		n = next
+	}
+	return n, segments[len(segments)-1], nil
+}
+
+// child returns the value of a mapping entry or the item of a list at a
+// numeric index, without resolving aliases, or nil
+func child(n *yaml.Node, segment string) *yaml.Node {
+	i := childIndex(n, segment)
+	if i < 0 {
+		return nil
+	}
+	if n.Kind == yaml.MappingNode {
+		return n.Content[i+1]
+	}
+	return n.Content[i]
+}
+
+// childIndex returns the index in n.Content of the key of a mapping entry or
+// of the item of a list, or -1
+func childIndex(n *yaml.Node, segment string) int {
+	if n == nil {
+		return -1
+	}
+	if n.Kind == yaml.SequenceNode {
+		index, err := strconv.Atoi(segment)
+		if err != nil || index < 0 || index >= len(n.Content) {
+			return -1
+		}
+		return index
+	}
+	return mappingIndex(n, segment)
+}
+
+// mappingIndex returns the index of the key node for key in a mapping node,
+// or -1
+func mappingIndex(n *yaml.Node, key string) int {
+	if n.Kind != yaml.MappingNode {
+		return -1
+	}
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if n.Content[i].Value == key {
+			return i
+		}
+	}
+	return -1
+}
+
+func resolveAlias(n *yaml.Node) *yaml.Node {
+	for n.Kind == yaml.AliasNode && n.Alias != nil {
+		n = n.Alias
+	}
+	return n
+}
@@ -0,0 +1,37 @@
+package config
+
+import (
+	"fmt"
+
+	"github.com/runs-on/config/pkg/extends"
+	"gopkg.in/yaml.v3"
+)
+
+// Load reads the config file at path into its typed form, with local
+// _extends merged and anchors expanded. The config is not validated: use
+// validate.ValidateAndParse to report invalid fields with their position.
+func Load(path string) (*Config, error) {
+	doc, err := extends.Load(path, extends.Options{})
+	if err != nil {
+		return nil, err
+	}
+	data, err := yaml.Marshal(doc.Data)
+	if err != nil {
+		return nil, err
+	}
+	cfg, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// Parse decodes a config held in memory into its typed form, with anchors
+// expanded. Local _extends are not merged.
+func Parse(data []byte) (*Config, error) {
+	var cfg Config
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
@@ -0,0 +1,166 @@
+package config
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Runner fields that accept a single value, a "+"-separated string or a list
+var (
+	numberListFields = []string{"cpu", "ram"}
+	stringListFields = []string{"family", "retry", "extras", "tags"}
+	boolFields       = []string{"ssh", "nested-virt", "private", "debug"}
+)
+
+// Numbers returns the canonical form of a decoded number field such as cpu:
+// 2, "2+4" or [2, 4]
+func Numbers(value any) (NumberList, error) {
+	var items []any
+	switch v := value.(type) {
+	case []any:
+		items = v
+	default:
+		items = []any{v}
+	}
+	var result NumberList
+	for _, item := range items {
+		switch v := item.(type) {
+		case int:
+			result = append(result, float64(v))
+		case float64:
+			result = append(result, v)
+		case string:
+			for _, part := range strings.Split(v, "+") {
+				n, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+				if err != nil {
+					return nil, fmt.Errorf("invalid number %q", part)
+				}
+				result = append(result, n)
+			}
+		default:
+			return nil, fmt.Errorf("invalid number %v", item)
+		}
+	}
+	return result, nil
+}
+
+// Strings returns the canonical form of a decoded string list field such as
+// retry: "when-interrupted+on-failure" or extras: [s3-cache, tmpfs]. List
+// items are kept as they are; other values than strings are left out.
+func Strings(value any) StringList {
+	switch v := value.(type) {
+	case string:
+		return splitItems(v)
+	case []any:
+		var result StringList
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				result = append(result, s)
+			}
+		}
+		return result
+	}
+	return nil
+}
+
+// ParseBool returns the value of a decoded boolean field, which may also be
+// the string "true" or "false"
+func ParseBool(value any) (bool, error) {
+	switch v := value.(type) {
+	case bool:
+		return v, nil
+	case string:
+		if b, err := strconv.ParseBool(v); err == nil {
+			return b, nil
+		}
+	}
+	return false, fmt.Errorf("invalid boolean %v", value)
+}
+
+// Normalize rewrites the flexible runner and image fields of a decoded
+// config in place into their canonical forms, so that equivalent spellings
+// compare equal: lists of numbers and strings, booleans, and spot values and
+// image owners as strings. Values that cannot be normalized are left as they
+// are.
+func Normalize(doc map[string]any) {
+	if runners, ok := doc["runners"].(map[string]any); ok {
+		for _, value := range runners {
+			runner, ok := value.(map[string]any)
+			if !ok {
+				continue
+			}
+			for key, field := range runner {
+				switch {
+				case slices.Contains(numberListFields, key):
+					if numbers, err := Numbers(field); err == nil {
+						runner[key] = numberValues(numbers)
+					}
+				case slices.Contains(stringListFields, key):
+					switch v := field.(type) {
+					case string:
+						runner[key] = anyValues(Strings(v))
+					case []any:
+					default:
+						runner[key] = []any{v}
+					}
+				case slices.Contains(boolFields, key):
+					if b, err := ParseBool(field); err == nil {
+						runner[key] = b
+					}
+				case key == "spot":
+					if b, ok := field.(bool); ok {
+						runner[key] = strconv.FormatBool(b)
+					}
+				}
+			}
+		}
+	}
+
+	if images, ok := doc["images"].(map[string]any); ok {
+		for _, value := range images {
+			image, ok := value.(map[string]any)
+			if !ok {
+				continue
+			}
+			// Account IDs are often written unquoted
+			if owner, ok := image["owner"].(int); ok {
+				image["owner"] = strconv.Itoa(owner)
+			}
+		}
+	}
+}
+
+// splitItems returns the trimmed, non-empty items of a "+"-separated value
+func splitItems(value string) []string {
+	var items []string
+	for _, part := range strings.Split(value, "+") {
+		if part = strings.TrimSpace(part); part != "" {
+			items = append(items, part)
+		}
+	}
+	return items
+}
+
+// numberValues returns numbers as decoded YAML values, integers as int
+func numberValues(numbers NumberList) []any {
+	result := make([]any, len(numbers))
+	for i, n := range numbers {
+		if n == math.Trunc(n) && math.Abs(n) < 1<<53 {
+			result[i] = int(n)
+		} else {
+			result[i] = n
+		}
+	}
+	return result
+}
+
+func anyValues[S ~[]string](values S) []any {
+	result := make([]any, len(values))
+	for i, v := range values {
+		result[i] = v
+	}
+	return result
+}
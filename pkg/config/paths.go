@@ -0,0 +1,127 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FieldPath is a field set in a config
+type FieldPath struct {
+	// Path is the dotted path of the field, with list indexes as segments,
+	// e.g. "runners.small.cpu" or "pools.main.schedule.0.hot"
+	Path string `json:"path"`
+	// Type is the type of the value: map, list, string, int, float, bool or
+	// null
+	Type string `json:"type"`
+	// Line and Column locate the field: its key, or the item for list items
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+// FieldPaths returns every field set in a config, in document order, with
+// anchors expanded. Fields merged in with '<<' are listed after the fields set
+// directly, at the position of the merged definition. Top-level x-* blocks are
+// listed like any other field.
+func FieldPaths(src []byte) ([]FieldPath, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(src, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse YAML: %w", err)
+	}
+	if len(doc.Content) == 0 {
+		return nil, nil
+	}
+	var paths []FieldPath
+	collectFieldPaths(&paths, "", doc.Content[0], make(map[*yaml.Node]bool))
+	return paths, nil
+}
+
+// collectFieldPaths appends the fields of the value n at path, without n
+// itself. active holds the aliased nodes being walked, so that recursive
+// aliases are not followed forever.
+func collectFieldPaths(paths *[]FieldPath, path string, n *yaml.Node, active map[*yaml.Node]bool) {
+	n = resolveAlias(n)
+	if active[n] {
+		return
+	}
+	active[n] = true
+	defer delete(active, n)
+
+	add := func(segment string, at, value *yaml.Node) {
+		child := segment
+		if path != "" {
+			child = path + "." + segment
+		}
+		*paths = append(*paths, FieldPath{Path: child, Type: valueType(value), Line: at.Line, Column: at.Column})
+		collectFieldPaths(paths, child, value, active)
+	}
+	switch n.Kind {
+	case yaml.MappingNode:
+		for _, field := range mergedFields(n, make(map[*yaml.Node]bool)) {
+			add(field[0].Value, field[0], field[1])
+		}
+	case yaml.SequenceNode:
+		for i, item := range n.Content {
+			add(strconv.Itoa(i), item, item)
+		}
+	}
+}
+
+// mergedFields returns the key and value nodes of a mapping: the fields set
+// directly, then those merged in with '<<' that are not set directly
+func mergedFields(n *yaml.Node, seen map[*yaml.Node]bool) [][2]*yaml.Node {
+	if seen[n] {
+		return nil
+	}
+	seen[n] = true
+
+	var fields, merged [][2]*yaml.Node
+	set := make(map[string]bool)
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		key, value := n.Content[i], n.Content[i+1]
+		if key.ShortTag() != "!!merge" {
+			fields = append(fields, [2]*yaml.Node{key, value})
+			set[key.Value] = true
+			continue
+		}
+		sources := []*yaml.Node{value}
+		if value = resolveAlias(value); value.Kind == yaml.SequenceNode {
+			sources = value.Content
+		}
+		for _, source := range sources {
+			if source = resolveAlias(source); source.Kind == yaml.MappingNode {
+				merged = append(merged, mergedFields(source, seen)...)
+			}
+		}
+	}
+	for _, field := range merged {
+		if !set[field[0].Value] {
+			fields = append(fields, field)
+			set[field[0].Value] = true
+		}
+	}
+	return fields
+}
+
+// valueType returns the type of a value for FieldPath
+func valueType(n *yaml.Node) string {
+	n = resolveAlias(n)
+	switch n.Kind {
+	case yaml.MappingNode:
+		return "map"
+	case yaml.SequenceNode:
+		return "list"
+	}
+	switch n.ShortTag() {
+	case "!!int":
+		return "int"
+	case "!!float":
+		return "float"
+	case "!!bool":
+		return "bool"
+	case "!!null":
+		return "null"
+	}
+	return "string"
+}
@@ -0,0 +1,146 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Week is the length of a pool timeline
+const Week = 7 * 24 * time.Hour
+
+// weekdays are the day names of schedule match criteria, in timeline order
+var weekdays = []string{"monday", "tuesday", "wednesday", "thursday", "friday", "saturday", "sunday"}
+
+// Interval is a span of the week during which a pool keeps the instances of
+// one schedule entry
+type Interval struct {
+	// Start and End are offsets from Monday 00:00 in the pool timezone. End
+	// is exclusive and at most Week.
+	Start, End time.Duration
+	// Entry is the index of the schedule entry in Pool.Schedule
+	Entry   int
+	Name    string
+	Hot     int
+	Stopped int
+}
+
+// Duration returns the length of the interval
+func (i Interval) Duration() time.Duration {
+	return i.End - i.Start
+}
+
+// Timeline expands the schedule of the pool into the intervals of a week,
+// in order. An entry with match criteria applies on its days (every day if
+// none) during its [start, end] time range (all day if none), which may wrap
+// around midnight; the first entry that matches wins. The first entry
+// without criteria applies whenever no other entry does. Times no entry
+// applies to keep no instances and are left out. Timeline fails for
+// unknown days and invalid time ranges.
+func (p Pool) Timeline() ([]Interval, error) {
+	const minutes = int(Week / time.Minute)
+	owner := make([]int, minutes)
+	for i := range owner {
+		owner[i] = -1
+	}
+
+	fallback := -1
+	for index, entry := range p.Schedule {
+		if entry.Match == nil || (len(entry.Match.Day) == 0 && len(entry.Match.Time) == 0) {
+			if fallback < 0 {
+				fallback = index
+			}
+			continue
+		}
+		days, err := matchedDays(entry.Match.Day)
+		if err != nil {
+			return nil, fmt.Errorf("schedule '%s': %w", entry.Name, err)
+		}
+		start, length, err := matchedTimes(entry.Match.Time)
+		if err != nil {
+			return nil, fmt.Errorf("schedule '%s': %w", entry.Name, err)
+		}
+		for _, day := range days {
+			for m := range length {
+				// A range wrapping past Sunday midnight continues on Monday
+				minute := (day*24*60 + start + m) % minutes
+				if owner[minute] < 0 {
+					owner[minute] = index
+				}
+			}
+		}
+	}
+	if fallback >= 0 {
+		for i, index := range owner {
+			if index < 0 {
+				owner[i] = fallback
+			}
+		}
+	}
+
+	var intervals []Interval
+	for start := 0; start < minutes; {
+		end := start + 1
+		for end < minutes && owner[end] == owner[start] {
+			end++
+		}
+		if index := owner[start]; index >= 0 {
+			entry := p.Schedule[index]
+			intervals = append(intervals, Interval{
+				Start:   time.Duration(start) * time.Minute,
+				End:     time.Duration(end) * time.Minute,
+				Entry:   index,
+				Name:    entry.Name,
+				Hot:     entry.Hot,
+				Stopped: entry.Stopped,
+			})
+		}
+		start = end
+	}
+	return intervals, nil
+}
+
+// matchedDays returns the indexes in weekdays of the days of a schedule
+// entry, or every day if there are none
+func matchedDays(days []string) ([]int, error) {
+	if len(days) == 0 {
+		return []int{0, 1, 2, 3, 4, 5, 6}, nil
+	}
+	var result []int
+	for _, day := range days {
+		index := slices.Index(weekdays, strings.ToLower(day))
+		if index < 0 {
+			return nil, fmt.Errorf("unknown day %q", day)
+		}
+		if !slices.Contains(result, index) {
+			result = append(result, index)
+		}
+	}
+	return result, nil
+}
+
+// matchedTimes returns the start and length in minutes of a [start, end]
+// time range such as ["22:00", "06:00"], or the whole day if there is none.
+// A range ending at or before its start ends the next day.
+func matchedTimes(times []string) (int, int, error) {
+	if len(times) == 0 {
+		return 0, 24 * 60, nil
+	}
+	if len(times) != 2 {
+		return 0, 0, fmt.Errorf("expected a start and an end time, got %v", times)
+	}
+	start, err := time.Parse("15:04", times[0])
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid time %q", times[0])
+	}
+	end, err := time.Parse("15:04", times[1])
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid time %q", times[1])
+	}
+	length := int(end.Sub(start).Minutes())
+	if length <= 0 {
+		length += 24 * 60
+	}
+	return start.Hour()*60 + start.Minute(), length, nil
+}
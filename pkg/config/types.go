@@ -0,0 +1,125 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// NumberList is a number field that accepts a single value, a "+"-separated
+// string ("2+4") or a list
+type NumberList []float64
+
+// UnmarshalYAML implements yaml.Unmarshaler
+func (l *NumberList) UnmarshalYAML(value *yaml.Node) error {
+	items, err := scalarItems(value)
+	if err != nil {
+		return err
+	}
+	var result NumberList
+	for _, item := range items {
+		for _, part := range strings.Split(item, "+") {
+			n, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+			if err != nil {
+				return fmt.Errorf("line %d: invalid number %q", value.Line, part)
+			}
+			result = append(result, n)
+		}
+	}
+	*l = result
+	return nil
+}
+
+// StringList is a string field that accepts a single value, a "+"-separated
+// string ("s3-cache+tmpfs") or a list
+type StringList []string
+
+// UnmarshalYAML implements yaml.Unmarshaler
+func (l *StringList) UnmarshalYAML(value *yaml.Node) error {
+	items, err := scalarItems(value)
+	if err != nil {
+		return err
+	}
+	var result StringList
+	for _, item := range items {
+		if value.Kind == yaml.SequenceNode {
+			result = append(result, item)
+			continue
+		}
+		result = append(result, splitItems(item)...)
+	}
+	*l = result
+	return nil
+}
+
+// Bool is a boolean field that also accepts the strings "true" and "false"
+type Bool bool
+
+// UnmarshalYAML implements yaml.Unmarshaler
+func (b *Bool) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind != yaml.ScalarNode {
+		return fmt.Errorf("line %d: expected a boolean", value.Line)
+	}
+	parsed, err := strconv.ParseBool(value.Value)
+	if err != nil {
+		return fmt.Errorf("line %d: invalid boolean %q", value.Line, value.Value)
+	}
+	*b = Bool(parsed)
+	return nil
+}
+
+// IsTrue reports whether b is set to true. It is false for a nil Bool.
+func (b *Bool) IsTrue() bool {
+	return b != nil && bool(*b)
+}
+
+// Spot is the runner spot strategy. Booleans are read as "true" or "false".
+type Spot string
+
+// UnmarshalYAML implements yaml.Unmarshaler
+func (s *Spot) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind != yaml.ScalarNode {
+		return fmt.Errorf("line %d: expected a spot value", value.Line)
+	}
+	*s = Spot(value.Value)
+	return nil
+}
+
+// Canonical returns the spot value with aliases resolved: "never" becomes
+// "false", and "price-capacity-optimized", "lowest-price" and
+// "capacity-optimized" become "pco", "lp" and "co"
+func (s Spot) Canonical() string {
+	switch value := string(s); value {
+	case "never":
+		return "false"
+	case "price-capacity-optimized":
+		return "pco"
+	case "lowest-price":
+		return "lp"
+	case "capacity-optimized":
+		return "co"
+	default:
+		return value
+	}
+}
+
+// scalarItems returns the values of a scalar or of a sequence of scalars
+func scalarItems(value *yaml.Node) ([]string, error) {
+	switch value.Kind {
+	case yaml.ScalarNode:
+		return []string{value.Value}, nil
+	case yaml.SequenceNode:
+		items := make([]string, 0, len(value.Content))
+		for _, item := range value.Content {
+			if item.Kind != yaml.ScalarNode {
+				return nil, fmt.Errorf("line %d: expected a list of values", item.Line)
+			}
+			items = append(items, item.Value)
+		}
+		return items, nil
+	default:
+		return nil, fmt.Errorf("line %d: expected a value or a list of values", value.Line)
+	}
+}
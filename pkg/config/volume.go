@@ -0,0 +1,80 @@
+package config
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// VolumeComponentKind is the kind of a component of a volume specification
+type VolumeComponentKind int
+
+// Kinds of volume specification components, in their canonical order
+const (
+	VolumeSize VolumeComponentKind = iota
+	VolumeType
+	VolumeThroughput
+	VolumeIOPS
+	// VolumeInvalid is a component that is none of the above, e.g. empty
+	VolumeInvalid
+)
+
+var (
+	volumeQuantity = regexp.MustCompile(`^([0-9]+)(gb|mbs|mbps|iops)$`)
+	volumeWord     = regexp.MustCompile(`^[a-z][a-z0-9]*$`)
+)
+
+// VolumeComponent is a component of a volume specification
+type VolumeComponent struct {
+	Kind VolumeComponentKind
+	// Text is the component as written
+	Text string
+	// Value is the number of a size, throughput or iops component
+	Value int
+	// Name is the lowercase name of a type component
+	Name string
+	// Offset is the byte offset of the component in the specification
+	Offset int
+}
+
+// ParseVolume splits a volume specification such as
+// "80gb:gp3:125mbs:3000iops" into its components, which may be given in any
+// order. Volume types are not checked against the types EBS supports.
+func ParseVolume(spec string) []VolumeComponent {
+	var components []VolumeComponent
+	offset := 0
+	for _, text := range strings.Split(spec, ":") {
+		component := VolumeComponent{Kind: VolumeInvalid, Text: text, Offset: offset}
+		offset += len(text) + 1
+		lower := strings.ToLower(strings.TrimSpace(text))
+		if match := volumeQuantity.FindStringSubmatch(lower); match != nil {
+			if value, err := strconv.Atoi(match[1]); err == nil {
+				component.Value = value
+				switch match[2] {
+				case "gb":
+					component.Kind = VolumeSize
+				case "mbs", "mbps":
+					component.Kind = VolumeThroughput
+				default:
+					component.Kind = VolumeIOPS
+				}
+			}
+		} else if volumeWord.MatchString(lower) {
+			component.Kind = VolumeType
+			component.Name = lower
+		}
+		components = append(components, component)
+	}
+	return components
+}
+
+// VolumeSizeGB returns the size in GB a volume specification sets, wherever
+// its size component is, or false if it sets none
+func VolumeSizeGB(spec string) (int, bool) {
+	for _, component := range ParseVolume(spec) {
+		if component.Kind == VolumeSize {
+			return component.Value, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,191 @@
+// Package cost estimates the monthly spend of the pools in a config from a
+// table of EC2 prices.
+package cost
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/runs-on/config/pkg/catalog"
+	"github.com/runs-on/config/pkg/config"
+)
+
+const (
+	hoursPerWeek  = 168
+	hoursPerMonth = 730
+	// defaultVolumeGB is the volume size of runners without a volume spec
+	defaultVolumeGB = 40
+)
+
+// Report is the estimated monthly cost of the pools of a config
+type Report struct {
+	Region        string     `json:"region"`
+	Currency      string     `json:"currency"`
+	PricesUpdated string     `json:"prices_updated"`
+	Pools         []PoolCost `json:"pools"`
+	OnDemand      float64    `json:"on_demand"`
+	Spot          float64    `json:"spot"`
+}
+
+// PoolCost is the estimated monthly cost of a pool
+type PoolCost struct {
+	Name   string `json:"name"`
+	Runner string `json:"runner"`
+	// OnDemandInstance and SpotInstance are the cheapest instance types
+	// matching the runner for each pricing model
+	OnDemandInstance string       `json:"on_demand_instance,omitempty"`
+	SpotInstance     string       `json:"spot_instance,omitempty"`
+	VolumeGB         int          `json:"volume_gb,omitempty"`
+	Windows          []WindowCost `json:"windows,omitempty"`
+	OnDemand         float64      `json:"on_demand"`
+	Spot             float64      `json:"spot"`
+	// Error explains why the pool could not be estimated
+	Error string `json:"error,omitempty"`
+}
+
+// WindowCost is the estimated monthly cost of one schedule entry of a pool.
+// Hot instances are billed for compute and storage, stopped instances for
+// storage only.
+type WindowCost struct {
+	Name          string  `json:"name"`
+	HoursPerMonth float64 `json:"hours_per_month"`
+	Hot           int     `json:"hot"`
+	Stopped       int     `json:"stopped"`
+	OnDemand      float64 `json:"on_demand"`
+	Spot          float64 `json:"spot"`
+}
+
+// Estimate returns the monthly cost of the pools in cfg, sorted by pool
+// name. Pools that cannot be estimated, e.g. because no instance in the
+// price table matches their runner, have Error set and cost nothing.
+func Estimate(cfg *config.Config, prices *PriceTable) Report {
+	report := Report{Region: prices.Region, Currency: prices.Currency, PricesUpdated: prices.Updated}
+
+	names := make([]string, 0, len(cfg.Pools))
+	for name := range cfg.Pools {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		poolCost := estimatePool(name, cfg.Pools[name], cfg.Runners, prices)
+		report.Pools = append(report.Pools, poolCost)
+		report.OnDemand += poolCost.OnDemand
+		report.Spot += poolCost.Spot
+	}
+	return report
+}
+
+func estimatePool(name string, pool config.Pool, runners map[string]config.Runner, prices *PriceTable) PoolCost {
+	poolCost := PoolCost{Name: name, Runner: pool.Runner}
+
+	runner, ok := runners[pool.Runner]
+	if !ok {
+		poolCost.Error = fmt.Sprintf("runner '%s' is not defined", pool.Runner)
+		return poolCost
+	}
+	onDemand, spot, ok := cheapestInstances(runner, prices)
+	if !ok {
+		poolCost.Error = fmt.Sprintf("no instance in the price table matches runner '%s'", pool.Runner)
+		return poolCost
+	}
+	hours, err := windowHours(pool)
+	if err != nil {
+		poolCost.Error = err.Error()
+		return poolCost
+	}
+
+	poolCost.OnDemandInstance = onDemand.Type
+	poolCost.SpotInstance = spot.Type
+	poolCost.VolumeGB = volumeSize(runner.Volume)
+	storageHour := float64(poolCost.VolumeGB) * prices.EBSGBMonth / hoursPerMonth
+	for i, schedule := range pool.Schedule {
+		window := WindowCost{
+			Name:          schedule.Name,
+			HoursPerMonth: hours[i],
+			Hot:           schedule.Hot,
+			Stopped:       schedule.Stopped,
+		}
+		storage := float64(schedule.Hot+schedule.Stopped) * storageHour * hours[i]
+		window.OnDemand = float64(schedule.Hot)*onDemand.OnDemand*hours[i] + storage
+		window.Spot = float64(schedule.Hot)*spot.Spot*hours[i] + storage
+		poolCost.Windows = append(poolCost.Windows, window)
+		poolCost.OnDemand += window.OnDemand
+		poolCost.Spot += window.Spot
+	}
+	return poolCost
+}
+
+// cheapestInstances returns the cheapest on-demand and spot instances
+// matching the runner's family, cpu and ram. A runner without a family
+// matches any instance.
+func cheapestInstances(runner config.Runner, prices *PriceTable) (onDemand, spot Instance, ok bool) {
+	matchFamily := func(Instance) bool { return true }
+	if len(runner.Family) > 0 {
+		families := make(map[string]bool)
+		types := make(map[string]bool)
+		for _, value := range runner.Family {
+			if strings.Contains(value, ".") {
+				types[value] = true
+				continue
+			}
+			expanded, err := catalog.Expand(value)
+			if err != nil {
+				continue
+			}
+			for _, family := range expanded {
+				families[family] = true
+			}
+		}
+		matchFamily = func(instance Instance) bool {
+			return types[instance.Type] || families[instance.Family()]
+		}
+	}
+
+	for _, instance := range prices.Instances {
+		if !matchFamily(instance) ||
+			(len(runner.CPU) > 0 && !slices.Contains(runner.CPU, instance.VCPU)) ||
+			(len(runner.RAM) > 0 && !slices.Contains(runner.RAM, instance.MemoryGB)) {
+			continue
+		}
+		if !ok || instance.OnDemand < onDemand.OnDemand {
+			onDemand = instance
+		}
+		if instance.Spot > 0 && (spot.Spot == 0 || instance.Spot < spot.Spot) {
+			spot = instance
+		}
+		ok = true
+	}
+	if ok && spot.Spot == 0 {
+		// No spot price is known: assume on-demand pricing
+		spot = onDemand
+		spot.Spot = onDemand.OnDemand
+	}
+	return onDemand, spot, ok
+}
+
+// windowHours returns the hours per month each schedule entry of the pool
+// applies, from its timeline (see config.Pool.Timeline)
+func windowHours(pool config.Pool) ([]float64, error) {
+	timeline, err := pool.Timeline()
+	if err != nil {
+		return nil, err
+	}
+	hours := make([]float64, len(pool.Schedule))
+	for _, interval := range timeline {
+		hours[interval.Entry] += interval.Duration().Hours() * hoursPerMonth / hoursPerWeek
+	}
+	return hours, nil
+}
+
+// volumeSize returns the size in GB of a volume specification such as
+// "80gb:gp3:125mbs:3000iops" or "gp3:500gb", or the default size
+func volumeSize(spec string) int {
+	gb, ok := config.VolumeSizeGB(spec)
+	if !ok || gb <= 0 {
+		return defaultVolumeGB
+	}
+	return gb
+}
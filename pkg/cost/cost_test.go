@@ -0,0 +1,143 @@
+package cost_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/runs-on/config/pkg/config"
+	"github.com/runs-on/config/pkg/cost"
+	"gopkg.in/yaml.v3"
+)
+
+func approx(a, b float64) bool {
+	return math.Abs(a-b) < 0.01
+}
+
+func TestEstimate(t *testing.T) {
+	src := `runners:
+  small:
+    cpu: 2
+    family: c7a+c7g
+pools:
+  main:
+    runner: small
+    schedule:
+      - name: default
+        hot: 1
+        stopped: 2
+      - name: nights
+        hot: 0
+        stopped: 1
+        match:
+          day: [monday, tuesday]
+          time: ["22:00", "06:00"]
+  orphan:
+    runner: missing
+`
+	var cfg config.Config
+	if err := yaml.Unmarshal([]byte(src), &cfg); err != nil {
+		t.Fatalf("Failed to parse config: %v", err)
+	}
+	prices := &cost.PriceTable{
+		Region:     "us-east-1",
+		EBSGBMonth: 0.1,
+		Instances: []cost.Instance{
+			{Type: "c7a.large", VCPU: 2, MemoryGB: 4, OnDemand: 0.10, Spot: 0.03},
+			{Type: "c7g.large", VCPU: 2, MemoryGB: 4, OnDemand: 0.08, Spot: 0.04},
+			{Type: "c7g.xlarge", VCPU: 4, MemoryGB: 8, OnDemand: 0.16, Spot: 0.05},
+			{Type: "m7g.large", VCPU: 2, MemoryGB: 8, OnDemand: 0.01, Spot: 0.01},
+		},
+	}
+
+	report := cost.Estimate(&cfg, prices)
+	if len(report.Pools) != 2 || report.Pools[0].Name != "main" || report.Pools[1].Name != "orphan" {
+		t.Fatalf("Unexpected pools: %+v", report.Pools)
+	}
+	if report.Pools[1].Error == "" || report.Pools[1].OnDemand != 0 {
+		t.Errorf("Expected an error for a pool with an undefined runner, got %+v", report.Pools[1])
+	}
+
+	pool := report.Pools[0]
+	if pool.OnDemandInstance != "c7g.large" || pool.SpotInstance != "c7a.large" || pool.VolumeGB != 40 {
+		t.Errorf("Unexpected instance selection: %+v", pool)
+	}
+	if len(pool.Windows) != 2 {
+		t.Fatalf("Expected 2 windows, got %+v", pool.Windows)
+	}
+
+	// nights covers 2 days of 8 hours; default the remaining 152 hours a week
+	nights, defaults := pool.Windows[1], pool.Windows[0]
+	if !approx(nights.HoursPerMonth, 16*730.0/168) || !approx(defaults.HoursPerMonth, 152*730.0/168) {
+		t.Errorf("Unexpected hours: default %v, nights %v", defaults.HoursPerMonth, nights.HoursPerMonth)
+	}
+	storageHour := 40 * 0.1 / 730
+	if want := defaults.HoursPerMonth * (0.08 + 3*storageHour); !approx(defaults.OnDemand, want) {
+		t.Errorf("Expected default on-demand cost %.2f, got %.2f", want, defaults.OnDemand)
+	}
+	if want := defaults.HoursPerMonth * (0.03 + 3*storageHour); !approx(defaults.Spot, want) {
+		t.Errorf("Expected default spot cost %.2f, got %.2f", want, defaults.Spot)
+	}
+	if want := nights.HoursPerMonth * storageHour; !approx(nights.OnDemand, want) || !approx(nights.Spot, want) {
+		t.Errorf("Expected storage-only cost %.2f for nights, got %+v", want, nights)
+	}
+	if !approx(report.OnDemand, defaults.OnDemand+nights.OnDemand) {
+		t.Errorf("Expected the total to sum the windows, got %.2f", report.OnDemand)
+	}
+}
+
+func TestEstimate_InvalidTime(t *testing.T) {
+	cfg := &config.Config{
+		Runners: map[string]config.Runner{"small": {}},
+		Pools: map[string]config.Pool{"main": {Runner: "small", Schedule: []config.Schedule{
+			{Name: "evenings", Hot: 1, Match: &config.ScheduleMatch{Time: []string{"18h"}}},
+		}}},
+	}
+	report := cost.Estimate(cfg, cost.EmbeddedPrices())
+	if report.Pools[0].Error == "" {
+		t.Errorf("Expected an error for an invalid time range, got %+v", report.Pools[0])
+	}
+}
+
+func TestEstimate_VolumeSize(t *testing.T) {
+	for volume, want := range map[string]int{
+		"gp3:500gb":                500,
+		"500gb:gp3":                500,
+		"gp3:125mbs:3000iops:80gb": 80,
+		"gp3":                      40,
+	} {
+		cfg := &config.Config{
+			Runners: map[string]config.Runner{"small": {Volume: volume}},
+			Pools: map[string]config.Pool{"main": {Runner: "small", Schedule: []config.Schedule{
+				{Name: "default", Hot: 1},
+			}}},
+		}
+		report := cost.Estimate(cfg, cost.EmbeddedPrices())
+		if got := report.Pools[0].VolumeGB; got != want {
+			t.Errorf("Expected volume %q to be costed at %dGB, got %dGB", volume, want, got)
+		}
+	}
+}
+
+func TestEmbeddedPrices(t *testing.T) {
+	prices := cost.EmbeddedPrices()
+	if prices.Region == "" || prices.Updated == "" || len(prices.Instances) == 0 {
+		t.Errorf("Unexpected embedded price table: %+v", prices)
+	}
+	for _, instance := range prices.Instances {
+		if instance.Spot <= 0 || instance.Spot >= instance.OnDemand {
+			t.Errorf("Expected a spot discount for %s", instance.Type)
+		}
+	}
+}
+
+func TestParsePrices_Invalid(t *testing.T) {
+	for _, src := range []string{
+		`{}`,
+		`{"instances": [{"type": "c7a.large", "vcpu": 2}]}`,
+		`not json`,
+	} {
+		if _, err := cost.ParsePrices([]byte(src)); err == nil {
+			t.Errorf("Expected an error for %s", src)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package cost
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+//go:embed prices.json
+var embeddedPrices []byte
+
+// PriceTable lists hourly EC2 prices for one region. The embedded table is a
+// snapshot; a refreshed table in the same JSON format can be loaded with
+// LoadPrices.
+type PriceTable struct {
+	Region   string `json:"region"`
+	Currency string `json:"currency"`
+	// Updated is the date the prices were collected
+	Updated string `json:"updated"`
+	// EBSGBMonth is the monthly price of one GB of gp3 storage
+	EBSGBMonth float64    `json:"ebs_gb_month"`
+	Instances  []Instance `json:"instances"`
+}
+
+// Instance is the price of an instance type. Spot is an average spot price.
+type Instance struct {
+	Type     string  `json:"type"`
+	VCPU     float64 `json:"vcpu"`
+	MemoryGB float64 `json:"memory_gb"`
+	OnDemand float64 `json:"on_demand"`
+	Spot     float64 `json:"spot"`
+}
+
+// Family returns the instance family, e.g. "c7a" for c7a.large
+func (i Instance) Family() string {
+	family, _, _ := strings.Cut(i.Type, ".")
+	return family
+}
+
+// EmbeddedPrices returns the price table embedded in this binary
+func EmbeddedPrices() *PriceTable {
+	table, err := ParsePrices(embeddedPrices)
+	if err != nil {
+		// The table is embedded at build time, so this cannot happen
+		panic(fmt.Sprintf("invalid embedded price table: %v", err))
+	}
+	return table
+}
+
+// LoadPrices reads a price table from a JSON file
+func LoadPrices(path string) (*PriceTable, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read price table: %w", err)
+	}
+	table, err := ParsePrices(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid price table %s: %w", path, err)
+	}
+	return table, nil
+}
+
+// ParsePrices parses a price table
+func ParsePrices(data []byte) (*PriceTable, error) {
+	var table PriceTable
+	if err := json.Unmarshal(data, &table); err != nil {
+		return nil, err
+	}
+	if len(table.Instances) == 0 {
+		return nil, fmt.Errorf("no instances")
+	}
+	for _, instance := range table.Instances {
+		if instance.Type == "" || instance.VCPU <= 0 || instance.OnDemand <= 0 {
+			return nil, fmt.Errorf("instance %q needs a type, vcpu and on_demand price", instance.Type)
+		}
+	}
+	return &table, nil
+}
@@ -0,0 +1,286 @@
+// Package extends resolves the _extends directive of runs-on.yml files that
+// point at local files, and merges the extended configs.
+package extends
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	// ErrPathTraversal is returned when a local _extends path escapes the root directory
+	ErrPathTraversal = errors.New("path escapes the root directory")
+	// ErrCycle is returned when configs extend each other in a loop
+	ErrCycle = errors.New("_extends cycle detected")
+)
+
+// Sections whose entries are merged by name. Entries of the extending config
+// replace entries of the same name in the extended config.
+var mergedSections = []string{"runners", "images", "pools"}
+
+// Options configures local _extends resolution
+type Options struct {
+	// Root restricts local _extends paths to files inside this directory. It
+	// must be set when resolving untrusted configs (e.g. in server mode).
+	Root string
+	// Fetch returns the config a repository reference such as
+	// ".github-private" points to, e.g. through the GitHub API. When set,
+	// repository references are merged like local files, under the
+	// reference as path; otherwise they are left in Data.
+	Fetch func(ref string) ([]byte, error)
+	// ReadFile reads local configs instead of the file system, e.g. from a
+	// git revision. It is given the paths ResolvePath returns.
+	ReadFile func(path string) ([]byte, error)
+}
+
+// readFile reads the local config at path
+func (opts Options) readFile(path string) ([]byte, error) {
+	if opts.ReadFile != nil {
+		return opts.ReadFile(path)
+	}
+	return os.ReadFile(path)
+}
+
+// Document is a config with all local _extends (and, with Options.Fetch,
+// repository references) resolved
+type Document struct {
+	// Data is the merged config. Its _extends field, if any, is the first
+	// non-local (repository) reference found in the chain.
+	Data map[string]any
+	// Sources lists the files that contributed to Data, extending file first
+	Sources []string
+	// Conflicts lists the entries that were replaced by a different
+	// definition while merging, extending files first
+	Conflicts []Conflict
+
+	// origins maps each merged section to the file defining each of its
+	// entries in Data
+	origins map[string]map[string]string
+}
+
+// Conflict is an entry of runners, images or pools that an extending config
+// defines differently from a config it extends
+type Conflict struct {
+	Section string
+	Name    string
+	// Path is the extending config, whose definition is used
+	Path string
+	// BasePath is the extended config whose definition is replaced
+	BasePath string
+}
+
+// IsLocal reports whether an _extends value refers to a local file rather
+// than a repository
+func IsLocal(ref string) bool {
+	return strings.HasPrefix(ref, "./") || strings.HasPrefix(ref, "../")
+}
+
+// ResolvePath returns the file a local _extends reference points to, relative
+// to the directory of the config that contains it
+func ResolvePath(configPath, ref string, opts Options) (string, error) {
+	if !IsLocal(ref) {
+		return "", fmt.Errorf("%q is not a local path (must start with ./ or ../)", ref)
+	}
+	resolved := filepath.Join(filepath.Dir(configPath), filepath.FromSlash(ref))
+	if opts.Root == "" {
+		return resolved, nil
+	}
+
+	root, err := filepath.Abs(opts.Root)
+	if err != nil {
+		return "", err
+	}
+	abs, err := filepath.Abs(resolved)
+	if err != nil {
+		return "", err
+	}
+	if !within(root, abs) {
+		return "", fmt.Errorf("_extends %q: %w", ref, ErrPathTraversal)
+	}
+
+	// Symlinks must not lead outside the root either
+	if realRoot, err := filepath.EvalSymlinks(root); err == nil {
+		if realPath, err := filepath.EvalSymlinks(abs); err == nil && !within(realRoot, realPath) {
+			return "", fmt.Errorf("_extends %q: %w", ref, ErrPathTraversal)
+		}
+	}
+	return resolved, nil
+}
+
+// Load reads the config at path and recursively merges the local configs it
+// extends, and the repository configs if opts.Fetch is set
+func Load(path string, opts Options) (*Document, error) {
+	data, err := opts.readFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return resolve(path, data, opts, nil, false)
+}
+
+// LoadBytes is like Load for a config already in memory. path is used to
+// resolve relative _extends references.
+func LoadBytes(path string, data []byte, opts Options) (*Document, error) {
+	return resolve(path, data, opts, nil, false)
+}
+
+// resolve resolves the config read from path, which is a repository
+// reference if fetched is set
+func resolve(path string, data []byte, opts Options, chain []string, fetched bool) (*Document, error) {
+	key := path
+	if !fetched {
+		if abs, err := filepath.Abs(path); err == nil {
+			key = abs
+		}
+	}
+	for _, seen := range chain {
+		if seen == key {
+			return nil, fmt.Errorf("%s: %w", path, ErrCycle)
+		}
+	}
+	chain = append(chain, key)
+
+	var doc map[string]any
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("%s: YAML parse error: %w", path, err)
+	}
+	if doc == nil {
+		doc = map[string]any{}
+	}
+
+	ref, _ := doc["_extends"].(string)
+	var base *Document
+	switch {
+	case IsLocal(ref) && fetched:
+		return nil, fmt.Errorf("%s: local _extends %q cannot be resolved in a repository config", path, ref)
+	case IsLocal(ref):
+		basePath, err := ResolvePath(path, ref, opts)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		baseData, err := opts.readFile(basePath)
+		if err != nil {
+			return nil, fmt.Errorf("%s: failed to read _extends %q: %w", path, ref, err)
+		}
+		if base, err = resolve(basePath, baseData, opts, chain, false); err != nil {
+			return nil, err
+		}
+	case ref != "" && opts.Fetch != nil:
+		baseData, err := opts.Fetch(ref)
+		if err != nil {
+			return nil, fmt.Errorf("%s: failed to fetch _extends %q: %w", path, ref, err)
+		}
+		if base, err = resolve(ref, baseData, opts, chain, true); err != nil {
+			return nil, err
+		}
+	default:
+		return &Document{Data: doc, Sources: []string{path}, origins: origins(path, doc, nil)}, nil
+	}
+
+	if !IsLocal(ref) {
+		// The reference is consumed by the merge, like local ones
+		doc = maps.Clone(doc)
+		delete(doc, "_extends")
+	}
+	return &Document{
+		Data:      Merge(base.Data, doc),
+		Sources:   append([]string{path}, base.Sources...),
+		Conflicts: append(conflicts(path, doc, base), base.Conflicts...),
+		origins:   origins(path, doc, base.origins),
+	}, nil
+}
+
+// origins returns the files defining the entries of the merged sections once
+// doc, read from path, is merged over configs whose entries come from base
+func origins(path string, doc map[string]any, base map[string]map[string]string) map[string]map[string]string {
+	result := make(map[string]map[string]string, len(mergedSections))
+	for _, section := range mergedSections {
+		files := make(map[string]string)
+		for name, file := range base[section] {
+			files[name] = file
+		}
+		entries, _ := doc[section].(map[string]any)
+		for name := range entries {
+			files[name] = path
+		}
+		result[section] = files
+	}
+	return result
+}
+
+// conflicts returns the entries of doc, read from path, that replace a
+// different definition in base, sorted by section and name
+func conflicts(path string, doc map[string]any, base *Document) []Conflict {
+	var result []Conflict
+	for _, section := range mergedSections {
+		entries, _ := doc[section].(map[string]any)
+		baseEntries, _ := base.Data[section].(map[string]any)
+		var names []string
+		for name, entry := range entries {
+			if baseEntry, ok := baseEntries[name]; ok && !reflect.DeepEqual(entry, baseEntry) {
+				names = append(names, name)
+			}
+		}
+		slices.Sort(names)
+		for _, name := range names {
+			result = append(result, Conflict{
+				Section:  section,
+				Name:     name,
+				Path:     path,
+				BasePath: base.origins[section][name],
+			})
+		}
+	}
+	return result
+}
+
+// Merge returns the result of overlay extending base. Entries of runners,
+// images and pools are merged by name, with overlay entries replacing base
+// entries of the same name; other top-level fields in overlay replace those
+// in base. A local _extends in overlay is consumed by the merge.
+func Merge(base, overlay map[string]any) map[string]any {
+	result := make(map[string]any, len(base)+len(overlay))
+	for key, value := range base {
+		result[key] = value
+	}
+
+	for key, value := range overlay {
+		if key == "_extends" {
+			if ref, _ := value.(string); IsLocal(ref) {
+				continue
+			}
+		}
+		if slices.Contains(mergedSections, key) {
+			baseEntries, baseOK := result[key].(map[string]any)
+			overlayEntries, overlayOK := value.(map[string]any)
+			if baseOK && overlayOK {
+				merged := make(map[string]any, len(baseEntries)+len(overlayEntries))
+				for name, entry := range baseEntries {
+					merged[name] = entry
+				}
+				for name, entry := range overlayEntries {
+					merged[name] = entry
+				}
+				result[key] = merged
+				continue
+			}
+		}
+		result[key] = value
+	}
+	return result
+}
+
+func within(root, path string) bool {
+	rel, err := filepath.Rel(root, path)
+	if err != nil {
+		return false
+	}
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) && !filepath.IsAbs(rel)
+}
@@ -0,0 +1,252 @@
+package extends_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/runs-on/config/pkg/extends"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatalf("Failed to create directory: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("Failed to write file: %v", err)
+	}
+}
+
+func TestLoad_Chain(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "shared", "org.yml"), `_extends: .github-private
+runners:
+  small:
+    cpu: [2]
+  large:
+    cpu: [16]
+admins: [org-admin]
+`)
+	writeFile(t, filepath.Join(dir, "shared", "base.yml"), `_extends: ./org.yml
+runners:
+  large:
+    cpu: [32]
+images:
+  custom:
+    ami: ami-1234567890abcdef0
+`)
+	writeFile(t, filepath.Join(dir, ".github", "runs-on.yml"), `_extends: ../shared/base.yml
+runners:
+  medium:
+    cpu: [8]
+admins: [repo-admin]
+`)
+
+	doc, err := extends.Load(filepath.Join(dir, ".github", "runs-on.yml"), extends.Options{})
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	if len(doc.Sources) != 3 {
+		t.Errorf("Expected 3 sources, got %v", doc.Sources)
+	}
+	if got := doc.Data["_extends"]; got != ".github-private" {
+		t.Errorf("Expected the repository _extends to be kept, got %v", got)
+	}
+
+	runners := doc.Data["runners"].(map[string]any)
+	for _, name := range []string{"small", "medium", "large"} {
+		if _, ok := runners[name]; !ok {
+			t.Errorf("Expected runner %q in merged config", name)
+		}
+	}
+	large := runners["large"].(map[string]any)
+	if cpu := large["cpu"].([]any); cpu[0] != 32 {
+		t.Errorf("Expected extending config to override runner 'large', got cpu %v", cpu)
+	}
+	if _, ok := doc.Data["images"].(map[string]any)["custom"]; !ok {
+		t.Error("Expected image from intermediate config")
+	}
+	if admins := doc.Data["admins"].([]any); len(admins) != 1 || admins[0] != "repo-admin" {
+		t.Errorf("Expected admins to be replaced by the extending config, got %v", admins)
+	}
+}
+
+func TestLoad_Conflicts(t *testing.T) {
+	dir := t.TempDir()
+	org := filepath.Join(dir, "org.yml")
+	base := filepath.Join(dir, "base.yml")
+	config := filepath.Join(dir, "runs-on.yml")
+	writeFile(t, org, `runners:
+  small:
+    cpu: [2]
+  large:
+    cpu: [16]
+`)
+	writeFile(t, base, `_extends: ./org.yml
+runners:
+  small:
+    cpu: [2]
+  large:
+    cpu: [32]
+`)
+	writeFile(t, config, `_extends: ./base.yml
+runners:
+  large:
+    cpu: [64]
+pools:
+  main:
+    runner: large
+`)
+
+	doc, err := extends.Load(config, extends.Options{})
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	// Identical redefinitions, like 'small' in base.yml, are not conflicts
+	expected := []extends.Conflict{
+		{Section: "runners", Name: "large", Path: config, BasePath: base},
+		{Section: "runners", Name: "large", Path: base, BasePath: org},
+	}
+	if !slices.Equal(doc.Conflicts, expected) {
+		t.Errorf("Expected conflicts %v, got %v", expected, doc.Conflicts)
+	}
+}
+
+func TestLoad_Cycle(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "a.yml"), "_extends: ./b.yml\n")
+	writeFile(t, filepath.Join(dir, "b.yml"), "_extends: ./a.yml\n")
+
+	_, err := extends.Load(filepath.Join(dir, "a.yml"), extends.Options{})
+	if !errors.Is(err, extends.ErrCycle) {
+		t.Errorf("Expected ErrCycle, got %v", err)
+	}
+}
+
+func TestLoad_Fetch(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "runs-on.yml"), "_extends: ./base.yml\npools:\n  main:\n    runner: small\n")
+	writeFile(t, filepath.Join(dir, "base.yml"), "_extends: .github-private\nrunners:\n  small:\n    cpu: [4]\n")
+	repos := map[string]string{
+		".github-private": "_extends: acme/defaults\nrunners:\n  small:\n    cpu: [2]\n  large:\n    cpu: [16]\n",
+		"acme/defaults":   "admins: [alice]\n",
+		"acme/loop":       "_extends: acme/loop\n",
+		"acme/local":      "_extends: ./other.yml\n",
+	}
+	fetch := func(ref string) ([]byte, error) {
+		if content, ok := repos[ref]; ok {
+			return []byte(content), nil
+		}
+		return nil, os.ErrNotExist
+	}
+
+	doc, err := extends.Load(filepath.Join(dir, "runs-on.yml"), extends.Options{Fetch: fetch})
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if _, ok := doc.Data["_extends"]; ok {
+		t.Errorf("Expected the references to be consumed, got %v", doc.Data["_extends"])
+	}
+	runners := doc.Data["runners"].(map[string]any)
+	if _, ok := runners["large"]; !ok || len(runners) != 2 || doc.Data["admins"] == nil {
+		t.Errorf("Expected the fetched configs to be merged, got %v", doc.Data)
+	}
+	if want := []string{filepath.Join(dir, "runs-on.yml"), filepath.Join(dir, "base.yml"), ".github-private", "acme/defaults"}; !slices.Equal(doc.Sources, want) {
+		t.Errorf("Expected sources %q, got %q", want, doc.Sources)
+	}
+
+	// Without Fetch, repository references are kept
+	doc, err = extends.Load(filepath.Join(dir, "base.yml"), extends.Options{})
+	if err != nil || doc.Data["_extends"] != ".github-private" {
+		t.Errorf("Expected the reference to be kept, got %v, %v", doc, err)
+	}
+
+	for ref, want := range map[string]error{"acme/loop": extends.ErrCycle, "acme/missing": os.ErrNotExist, "acme/local": nil} {
+		writeFile(t, filepath.Join(dir, "ref.yml"), "_extends: "+ref+"\n")
+		_, err := extends.Load(filepath.Join(dir, "ref.yml"), extends.Options{Fetch: fetch})
+		if err == nil || (want != nil && !errors.Is(err, want)) {
+			t.Errorf("%s: expected %v, got %v", ref, want, err)
+		}
+	}
+}
+
+func TestLoad_ReadFile(t *testing.T) {
+	files := map[string]string{
+		".github/runs-on.yml": "_extends: ../shared/base.yml\npools:\n  main:\n    runner: small\n",
+		"shared/base.yml":     "runners:\n  small:\n    cpu: [2]\n",
+	}
+	read := func(path string) ([]byte, error) {
+		if content, ok := files[filepath.ToSlash(path)]; ok {
+			return []byte(content), nil
+		}
+		return nil, os.ErrNotExist
+	}
+
+	doc, err := extends.Load(filepath.Join(".github", "runs-on.yml"), extends.Options{ReadFile: read})
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if runners, _ := doc.Data["runners"].(map[string]any); runners["small"] == nil {
+		t.Errorf("Expected the runners of the file read to be merged, got %v", doc.Data)
+	}
+	if want := []string{filepath.Join(".github", "runs-on.yml"), filepath.Join("shared", "base.yml")}; !slices.Equal(doc.Sources, want) {
+		t.Errorf("Expected sources %q, got %q", want, doc.Sources)
+	}
+
+	files["shared/base.yml"] = "_extends: ./missing.yml\n"
+	if _, err := extends.Load(filepath.Join(".github", "runs-on.yml"), extends.Options{ReadFile: read}); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Expected the read error, got %v", err)
+	}
+}
+
+func TestLoad_PathTraversal(t *testing.T) {
+	dir := t.TempDir()
+	root := filepath.Join(dir, "repo")
+	writeFile(t, filepath.Join(dir, "secret.yml"), "runners: {}\n")
+	writeFile(t, filepath.Join(root, ".github", "runs-on.yml"), "_extends: ../../secret.yml\n")
+
+	configPath := filepath.Join(root, ".github", "runs-on.yml")
+	if _, err := extends.Load(configPath, extends.Options{}); err != nil {
+		t.Errorf("Expected unrestricted load to succeed, got %v", err)
+	}
+
+	_, err := extends.Load(configPath, extends.Options{Root: root})
+	if !errors.Is(err, extends.ErrPathTraversal) {
+		t.Errorf("Expected ErrPathTraversal, got %v", err)
+	}
+}
+
+func TestLoad_SymlinkTraversal(t *testing.T) {
+	dir := t.TempDir()
+	root := filepath.Join(dir, "repo")
+	writeFile(t, filepath.Join(dir, "secret.yml"), "runners: {}\n")
+	writeFile(t, filepath.Join(root, ".github", "runs-on.yml"), "_extends: ./base.yml\n")
+	if err := os.Symlink(filepath.Join(dir, "secret.yml"), filepath.Join(root, ".github", "base.yml")); err != nil {
+		t.Skipf("Symlinks not supported: %v", err)
+	}
+
+	_, err := extends.Load(filepath.Join(root, ".github", "runs-on.yml"), extends.Options{Root: root})
+	if !errors.Is(err, extends.ErrPathTraversal) {
+		t.Errorf("Expected ErrPathTraversal for symlink escaping the root, got %v", err)
+	}
+}
+
+func TestIsLocal(t *testing.T) {
+	testCases := map[string]bool{
+		"./base.yml":         true,
+		"../shared/base.yml": true,
+		".github-private":    false,
+		"org/repo":           false,
+		"":                   false,
+	}
+	for ref, expected := range testCases {
+		if got := extends.IsLocal(ref); got != expected {
+			t.Errorf("IsLocal(%q) = %v, expected %v", ref, got, expected)
+		}
+	}
+}
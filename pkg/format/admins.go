@@ -0,0 +1,159 @@
+package format
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// FixAdmins removes duplicate entries from the admins list, keeping the first
+// occurrence, and also sorts the list alphabetically if sorted is set. Names
+// are compared case-insensitively, like GitHub usernames. Only the admins
+// list is rewritten: the rest of the document is left byte for byte, and
+// comments above or next to a block-style entry move with it.
+func FixAdmins(src []byte, sorted bool) ([]byte, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(src, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse YAML: %w", err)
+	}
+	if len(doc.Content) == 0 {
+		return src, nil
+	}
+	root := doc.Content[0]
+	var key, admins *yaml.Node
+	for i := 0; root.Kind == yaml.MappingNode && i+1 < len(root.Content); i += 2 {
+		if root.Content[i].Value == "admins" {
+			key, admins = root.Content[i], root.Content[i+1]
+		}
+	}
+	if admins == nil || admins.Kind != yaml.SequenceNode {
+		return src, nil
+	}
+	for _, item := range admins.Content {
+		if item.Kind != yaml.ScalarNode {
+			return nil, fmt.Errorf("admins: line %d: only plain names can be fixed", item.Line)
+		}
+	}
+
+	order := adminOrder(admins.Content, sorted)
+	changed := len(order) != len(admins.Content)
+	for i, index := range order {
+		changed = changed || i != index
+	}
+	if !changed {
+		return src, nil
+	}
+
+	if admins.Style&yaml.FlowStyle != 0 {
+		return fixFlowAdmins(src, admins, order)
+	}
+	return fixBlockAdmins(src, key.Line, admins, order)
+}
+
+// adminOrder returns the indices of the admins to keep, in their new order
+func adminOrder(items []*yaml.Node, sorted bool) []int {
+	seen := make(map[string]bool)
+	var order []int
+	for i, item := range items {
+		key := strings.ToLower(item.Value)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		order = append(order, i)
+	}
+	if sorted {
+		sort.SliceStable(order, func(a, b int) bool {
+			return strings.ToLower(items[order[a]].Value) < strings.ToLower(items[order[b]].Value)
+		})
+	}
+	return order
+}
+
+// fixBlockAdmins reorders the lines of a block sequence. Each entry owns its
+// own line and the comment lines between it and the previous entry, or the
+// admins key for the first entry.
+func fixBlockAdmins(src []byte, keyLine int, admins *yaml.Node, order []int) ([]byte, error) {
+	text := string(src)
+	missingNewline := !strings.HasSuffix(text, "\n")
+	if missingNewline {
+		text += "\n"
+	}
+	lines := strings.SplitAfter(text, "\n")
+
+	items := admins.Content
+	chunks := make([][]string, len(items))
+	for i, item := range items {
+		start := keyLine
+		if i > 0 {
+			if item.Line <= items[i-1].Line {
+				return nil, fmt.Errorf("admins: line %d: entries must be on separate lines", item.Line)
+			}
+			start = items[i-1].Line
+		}
+		if item.Style&(yaml.LiteralStyle|yaml.FoldedStyle) != 0 || strings.Contains(item.Value, "\n") {
+			return nil, fmt.Errorf("admins: line %d: multi-line entries cannot be fixed", item.Line)
+		}
+		chunks[i] = lines[start:item.Line]
+	}
+
+	first, last := keyLine, items[len(items)-1].Line
+	var b strings.Builder
+	b.WriteString(strings.Join(lines[:first], ""))
+	for _, index := range order {
+		b.WriteString(strings.Join(chunks[index], ""))
+	}
+	b.WriteString(strings.Join(lines[last:], ""))
+
+	result := b.String()
+	if missingNewline {
+		result = strings.TrimSuffix(result, "\n")
+	}
+	return []byte(result), nil
+}
+
+// fixFlowAdmins rewrites a flow sequence on a single line
+func fixFlowAdmins(src []byte, admins *yaml.Node, order []int) ([]byte, error) {
+	lines := strings.SplitAfter(string(src), "\n")
+	line := lines[admins.Line-1]
+	start := admins.Column - 1
+	end := flowEnd(line, start)
+	if end < 0 {
+		return nil, fmt.Errorf("admins: line %d: flow-style lists spanning several lines cannot be fixed; run 'runs-on-config fmt' first", admins.Line)
+	}
+
+	names := make([]string, len(order))
+	for i, index := range order {
+		names[i] = admins.Content[index].Value
+		if needsQuoting(names[i]) {
+			names[i] = strconv.Quote(names[i])
+		}
+	}
+	lines[admins.Line-1] = line[:start] + "[" + strings.Join(names, ", ") + "]" + line[end+1:]
+	return []byte(strings.Join(lines, "")), nil
+}
+
+// flowEnd returns the index of the bracket closing the flow sequence that
+// opens at start, or -1 if it is not on the same line
+func flowEnd(line string, start int) int {
+	var quote byte
+	for i := start + 1; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case quote != 0:
+			if c == '\\' && quote == '"' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == ']':
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,92 @@
+package format_test
+
+import (
+	"testing"
+
+	"github.com/runs-on/config/pkg/format"
+)
+
+func TestFixAdmins(t *testing.T) {
+	testCases := []struct {
+		name     string
+		input    string
+		sorted   bool
+		expected string
+	}{
+		{
+			name: "block duplicates",
+			input: `runners: {}
+admins:
+  - carol
+  - alice # lead
+  - Carol
+  - bob
+`,
+			expected: `runners: {}
+admins:
+  - carol
+  - alice # lead
+  - bob
+`,
+		},
+		{
+			name: "block sorted with comments",
+			input: `admins:
+  # platform team
+  - carol
+  # security
+  - alice
+  - bob
+  - alice
+pools: {}`,
+			sorted: true,
+			expected: `admins:
+  # security
+  - alice
+  - bob
+  # platform team
+  - carol
+pools: {}`,
+		},
+		{
+			name:     "flow",
+			input:    "admins: [bob, alice, 'bob'] # owners\nrunners: {}\n",
+			sorted:   true,
+			expected: "admins: [alice, bob] # owners\nrunners: {}\n",
+		},
+		{
+			name:     "unchanged",
+			input:    "admins:\n- alice\n- bob\n",
+			sorted:   true,
+			expected: "admins:\n- alice\n- bob\n",
+		},
+		{
+			name:     "order kept when not sorting",
+			input:    "admins: [carol, alice]\n",
+			expected: "admins: [carol, alice]\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := format.FixAdmins([]byte(tc.input), tc.sorted)
+			if err != nil {
+				t.Fatalf("FixAdmins failed: %v", err)
+			}
+			if string(got) != tc.expected {
+				t.Errorf("Unexpected output:\n%s\nexpected:\n%s", got, tc.expected)
+			}
+		})
+	}
+}
+
+func TestFixAdmins_Unsupported(t *testing.T) {
+	for _, input := range []string{
+		"admins: [bob,\n  alice]\n",
+		"admins:\n  - [alice]\n  - bob\n",
+	} {
+		if _, err := format.FixAdmins([]byte(input), true); err == nil {
+			t.Errorf("Expected an error for %q", input)
+		}
+	}
+}
@@ -0,0 +1,350 @@
+package format
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Canonical key order for each known mapping. Keys not listed keep their
+// relative order and are placed after the known keys, except at the top
+// level where custom keys (e.g. x-defaults) stay ahead of the sections that
+// usually reference their anchors.
+var (
+	topLevelOrder = []string{"schema-version", "_extends"}
+	sectionOrder  = []string{"runners", "images", "pools", "admins"}
+	runnerOrder   = []string{
+		"<<", "id", "cpu", "ram", "family", "image", "spot", "ssh", "nested-virt", "private",
+		"volume", "disk", "retry", "extras", "tags", "debug", "preinstall", "prerun",
+	}
+	imageOrder = []string{
+		"<<", "id", "ami", "platform", "arch", "name", "owner", "main_disk_size",
+		"root_device_name", "tags", "preinstall", "prerun",
+	}
+	poolOrder     = []string{"<<", "runner", "env", "environment", "version", "timezone", "schedule"}
+	scheduleOrder = []string{"<<", "name", "hot", "stopped", "match"}
+	matchOrder    = []string{"<<", "day", "time"}
+
+	yaml11Bools = []string{
+		"y", "Y", "yes", "Yes", "YES", "n", "N", "no", "No", "NO",
+		"on", "On", "ON", "off", "Off", "OFF",
+	}
+)
+
+// Format rewrites a runs-on.yml document into canonical style: two-space
+// indentation, minimal quoting, a stable key order and one blank line between
+// top-level sections. Comments, anchors and aliases are preserved.
+func Format(src []byte) ([]byte, error) {
+	if len(bytes.TrimSpace(src)) == 0 {
+		return src, nil
+	}
+
+	var out bytes.Buffer
+	decoder := yaml.NewDecoder(bytes.NewReader(src))
+	encoder := yaml.NewEncoder(&out)
+	encoder.SetIndent(2)
+
+	for {
+		var doc yaml.Node
+		if err := decoder.Decode(&doc); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, fmt.Errorf("failed to parse YAML: %w", err)
+		}
+		if len(doc.Content) > 0 {
+			formatRoot(doc.Content[0])
+		}
+		if err := encoder.Encode(&doc); err != nil {
+			return nil, fmt.Errorf("failed to encode YAML: %w", err)
+		}
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, fmt.Errorf("failed to encode YAML: %w", err)
+	}
+
+	return separateSections(out.Bytes()), nil
+}
+
+// IsFormatted reports whether src is already in canonical style
+func IsFormatted(src []byte) (bool, error) {
+	formatted, err := Format(src)
+	if err != nil {
+		return false, err
+	}
+	return bytes.Equal(src, formatted), nil
+}
+
+// separateSections inserts a blank line before every top-level key but the
+// first and between the entries of the runners, images and pools sections.
+// Comment lines directly above a key stay attached to it.
+func separateSections(src []byte) []byte {
+	lines := strings.SplitAfter(string(src), "\n")
+	var out []string
+	section := ""
+	firstEntry := false
+	for _, line := range lines {
+		separate := false
+		switch {
+		case isKeyLine(line, 0):
+			section = strings.TrimSpace(strings.SplitN(line, ":", 2)[0])
+			firstEntry = true
+			separate = true
+		case isKeyLine(line, 2) && slices.Contains(sectionOrder[:3], section):
+			separate = !firstEntry
+			firstEntry = false
+		}
+
+		if separate {
+			start := len(out)
+			for start > 0 && strings.HasPrefix(strings.TrimLeft(out[start-1], " "), "#") {
+				start--
+			}
+			if start > 0 && strings.TrimSpace(out[start-1]) != "" && out[start-1] != "---\n" {
+				out = slices.Insert(out, start, "\n")
+			}
+		}
+		out = append(out, line)
+	}
+	return []byte(strings.Join(out, ""))
+}
+
+// isKeyLine reports whether line starts a mapping key at the given indentation
+func isKeyLine(line string, indent int) bool {
+	if len(line) <= indent || strings.TrimLeft(line[:indent], " ") != "" {
+		return false
+	}
+	switch line[indent] {
+	case ' ', '#', '\n', '-', '.':
+		return false
+	}
+	return true
+}
+
+func formatRoot(root *yaml.Node) {
+	normalizeQuoting(root)
+	if root.Kind != yaml.MappingNode {
+		return
+	}
+
+	// Custom top-level keys go between _extends and the known sections
+	order := append([]string{}, topLevelOrder...)
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		key := root.Content[i].Value
+		if !slices.Contains(topLevelOrder, key) && !slices.Contains(sectionOrder, key) {
+			order = append(order, key)
+		}
+	}
+	order = append(order, sectionOrder...)
+	sortMapping(root, order)
+
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		value := root.Content[i+1]
+		switch root.Content[i].Value {
+		case "runners":
+			forEachEntry(value, func(spec *yaml.Node) { sortMapping(spec, runnerOrder) })
+		case "images":
+			forEachEntry(value, func(spec *yaml.Node) { sortMapping(spec, imageOrder) })
+		case "pools":
+			forEachEntry(value, func(spec *yaml.Node) {
+				sortMapping(spec, poolOrder)
+				schedule := mappingValue(spec, "schedule")
+				if schedule == nil || schedule.Kind != yaml.SequenceNode {
+					return
+				}
+				for _, entry := range schedule.Content {
+					sortMapping(entry, scheduleOrder)
+					if match := mappingValue(entry, "match"); match != nil {
+						sortMapping(match, matchOrder)
+					}
+				}
+			})
+		}
+	}
+}
+
+// normalizeQuoting drops quotes that are not needed to keep a scalar a
+// string, and uses double quotes wherever quoting is required (e.g. "true",
+// "22:00" or values containing YAML indicators).
+func normalizeQuoting(n *yaml.Node) {
+	if n == nil {
+		return
+	}
+	if n.Kind == yaml.ScalarNode {
+		switch {
+		case n.Tag == "!!merge":
+			// yaml.v3 would otherwise emit the merge key as "!!merge <<"
+			n.Tag = ""
+		case n.Tag == "!!str" && n.Style&(yaml.DoubleQuotedStyle|yaml.SingleQuotedStyle) != 0:
+			n.Style &^= yaml.DoubleQuotedStyle | yaml.SingleQuotedStyle
+			if needsQuoting(n.Value) {
+				n.Style |= yaml.DoubleQuotedStyle
+			}
+		}
+	}
+	for _, child := range n.Content {
+		normalizeQuoting(child)
+	}
+}
+
+// needsQuoting reports whether value cannot be written as a plain string
+// scalar. The check uses a flow sequence, whose rules are the strictest, so
+// the result does not depend on where the scalar appears.
+func needsQuoting(value string) bool {
+	// YAML 1.1 booleans stay quoted for parsers other than yaml.v3
+	if slices.Contains(yaml11Bools, value) {
+		return true
+	}
+	probe := &yaml.Node{
+		Kind:    yaml.SequenceNode,
+		Style:   yaml.FlowStyle,
+		Content: []*yaml.Node{{Kind: yaml.ScalarNode, Tag: "!!str", Value: value}},
+	}
+	out, err := yaml.Marshal(probe)
+	if err != nil {
+		return true
+	}
+	return !bytes.HasPrefix(out, []byte("["+value))
+}
+
+// forEachEntry calls fn for every mapping value of a user-named section
+// (runners, images, pools). User-chosen entry order is left untouched.
+func forEachEntry(section *yaml.Node, fn func(*yaml.Node)) {
+	if section == nil || section.Kind != yaml.MappingNode {
+		return
+	}
+	for i := 0; i+1 < len(section.Content); i += 2 {
+		fn(section.Content[i+1])
+	}
+}
+
+func mappingValue(n *yaml.Node, key string) *yaml.Node {
+	if n == nil || n.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if n.Content[i].Value == key {
+			return n.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// mappingEntry is a key/value pair of a mapping node with its sort rank
+type mappingEntry struct {
+	key, value *yaml.Node
+	rank       int
+	anchors    []string
+	aliases    []string
+}
+
+// sortMapping stably reorders the key/value pairs of a mapping according to
+// order. Unknown keys keep their relative position after the known ones. The
+// mapping is left untouched if reordering would move an alias before the
+// anchor it refers to.
+func sortMapping(n *yaml.Node, order []string) {
+	if n == nil || n.Kind != yaml.MappingNode || len(n.Content) < 4 {
+		return
+	}
+
+	entries := make([]mappingEntry, 0, len(n.Content)/2)
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		rank := len(order)
+		for r, name := range order {
+			if n.Content[i].Value == name {
+				rank = r
+				break
+			}
+		}
+		entries = append(entries, mappingEntry{
+			key:     n.Content[i],
+			value:   n.Content[i+1],
+			rank:    rank,
+			anchors: collectAnchors(n.Content[i], n.Content[i+1]),
+			aliases: collectAliases(n.Content[i], n.Content[i+1]),
+		})
+	}
+
+	// Insertion sort keeps equal ranks in document order
+	sorted := append([]mappingEntry{}, entries...)
+	for i := 1; i < len(sorted); i++ {
+		for j := i; j > 0 && sorted[j].rank < sorted[j-1].rank; j-- {
+			sorted[j], sorted[j-1] = sorted[j-1], sorted[j]
+		}
+	}
+
+	// Anchors defined by sibling entries must still precede their aliases
+	siblingAnchors := make(map[string]bool)
+	for _, e := range entries {
+		for _, anchor := range e.anchors {
+			siblingAnchors[anchor] = true
+		}
+	}
+	defined := make(map[string]bool)
+	for _, e := range sorted {
+		own := make(map[string]bool)
+		for _, anchor := range e.anchors {
+			own[anchor] = true
+		}
+		for _, alias := range e.aliases {
+			if siblingAnchors[alias] && !own[alias] && !defined[alias] {
+				return
+			}
+		}
+		for anchor := range own {
+			defined[anchor] = true
+		}
+	}
+
+	content := make([]*yaml.Node, 0, len(n.Content))
+	for _, e := range sorted {
+		content = append(content, e.key, e.value)
+	}
+	n.Content = content
+}
+
+func collectAnchors(nodes ...*yaml.Node) []string {
+	var anchors []string
+	var walk func(*yaml.Node)
+	walk = func(n *yaml.Node) {
+		if n == nil || n.Kind == yaml.AliasNode {
+			return
+		}
+		if n.Anchor != "" {
+			anchors = append(anchors, n.Anchor)
+		}
+		for _, child := range n.Content {
+			walk(child)
+		}
+	}
+	for _, n := range nodes {
+		walk(n)
+	}
+	return anchors
+}
+
+func collectAliases(nodes ...*yaml.Node) []string {
+	var aliases []string
+	var walk func(*yaml.Node)
+	walk = func(n *yaml.Node) {
+		if n == nil {
+			return
+		}
+		if n.Kind == yaml.AliasNode {
+			aliases = append(aliases, n.Value)
+			return
+		}
+		for _, child := range n.Content {
+			walk(child)
+		}
+	}
+	for _, n := range nodes {
+		walk(n)
+	}
+	return aliases
+}
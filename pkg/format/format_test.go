@@ -0,0 +1,192 @@
+package format_test
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/runs-on/config/pkg/format"
+	"gopkg.in/yaml.v3"
+)
+
+func TestFormat_Canonical(t *testing.T) {
+	input := `pools:
+  test-pool:
+    schedule:
+    - stopped: 2
+      name: 'default'
+      hot: 1
+    runner: "test-runner"
+runners:
+    test-runner:
+        family: ["c7a"]
+        ram: [16]
+        cpu: [2]
+        spot: "false"
+        image: 'ubuntu22-full-x64'
+    other-runner:
+        cpu: 4
+_extends: ".github-private"
+`
+	expected := `_extends: .github-private
+
+runners:
+  test-runner:
+    cpu: [2]
+    ram: [16]
+    family: [c7a]
+    image: ubuntu22-full-x64
+    spot: "false"
+
+  other-runner:
+    cpu: 4
+
+pools:
+  test-pool:
+    runner: test-runner
+    schedule:
+      - name: default
+        hot: 1
+        stopped: 2
+`
+
+	got, err := format.Format([]byte(input))
+	if err != nil {
+		t.Fatalf("Format failed: %v", err)
+	}
+	if string(got) != expected {
+		t.Errorf("Unexpected output:\n%s\nexpected:\n%s", got, expected)
+	}
+}
+
+func TestFormat_Quoting(t *testing.T) {
+	input := `runners:
+  test-runner:
+    tags: ['plain', "a: b", "123", 'yes', "true", "@team"]
+pools:
+  test-pool:
+    runner: test-runner
+    schedule:
+      - name: nights
+        hot: 0
+        stopped: 1
+        match:
+          time: ['22:00', "06:00"]
+`
+
+	got, err := format.Format([]byte(input))
+	if err != nil {
+		t.Fatalf("Format failed: %v", err)
+	}
+
+	for _, want := range []string{
+		`tags: [plain, "a: b", "123", "yes", "true", "@team"]`,
+		`time: ["22:00", "06:00"]`,
+	} {
+		if !strings.Contains(string(got), want) {
+			t.Errorf("Expected output to contain %q, got:\n%s", want, got)
+		}
+	}
+}
+
+func TestFormat_PreservesCommentsAndAnchors(t *testing.T) {
+	input := `# Shared defaults
+x-defaults: &defaults
+  family: [c7a]
+  cpu: [2]
+
+runners:
+  # Main runner
+  test-runner:
+    <<: *defaults
+    image: ubuntu22-full-x64 # pinned
+`
+
+	got, err := format.Format([]byte(input))
+	if err != nil {
+		t.Fatalf("Format failed: %v", err)
+	}
+
+	for _, want := range []string{
+		"# Shared defaults",
+		"x-defaults: &defaults",
+		"# Main runner",
+		"<<: *defaults",
+		"image: ubuntu22-full-x64 # pinned",
+	} {
+		if !strings.Contains(string(got), want) {
+			t.Errorf("Expected output to contain %q, got:\n%s", want, got)
+		}
+	}
+	if strings.Contains(string(got), "!!merge") {
+		t.Errorf("Merge key should not be tagged, got:\n%s", got)
+	}
+}
+
+func TestFormat_KeepsAnchorsBeforeAliases(t *testing.T) {
+	// Canonical order would move x-copy, which uses the alias, ahead of the
+	// pools section that defines the anchor
+	input := `pools:
+  test-pool: &pool
+    runner: test-runner
+runners:
+  test-runner:
+    cpu: 2
+x-copy: *pool
+`
+
+	got, err := format.Format([]byte(input))
+	if err != nil {
+		t.Fatalf("Format failed: %v", err)
+	}
+
+	var decoded any
+	if err := yaml.Unmarshal(got, &decoded); err != nil {
+		t.Fatalf("Formatted output is not valid YAML: %v\n%s", err, got)
+	}
+	if !strings.HasPrefix(string(got), "pools:") {
+		t.Errorf("Expected top-level order to be kept, got:\n%s", got)
+	}
+}
+
+func TestFormat_TestdataSemanticsAndIdempotence(t *testing.T) {
+	files, err := filepath.Glob("../../schema/testdata/valid/*.yml")
+	if err != nil {
+		t.Fatalf("Failed to list testdata: %v", err)
+	}
+
+	for _, file := range files {
+		t.Run(filepath.Base(file), func(t *testing.T) {
+			src, err := os.ReadFile(file)
+			if err != nil {
+				t.Fatalf("Failed to read test file: %v", err)
+			}
+
+			formatted, err := format.Format(src)
+			if err != nil {
+				t.Fatalf("Format failed: %v", err)
+			}
+
+			var before, after any
+			if err := yaml.Unmarshal(src, &before); err != nil {
+				t.Fatalf("Failed to parse source: %v", err)
+			}
+			if err := yaml.Unmarshal(formatted, &after); err != nil {
+				t.Fatalf("Failed to parse formatted output: %v", err)
+			}
+			if !reflect.DeepEqual(before, after) {
+				t.Errorf("Formatting changed the document content:\n%s", formatted)
+			}
+
+			ok, err := format.IsFormatted(formatted)
+			if err != nil {
+				t.Fatalf("IsFormatted failed: %v", err)
+			}
+			if !ok {
+				t.Errorf("Formatting is not idempotent:\n%s", formatted)
+			}
+		})
+	}
+}
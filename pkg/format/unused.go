@@ -0,0 +1,152 @@
+package format
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+)
+
+// RemoveUnused removes top-level x-* blocks and anchors that are never
+// referenced by an alias. Removing a block can leave the anchors it used
+// unreferenced, so passes repeat until nothing changes. A block is removed
+// with the comment lines directly above it; an anchor is removed from its
+// line, keeping its value. The rest of the document is left byte for byte.
+func RemoveUnused(src []byte) ([]byte, error) {
+	for {
+		fixed, err := removeUnusedOnce(src)
+		if err != nil || string(fixed) == string(src) {
+			return fixed, err
+		}
+		src = fixed
+	}
+}
+
+func removeUnusedOnce(src []byte) ([]byte, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(src, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse YAML: %w", err)
+	}
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return src, nil
+	}
+	root := doc.Content[0]
+
+	aliased := make(map[*yaml.Node]bool)
+	walkNodes(root, func(n *yaml.Node) {
+		if n.Kind == yaml.AliasNode && n.Alias != nil {
+			aliased[n.Alias] = true
+		}
+	})
+
+	// Unused blocks, as the index of their key, and unused anchors outside
+	// them
+	var blocks []int
+	var anchors []*yaml.Node
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		key, value := root.Content[i], root.Content[i+1]
+		used := false
+		walkNodes(value, func(n *yaml.Node) {
+			used = used || aliased[n]
+		})
+		if strings.HasPrefix(key.Value, "x-") && !used {
+			if root.Style&yaml.FlowStyle != 0 {
+				return nil, fmt.Errorf("%s: line %d: blocks of a flow-style mapping cannot be removed", key.Value, key.Line)
+			}
+			blocks = append(blocks, i)
+			continue
+		}
+		walkNodes(value, func(n *yaml.Node) {
+			if n.Anchor != "" && !aliased[n] {
+				anchors = append(anchors, n)
+			}
+		})
+	}
+	if len(blocks) == 0 && len(anchors) == 0 {
+		return src, nil
+	}
+
+	text := string(src)
+	missingNewline := !strings.HasSuffix(text, "\n")
+	if missingNewline {
+		text += "\n"
+	}
+	lines := strings.SplitAfter(strings.TrimSuffix(text, "\n"), "\n")
+	lines[len(lines)-1] += "\n"
+
+	// Anchors first: they only change the content of their lines
+	for _, n := range slices.Backward(anchors) {
+		lines[n.Line-1] = removeAnchor(lines[n.Line-1], n.Column-1, n.Anchor)
+	}
+
+	remove := make([]bool, len(lines))
+	for _, i := range blocks {
+		// A block spans the lines up to the next key. The comments directly
+		// above the next key belong to it, those above the block to the block.
+		start, end := root.Content[i].Line-1, len(lines)
+		if i+2 < len(root.Content) {
+			end = root.Content[i+2].Line - 1
+		}
+		for end > start+1 && isComment(lines[end-1]) {
+			end--
+		}
+		for start > 0 && isComment(lines[start-1]) {
+			start--
+		}
+		for line := start; line < end; line++ {
+			remove[line] = true
+		}
+	}
+	var kept []string
+	for i, line := range lines {
+		if !remove[i] {
+			kept = append(kept, line)
+		}
+	}
+	// Removing the last block leaves the blank lines that separated it
+	for len(kept) > 0 && strings.TrimSpace(kept[len(kept)-1]) == "" && remove[len(remove)-1] {
+		kept = kept[:len(kept)-1]
+	}
+
+	result := strings.Join(kept, "")
+	if missingNewline {
+		result = strings.TrimSuffix(result, "\n")
+	}
+	return []byte(result), nil
+}
+
+// removeAnchor removes "&anchor" starting at the given column (in
+// characters) of a line, with the space separating it from the value. The
+// line is returned unchanged if the anchor is not found there.
+func removeAnchor(line string, column int, anchor string) string {
+	start := 0
+	for i := 0; i < column && start < len(line); i++ {
+		_, size := utf8.DecodeRuneInString(line[start:])
+		start += size
+	}
+	if !strings.HasPrefix(line[start:], "&"+anchor) {
+		return line
+	}
+	end := start + len(anchor) + 1
+	rest := strings.TrimLeft(line[end:], " \t")
+	if strings.TrimSpace(rest) == "" {
+		// Nothing follows on the line: drop the space before the anchor
+		return strings.TrimRight(line[:start], " \t") + rest
+	}
+	return line[:start] + rest
+}
+
+// isComment reports whether a line only holds a comment
+func isComment(line string) bool {
+	return strings.HasPrefix(strings.TrimSpace(line), "#")
+}
+
+// walkNodes calls fn for n and every node under it, without following aliases
+func walkNodes(n *yaml.Node, fn func(*yaml.Node)) {
+	fn(n)
+	for _, child := range n.Content {
+		walkNodes(child, fn)
+	}
+}
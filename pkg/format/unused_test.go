@@ -0,0 +1,96 @@
+package format_test
+
+import (
+	"testing"
+
+	"github.com/runs-on/config/pkg/format"
+)
+
+func TestRemoveUnused(t *testing.T) {
+	testCases := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name: "unused block and anchor",
+			input: `# Shared settings
+x-defaults: &defaults
+  cpu: 2
+
+# Old settings
+x-legacy: &legacy
+  cpu: 4
+
+runners:
+  small: &small
+    <<: *defaults
+    ram: &ram 16 # memory
+  large:
+    cpu: [8]
+`,
+			expected: `# Shared settings
+x-defaults: &defaults
+  cpu: 2
+
+runners:
+  small:
+    <<: *defaults
+    ram: 16 # memory
+  large:
+    cpu: [8]
+`,
+		},
+		{
+			name: "anchor only used by an unused block",
+			input: `x-base: &base
+  cpu: 2
+x-extended:
+  <<: *base
+  ram: 16
+runners:
+  small:
+    cpu: 2
+x-trailing: true
+`,
+			expected: `runners:
+  small:
+    cpu: 2
+`,
+		},
+		{
+			name: "everything used",
+			input: `x-defaults: &defaults
+  cpu: 2
+runners:
+  small: *defaults
+`,
+			expected: `x-defaults: &defaults
+  cpu: 2
+runners:
+  small: *defaults
+`,
+		},
+		{
+			name: "flow values",
+			input: `runners:
+  small: {cpu: &cpu [2], ram: 16}
+admins: [&first alice]`,
+			expected: `runners:
+  small: {cpu: [2], ram: 16}
+admins: [alice]`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := format.RemoveUnused([]byte(tc.input))
+			if err != nil {
+				t.Fatalf("RemoveUnused failed: %v", err)
+			}
+			if string(got) != tc.expected {
+				t.Errorf("Unexpected result:\n%s\nexpected:\n%s", got, tc.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,157 @@
+// Package labels checks RunsOn job labels, as written in workflow runs-on:
+// lines, against configured runners.
+package labels
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/runs-on/config/pkg/config"
+)
+
+// Mismatch is a label setting that a configured runner does not satisfy
+type Mismatch struct {
+	// Key is the label key, e.g. "cpu"
+	Key string
+	// Label is the value requested by the label
+	Label string
+	// Config is the runner's value, empty when the runner does not set it
+	Config string
+	// Message describes the mismatch
+	Message string
+}
+
+// identityKeys select the runner or job rather than describe the instance,
+// so they are not compared against the runner spec
+var identityKeys = []string{"runs-on", "runner", "run-id", "pool", "env", "region"}
+
+// Matches reports whether a job label such as
+// "runs-on=${{ github.run_id }}/cpu=4/family=c7a+m7a/spot=false" would be
+// satisfied by runner. Every setting of the label that the runner spec
+// differs from is returned as a Mismatch; settings the runner does not
+// configure count as mismatches too. The parts Parse rejects are returned
+// first, keyed by the part when it is not key=value.
+func Matches(runner config.Runner, label string) (bool, []Mismatch) {
+	settings, diags := Parse(label)
+	var mismatches []Mismatch
+	for _, diag := range diags {
+		key := diag.Key
+		if key == "" {
+			key = diag.Part
+		}
+		mismatches = append(mismatches, Mismatch{Key: key, Message: diag.Message})
+	}
+	for _, setting := range settings {
+		if slices.Contains(identityKeys, setting.Key) {
+			continue
+		}
+		if mismatch, ok := compare(runner, setting.Key, setting.Value); !ok {
+			mismatches = append(mismatches, mismatch)
+		}
+	}
+	return len(mismatches) == 0, mismatches
+}
+
+// compare compares runner to a setting whose value Parse checked
+func compare(runner config.Runner, key, value string) (Mismatch, bool) {
+	var configured string
+	var equal bool
+
+	switch key {
+	case "cpu", "ram":
+		list := runner.CPU
+		if key == "ram" {
+			list = runner.RAM
+		}
+		configured = formatNumbers(list)
+		want, _ := parseNumbers(value)
+		equal = sameSet(formatNumbers(want), configured, "+")
+	case "family", "extras", "retry", "tags":
+		configured = strings.Join(runnerList(runner, key), "+")
+		equal = sameSet(value, configured, "+")
+	case "image":
+		configured = runner.Image
+		equal = value == configured
+	case "volume":
+		configured = runner.Volume
+		equal = value == configured
+	case "spot":
+		configured = string(runner.Spot)
+		equal = configured != "" && config.Spot(value).Canonical() == runner.Spot.Canonical()
+	case "ssh", "private", "nested-virt", "debug":
+		flag := runnerFlag(runner, key)
+		if flag != nil {
+			configured = strconv.FormatBool(flag.IsTrue())
+		}
+		want, _ := strconv.ParseBool(value)
+		equal = flag != nil && want == flag.IsTrue()
+	}
+
+	if equal {
+		return Mismatch{}, true
+	}
+	message := fmt.Sprintf("label requests %s=%s but the runner has %s=%s", key, value, key, configured)
+	if configured == "" {
+		message = fmt.Sprintf("label requests %s=%s but the runner does not set %s", key, value, key)
+	}
+	return Mismatch{Key: key, Label: value, Config: configured, Message: message}, false
+}
+
+func runnerList(runner config.Runner, key string) config.StringList {
+	switch key {
+	case "family":
+		return runner.Family
+	case "extras":
+		return runner.Extras
+	case "retry":
+		return runner.Retry
+	default:
+		return runner.Tags
+	}
+}
+
+func runnerFlag(runner config.Runner, key string) *config.Bool {
+	switch key {
+	case "ssh":
+		return runner.SSH
+	case "private":
+		return runner.Private
+	case "nested-virt":
+		return runner.NestedVirt
+	default:
+		return runner.Debug
+	}
+}
+
+func parseNumbers(value string) ([]float64, error) {
+	var numbers []float64
+	for _, part := range strings.Split(value, "+") {
+		n, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil {
+			return nil, err
+		}
+		numbers = append(numbers, n)
+	}
+	return numbers, nil
+}
+
+func formatNumbers(numbers []float64) string {
+	parts := make([]string, len(numbers))
+	for i, n := range numbers {
+		parts[i] = strconv.FormatFloat(n, 'f', -1, 64)
+	}
+	return strings.Join(parts, "+")
+}
+
+// sameSet reports whether two sep-separated lists hold the same values
+func sameSet(a, b, sep string) bool {
+	if a == "" || b == "" {
+		return a == b
+	}
+	left, right := strings.Split(a, sep), strings.Split(b, sep)
+	slices.Sort(left)
+	slices.Sort(right)
+	return slices.Equal(slices.Compact(left), slices.Compact(right))
+}
@@ -0,0 +1,163 @@
+package labels_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/runs-on/config/pkg/config"
+	"github.com/runs-on/config/pkg/labels"
+	"gopkg.in/yaml.v3"
+)
+
+func parseRunner(t *testing.T, src string) config.Runner {
+	t.Helper()
+	var runner config.Runner
+	if err := yaml.Unmarshal([]byte(src), &runner); err != nil {
+		t.Fatalf("Failed to parse runner: %v", err)
+	}
+	return runner
+}
+
+func TestMatches(t *testing.T) {
+	runner := parseRunner(t, `
+cpu: "2+4"
+ram: 16
+family: [m7a, c7a]
+image: ubuntu24-full-x64
+spot: price-capacity-optimized
+ssh: "false"
+extras: s3-cache+tmpfs
+`)
+
+	testCases := []struct {
+		label string
+		match bool
+		keys  []string
+	}{
+		{"runs-on=123/runner=small", true, nil},
+		{"runs-on=123/cpu=4+2/ram=16/family=c7a+m7a", true, nil},
+		{"runs-on=123,image=ubuntu24-full-x64,spot=pco,ssh=false", true, nil},
+		{"runs-on=123/extras=tmpfs+s3-cache", true, nil},
+		{"runs-on=123/cpu=8", false, []string{"cpu"}},
+		{"runs-on=123/family=c7a/spot=false", false, []string{"family", "spot"}},
+		{"runs-on=123/private=true", false, []string{"private"}},
+		{"runs-on=123/ssh=maybe", false, []string{"ssh"}},
+		{"runs-on=123/gpu=1", false, []string{"gpu"}},
+		{"runs-on=123/oops", false, []string{"oops"}},
+		{"runs-on=123/cpu=2+4/cpu=8", false, []string{"cpu"}},
+		{"runs-on=123/ram=/family=c7a", false, []string{"ram", "family"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.label, func(t *testing.T) {
+			match, mismatches := labels.Matches(runner, tc.label)
+			if match != tc.match {
+				t.Errorf("Expected match=%v, got %v (%v)", tc.match, match, mismatches)
+			}
+			if len(mismatches) != len(tc.keys) {
+				t.Fatalf("Expected mismatches for %v, got %v", tc.keys, mismatches)
+			}
+			for i, key := range tc.keys {
+				if mismatches[i].Key != key || mismatches[i].Message == "" {
+					t.Errorf("Expected mismatch for %q, got %+v", key, mismatches[i])
+				}
+			}
+		})
+	}
+}
+
+func TestMatches_UnsetField(t *testing.T) {
+	runner := parseRunner(t, "cpu: [2]\n")
+	_, mismatches := labels.Matches(runner, "runs-on=1/image=ubuntu24-full-x64")
+	if len(mismatches) != 1 || mismatches[0].Config != "" || mismatches[0].Label != "ubuntu24-full-x64" {
+		t.Errorf("Expected a mismatch for the unset image, got %+v", mismatches)
+	}
+}
+
+func TestResolve(t *testing.T) {
+	cfg, err := config.Parse([]byte(`runners:
+  small:
+    cpu: 2
+    family: [m7a]
+    image: custom
+    ssh: false
+pools:
+  main:
+    runner: small
+`))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	resolution, err := labels.Resolve(cfg, "runs-on=${{ github.run_id }}/runner=small/cpu=8/spot=false")
+	if err != nil {
+		t.Fatalf("Resolve failed: %v", err)
+	}
+	runner := resolution.Runner
+	if resolution.Name != "small" || !slices.Equal(resolution.Overrides, []string{"cpu", "spot"}) {
+		t.Errorf("Unexpected resolution %+v", resolution)
+	}
+	if !slices.Equal(runner.CPU, config.NumberList{8}) || runner.Spot != "false" || runner.Image != "custom" || runner.SSH.IsTrue() {
+		t.Errorf("Expected the label to override the runner, got %+v", runner)
+	}
+	if runner.Volume != config.DefaultVolume || runner.Private == nil {
+		t.Errorf("Expected defaults for unset fields, got %+v", runner)
+	}
+
+	resolution, err = labels.Resolve(cfg, "runs-on=1/pool=main")
+	if err != nil || resolution.Name != "small" || resolution.Pool != "main" {
+		t.Errorf("Expected the runner of the pool, got %+v, %v", resolution, err)
+	}
+
+	resolution, err = labels.Resolve(cfg, "runs-on=1/family=c7a+m7a/ram=16")
+	if err != nil || resolution.Name != "" || !slices.Equal(resolution.Runner.Family, config.StringList{"c7a", "m7a"}) || resolution.Runner.Image != config.DefaultImage {
+		t.Errorf("Expected a runner from the label and defaults, got %+v, %v", resolution, err)
+	}
+
+	for _, label := range []string{"runs-on=1/runner=missing", "runs-on=1/pool=missing", "runs-on=1/pool=main/runner=other", "runs-on=1/gpu=1", "runs-on=1/cpu=lots", "runs-on=1/oops", "runs-on=1/runner=small/cpu=2/cpu=4"} {
+		if _, err := labels.Resolve(cfg, label); err == nil {
+			t.Errorf("%s: expected an error", label)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	settings, diags := labels.Parse("runs-on=${{ github.run_id }}/runner=small, cpu=2+4/family=c7a/spot=never/cpu=4+2")
+	want := []labels.Setting{
+		{Key: "runs-on", Value: "${{ github.run_id }}"},
+		{Key: "runner", Value: "small"},
+		{Key: "cpu", Value: "2+4"},
+		{Key: "family", Value: "c7a"},
+		{Key: "spot", Value: "never"},
+	}
+	if !slices.Equal(settings, want) || len(diags) != 0 {
+		t.Errorf("Expected settings %v, got %v, %v", want, settings, diags)
+	}
+
+	testCases := []struct {
+		label string
+		keys  []string
+	}{
+		{"runs-on=1/oops", []string{""}},
+		{"runs-on=1/gpu=1", []string{"gpu"}},
+		{"runs-on=1/cpu=lots/ram=", []string{"cpu", "ram"}},
+		{"runs-on=1/spot=cheap/ssh=maybe", []string{"spot", "ssh"}},
+		{"runs-on=1/cpu=2/cpu=4", []string{"cpu"}},
+		{"runs-on=1/runner=small/runner=large/spot=false/spot=never", []string{"runner"}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.label, func(t *testing.T) {
+			_, diags := labels.Parse(tc.label)
+			var keys []string
+			for _, diag := range diags {
+				if diag.Message == "" || diag.Part == "" {
+					t.Errorf("Expected a message and a part, got %+v", diag)
+				}
+				keys = append(keys, diag.Key)
+			}
+			if !slices.Equal(keys, tc.keys) {
+				t.Errorf("Expected diagnostics for %q, got %+v", tc.keys, diags)
+			}
+		})
+	}
+}
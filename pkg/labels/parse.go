@@ -0,0 +1,127 @@
+package labels
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/runs-on/config/pkg/config"
+)
+
+// Setting is a key=value part of a job label
+type Setting struct {
+	Key   string
+	Value string
+}
+
+// Diagnostic is a problem with a part of a job label
+type Diagnostic struct {
+	// Part is the label part at fault, as written
+	Part string
+	// Key is the key of the part, empty when the part is not key=value
+	Key string
+	// Message describes the problem
+	Message string
+}
+
+// settingKeys describe the runner and can override its configured fields
+var settingKeys = []string{
+	"cpu", "ram", "family", "image", "volume", "spot",
+	"ssh", "private", "nested-virt", "debug", "extras", "retry", "tags",
+}
+
+// spotValues are the canonical spot strategies (see config.Spot)
+var spotValues = []string{"true", "false", "pco", "lp", "co"}
+
+// Parse splits a job label such as
+// "runs-on=${{ github.run_id }}/runner=small/cpu=8/spot=false" into its
+// settings, in label order. Label parts may be separated by "/" or ",".
+// Malformed parts, unknown keys, invalid values and keys set again to
+// another value are reported as diagnostics and left out of the settings.
+func Parse(label string) ([]Setting, []Diagnostic) {
+	var settings []Setting
+	var diags []Diagnostic
+	for _, part := range splitLabel(label) {
+		key, value, ok := strings.Cut(part, "=")
+		key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+		if !ok || key == "" {
+			diags = append(diags, Diagnostic{Part: part, Message: fmt.Sprintf("malformed label part %q (expected key=value)", part)})
+			continue
+		}
+		if message := checkValue(key, value); message != "" {
+			diags = append(diags, Diagnostic{Part: part, Key: key, Message: message})
+			continue
+		}
+		i := slices.IndexFunc(settings, func(s Setting) bool { return s.Key == key })
+		switch {
+		case i < 0:
+			settings = append(settings, Setting{Key: key, Value: value})
+		case !sameValue(key, settings[i].Value, value):
+			diags = append(diags, Diagnostic{Part: part, Key: key,
+				Message: fmt.Sprintf("%s=%s conflicts with %s=%s set earlier in the label", key, value, key, settings[i].Value)})
+		}
+	}
+	return settings, diags
+}
+
+// splitLabel splits a label into its key=value parts
+func splitLabel(label string) []string {
+	var parts []string
+	for _, part := range strings.FieldsFunc(label, func(r rune) bool { return r == '/' || r == ',' }) {
+		if part = strings.TrimSpace(part); part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return parts
+}
+
+// checkValue returns why value is not valid for key, or ""
+func checkValue(key, value string) string {
+	if !slices.Contains(identityKeys, key) && !slices.Contains(settingKeys, key) {
+		return fmt.Sprintf("unknown label key %q", key)
+	}
+	if value == "" {
+		return fmt.Sprintf("label key %q has no value", key)
+	}
+	invalid := fmt.Sprintf("invalid %s value %q", key, value)
+	switch key {
+	case "cpu", "ram":
+		if _, err := parseNumbers(value); err != nil {
+			return invalid
+		}
+	case "spot":
+		if !slices.Contains(spotValues, config.Spot(value).Canonical()) {
+			return invalid
+		}
+	case "ssh", "private", "nested-virt", "debug":
+		if _, err := strconv.ParseBool(value); err != nil {
+			return invalid
+		}
+	case "family":
+		if len(config.Strings(value)) == 0 {
+			return invalid
+		}
+	}
+	return ""
+}
+
+// sameValue reports whether two values of key request the same setting
+func sameValue(key, a, b string) bool {
+	switch key {
+	case "cpu", "ram":
+		x, _ := parseNumbers(a)
+		y, _ := parseNumbers(b)
+		return sameSet(formatNumbers(x), formatNumbers(y), "+")
+	case "family", "extras", "retry", "tags":
+		return sameSet(a, b, "+")
+	case "spot":
+		return config.Spot(a).Canonical() == config.Spot(b).Canonical()
+	case "ssh", "private", "nested-virt", "debug":
+		x, _ := strconv.ParseBool(a)
+		y, _ := strconv.ParseBool(b)
+		return x == y
+	default:
+		return a == b
+	}
+}
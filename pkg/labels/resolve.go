@@ -0,0 +1,125 @@
+package labels
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/runs-on/config/pkg/config"
+)
+
+// Resolution is the runner a job label resolves to
+type Resolution struct {
+	// Runner is the effective runner specification
+	Runner config.Runner
+	// Name is the configured runner the label selects, directly or through
+	// a pool, or empty when the label describes the runner entirely
+	Name string
+	// Pool is the pool the label selects, or empty
+	Pool string
+	// Overrides are the label keys that override the configured runner, in
+	// label order
+	Overrides []string
+}
+
+// Resolve returns the runner a job label such as
+// "runs-on=${{ github.run_id }}/runner=small/cpu=8/spot=false" gets with cfg:
+// the runner selected with runner= (or the runner of the pool selected with
+// pool=), with the settings of the label overriding its fields, and RunsOn
+// defaults for the fields that are still unset. A label that selects no
+// runner is resolved from its settings and the defaults only. Resolve fails
+// if Parse reports diagnostics for the label, and if the selected runner or
+// pool is not defined.
+func Resolve(cfg *config.Config, label string) (Resolution, error) {
+	parsed, diags := Parse(label)
+	if len(diags) > 0 {
+		messages := make([]string, len(diags))
+		for i, diag := range diags {
+			messages[i] = diag.Message
+		}
+		return Resolution{}, errors.New(strings.Join(messages, "; "))
+	}
+
+	var resolution Resolution
+	var settings []Setting
+	for _, setting := range parsed {
+		switch setting.Key {
+		case "runner":
+			resolution.Name = setting.Value
+		case "pool":
+			resolution.Pool = setting.Value
+		default:
+			if !slices.Contains(identityKeys, setting.Key) {
+				settings = append(settings, setting)
+			}
+		}
+	}
+
+	if resolution.Pool != "" {
+		pool, ok := cfg.Pools[resolution.Pool]
+		if !ok {
+			return Resolution{}, fmt.Errorf("pool %q is not defined", resolution.Pool)
+		}
+		if resolution.Name != "" && resolution.Name != pool.Runner {
+			return Resolution{}, fmt.Errorf("pool %q uses runner %q, not %q", resolution.Pool, pool.Runner, resolution.Name)
+		}
+		resolution.Name = pool.Runner
+	}
+	if resolution.Name != "" {
+		runner, ok := cfg.Runners[resolution.Name]
+		if !ok {
+			return Resolution{}, fmt.Errorf("runner %q is not defined", resolution.Name)
+		}
+		resolution.Runner = runner
+	}
+
+	for _, setting := range settings {
+		override(&resolution.Runner, setting.Key, setting.Value)
+		resolution.Overrides = append(resolution.Overrides, setting.Key)
+	}
+	resolution.Runner.ApplyDefaults()
+	return resolution, nil
+}
+
+// override sets the runner field of a label key to value, which Parse has
+// checked
+func override(runner *config.Runner, key, value string) {
+	switch key {
+	case "cpu", "ram":
+		numbers, _ := parseNumbers(value)
+		if key == "cpu" {
+			runner.CPU = numbers
+		} else {
+			runner.RAM = numbers
+		}
+	case "family":
+		runner.Family = config.Strings(value)
+	case "extras":
+		runner.Extras = config.Strings(value)
+	case "retry":
+		runner.Retry = config.Strings(value)
+	case "tags":
+		runner.Tags = config.Strings(value)
+	case "image":
+		runner.Image = value
+	case "volume":
+		runner.Volume = value
+	case "spot":
+		runner.Spot = config.Spot(value)
+	case "ssh", "private", "nested-virt", "debug":
+		b, _ := strconv.ParseBool(value)
+		flag := config.Bool(b)
+		switch key {
+		case "ssh":
+			runner.SSH = &flag
+		case "private":
+			runner.Private = &flag
+		case "nested-virt":
+			runner.NestedVirt = &flag
+		default:
+			runner.Debug = &flag
+		}
+	}
+}
@@ -0,0 +1,278 @@
+// Package migrate upgrades runs-on.yml files across breaking schema changes
+// by applying a chain of versioned transforms to the YAML node tree, so that
+// comments and anchors survive the upgrade.
+package migrate
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/runs-on/config/pkg/format"
+	"gopkg.in/yaml.v3"
+)
+
+// Migration is a transform upgrading configs to a schema version
+type Migration struct {
+	// Version is the schema version the migration upgrades to
+	Version int
+	// ID identifies the migration in reports
+	ID string
+	// Description explains what the migration changes and why
+	Description string
+
+	// apply proposes each edit to propose before making it, and skips the
+	// edit if propose returns false
+	apply func(root *yaml.Node, propose func(Change) bool)
+}
+
+// Change is a single edit made by a migration
+type Change struct {
+	Migration string
+	Line      int
+	Message   string
+	// Before and After are the edited entry as YAML before and after the
+	// change. After is empty for removed entries.
+	Before string
+	After  string
+}
+
+// migrations are applied in order. Each transform only touches configs that
+// still use the old form, so running a migration twice is harmless.
+var migrations = []Migration{
+	{
+		Version:     2,
+		ID:          "pool-environment-to-env",
+		Description: "Rename the deprecated pool field 'environment' to 'env'",
+		apply:       migratePoolEnvironment,
+	},
+	{
+		Version:     3,
+		ID:          "runner-remove-disk",
+		Description: "Remove the deprecated runner field 'disk', which RunsOn ignores (use 'volume' instead)",
+		apply:       migrateRunnerDisk,
+	},
+}
+
+// Migrations returns all migrations in the order they are applied
+func Migrations() []Migration {
+	return append([]Migration(nil), migrations...)
+}
+
+// LatestVersion returns the schema version configs are upgraded to
+func LatestVersion() int {
+	return migrations[len(migrations)-1].Version
+}
+
+// Migrate applies the migrations after version from to src and returns the
+// upgraded config in canonical format together with the changes made. Use
+// from 0 (or 1) when the config's schema version is unknown: the version the
+// config declares with schema-version (or x-schema-version), if any, is used
+// then. A declared version is updated to the latest one.
+func Migrate(src []byte, from int) ([]byte, []Change, error) {
+	return MigrateFunc(src, from, nil)
+}
+
+// MigrateFunc is like Migrate, but only makes the changes accept returns true
+// for. Changes are proposed in the order they are applied, so accept may ask
+// for confirmation. A nil accept makes every change.
+func MigrateFunc(src []byte, from int, accept func(Change) bool) ([]byte, []Change, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(src, &doc); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse YAML: %w", err)
+	}
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return src, nil, nil
+	}
+	root := doc.Content[0]
+	versionKey, declared := declaredVersion(root)
+	if from == 0 {
+		from = declared
+	}
+
+	var changes []Change
+	for _, migration := range migrations {
+		if migration.Version <= from {
+			continue
+		}
+		migration.apply(root, func(change Change) bool {
+			change.Migration = migration.ID
+			if accept != nil && !accept(change) {
+				return false
+			}
+			changes = append(changes, change)
+			return true
+		})
+	}
+	if versionKey != nil && declared < LatestVersion() {
+		value := mappingValue(root, versionKey.Value)
+		latest := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: fmt.Sprintf("v%d", LatestVersion())}
+		change := Change{
+			Migration: "schema-version",
+			Line:      versionKey.Line,
+			Message:   fmt.Sprintf("set '%s' to %s", versionKey.Value, latest.Value),
+			Before:    entryText(versionKey.Value, value),
+			After:     entryText(versionKey.Value, latest),
+		}
+		if accept == nil || accept(change) {
+			value.Value, value.Tag, value.Style = latest.Value, latest.Tag, 0
+			changes = append(changes, change)
+		}
+	}
+	if len(changes) == 0 {
+		return src, nil, nil
+	}
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&doc); err != nil {
+		return nil, nil, fmt.Errorf("failed to encode YAML: %w", err)
+	}
+	out, err := format.Format(buf.Bytes())
+	if err != nil {
+		return nil, nil, err
+	}
+	return out, changes, nil
+}
+
+func migratePoolEnvironment(root *yaml.Node, propose func(Change) bool) {
+	forEachEntry(mappingValue(root, "pools"), func(name string, pool *yaml.Node) {
+		key := mappingKey(pool, "environment")
+		if key == nil {
+			return
+		}
+		value := mappingValue(pool, "environment")
+		if mappingKey(pool, "env") == nil {
+			if propose(Change{
+				Line:    key.Line,
+				Message: fmt.Sprintf("pool '%s': renamed 'environment' to 'env'", name),
+				Before:  entryText("environment", value),
+				After:   entryText("env", value),
+			}) {
+				renameKey(pool, "environment", "env")
+			}
+			return
+		}
+		if propose(Change{
+			Line:    key.Line,
+			Message: fmt.Sprintf("pool '%s': removed 'environment', 'env' is already set", name),
+			Before:  entryText("environment", value),
+		}) {
+			removeKey(pool, "environment")
+		}
+	})
+}
+
+func migrateRunnerDisk(root *yaml.Node, propose func(Change) bool) {
+	forEachEntry(mappingValue(root, "runners"), func(name string, runner *yaml.Node) {
+		key := mappingKey(runner, "disk")
+		if key == nil {
+			return
+		}
+		if propose(Change{
+			Line:    key.Line,
+			Message: fmt.Sprintf("runner '%s': removed ignored 'disk' field", name),
+			Before:  entryText("disk", mappingValue(runner, "disk")),
+		}) {
+			removeKey(runner, "disk")
+		}
+	})
+}
+
+// declaredVersion returns the key of the schema version a config declares
+// and its number, e.g. 2 for "v2", or nil and 0 if it declares none or a
+// value that is not a version
+func declaredVersion(root *yaml.Node) (*yaml.Node, int) {
+	for _, field := range []string{"schema-version", "x-schema-version"} {
+		key, value := mappingKey(root, field), mappingValue(root, field)
+		if key == nil {
+			continue
+		}
+		if value.Kind != yaml.ScalarNode {
+			return nil, 0
+		}
+		version, err := strconv.Atoi(strings.TrimPrefix(value.Value, "v"))
+		if err != nil || !strings.HasPrefix(value.Value, "v") || version < 1 {
+			return nil, 0
+		}
+		return key, version
+	}
+	return nil, 0
+}
+
+// entryText renders a mapping entry as YAML, without trailing newline
+func entryText(key string, value *yaml.Node) string {
+	entry := &yaml.Node{Kind: yaml.MappingNode, Content: []*yaml.Node{
+		{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, value,
+	}}
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(entry); err != nil {
+		return key + ": ..."
+	}
+	return strings.TrimRight(buf.String(), "\n")
+}
+
+// forEachEntry calls fn for every named entry of a section mapping
+func forEachEntry(section *yaml.Node, fn func(name string, entry *yaml.Node)) {
+	if section == nil || section.Kind != yaml.MappingNode {
+		return
+	}
+	for i := 0; i+1 < len(section.Content); i += 2 {
+		if entry := section.Content[i+1]; entry.Kind == yaml.MappingNode {
+			fn(section.Content[i].Value, entry)
+		}
+	}
+}
+
+// renameKey renames key old to new in a mapping, unless new already exists
+func renameKey(n *yaml.Node, old, new string) bool {
+	key := mappingKey(n, old)
+	if key == nil || mappingKey(n, new) != nil {
+		return false
+	}
+	key.Value = new
+	return true
+}
+
+// removeKey deletes a key and its value from a mapping, keeping comments
+// attached to the key on the following entry
+func removeKey(n *yaml.Node, key string) {
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if n.Content[i].Value != key {
+			continue
+		}
+		if comment := n.Content[i].HeadComment; comment != "" && i+2 < len(n.Content) && n.Content[i+2].HeadComment == "" {
+			n.Content[i+2].HeadComment = comment
+		}
+		n.Content = append(n.Content[:i], n.Content[i+2:]...)
+		return
+	}
+}
+
+func mappingKey(n *yaml.Node, key string) *yaml.Node {
+	if n == nil || n.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if n.Content[i].Value == key {
+			return n.Content[i]
+		}
+	}
+	return nil
+}
+
+func mappingValue(n *yaml.Node, key string) *yaml.Node {
+	if n == nil || n.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if n.Content[i].Value == key {
+			return n.Content[i+1]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,169 @@
+package migrate_test
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/runs-on/config/pkg/migrate"
+)
+
+const legacyConfig = `runners:
+  small:
+    cpu: [2]
+    # Deprecated
+    disk: large
+    family: [c7a]
+pools:
+  main:
+    runner: small
+    environment: staging
+    schedule:
+      - name: default
+        hot: 1
+        stopped: 1
+`
+
+func TestMigrate(t *testing.T) {
+	out, changes, err := migrate.Migrate([]byte(legacyConfig), 0)
+	if err != nil {
+		t.Fatalf("Migrate failed: %v", err)
+	}
+
+	expected := `runners:
+  small:
+    cpu: [2]
+    # Deprecated
+    family: [c7a]
+
+pools:
+  main:
+    runner: small
+    env: staging
+    schedule:
+      - name: default
+        hot: 1
+        stopped: 1
+`
+	if string(out) != expected {
+		t.Errorf("Unexpected output:\n%s\nExpected:\n%s", out, expected)
+	}
+
+	if len(changes) != 2 {
+		t.Fatalf("Expected 2 changes, got %v", changes)
+	}
+	if changes[0].Migration != "pool-environment-to-env" || changes[0].Line != 10 {
+		t.Errorf("Unexpected first change: %+v", changes[0])
+	}
+	if changes[1].Migration != "runner-remove-disk" || changes[1].Line != 5 {
+		t.Errorf("Unexpected second change: %+v", changes[1])
+	}
+
+	again, changes, err := migrate.Migrate(out, 0)
+	if err != nil {
+		t.Fatalf("Migrate failed: %v", err)
+	}
+	if len(changes) != 0 || string(again) != string(out) {
+		t.Errorf("Expected migrating twice to be a no-op, got changes %v", changes)
+	}
+}
+
+func TestMigrate_From(t *testing.T) {
+	out, changes, err := migrate.Migrate([]byte(legacyConfig), 2)
+	if err != nil {
+		t.Fatalf("Migrate failed: %v", err)
+	}
+	if len(changes) != 1 || changes[0].Migration != "runner-remove-disk" {
+		t.Errorf("Expected only migrations after version 2, got %v", changes)
+	}
+	if !strings.Contains(string(out), "environment: staging") {
+		t.Errorf("Expected 'environment' to be kept when starting from version 2:\n%s", out)
+	}
+}
+
+func TestMigrate_DeclaredVersion(t *testing.T) {
+	src := "schema-version: v2\n" + legacyConfig
+	out, changes, err := migrate.Migrate([]byte(src), 0)
+	if err != nil {
+		t.Fatalf("Migrate failed: %v", err)
+	}
+	var ids []string
+	for _, change := range changes {
+		ids = append(ids, change.Migration)
+	}
+	if want := []string{"runner-remove-disk", "schema-version"}; !slices.Equal(ids, want) {
+		t.Errorf("Expected changes %v, got %v", want, ids)
+	}
+	if !strings.Contains(string(out), "schema-version: v3") || !strings.Contains(string(out), "environment: staging") {
+		t.Errorf("Expected the declared version to be used and updated:\n%s", out)
+	}
+}
+
+func TestMigrate_EnvAlreadySet(t *testing.T) {
+	src := `pools:
+  main:
+    runner: small
+    env: production
+    environment: staging
+`
+	out, changes, err := migrate.Migrate([]byte(src), 0)
+	if err != nil {
+		t.Fatalf("Migrate failed: %v", err)
+	}
+	if len(changes) != 1 || !strings.Contains(changes[0].Message, "already set") {
+		t.Errorf("Unexpected changes: %v", changes)
+	}
+	if strings.Contains(string(out), "environment") || !strings.Contains(string(out), "env: production") {
+		t.Errorf("Expected 'env' to win:\n%s", out)
+	}
+}
+
+func TestMigrateFunc(t *testing.T) {
+	var proposed []migrate.Change
+	out, changes, err := migrate.MigrateFunc([]byte(legacyConfig), 0, func(change migrate.Change) bool {
+		proposed = append(proposed, change)
+		return change.Migration == "runner-remove-disk"
+	})
+	if err != nil {
+		t.Fatalf("MigrateFunc failed: %v", err)
+	}
+	if len(proposed) != 2 || proposed[0].Before != "environment: staging" || proposed[0].After != "env: staging" {
+		t.Fatalf("Unexpected proposed changes: %+v", proposed)
+	}
+	if proposed[1].Before != "disk: large" || proposed[1].After != "" {
+		t.Errorf("Unexpected proposed removal: %+v", proposed[1])
+	}
+	if len(changes) != 1 || changes[0].Migration != "runner-remove-disk" {
+		t.Errorf("Expected only the accepted change, got %v", changes)
+	}
+	if !strings.Contains(string(out), "environment: staging") || strings.Contains(string(out), "disk:") {
+		t.Errorf("Expected only 'disk' to be removed:\n%s", out)
+	}
+}
+
+func TestMigrate_UpToDate(t *testing.T) {
+	src := "runners:\n  small:\n    cpu: [ 2 ]\n"
+	out, changes, err := migrate.Migrate([]byte(src), 0)
+	if err != nil {
+		t.Fatalf("Migrate failed: %v", err)
+	}
+	if len(changes) != 0 || string(out) != src {
+		t.Errorf("Expected an up-to-date config to be returned unchanged, got %q", out)
+	}
+}
+
+func TestMigrations(t *testing.T) {
+	previous := 0
+	for _, migration := range migrate.Migrations() {
+		if migration.Version < previous {
+			t.Errorf("Migration %s is out of version order", migration.ID)
+		}
+		if migration.ID == "" || migration.Description == "" {
+			t.Errorf("Migration %+v must have an ID and description", migration)
+		}
+		previous = migration.Version
+	}
+	if migrate.LatestVersion() != previous {
+		t.Errorf("LatestVersion() = %d, expected %d", migrate.LatestVersion(), previous)
+	}
+}
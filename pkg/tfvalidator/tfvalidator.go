@@ -0,0 +1,84 @@
+// Package tfvalidator adapts the validator to Terraform providers built with
+// the plugin SDK, so that an attribute holding a runs-on.yml document can be
+// validated with the same rules as the CLI
+package tfvalidator
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/runs-on/config/pkg/validate"
+)
+
+// sourceName is used as the path of the validated document
+const sourceName = "runs-on.yml"
+
+// ValidateDiagFunc validates a string attribute holding a RunsOn config. It
+// has the signature of schema.SchemaValidateDiagFunc and can be set as the
+// ValidateDiagFunc of a schema.Schema.
+func ValidateDiagFunc(value any, path cty.Path) diag.Diagnostics {
+	return New(validate.Options{})(value, path)
+}
+
+// New returns a ValidateDiagFunc validating with the given options. Local
+// _extends are never read, as the config does not come from a file.
+func New(opts validate.Options) func(any, cty.Path) diag.Diagnostics {
+	opts.DisableLocalExtends = true
+	return func(value any, path cty.Path) diag.Diagnostics {
+		content, ok := value.(string)
+		if !ok {
+			return diag.Diagnostics{{
+				Severity:      diag.Error,
+				Summary:       "Invalid RunsOn config",
+				Detail:        fmt.Sprintf("expected a string, got %T", value),
+				AttributePath: path,
+			}}
+		}
+
+		diags, err := validate.ValidateBytesWithOptions(context.Background(), []byte(content), sourceName, opts)
+		if err != nil {
+			return diag.Diagnostics{{
+				Severity:      diag.Error,
+				Summary:       "Invalid RunsOn config",
+				Detail:        err.Error(),
+				AttributePath: path,
+			}}
+		}
+		return Convert(diags, path)
+	}
+}
+
+// Convert turns validation diagnostics into Terraform diagnostics attached to
+// the attribute at path. The summary is the diagnostic message; the detail
+// gives its position in the document and its rule ID.
+func Convert(diags []validate.Diagnostic, path cty.Path) diag.Diagnostics {
+	var result diag.Diagnostics
+	for _, d := range diags {
+		severity := diag.Error
+		if d.Severity != validate.SeverityError {
+			severity = diag.Warning
+		}
+		result = append(result, diag.Diagnostic{
+			Severity:      severity,
+			Summary:       d.Message,
+			Detail:        detail(d),
+			AttributePath: path,
+		})
+	}
+	return result
+}
+
+// detail describes where a diagnostic is reported and which rule reported it
+func detail(d validate.Diagnostic) string {
+	var parts []string
+	if d.Line > 0 {
+		parts = append(parts, fmt.Sprintf("line %d, column %d", d.Line, d.Column))
+	}
+	if d.RuleID != "" {
+		parts = append(parts, fmt.Sprintf("rule %s", d.RuleID))
+	}
+	return strings.Join(parts, ", ")
+}
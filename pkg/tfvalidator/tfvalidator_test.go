@@ -0,0 +1,61 @@
+package tfvalidator_test
+
+import (
+	"testing"
+
+	"github.com/hashicorp/go-cty/cty"
+	"github.com/hashicorp/terraform-plugin-sdk/v2/diag"
+	"github.com/runs-on/config/pkg/tfvalidator"
+	"github.com/runs-on/config/pkg/validate"
+)
+
+func TestValidateDiagFunc(t *testing.T) {
+	path := cty.GetAttrPath("config")
+
+	valid := `runners:
+  small:
+    cpu: 2
+`
+	if diags := tfvalidator.ValidateDiagFunc(valid, path); diags.HasError() {
+		t.Errorf("Expected no errors, got %v", diags)
+	}
+
+	invalid := `runners:
+  small:
+    cpu: 2
+pools:
+  main:
+    runner: large
+`
+	diags := tfvalidator.ValidateDiagFunc(invalid, path)
+	if !diags.HasError() {
+		t.Fatal("Expected an error for an undefined pool runner")
+	}
+	for _, d := range diags {
+		if !d.AttributePath.Equals(path) {
+			t.Errorf("Expected the diagnostic on %v, got %v", path, d.AttributePath)
+		}
+	}
+
+	if diags := tfvalidator.ValidateDiagFunc(42, path); !diags.HasError() {
+		t.Error("Expected an error for a non-string value")
+	}
+}
+
+func TestConvert(t *testing.T) {
+	path := cty.GetAttrPath("config")
+	diags := tfvalidator.Convert([]validate.Diagnostic{
+		{Line: 3, Column: 5, Message: "bad cpu", Severity: validate.SeverityError, RuleID: "schema"},
+		{Message: "no position", Severity: validate.SeverityWarning},
+	}, path)
+
+	if len(diags) != 2 {
+		t.Fatalf("Expected 2 diagnostics, got %v", diags)
+	}
+	if diags[0].Severity != diag.Error || diags[0].Summary != "bad cpu" || diags[0].Detail != "line 3, column 5, rule schema" {
+		t.Errorf("Unexpected first diagnostic %+v", diags[0])
+	}
+	if diags[1].Severity != diag.Warning || diags[1].Detail != "" {
+		t.Errorf("Unexpected second diagnostic %+v", diags[1])
+	}
+}
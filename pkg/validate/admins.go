@@ -0,0 +1,103 @@
+package validate
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// githubUsername matches the characters GitHub allows in usernames, which
+// cannot start with a hyphen
+var githubUsername = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9-]*$`)
+
+// maxUsernameLength is the maximum length of a GitHub username
+const maxUsernameLength = 39
+
+// checkAdmins reports admins that are not GitHub usernames, admins listed
+// more than once and, if sorted is set, an admins list that is not in
+// alphabetical order. GitHub usernames are case-insensitive, so are the
+// comparisons.
+func checkAdmins(root *yaml.Node, sourceName string, sorted bool) []Diagnostic {
+	var warnings []Diagnostic
+
+	admins := mappingValue(root, "admins")
+	if admins == nil || admins.Kind != yaml.SequenceNode {
+		return warnings
+	}
+
+	seen := make(map[string]*yaml.Node)
+	var previous *yaml.Node
+	outOfOrder := false
+	for _, item := range admins.Content {
+		if item.Kind != yaml.ScalarNode {
+			continue
+		}
+		if diag, ok := checkUsername(item, sourceName); ok {
+			warnings = append(warnings, diag)
+		}
+		key := strings.ToLower(item.Value)
+		if first, ok := seen[key]; ok {
+			warnings = append(warnings, Diagnostic{
+				Path:     sourceName,
+				Line:     item.Line,
+				Column:   item.Column,
+				text:     message(RuleAdminsDuplicate, "admin", item.Value, "line", strconv.Itoa(first.Line)),
+				Severity: SeverityWarning,
+				RuleID:   RuleAdminsDuplicate,
+			})
+			continue
+		}
+		seen[key] = item
+
+		if sorted && !outOfOrder && previous != nil && key < strings.ToLower(previous.Value) {
+			outOfOrder = true
+			warnings = append(warnings, Diagnostic{
+				Path:     sourceName,
+				Line:     item.Line,
+				Column:   item.Column,
+				text:     message(RuleAdminsOrder, "admin", item.Value, "previous", previous.Value),
+				Severity: SeverityWarning,
+				RuleID:   RuleAdminsOrder,
+			})
+		}
+		previous = item
+	}
+
+	return warnings
+}
+
+// checkUsername reports an admins entry that is not a GitHub username: an
+// empty or malformed entry is an error, an email address or an @-prefixed
+// handle a warning, as it likely stands for a user under another form
+func checkUsername(item *yaml.Node, sourceName string) (Diagnostic, bool) {
+	diag := Diagnostic{
+		Path:     sourceName,
+		Line:     item.Line,
+		Column:   item.Column,
+		Severity: SeverityError,
+		RuleID:   RuleAdminsUsername,
+	}
+	name := item.Value
+	switch {
+	case item.Tag != "!!str":
+		// Other types are reported by the schema
+		return diag, false
+	case strings.TrimSpace(name) == "":
+		diag.text = message(RuleAdminsUsername + ".empty")
+	case strings.HasPrefix(name, "@"):
+		diag.text = message(RuleAdminsUsernameForm+".handle", "admin", name, "username", strings.TrimPrefix(name, "@"))
+		diag.Severity = SeverityWarning
+		diag.RuleID = RuleAdminsUsernameForm
+	case strings.Contains(name, "@"):
+		diag.text = message(RuleAdminsUsernameForm+".email", "admin", name)
+		diag.Severity = SeverityWarning
+		diag.RuleID = RuleAdminsUsernameForm
+	case !githubUsername.MatchString(name) || len(name) > maxUsernameLength:
+		diag.text = message(RuleAdminsUsername, "admin", name)
+	default:
+		return diag, false
+	}
+	return diag, true
+}
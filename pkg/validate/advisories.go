@@ -0,0 +1,48 @@
+package validate
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/runs-on/config/pkg/advisory"
+	"gopkg.in/yaml.v3"
+)
+
+// checkAdvisories reports the config entries matched by advisories. The
+// diagnostics carry the advisory ID as their rule ID.
+func checkAdvisories(yamlData any, root *yaml.Node, sourceName string, db *advisory.Database) []Diagnostic {
+	var diags []Diagnostic
+
+	data, ok := yamlData.(map[string]any)
+	if !ok {
+		return diags
+	}
+
+	for _, finding := range db.Check(data) {
+		section := mappingValue(root, finding.Section)
+		line, column := position(mappingKey(section, finding.Entry))
+		if key := mappingKey(resolveAlias(mappingValue(section, finding.Entry)), finding.Field); key != nil {
+			line, column = position(key)
+		}
+
+		message := fmt.Sprintf("%s '%s' matches advisory %s: %s",
+			strings.TrimSuffix(finding.Section, "s"), finding.Entry, finding.Advisory.ID, finding.Advisory.Summary)
+		if finding.Advisory.URL != "" {
+			message += fmt.Sprintf(" (see %s)", finding.Advisory.URL)
+		}
+		severity := SeverityWarning
+		if finding.Advisory.Severity == string(SeverityError) {
+			severity = SeverityError
+		}
+		diags = append(diags, Diagnostic{
+			Path:     sourceName,
+			Line:     line,
+			Column:   column,
+			Message:  message,
+			Severity: severity,
+			RuleID:   finding.Advisory.ID,
+		})
+	}
+
+	return diags
+}
@@ -0,0 +1,112 @@
+package validate
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"testing/fstest"
+)
+
+// maxArchiveFileSize bounds the size of a config read from an archive
+const maxArchiveFileSize = 10 << 20
+
+// IsConfigFile reports whether a file name is that of a runs-on config:
+// runs-on.yml or runs-on.yaml, in any directory
+func IsConfigFile(name string) bool {
+	base := path.Base(name)
+	return base == "runs-on.yml" || base == "runs-on.yaml"
+}
+
+// ValidateFS validates the config files of fsys (see IsConfigFile), in lexical
+// order of their paths, which are used as the source names of diagnostics.
+// Local _extends are not resolved, as they would be read from disk rather
+// than from fsys: DisableLocalExtends is always set. Errors are reported as
+// with ValidateFiles.
+func ValidateFS(ctx context.Context, fsys fs.FS, opts Options) ([]FileResult, error) {
+	schema, err := schemaFor(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	v := &Validator{opts: opts, schema: schema}
+	return v.ValidateFS(ctx, fsys)
+}
+
+// ValidateFS validates the config files of fsys, like the ValidateFS function
+func (v *Validator) ValidateFS(ctx context.Context, fsys fs.FS) ([]FileResult, error) {
+	var paths []string
+	err := fs.WalkDir(fsys, ".", func(name string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !entry.IsDir() && IsConfigFile(name) {
+			paths = append(paths, name)
+		}
+		return checkContext(ctx)
+	})
+	if CodeOf(err) == CodeCanceled {
+		return nil, err
+	}
+	if err != nil {
+		return nil, &Error{Code: CodeInput, Message: "failed to list config files", Err: err}
+	}
+
+	opts := v.opts
+	opts.DisableLocalExtends = true
+	read := func(name string) ([]byte, error) { return fs.ReadFile(fsys, name) }
+	return validateAll(ctx, paths, read, opts, v.schema)
+}
+
+// OpenArchive reads a gzip-compressed tar archive, such as a bundle of configs
+// exported from several repositories, and returns its config files (see
+// IsConfigFile) as an fs.FS to pass to ValidateFS. Other entries are skipped.
+// Entries are named after their cleaned path in the archive, e.g.
+// "acme/api/.github/runs-on.yml".
+func OpenArchive(r io.Reader) (fs.FS, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, &Error{Code: CodeInput, Message: "failed to read archive", Err: err}
+	}
+	defer gz.Close()
+
+	files := fstest.MapFS{}
+	archive := tar.NewReader(gz)
+	for {
+		header, err := archive.Next()
+		if errors.Is(err, io.EOF) {
+			return files, nil
+		}
+		if err != nil {
+			return nil, &Error{Code: CodeInput, Message: "failed to read archive", Err: err}
+		}
+		if header.Typeflag != tar.TypeReg || !IsConfigFile(header.Name) {
+			continue
+		}
+		name := path.Clean(header.Name)
+		if !fs.ValidPath(name) {
+			return nil, &Error{Code: CodeInput, Message: fmt.Sprintf("archive entry %q escapes the archive", header.Name)}
+		}
+		if header.Size > maxArchiveFileSize {
+			return nil, &Error{Code: CodeInput, Message: fmt.Sprintf("archive entry %q is larger than %d bytes", name, maxArchiveFileSize)}
+		}
+		data, err := io.ReadAll(io.LimitReader(archive, maxArchiveFileSize))
+		if err != nil {
+			return nil, &Error{Code: CodeInput, Message: "failed to read archive", Err: err}
+		}
+		files[name] = &fstest.MapFile{Data: data, Mode: 0o644, ModTime: header.ModTime}
+	}
+}
+
+// ValidateArchive validates the config files of a gzip-compressed tar archive
+// (see OpenArchive and ValidateFS)
+func ValidateArchive(ctx context.Context, r io.Reader, opts Options) ([]FileResult, error) {
+	fsys, err := OpenArchive(r)
+	if err != nil {
+		return nil, err
+	}
+	return ValidateFS(ctx, fsys, opts)
+}
@@ -0,0 +1,93 @@
+package validate
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"runtime"
+	"runtime/debug"
+	"sync"
+
+	appversion "github.com/runs-on/config/internal/version"
+)
+
+// modulePath is the path of the module this package belongs to
+const modulePath = "github.com/runs-on/config"
+
+// Build identifies the validator that produced a result, so that audits can
+// tell which code and schema revision a verdict comes from
+type Build struct {
+	// Version is the version of the config module, e.g. v2.12.0, or "dev"
+	Version string `json:"version"`
+	// Revision and Time are the git commit the binary was built from and its
+	// time, when known
+	Revision string `json:"revision,omitempty"`
+	Time     string `json:"time,omitempty"`
+	// Modified is set when the binary was built from a modified work tree
+	Modified  bool   `json:"modified,omitempty"`
+	GoVersion string `json:"go_version"`
+	// SchemaDigest is the digest of the embedded schema (see SchemaDigest)
+	SchemaDigest string `json:"schema_digest"`
+}
+
+var (
+	buildOnce sync.Once
+	buildInfo Build
+)
+
+// BuildInfo returns the build of this package. Values set with ldflags in
+// github.com/runs-on/config/internal/version take precedence over the build
+// information recorded by the Go toolchain. When the package is used as a
+// library, the version is that of the config module dependency.
+func BuildInfo() Build {
+	buildOnce.Do(func() {
+		b := Build{
+			Version:      appversion.Version,
+			Revision:     appversion.Commit,
+			Time:         appversion.Date,
+			GoVersion:    runtime.Version(),
+			SchemaDigest: SchemaDigest(CUESchema()),
+		}
+		info, ok := debug.ReadBuildInfo()
+		if !ok {
+			buildInfo = b
+			return
+		}
+		if b.Version == "" || b.Version == "dev" {
+			module := &info.Main
+			for _, dep := range info.Deps {
+				if dep.Path == modulePath {
+					module = dep
+				}
+			}
+			if module.Path == modulePath && module.Version != "" && module.Version != "(devel)" {
+				b.Version = module.Version
+			}
+		}
+		// VCS settings describe the main module only
+		if info.Main.Path == modulePath {
+			for _, setting := range info.Settings {
+				switch setting.Key {
+				case "vcs.revision":
+					if b.Revision == "" {
+						b.Revision = setting.Value
+					}
+				case "vcs.time":
+					if b.Time == "" {
+						b.Time = setting.Value
+					}
+				case "vcs.modified":
+					b.Modified = setting.Value == "true"
+				}
+			}
+		}
+		buildInfo = b
+	})
+	return buildInfo
+}
+
+// SchemaDigest returns the digest of a schema's source, "sha256:" followed by
+// the hex SHA-256 of its bytes
+func SchemaDigest(schema []byte) string {
+	sum := sha256.Sum256(schema)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,169 @@
+package validate
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/runs-on/config/pkg/catalog"
+	"github.com/runs-on/config/pkg/config"
+	"gopkg.in/yaml.v3"
+)
+
+// checkBurstableCapacity warns about runners that may use burstable instance
+// families while running on spot instances or backing pools that keep hot
+// instances, where CPU credits make performance unpredictable
+func checkBurstableCapacity(yamlData any, root *yaml.Node, sourceName string) []Diagnostic {
+	var warnings []Diagnostic
+
+	data, ok := yamlData.(map[string]any)
+	if !ok {
+		return warnings
+	}
+	hotPools := hotPoolsByRunner(data)
+
+	for _, runner := range runnerEntries(data, root) {
+		var burstable []string
+		for _, value := range familyValues(runner.spec["family"]) {
+			names, _ := catalog.Expand(value)
+			for _, name := range names {
+				if family, ok := catalog.Lookup(name); ok && family.Burstable() && !slices.Contains(burstable, name) {
+					burstable = append(burstable, name)
+				}
+			}
+		}
+		if len(burstable) == 0 {
+			continue
+		}
+
+		spot, _ := runner.spec["spot"].(string)
+		onSpot := spot != "" && config.Spot(spot).Canonical() != "false"
+		pools := hotPools[runner.name]
+		var key string
+		switch {
+		case onSpot && len(pools) > 0:
+			key = RuleBurstableCapacity + ".spot-hot"
+		case onSpot:
+			key = RuleBurstableCapacity
+		case len(pools) > 0:
+			key = RuleBurstableCapacity + ".hot"
+		default:
+			continue
+		}
+
+		line, column := position(mappingKey(runner.node, "family"))
+		warnings = append(warnings, Diagnostic{
+			Path:     sourceName,
+			Line:     line,
+			Column:   column,
+			text:     message(key, "runner", runner.name, "families", strings.Join(burstable, ", "), "pools", quoted(pools)),
+			Severity: SeverityWarning,
+			RuleID:   RuleBurstableCapacity,
+		})
+	}
+
+	return warnings
+}
+
+// DefaultMaxHot is the number of hot instances above which a schedule entry
+// is reported as implausible, unless Options.MaxHot says otherwise
+const DefaultMaxHot = 50
+
+const (
+	// hotStoppedRatio is the ratio of hot to stopped instances above which
+	// an entry keeping at least minRatioHot hot instances is reported
+	hotStoppedRatio = 10
+	minRatioHot     = 10
+)
+
+// checkPoolCapacity warns about pool schedule counts that look like typos
+// costing money: entries keeping more than maxHot hot instances (no limit if
+// negative), entries keeping far more hot instances than stopped ones, and
+// pools whose schedule entries all keep no instances
+func checkPoolCapacity(root *yaml.Node, sourceName string, maxHot int) []Diagnostic {
+	var warnings []Diagnostic
+
+	report := func(n *yaml.Node, text messageText) {
+		line, column := position(n)
+		warnings = append(warnings, Diagnostic{
+			Path:     sourceName,
+			Line:     line,
+			Column:   column,
+			text:     text,
+			Severity: SeverityWarning,
+			RuleID:   RulePoolCapacity,
+		})
+	}
+
+	pools := resolveAlias(mappingValue(root, "pools"))
+	if pools == nil || pools.Kind != yaml.MappingNode {
+		return warnings
+	}
+	for i := 0; i+1 < len(pools.Content); i += 2 {
+		poolName := pools.Content[i].Value
+		schedule := fieldNode(pools.Content[i+1], "schedule")
+		if schedule == nil || schedule.Kind != yaml.SequenceNode || len(schedule.Content) == 0 {
+			continue
+		}
+
+		empty := true
+		for index, item := range schedule.Content {
+			item = resolveAlias(item)
+			hot, hotErr := strconv.Atoi(fieldValue(item, "hot"))
+			stopped, stoppedErr := strconv.Atoi(fieldValue(item, "stopped"))
+			if hotErr != nil || stoppedErr != nil {
+				// Missing and malformed counts are schema errors
+				empty = false
+				continue
+			}
+			if hot > 0 || stopped > 0 {
+				empty = false
+			}
+			label := scheduleLabel(poolName, index, fieldValue(item, "name"))
+			switch {
+			case maxHot >= 0 && hot > maxHot:
+				report(fieldNode(item, "hot"), message(RulePoolCapacity, "schedule", label,
+					"hot", strconv.Itoa(hot), "max", strconv.Itoa(maxHot)))
+			case hot >= minRatioHot && hot > hotStoppedRatio*stopped:
+				report(fieldNode(item, "hot"), message(RulePoolCapacity+".ratio", "schedule", label,
+					"hot", strconv.Itoa(hot), "stopped", strconv.Itoa(stopped)))
+			}
+		}
+		if empty {
+			report(pools.Content[i], message(RulePoolCapacity+".empty", "pool", poolName))
+		}
+	}
+
+	return warnings
+}
+
+// hotPoolsByRunner returns the names of the pools keeping hot instances,
+// sorted, by the name of their runner
+func hotPoolsByRunner(data map[string]any) map[string][]string {
+	result := make(map[string][]string)
+	pools, _ := data["pools"].(map[string]any)
+	for _, name := range sortedKeys(pools) {
+		pool, _ := pools[name].(map[string]any)
+		runner, _ := pool["runner"].(string)
+		schedule, _ := pool["schedule"].([]any)
+		if runner == "" || !slices.ContainsFunc(schedule, func(entry any) bool {
+			entryMap, _ := entry.(map[string]any)
+			hot, _ := strconv.Atoi(fmt.Sprint(entryMap["hot"]))
+			return hot > 0
+		}) {
+			continue
+		}
+		result[runner] = append(result[runner], name)
+	}
+	return result
+}
+
+// quoted returns names quoted and separated by commas, e.g. "'a', 'b'"
+func quoted(names []string) string {
+	quoted := make([]string, len(names))
+	for i, name := range names {
+		quoted[i] = "'" + name + "'"
+	}
+	return strings.Join(quoted, ", ")
+}
@@ -0,0 +1,36 @@
+package validate
+
+import "context"
+
+// withContext runs fn and returns its result, or a CodeCanceled error as soon
+// as ctx is done. CUE evaluation cannot be interrupted, so fn then keeps
+// running in the background until it returns.
+func withContext[T any](ctx context.Context, fn func() T) (T, error) {
+	var zero T
+	if ctx.Done() == nil {
+		return fn(), nil
+	}
+	if err := ctx.Err(); err != nil {
+		return zero, canceled(err)
+	}
+	done := make(chan T, 1)
+	go func() { done <- fn() }()
+	select {
+	case result := <-done:
+		return result, nil
+	case <-ctx.Done():
+		return zero, canceled(ctx.Err())
+	}
+}
+
+// checkContext returns a CodeCanceled error if ctx is done
+func checkContext(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return canceled(err)
+	}
+	return nil
+}
+
+func canceled(err error) error {
+	return &Error{Code: CodeCanceled, Message: "validation canceled", Err: err}
+}
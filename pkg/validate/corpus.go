@@ -0,0 +1,53 @@
+package validate
+
+import (
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"io/fs"
+)
+
+// corpusFS is a copy of schema/testdata, synced by make sync-schema
+//
+//go:embed corpus
+var corpusFS embed.FS
+
+// TestCorpus returns the configs this package is tested with, so that other
+// implementations, such as the RunsOn server, can check that they reach the
+// same verdicts. The configs in valid/ have no errors, and those in invalid/
+// at least one. Files in subdirectories, such as valid/shared, are not cases
+// themselves but are extended by cases with a local _extends: copy the corpus
+// to a directory (e.g. with os.CopyFS) to validate those.
+func TestCorpus() fs.FS {
+	corpus, err := fs.Sub(corpusFS, "corpus")
+	if err != nil {
+		// The corpus is embedded at build time, so this cannot happen
+		panic(err)
+	}
+	return corpus
+}
+
+// TestCorpusDigest returns the digest of TestCorpus, "sha256:" followed by
+// the hex SHA-256 of its file paths and contents in path order, so that
+// implementations can record which revision of the corpus they passed
+func TestCorpusDigest() string {
+	hash := sha256.New()
+	err := fs.WalkDir(corpusFS, "corpus", func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+		data, err := corpusFS.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		hash.Write([]byte(path))
+		hash.Write([]byte{0})
+		hash.Write(data)
+		hash.Write([]byte{0})
+		return nil
+	})
+	if err != nil {
+		panic(err)
+	}
+	return "sha256:" + hex.EncodeToString(hash.Sum(nil))
+}
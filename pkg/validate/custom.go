@@ -0,0 +1,109 @@
+package validate
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Rule is a check run alongside the built-in ones, such as an organization's
+// naming conventions or mandatory tags. Register it with RegisterRule.
+type Rule interface {
+	// ID identifies the rule in diagnostics and Options.Rules, e.g.
+	// "acme-runner-name". It must not be the ID of a built-in rule.
+	ID() string
+	// Severity is the severity of the diagnostics that do not set one
+	Severity() Severity
+	// Check returns the diagnostics of a config. Path and RuleID are set by
+	// the validator, as well as FieldPath, end positions and offsets from
+	// Line and Column.
+	Check(ctx context.Context, config *ConfigNode) []Diagnostic
+}
+
+// ConfigNode is the config a Rule checks
+type ConfigNode struct {
+	// SourceName is the name of the config, e.g. its path
+	SourceName string
+	// Root is the top-level mapping of the YAML document, with the
+	// positions to report diagnostics at, or nil if the document is not a
+	// mapping
+	Root *yaml.Node
+	// Data is the decoded config, with anchors expanded: maps, lists and
+	// scalars as decoded by yaml.v3
+	Data any
+}
+
+// Lookup returns the node at a dotted field path such as "runners.gpu.tags"
+// or "pools.main.schedule.0", with aliases resolved, or nil if there is none
+func (c *ConfigNode) Lookup(fieldPath string) *yaml.Node {
+	node := c.Root
+	for _, segment := range strings.Split(fieldPath, ".") {
+		if _, node = childNode(node, segment); node == nil {
+			return nil
+		}
+	}
+	return resolveAlias(node)
+}
+
+var (
+	customRulesMu sync.RWMutex
+	customRules   []Rule
+)
+
+// RegisterRule adds a rule to the checks of every validation, e.g. from the
+// init function of the package defining it. Its diagnostics are reported
+// after those of the built-in rules, and it can be turned off by ID like
+// them. LookupRule and Rules describe it with its ID and severity.
+// RegisterRule panics if the ID is empty or already taken, or the severity
+// is unknown.
+func RegisterRule(rule Rule) {
+	id := rule.ID()
+	if id == "" {
+		panic("validate: RegisterRule called with an empty rule ID")
+	}
+	if severity := rule.Severity(); severity != SeverityError && severity != SeverityWarning {
+		panic(fmt.Sprintf("validate: rule %s has unknown severity %q", id, severity))
+	}
+	customRulesMu.Lock()
+	defer customRulesMu.Unlock()
+	if _, builtin := ruleRegistry[id]; builtin || slices.ContainsFunc(customRules, func(r Rule) bool { return r.ID() == id }) {
+		panic(fmt.Sprintf("validate: RegisterRule called twice for rule %s", id))
+	}
+	customRules = append(customRules, rule)
+}
+
+// registeredRules returns the registered rules in registration order
+func registeredRules() []Rule {
+	customRulesMu.RLock()
+	defer customRulesMu.RUnlock()
+	return slices.Clone(customRules)
+}
+
+// checkCustomRules runs the registered rules that opts does not turn off,
+// tracing each as a step
+func checkCustomRules(ctx context.Context, config *ConfigNode, opts Options, trace *tracer) ([]Diagnostic, error) {
+	var diags []Diagnostic
+	for _, rule := range registeredRules() {
+		if enabled, ok := opts.Rules[rule.ID()]; ok && !enabled {
+			continue
+		}
+		if err := checkContext(ctx); err != nil {
+			return nil, err
+		}
+		ruleDiags := rule.Check(ctx, config)
+		for i := range ruleDiags {
+			ruleDiags[i].Path = config.SourceName
+			ruleDiags[i].RuleID = rule.ID()
+			if ruleDiags[i].Severity == "" {
+				ruleDiags[i].Severity = rule.Severity()
+			}
+		}
+		trace.step(ctx, rule.ID(), len(ruleDiags))
+		diags = append(diags, ruleDiags...)
+	}
+	return diags, nil
+}
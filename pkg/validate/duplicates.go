@@ -0,0 +1,42 @@
+package validate
+
+import (
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+)
+
+// checkDuplicateKeys reports keys defined more than once in the same mapping,
+// at any level. Decoding fails on them, so each is reported at its position
+// with the line of the first definition rather than as a YAML syntax error.
+func checkDuplicateKeys(doc *yaml.Node, sourceName string) []Diagnostic {
+	var errors []Diagnostic
+
+	walkNodes(doc, func(n *yaml.Node) {
+		if n.Kind != yaml.MappingNode {
+			return
+		}
+		seen := make(map[string]*yaml.Node)
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			key := n.Content[i]
+			if key.Kind != yaml.ScalarNode {
+				continue
+			}
+			first, ok := seen[key.Value]
+			if !ok {
+				seen[key.Value] = key
+				continue
+			}
+			errors = append(errors, Diagnostic{
+				Path:     sourceName,
+				Line:     key.Line,
+				Column:   key.Column,
+				text:     message(RuleDuplicateKey, "key", key.Value, "line", strconv.Itoa(first.Line)),
+				Severity: SeverityError,
+				RuleID:   RuleDuplicateKey,
+			})
+		}
+	})
+
+	return errors
+}
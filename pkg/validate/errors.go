@@ -0,0 +1,58 @@
+package validate
+
+import "errors"
+
+// ErrorCode classifies the errors that stop validation, as opposed to the
+// diagnostics reported for a config
+type ErrorCode string
+
+const (
+	// CodeInput means the config could not be read, e.g. its file does not
+	// exist
+	CodeInput ErrorCode = "input"
+	// CodeOption means an option is invalid, e.g. a scope path that is not in
+	// the config or an unknown shutdown day
+	CodeOption ErrorCode = "option"
+	// CodeSchema means a schema given by the caller cannot be read or
+	// compiled
+	CodeSchema ErrorCode = "schema"
+	// CodeEmbeddedSchema means the schema embedded at build time cannot be
+	// compiled: the binary is broken, whatever the input
+	CodeEmbeddedSchema ErrorCode = "embedded-schema"
+	// CodeCanceled means the context of the validation was canceled or its
+	// deadline exceeded. The context's error is wrapped.
+	CodeCanceled ErrorCode = "canceled"
+)
+
+// Error is an error that stopped validation. The error that caused it, such
+// as an fs.ErrNotExist, can be matched with errors.Is and errors.As.
+type Error struct {
+	Code ErrorCode
+	// Message describes the failure, e.g. "failed to open file"
+	Message string
+	Err     error
+}
+
+func (e *Error) Error() string {
+	switch {
+	case e.Err == nil:
+		return e.Message
+	case e.Message == "":
+		return e.Err.Error()
+	}
+	return e.Message + ": " + e.Err.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// CodeOf returns the code of the first *Error in the chain of err, or "" if
+// there is none
+func CodeOf(err error) ErrorCode {
+	var validateErr *Error
+	if errors.As(err, &validateErr) {
+		return validateErr.Code
+	}
+	return ""
+}
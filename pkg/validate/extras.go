@@ -0,0 +1,171 @@
+package validate
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/runs-on/config/pkg/config"
+	"gopkg.in/yaml.v3"
+)
+
+// extraRequirement declares the runner settings a value of 'extras' depends
+// on. Extras without an entry are not checked.
+type extraRequirement struct {
+	// platforms lists the image platforms the extra works on; empty means any
+	platforms []string
+	// redundantVolumeGB is the local volume size above which the extra makes
+	// the volume likely redundant; 0 disables the check
+	redundantVolumeGB int
+	// redundantMessage is the key of the message explaining why a large
+	// volume is likely redundant
+	redundantMessage string
+}
+
+var extraRequirements = map[string]extraRequirement{
+	"efs": {
+		platforms:         []string{"linux"},
+		redundantVolumeGB: 100,
+		redundantMessage:  RuleExtrasRequirement + ".efs-volume",
+	},
+	"tmpfs": {
+		platforms: []string{"linux"},
+	},
+}
+
+// knownExtras are the values of 'extras' RunsOn supports
+var knownExtras = []string{"ecr-cache", "efs", "s3-cache", "tmpfs"}
+
+// checkUnknownExtras warns about runner extras RunsOn does not support,
+// suggesting the extra a misspelled one most likely refers to. Extras given
+// as a plus-separated string are reported at the offending item.
+func checkUnknownExtras(yamlData any, root *yaml.Node, sourceName string) []Diagnostic {
+	var warnings []Diagnostic
+
+	data, ok := yamlData.(map[string]any)
+	if !ok {
+		return warnings
+	}
+
+	for _, runner := range runnerEntries(data, root) {
+		if config.Strings(runner.spec["extras"]) == nil {
+			continue
+		}
+		var scalars []*yaml.Node
+		if runner.node != nil {
+			if n := fieldNode(runner.node, "extras"); n != nil && n.Kind == yaml.SequenceNode {
+				scalars = n.Content
+			} else if n != nil {
+				scalars = []*yaml.Node{n}
+			}
+		}
+		for _, n := range scalars {
+			if n.Kind != yaml.ScalarNode {
+				continue
+			}
+			offset := 0
+			for _, part := range strings.Split(n.Value, "+") {
+				start := offset + len(part) - len(strings.TrimLeft(part, " "))
+				offset += len(part) + 1
+				extra := strings.TrimSpace(part)
+				if extra == "" || slices.Contains(knownExtras, extra) {
+					continue
+				}
+				text := message(RuleExtrasUnknown, "runner", runner.name, "extra", extra, "extras", strings.Join(knownExtras, ", "))
+				if name := closestName(strings.ToLower(extra), knownExtras); name != "" {
+					text = message(RuleExtrasUnknown+".suggest", "runner", runner.name, "extra", extra, "suggestion", name)
+				}
+				diag := Diagnostic{
+					Path:      sourceName,
+					text:      text,
+					Severity:  SeverityWarning,
+					RuleID:    RuleExtrasUnknown,
+					FieldPath: runner.path + ".extras",
+				}
+				diag.Line, diag.Column = position(n)
+				if column := scalarColumn(n); column > 0 {
+					diag.Column = column + start
+					diag.EndLine = diag.Line
+					diag.EndColumn = diag.Column + len(extra)
+				}
+				warnings = append(warnings, diag)
+			}
+		}
+	}
+
+	return warnings
+}
+
+// checkExtras warns about runner extras whose requirements are not met by the
+// rest of the runner spec
+func checkExtras(yamlData any, root *yaml.Node, sourceName string) []Diagnostic {
+	var warnings []Diagnostic
+
+	data, ok := yamlData.(map[string]any)
+	if !ok {
+		return warnings
+	}
+	images, _ := data["images"].(map[string]any)
+
+	for _, runner := range runnerEntries(data, root) {
+		line, column := position(mappingKey(runner.node, "extras"))
+		warn := func(text messageText) {
+			warnings = append(warnings, Diagnostic{
+				Path:     sourceName,
+				Line:     line,
+				Column:   column,
+				text:     text,
+				Severity: SeverityWarning,
+				RuleID:   RuleExtrasRequirement,
+			})
+		}
+
+		platform := imagePlatform(runner.spec["image"], images)
+		volumeGB, hasVolume := volumeSize(runner.spec["volume"])
+
+		for _, extra := range config.Strings(runner.spec["extras"]) {
+			requirement, ok := extraRequirements[extra]
+			if !ok {
+				continue
+			}
+			if len(requirement.platforms) > 0 && platform != "" && !slices.Contains(requirement.platforms, platform) {
+				warn(message(RuleExtrasRequirement, "runner", runner.name, "extra", extra,
+					"platforms", strings.Join(requirement.platforms, "/"), "platform", platform))
+			}
+			if requirement.redundantVolumeGB > 0 && hasVolume && volumeGB > requirement.redundantVolumeGB {
+				warn(message(requirement.redundantMessage, "runner", runner.name, "extra", extra, "size", strconv.Itoa(volumeGB)))
+			}
+		}
+	}
+
+	return warnings
+}
+
+// imagePlatform returns the platform of a runner image: the platform of the
+// image spec when the image is defined in the config, or the platform
+// implied by the name of a RunsOn-provided image (e.g. windows22-full-x64).
+// It returns "" when the platform is unknown.
+func imagePlatform(image any, images map[string]any) string {
+	ref, ok := image.(string)
+	if !ok || ref == "" {
+		return ""
+	}
+	if spec, ok := images[ref].(map[string]any); ok {
+		platform, _ := spec["platform"].(string)
+		return strings.ToLower(platform)
+	}
+	if strings.HasPrefix(ref, "windows") {
+		return "windows"
+	}
+	return "linux"
+}
+
+// volumeSize returns the size in GB of a volume specification such as
+// "80gb:gp3:125mbs:3000iops"
+func volumeSize(value any) (int, bool) {
+	spec, ok := value.(string)
+	if !ok {
+		return 0, false
+	}
+	return config.VolumeSizeGB(spec)
+}
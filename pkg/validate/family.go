@@ -0,0 +1,67 @@
+package validate
+
+import (
+	"strings"
+
+	"github.com/runs-on/config/pkg/catalog"
+	"gopkg.in/yaml.v3"
+)
+
+// checkFamilyPatterns reports runner family wildcards that are malformed or
+// match no known instance family. Plain family names are not checked, as the
+// catalog may lag behind new EC2 families.
+func checkFamilyPatterns(yamlData any, root *yaml.Node, sourceName string) []Diagnostic {
+	var errors []Diagnostic
+
+	data, ok := yamlData.(map[string]any)
+	if !ok {
+		return errors
+	}
+
+	for _, runner := range runnerEntries(data, root) {
+		line, column := position(mappingKey(runner.node, "family"))
+
+		for _, pattern := range familyValues(runner.spec["family"]) {
+			if !catalog.IsPattern(pattern) {
+				continue
+			}
+			var text messageText
+			matches, err := catalog.Expand(pattern)
+			switch {
+			case err != nil:
+				text = message(RuleFamilyNoMatch+".invalid", "runner", runner.name, "error", err.Error())
+			case len(matches) == 0:
+				text = message(RuleFamilyNoMatch, "runner", runner.name, "pattern", pattern)
+			default:
+				continue
+			}
+			errors = append(errors, Diagnostic{
+				Path:     sourceName,
+				Line:     line,
+				Column:   column,
+				text:     text,
+				Severity: SeverityError,
+				RuleID:   RuleFamilyNoMatch,
+			})
+		}
+	}
+
+	return errors
+}
+
+// familyValues returns the entries of a family field in string or list form
+func familyValues(value any) []string {
+	switch v := value.(type) {
+	case string:
+		return strings.Split(v, "+")
+	case []any:
+		var values []string
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values
+	}
+	return nil
+}
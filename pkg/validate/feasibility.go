@@ -0,0 +1,111 @@
+package validate
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/runs-on/config/pkg/catalog"
+	"github.com/runs-on/config/pkg/config"
+	"gopkg.in/yaml.v3"
+)
+
+// checkFeasibility reports runners whose cpu or ram is out of reach of every
+// instance type of their families, naming the constraint that eliminates
+// them. Runners without family, and runners with families whose sizes the
+// catalog does not know, are not checked.
+func checkFeasibility(yamlData any, root *yaml.Node, sourceName string) []Diagnostic {
+	var warnings []Diagnostic
+
+	data, ok := yamlData.(map[string]any)
+	if !ok {
+		return warnings
+	}
+
+	for _, runner := range runnerEntries(data, root) {
+		families := familyValues(runner.spec["family"])
+		cpu, cpuOK := optionalNumbers(runner.spec["cpu"])
+		ram, ramOK := optionalNumbers(runner.spec["ram"])
+		if len(families) == 0 || !cpuOK || !ramOK || (len(cpu) == 0 && len(ram) == 0) {
+			continue
+		}
+		largest, ok := largestSize(families)
+		if !ok {
+			continue
+		}
+
+		warn := func(field string, text messageText) {
+			line, column := position(mappingKey(runner.node, field))
+			if line == 0 {
+				line, column = position(runner.key)
+			}
+			warnings = append(warnings, Diagnostic{
+				Path:     sourceName,
+				Line:     line,
+				Column:   column,
+				text:     text,
+				Severity: SeverityWarning,
+				RuleID:   RuleNoMatchingInstance,
+			})
+		}
+		family := strings.Join(families, ", ")
+		if len(cpu) > 0 && slices.Min(cpu) > largest.VCPU {
+			warn("cpu", message(RuleNoMatchingInstance+".cpu", "runner", runner.name, "family", family,
+				"max", formatNumber(largest.VCPU), "cpu", formatNumber(slices.Min(cpu))))
+		} else if len(ram) > 0 && slices.Min(ram) > largest.MemoryGB {
+			warn("ram", message(RuleNoMatchingInstance+".ram", "runner", runner.name, "family", family,
+				"max", formatNumber(largest.MemoryGB), "ram", formatNumber(slices.Min(ram))))
+		}
+	}
+
+	return warnings
+}
+
+// largestSize returns the largest vCPU count and memory of the instance
+// types matching runner family values: family names, prefixes, patterns and
+// instance types. It fails if a value matches no family, or a family whose
+// sizes are unknown.
+func largestSize(values []string) (catalog.Size, bool) {
+	var largest catalog.Size
+	grow := func(size catalog.Size) {
+		largest.VCPU = max(largest.VCPU, size.VCPU)
+		largest.MemoryGB = max(largest.MemoryGB, size.MemoryGB)
+	}
+	for _, value := range values {
+		if strings.Contains(value, ".") {
+			size, ok := catalog.InstanceSize(value)
+			if !ok {
+				return catalog.Size{}, false
+			}
+			grow(size)
+			continue
+		}
+		names, err := catalog.Expand(value)
+		if err != nil || len(names) == 0 {
+			return catalog.Size{}, false
+		}
+		for _, name := range names {
+			family, _ := catalog.Lookup(name)
+			size, ok := family.MaxSize()
+			if !ok {
+				return catalog.Size{}, false
+			}
+			grow(size)
+		}
+	}
+	return largest, true
+}
+
+// optionalNumbers returns the values of a number field that may be unset,
+// and whether they are valid (see config.Numbers)
+func optionalNumbers(value any) ([]float64, bool) {
+	if value == nil {
+		return nil, true
+	}
+	numbers, err := config.Numbers(value)
+	return numbers, err == nil
+}
+
+func formatNumber(n float64) string {
+	return strconv.FormatFloat(n, 'f', -1, 64)
+}
@@ -0,0 +1,130 @@
+package validate
+
+import (
+	"slices"
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+)
+
+// fieldVisitor is called for a field of an entry in a top-level section, e.g.
+// the 'spot' field of a runner. It may rewrite the value node in place, before
+// the document is decoded for schema validation.
+type fieldVisitor func(key, value *yaml.Node, sourceName string) []Diagnostic
+
+// fieldVisitors registers field-level checks and normalizations by section
+// and field name
+var fieldVisitors = []struct {
+	section string
+	field   string
+	visit   fieldVisitor
+}{
+	{"runners", "disk", deprecated(RuleDeprecatedDisk, message(RuleDeprecatedDisk))},
+	{"pools", "environment", deprecated(RuleDeprecatedEnvironment, message(RuleDeprecatedEnvironment))},
+	// Fields the schema expects as strings but that are often written as
+	// other scalars
+	{"runners", "spot", coerce("!!str", "!!bool")},
+	{"images", "owner", coerce("!!str", "!!int", "!!float")},
+	// Fields accepting both booleans and the strings "true" and "false"
+	{"runners", "ssh", coerce("!!bool", "!!str")},
+	{"runners", "nested-virt", coerce("!!bool", "!!str")},
+	{"runners", "private", coerce("!!bool", "!!str")},
+	{"runners", "debug", coerce("!!bool", "!!str")},
+}
+
+// visitFields walks the entries of each top-level section of doc in a single
+// pass and calls the registered visitors for their fields, including fields
+// merged in from anchors with '<<'. A field shared through an anchor is
+// visited once per section.
+func visitFields(doc *yaml.Node, sourceName string) []Diagnostic {
+	var diags []Diagnostic
+
+	root := rootMapping(doc)
+	if root == nil {
+		return diags
+	}
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		section := root.Content[i].Value
+		entries := resolveAlias(root.Content[i+1])
+		if entries.Kind != yaml.MappingNode {
+			continue
+		}
+		seen := make(map[*yaml.Node]bool)
+		for j := 0; j+1 < len(entries.Content); j += 2 {
+			eachField(entries.Content[j+1], seen, func(key, value *yaml.Node) {
+				for _, visitor := range fieldVisitors {
+					if visitor.section == section && visitor.field == key.Value {
+						diags = append(diags, visitor.visit(key, value, sourceName)...)
+					}
+				}
+			})
+		}
+	}
+
+	return diags
+}
+
+// eachField calls fn for each field of a mapping node and of the mappings
+// merged into it. Mappings in seen are skipped.
+func eachField(n *yaml.Node, seen map[*yaml.Node]bool, fn func(key, value *yaml.Node)) {
+	n = resolveAlias(n)
+	if n.Kind != yaml.MappingNode || seen[n] {
+		return
+	}
+	seen[n] = true
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		key, value := n.Content[i], n.Content[i+1]
+		if key.ShortTag() != "!!merge" {
+			fn(key, value)
+			continue
+		}
+		if value = resolveAlias(value); value.Kind == yaml.SequenceNode {
+			for _, item := range value.Content {
+				eachField(item, seen, fn)
+			}
+		} else {
+			eachField(value, seen, fn)
+		}
+	}
+}
+
+// deprecated returns a visitor warning that a field is deprecated
+func deprecated(ruleID string, text messageText) fieldVisitor {
+	return func(key, _ *yaml.Node, sourceName string) []Diagnostic {
+		return []Diagnostic{{
+			Path:     sourceName,
+			Line:     key.Line,
+			Column:   key.Column,
+			text:     text,
+			Severity: SeverityWarning,
+			RuleID:   ruleID,
+		}}
+	}
+}
+
+// coerce returns a visitor retagging scalar values of one of the types from
+// as type to, so that equivalent spellings are validated and decoded alike.
+// For example, spot: false becomes spot: "false", an unquoted account ID in
+// owner becomes a string, and ssh: "true" becomes ssh: true. Only the
+// strings "true" and "false" become booleans, and numbers keep their
+// spelling, leading zeros included.
+func coerce(to string, from ...string) fieldVisitor {
+	return func(_, value *yaml.Node, _ string) []Diagnostic {
+		value = resolveAlias(value)
+		if value.Kind != yaml.ScalarNode || !slices.Contains(from, value.ShortTag()) {
+			return nil
+		}
+		switch {
+		case value.ShortTag() == "!!bool":
+			var b bool
+			if value.Decode(&b) != nil {
+				return nil
+			}
+			value.Value = strconv.FormatBool(b)
+		case to == "!!bool" && value.Value != "true" && value.Value != "false":
+			return nil
+		}
+		value.Tag = to
+		return nil
+	}
+}
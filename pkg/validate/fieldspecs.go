@@ -0,0 +1,120 @@
+package validate
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/cuecontext"
+)
+
+// sectionDefinitions maps config sections to the schema definitions of their
+// entries
+var sectionDefinitions = map[string]string{
+	"runners": "#RunnerSpec",
+	"images":  "#ImageSpec",
+	"pools":   "#PoolSpec",
+}
+
+// definitionFields returns the field names of the definitions of runner,
+// image, pool and schedule entries in a compiled schema, by definition
+func definitionFields(schema cue.Value) map[string][]string {
+	fields := make(map[string][]string)
+	for _, definition := range []string{"#RunnerSpec", "#ImageSpec", "#PoolSpec", "#PoolSchedule", "#ScheduleMatch"} {
+		iter, err := schema.LookupPath(cue.ParsePath(definition)).Fields(cue.Optional(true))
+		if err != nil {
+			continue
+		}
+		for iter.Next() {
+			fields[definition] = append(fields[definition], iter.Selector().Unquoted())
+		}
+	}
+	return fields
+}
+
+// FieldSpec describes a field of a runner, image or pool in the schema
+type FieldSpec struct {
+	Name string
+	// Doc is the schema comment of the field
+	Doc string
+	// Enum lists the accepted values when the field only accepts a fixed set
+	// of them, e.g. the spot strategies
+	Enum []string
+	// Required is set for fields that must be present
+	Required bool
+}
+
+// FieldSpecs returns the fields of the entries of a section ("runners",
+// "images" or "pools") in the embedded schema, in schema order
+func FieldSpecs(section string) ([]FieldSpec, error) {
+	definition, ok := sectionDefinitions[section]
+	if !ok {
+		return nil, &Error{Code: CodeOption, Message: fmt.Sprintf("unknown section %q", section)}
+	}
+	schema := cuecontext.New().CompileBytes(CUESchema())
+	if err := schema.Err(); err != nil {
+		return nil, &Error{Code: CodeEmbeddedSchema, Message: "failed to compile schema", Err: err}
+	}
+	spec := schema.LookupPath(cue.ParsePath(definition))
+	if !spec.Exists() {
+		return nil, &Error{Code: CodeEmbeddedSchema, Message: "schema does not define " + definition}
+	}
+
+	iter, err := spec.Fields(cue.Optional(true))
+	if err != nil {
+		return nil, err
+	}
+	var specs []FieldSpec
+	for iter.Next() {
+		field := FieldSpec{
+			Name:     iter.Selector().Unquoted(),
+			Required: !iter.IsOptional(),
+			Enum:     enumValues(iter.Value()),
+		}
+		var doc []string
+		for _, group := range iter.Value().Doc() {
+			doc = append(doc, strings.TrimSpace(group.Text()))
+		}
+		field.Doc = strings.Join(doc, "\n")
+		specs = append(specs, field)
+	}
+	return specs, nil
+}
+
+// enumValues returns the values of a disjunction of concrete strings and
+// booleans, such as #SpotValue or #BoolOrString, or nil if the value accepts
+// other values too
+func enumValues(v cue.Value) []string {
+	op, args := cue.Dereference(v).Expr()
+	if op != cue.OrOp {
+		return nil
+	}
+	var values []string
+	add := func(value string) {
+		if !slices.Contains(values, value) {
+			values = append(values, value)
+		}
+	}
+	for _, arg := range args {
+		switch arg.IncompleteKind() {
+		case cue.StringKind:
+			s, err := arg.String()
+			if err != nil {
+				// A non-concrete string accepts any value
+				return nil
+			}
+			add(s)
+		case cue.BoolKind:
+			if b, err := arg.Bool(); err == nil {
+				add(fmt.Sprint(b))
+			} else {
+				add("true")
+				add("false")
+			}
+		default:
+			return nil
+		}
+	}
+	return values
+}
@@ -0,0 +1,100 @@
+package validate
+
+import (
+	"context"
+	"os"
+)
+
+// FileResult is the result of validating one of several files
+type FileResult struct {
+	Path        string
+	Diagnostics []Diagnostic
+	// Err is set when the file could not be validated, e.g. it does not
+	// exist
+	Err error
+}
+
+// DiagnosticsByPath returns the diagnostics of results by file path, e.g. to
+// look up those of a file validated by ValidateFS. Files that could not be
+// validated are left out: their Err is only in results.
+func DiagnosticsByPath(results []FileResult) map[string][]Diagnostic {
+	byPath := make(map[string][]Diagnostic, len(results))
+	for _, result := range results {
+		if result.Err == nil {
+			byPath[result.Path] = result.Diagnostics
+		}
+	}
+	return byPath
+}
+
+// ValidateFiles validates files in order with the same options, compiling the
+// schema only once. A file that cannot be validated gets an Err and the next
+// files are still validated. When ctx is done, it returns the results so far
+// with a CodeCanceled error. Set Options.OnFileDone to get each result as
+// soon as it is available.
+func ValidateFiles(ctx context.Context, paths []string, opts Options) ([]FileResult, error) {
+	schema, err := schemaFor(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	v := &Validator{opts: opts, schema: schema}
+	return v.ValidateFiles(ctx, paths)
+}
+
+// ValidateFiles validates files in order, like the ValidateFiles function
+func (v *Validator) ValidateFiles(ctx context.Context, paths []string) ([]FileResult, error) {
+	return validateAll(ctx, paths, os.ReadFile, v.opts, v.schema)
+}
+
+// validateAll validates the files at paths, read with read, in order
+func validateAll(ctx context.Context, paths []string, read func(string) ([]byte, error), opts Options, schema *compiledSchema) ([]FileResult, error) {
+	results := make([]FileResult, 0, len(paths))
+	for _, path := range paths {
+		if err := checkContext(ctx); err != nil {
+			return results, err
+		}
+		diags, err := validateFile(ctx, path, read, opts, schema)
+		if CodeOf(err) == CodeCanceled {
+			return results, err
+		}
+		results = append(results, FileResult{Path: path, Diagnostics: diags, Err: err})
+	}
+	return results, nil
+}
+
+// validateFile validates the file at path, read with read, against schema, or
+// against the schema for opts if nil, between the OnFileStart and OnFileDone
+// callbacks
+func validateFile(ctx context.Context, path string, read func(string) ([]byte, error), opts Options, schema *compiledSchema) ([]Diagnostic, error) {
+	if opts.OnFileStart != nil {
+		opts.OnFileStart(path)
+	}
+	diags, err := func() ([]Diagnostic, error) {
+		data, err := read(path)
+		if err != nil {
+			return nil, &Error{Code: CodeInput, Message: "failed to open file", Err: err}
+		}
+		if schema == nil {
+			if schema, err = schemaFor(ctx, opts); err != nil {
+				return nil, err
+			}
+		}
+		diags, _, err := validateBytes(ctx, data, path, opts, schema)
+		return diags, err
+	}()
+	if opts.OnFileDone != nil {
+		opts.OnFileDone(path, diags, err)
+	}
+	return diags, err
+}
+
+// notifyDiagnostics passes the diagnostics of a config to the OnDiagnostic
+// callback of opts and returns them
+func notifyDiagnostics(opts Options, diags []Diagnostic) []Diagnostic {
+	if opts.OnDiagnostic != nil {
+		for _, diag := range diags {
+			opts.OnDiagnostic(diag)
+		}
+	}
+	return diags
+}
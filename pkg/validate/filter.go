@@ -0,0 +1,96 @@
+package validate
+
+import (
+	"path"
+	"slices"
+	"strings"
+)
+
+// Predicate reports whether a diagnostic is kept by Filter
+type Predicate func(Diagnostic) bool
+
+// Filter returns the diagnostics all predicates keep, in order. diags is not
+// modified.
+func Filter(diags []Diagnostic, predicates ...Predicate) []Diagnostic {
+	var kept []Diagnostic
+	for _, diag := range diags {
+		if !slices.ContainsFunc(predicates, func(keep Predicate) bool { return !keep(diag) }) {
+			kept = append(kept, diag)
+		}
+	}
+	return kept
+}
+
+// Not keeps the diagnostics p drops
+func Not(p Predicate) Predicate {
+	return func(diag Diagnostic) bool { return !p(diag) }
+}
+
+// ByRule keeps diagnostics reported by one of the rules (or advisories) ids
+func ByRule(ids ...string) Predicate {
+	return func(diag Diagnostic) bool { return slices.Contains(ids, diag.RuleID) }
+}
+
+// BySeverity keeps diagnostics with one of severities
+func BySeverity(severities ...Severity) Predicate {
+	return func(diag Diagnostic) bool { return slices.Contains(severities, diag.Severity) }
+}
+
+// ByPathPrefix keeps diagnostics of the files whose path starts with prefix,
+// e.g. "services/" or ".github/runs-on.yml"
+func ByPathPrefix(prefix string) Predicate {
+	return func(diag Diagnostic) bool { return strings.HasPrefix(diag.Path, prefix) }
+}
+
+// ByFieldPath keeps diagnostics about the config field at a dotted path or
+// inside it: "runners.gpu" keeps "runners.gpu" and "runners.gpu.cpu", but not
+// "runners.gpu-large"
+func ByFieldPath(prefix string) Predicate {
+	return func(diag Diagnostic) bool { return withinFieldPath(diag.FieldPath, prefix) }
+}
+
+// InLines keeps diagnostics starting between lines start and end, inclusive
+func InLines(start, end int) Predicate {
+	return func(diag Diagnostic) bool { return diag.Line >= start && diag.Line <= end }
+}
+
+// Suppression is an entry of a suppression list, matching the diagnostics
+// that satisfy all of its fields that are set
+type Suppression struct {
+	// Rule is the rule or advisory ID, e.g. "public-ssh"
+	Rule string `json:"rule,omitempty" yaml:"rule,omitempty"`
+	// Path is the file path, or a glob pattern as in path.Match, e.g.
+	// "services/*/runs-on.yml"
+	Path string `json:"path,omitempty" yaml:"path,omitempty"`
+	// Field is a dotted config path; diagnostics inside it match too
+	Field string `json:"field,omitempty" yaml:"field,omitempty"`
+	// Reason records why the diagnostic is accepted. It is not matched.
+	Reason string `json:"reason,omitempty" yaml:"reason,omitempty"`
+}
+
+// Matches reports whether s suppresses diag
+func (s Suppression) Matches(diag Diagnostic) bool {
+	if s.Rule != "" && s.Rule != diag.RuleID {
+		return false
+	}
+	if s.Path != "" && s.Path != diag.Path {
+		if ok, err := path.Match(s.Path, diag.Path); err != nil || !ok {
+			return false
+		}
+	}
+	return s.Field == "" || withinFieldPath(diag.FieldPath, s.Field)
+}
+
+// Suppress keeps the diagnostics that no entry of list matches
+func Suppress(list []Suppression) Predicate {
+	return func(diag Diagnostic) bool {
+		return !slices.ContainsFunc(list, func(s Suppression) bool { return s.Matches(diag) })
+	}
+}
+
+// withinFieldPath reports whether a dotted field path is prefix or one of
+// its descendants
+func withinFieldPath(fieldPath, prefix string) bool {
+	rest, ok := strings.CutPrefix(fieldPath, prefix)
+	return ok && (rest == "" || strings.HasPrefix(rest, "."))
+}
@@ -0,0 +1,225 @@
+package validate
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// SuggestedFix is a change to the file that resolves a diagnostic
+type SuggestedFix struct {
+	// Description says what the fix does, e.g. "Rename 'environment' to 'env'"
+	Description string
+	Edits       []TextEdit
+}
+
+// TextEdit replaces the bytes of a file from Offset to EndOffset (exclusive)
+// with NewText
+type TextEdit struct {
+	Offset    int
+	EndOffset int
+	NewText   string
+}
+
+// fixTarget is the node a diagnostic points at
+type fixTarget struct {
+	// key is the key of a mapping entry, or nil for a list item
+	key   *yaml.Node
+	value *yaml.Node
+	// parent is the mapping of an entry
+	parent *yaml.Node
+	// field is the key of the entry, or of the list holding the item
+	field string
+}
+
+// suggestFixes sets the fixes of the diagnostics of sourceName that have one:
+// removing or renaming deprecated fields, correcting misspelled fields (given
+// the known top-level ones and the fields of definitions), pool runner
+// references, runner extras and schedule days, and scaffolding pool schedules
+func suggestFixes(doc *yaml.Node, src []byte, sourceName string, known map[string]bool, definitions map[string][]string, diags []Diagnostic) {
+	targets := make(map[[2]int]fixTarget)
+	var walk func(n *yaml.Node, field string)
+	walk = func(n *yaml.Node, field string) {
+		switch n.Kind {
+		case yaml.DocumentNode:
+			for _, c := range n.Content {
+				walk(c, field)
+			}
+		case yaml.MappingNode:
+			for i := 0; i+1 < len(n.Content); i += 2 {
+				key, value := n.Content[i], n.Content[i+1]
+				targets[[2]int{key.Line, key.Column}] = fixTarget{key: key, value: value, parent: n, field: key.Value}
+				if value.Kind == yaml.ScalarNode {
+					targets[[2]int{value.Line, value.Column}] = fixTarget{value: value, parent: n, field: key.Value}
+				}
+				walk(value, key.Value)
+			}
+		case yaml.SequenceNode:
+			for _, item := range n.Content {
+				if item.Kind == yaml.ScalarNode {
+					targets[[2]int{item.Line, item.Column}] = fixTarget{value: item, field: field}
+				}
+				walk(item, field)
+			}
+		}
+	}
+	walk(doc, "")
+	index := newLineIndex(src)
+
+	for i, diag := range diags {
+		target, ok := targets[[2]int{diag.Line, diag.Column}]
+		if diag.Path != sourceName || diag.Fix != nil || !ok {
+			continue
+		}
+		switch {
+		case diag.RuleID == RuleDeprecatedDisk && target.key != nil:
+			diags[i].Fix = removeField(index, target, "Remove the ignored 'disk' field")
+		case diag.RuleID == RuleDeprecatedEnvironment && target.key != nil:
+			if mappingKey(target.parent, "env") == nil {
+				diags[i].Fix = replaceToken(index, target.key, "env", "Rename 'environment' to 'env'")
+			}
+		case (diag.RuleID == RuleUnknownField || diag.RuleID == RuleFieldTypo) && target.key != nil:
+			if name := suggestField(target.parent, target.key.Value, fieldCandidates(diag.FieldPath, known, definitions)); name != "" {
+				diags[i].Fix = replaceToken(index, target.key, name, fmt.Sprintf("Rename '%s' to '%s'", target.key.Value, name))
+			}
+		case diag.RuleID == RulePoolMode && target.key != nil:
+			diags[i].Fix = poolModeFix(index, target.parent)
+		case diag.RuleID == RulePoolNoSchedule && target.key != nil && target.value.Kind == yaml.MappingNode:
+			diags[i].Fix = scheduleFix(index, target.value)
+		case diag.RuleID == RulePoolRunnerUndefined && target.key == nil && target.field == "runner":
+			var runners []string
+			if n := resolveAlias(mappingValue(rootMapping(doc), "runners")); n != nil && n.Kind == yaml.MappingNode {
+				for j := 0; j+1 < len(n.Content); j += 2 {
+					runners = append(runners, n.Content[j].Value)
+				}
+			}
+			if name := closestName(target.value.Value, runners); name != "" {
+				diags[i].Fix = replaceToken(index, target.value, name, fmt.Sprintf("Replace '%s' with '%s'", target.value.Value, name))
+			}
+		case diag.RuleID == RuleExtrasUnknown && target.key == nil && target.field == "extras" && !strings.Contains(target.value.Value, "+"):
+			if name := closestName(strings.ToLower(target.value.Value), knownExtras); name != "" {
+				diags[i].Fix = replaceToken(index, target.value, name, fmt.Sprintf("Replace '%s' with '%s'", target.value.Value, name))
+			}
+		case diag.RuleID == RuleScheduleMatch && target.key == nil && target.field == "day":
+			if day := closestName(strings.ToLower(target.value.Value), weekdays); day != "" {
+				diags[i].Fix = replaceToken(index, target.value, day, fmt.Sprintf("Replace '%s' with '%s'", target.value.Value, day))
+			}
+		}
+	}
+}
+
+// removeField returns a fix deleting the lines of a mapping entry, or nil if
+// the entry does not start its line or is the only field of its mapping
+func removeField(index *lineIndex, target fixTarget, description string) *SuggestedFix {
+	if len(target.parent.Content) < 4 || !startsLine(index, target.key) {
+		return nil
+	}
+	start := index.offset(target.key.Line, 1)
+	end := index.lineEnd(min(lastLine(target.value), len(index.lines)))
+	return &SuggestedFix{Description: description, Edits: []TextEdit{{Offset: start, EndOffset: end}}}
+}
+
+// replaceToken returns a fix replacing a single-line scalar as written, or
+// nil if the token cannot be found
+func replaceToken(index *lineIndex, n *yaml.Node, text, description string) *SuggestedFix {
+	if n.Line > len(index.lines) {
+		return nil
+	}
+	length := tokenLength(index.lines[n.Line-1], n)
+	if length == 0 {
+		return nil
+	}
+	return &SuggestedFix{Description: description, Edits: []TextEdit{{
+		Offset:    index.offset(n.Line, n.Column),
+		EndOffset: index.offset(n.Line, n.Column+length),
+		NewText:   text,
+	}}}
+}
+
+// closestName returns the candidate name is most likely a misspelling of: the
+// only one it is a prefix of, or else the only one closest within two edits.
+// It returns "" if there is none or several.
+func closestName(name string, candidates []string) string {
+	var prefixed []string
+	for _, candidate := range candidates {
+		if len(name) >= 2 && strings.HasPrefix(candidate, name) {
+			prefixed = append(prefixed, candidate)
+		}
+	}
+	if len(prefixed) == 1 {
+		return prefixed[0]
+	}
+
+	best, bestDistance, ties := "", 3, 0
+	for _, candidate := range candidates {
+		switch d := editDistance(name, candidate); {
+		case d < bestDistance:
+			best, bestDistance, ties = candidate, d, 0
+		case d == bestDistance:
+			ties++
+		}
+	}
+	if ties > 0 || bestDistance == 0 {
+		return ""
+	}
+	return best
+}
+
+// editDistance returns the Levenshtein distance between two strings
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	previous := make([]int, len(rb)+1)
+	current := make([]int, len(rb)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		current[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(rb)]
+}
+
+// ApplyFixes applies the fixes of diags to src, the content of the file they
+// were reported for. A fix whose edits overlap those of a fix applied before
+// is skipped. It returns the new content and the number of fixes applied.
+func ApplyFixes(src []byte, diags []Diagnostic) ([]byte, int) {
+	var edits []TextEdit
+	applied := 0
+	for _, diag := range diags {
+		if diag.Fix == nil || slices.ContainsFunc(diag.Fix.Edits, func(edit TextEdit) bool {
+			return edit.Offset < 0 || edit.EndOffset < edit.Offset || edit.EndOffset > len(src) ||
+				slices.ContainsFunc(edits, func(other TextEdit) bool { return overlaps(edit, other) })
+		}) {
+			continue
+		}
+		edits = append(edits, diag.Fix.Edits...)
+		applied++
+	}
+
+	sort.SliceStable(edits, func(i, j int) bool { return edits[i].Offset < edits[j].Offset })
+	var out []byte
+	last := 0
+	for _, edit := range edits {
+		out = append(out, src[last:edit.Offset]...)
+		out = append(out, edit.NewText...)
+		last = edit.EndOffset
+	}
+	return append(out, src[last:]...), applied
+}
+
+// overlaps reports whether two edits touch the same bytes. Insertions at the
+// same offset overlap too, as their order would be ambiguous.
+func overlaps(a, b TextEdit) bool {
+	return a.Offset < b.EndOffset && b.Offset < a.EndOffset || a.Offset == b.Offset
+}
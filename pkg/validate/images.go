@@ -0,0 +1,198 @@
+package validate
+
+import (
+	"regexp"
+
+	"github.com/runs-on/config/pkg/catalog"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	// amiID matches AMI IDs, with 8 hexadecimal characters for older AMIs
+	// and 17 for newer ones
+	amiID = regexp.MustCompile(`^ami-([0-9a-f]{8}|[0-9a-f]{17})$`)
+	// amiPlaceholder matches values left from examples, such as ami-xxxx or
+	// ami-<your-ami>
+	amiPlaceholder = regexp.MustCompile(`(?i)^ami-(x+|\.+|\*+|<.*>|\{.*\}|todo|tbd|changeme|placeholder|replace.*|your.*)$`)
+)
+
+// checkImageReferences reports runners whose image is neither defined in the
+// images section nor a built-in RunsOn image. Runners inherited through
+// _extends are checked in their own file.
+func checkImageReferences(yamlData any, root *yaml.Node, sourceName string) []Diagnostic {
+	var warnings []Diagnostic
+
+	data, ok := yamlData.(map[string]any)
+	if !ok {
+		return warnings
+	}
+	images, _ := data["images"].(map[string]any)
+	candidates := append(catalog.Images(), sortedKeys(images)...)
+
+	for _, runner := range runnerEntries(data, root) {
+		image, ok := runner.spec["image"].(string)
+		if _, defined := images[image]; !ok || defined || catalog.IsImage(image) || runner.node == nil {
+			continue
+		}
+		node := fieldNode(runner.node, "image")
+		if node == nil {
+			continue
+		}
+		text := message(RuleRunnerImageUndefined, "runner", runner.name, "image", image)
+		if name := closestName(image, candidates); name != "" {
+			text = message(RuleRunnerImageUndefined+".suggest", "runner", runner.name, "image", image, "suggestion", name)
+		}
+		warnings = append(warnings, Diagnostic{
+			Path:     sourceName,
+			Line:     node.Line,
+			Column:   node.Column,
+			text:     text,
+			Severity: SeverityWarning,
+			RuleID:   RuleRunnerImageUndefined,
+		})
+	}
+
+	return warnings
+}
+
+// checkUnusedImages reports the images of this file that no runner uses,
+// including runners inherited through _extends. Configs without runners are
+// shared image definitions for other configs to extend, and are not checked.
+func checkUnusedImages(yamlData any, root *yaml.Node, sourceName string) []Diagnostic {
+	var warnings []Diagnostic
+
+	data, ok := yamlData.(map[string]any)
+	if !ok {
+		return warnings
+	}
+	if runners, _ := data["runners"].(map[string]any); len(runners) == 0 {
+		return warnings
+	}
+	images, _ := data["images"].(map[string]any)
+	used := make(map[string]bool)
+	for _, runner := range runnerEntries(data, root) {
+		if image, ok := runner.spec["image"].(string); ok {
+			used[image] = true
+		}
+	}
+
+	imagesNode := resolveAlias(mappingValue(root, "images"))
+	for _, name := range sortedKeys(images) {
+		key := mappingKey(imagesNode, name)
+		if used[name] || key == nil {
+			continue
+		}
+		warnings = append(warnings, Diagnostic{
+			Path:     sourceName,
+			Line:     key.Line,
+			Column:   key.Column,
+			text:     message(RuleUnusedImage, "image", name),
+			Severity: SeverityWarning,
+			RuleID:   RuleUnusedImage,
+		})
+	}
+
+	return warnings
+}
+
+// checkImageAMIs reports images whose ami is not an AMI ID, pointing out
+// placeholders left from examples
+func checkImageAMIs(yamlData any, root *yaml.Node, sourceName string) []Diagnostic {
+	var errors []Diagnostic
+
+	data, ok := yamlData.(map[string]any)
+	if !ok {
+		return errors
+	}
+	images, _ := data["images"].(map[string]any)
+	imagesNode := resolveAlias(mappingValue(root, "images"))
+	for _, name := range sortedKeys(images) {
+		image, _ := images[name].(map[string]any)
+		ami, ok := image["ami"].(string)
+		if !ok || amiID.MatchString(ami) {
+			continue
+		}
+		var node *yaml.Node
+		if imageNode := resolveAlias(mappingValue(imagesNode, name)); imageNode != nil {
+			node = fieldNode(imageNode, "ami")
+		}
+		line, column := position(node)
+		key := RuleImageAMI
+		if amiPlaceholder.MatchString(ami) {
+			key = RuleImageAMI + ".placeholder"
+		}
+		errors = append(errors, Diagnostic{
+			Path:     sourceName,
+			Line:     line,
+			Column:   column,
+			text:     message(key, "image", name, "ami", ami),
+			Severity: SeverityError,
+			RuleID:   RuleImageAMI,
+		})
+	}
+
+	return errors
+}
+
+// checkImageSources reports images that do not tell RunsOn which AMI to
+// launch: images need either an ami or an AMI search by name and owner, and
+// not both. A name without owner matches AMIs published by any account, and
+// an owner without name every AMI of the account. Images that are null or
+// not mappings are left to the schema.
+func checkImageSources(yamlData any, root *yaml.Node, sourceName string) []Diagnostic {
+	var errors []Diagnostic
+
+	data, ok := yamlData.(map[string]any)
+	if !ok {
+		return errors
+	}
+	images, _ := data["images"].(map[string]any)
+	imagesNode := resolveAlias(mappingValue(root, "images"))
+	for _, name := range sortedKeys(images) {
+		image, ok := images[name].(map[string]any)
+		if !ok {
+			continue
+		}
+		imageNode := mappingValue(imagesNode, name)
+		var search []string
+		for _, field := range []string{"name", "owner"} {
+			if _, set := image[field]; set {
+				search = append(search, field)
+			}
+		}
+		_, hasAMI := image["ami"]
+
+		var node *yaml.Node
+		var text messageText
+		switch {
+		case hasAMI && len(search) > 0:
+			node = mergedKey(imageNode, "ami")
+			text = message(RuleImageSource+".both", "image", name, "fields", quoted(search))
+		case hasAMI:
+			continue
+		case len(search) == 0:
+			node = mappingKey(imagesNode, name)
+			text = message(RuleImageSource, "image", name)
+		case len(search) == 1:
+			missing := "owner"
+			if search[0] == "owner" {
+				missing = "name"
+			}
+			node = mergedKey(imageNode, search[0])
+			text = message(RuleImageSource+".incomplete", "image", name, "field", search[0], "missing", missing)
+		default:
+			continue
+		}
+		line, column := position(node)
+		errors = append(errors, Diagnostic{
+			Path:     sourceName,
+			Line:     line,
+			Column:   column,
+			text:     text,
+			Severity: SeverityError,
+			RuleID:   RuleImageSource,
+		})
+	}
+
+	return errors
+}
@@ -0,0 +1,34 @@
+package validate
+
+import (
+	"strings"
+
+	"github.com/runs-on/config/pkg/extends"
+	"gopkg.in/yaml.v3"
+)
+
+// checkMergeConflicts reports runners, images and pools that replace a
+// different definition in an extended config. Replacing entries by name is
+// how _extends works, but in strict mode an entry silently changed by an
+// extending file is reported with both origins. Conflicts between two
+// extended files are reported on the _extends key.
+func checkMergeConflicts(doc *extends.Document, root *yaml.Node, sourceName string) []Diagnostic {
+	var errors []Diagnostic
+	for _, conflict := range doc.Conflicts {
+		line, column := position(mappingKey(root, "_extends"))
+		if conflict.Path == sourceName {
+			if key := mappingKey(resolveAlias(mappingValue(root, conflict.Section)), conflict.Name); key != nil {
+				line, column = position(key)
+			}
+		}
+		errors = append(errors, Diagnostic{
+			Path:     sourceName,
+			Line:     line,
+			Column:   column,
+			text:     message(kindKey(RuleMergeConflict, strings.TrimSuffix(conflict.Section, "s")), "name", conflict.Name, "path", conflict.Path, "base", conflict.BasePath),
+			Severity: SeverityError,
+			RuleID:   RuleMergeConflict,
+		})
+	}
+	return errors
+}
@@ -0,0 +1,275 @@
+package validate
+
+import (
+	"embed"
+	"encoding/json"
+	"maps"
+	"path"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// Catalog maps message keys to message templates. A key is the ID of the
+// rule reporting the message, followed by a variant name for rules with
+// several messages, e.g. "pool-mode.schedule", and by the kind of entry for
+// messages about images or pools, e.g. "tag-limit.image". Templates refer to
+// the parameters of a message by name, e.g. "admin '{admin}' is listed more
+// than once (first at line {line})".
+type Catalog map[string]string
+
+// englishMessages are the messages diagnostics are reported with. Translations
+// use the same keys and parameters.
+var englishMessages = Catalog{
+	RuleYAMLSyntax:                          "YAML parse error: {error}",
+	RuleDuplicateKey:                        "key '{key}' is already defined at line {line}",
+	RuleSchema:                              "{error}",
+	RuleRequiredField:                       "{error}",
+	RuleDeprecatedDisk:                      "field 'disk' is deprecated and ignored; use 'volume' instead (e.g., volume=80gb:gp3:125mbs:3000iops)",
+	RuleDeprecatedEnvironment:               "field 'environment' is deprecated, use 'env' instead",
+	RulePoolRunnerUndefined:                 "pool '{pool}' references runner '{runner}' which is not defined in runners",
+	RulePoolRunnerUndefined + ".no-runners": "pool '{pool}' references runner '{runner}' but no runners are defined",
+	RulePoolRunnerUndefined + ".suggest":    "pool '{pool}' references runner '{runner}' which is not defined in runners; did you mean '{suggestion}'?",
+	RuleRunnerImageUndefined:                "runner '{runner}' uses image '{image}' which is neither defined in images nor a built-in image",
+	RuleRunnerImageUndefined + ".suggest":   "runner '{runner}' uses image '{image}' which is neither defined in images nor a built-in image; did you mean '{suggestion}'?",
+	RuleImageAMI:                            "image '{image}': ami '{ami}' is not an AMI ID: expected 'ami-' followed by 8 or 17 hexadecimal characters",
+	RuleImageAMI + ".placeholder":           "image '{image}': ami '{ami}' is a placeholder: set the ID of the AMI to launch",
+	RuleImageSource:                         "image '{image}' sets neither 'ami' nor 'name' and 'owner': set the AMI ID, or the name and owner to search the AMI by",
+	RuleImageSource + ".both":               "image '{image}' sets both 'ami' and an AMI search ({fields}): use either 'ami' or 'name' and 'owner'",
+	RuleImageSource + ".incomplete":         "image '{image}' searches its AMI by '{field}' without '{missing}': set both 'name' and 'owner'",
+	RuleExtendsLocal:                        "failed to resolve _extends: {error}",
+	RuleMergeConflict:                       "runner '{name}' in {path} replaces a different definition in {base}",
+	RulePublicSSH:                           "runner '{runner}' enables ssh on a public IP address; set 'private: true' or disable ssh",
+	RuleFamilyNoMatch:                       "runner '{runner}' family pattern '{pattern}' matches no known instance family",
+	RuleFamilyNoMatch + ".invalid":          "runner '{runner}' family: {error}",
+	RuleNoMatchingInstance + ".cpu":         "runner '{runner}': {family} instance types have at most {max} vCPUs, fewer than the {cpu} requested",
+	RuleNoMatchingInstance + ".ram":         "runner '{runner}': {family} instance types have at most {max}GB of memory, less than the {ram}GB requested",
+	RuleAdminsDuplicate:                     "admin '{admin}' is listed more than once (first at line {line})",
+	RuleAdminsOrder:                         "admins are not sorted: '{admin}' should come before '{previous}'",
+	RuleAdminsUsername:                      "admin '{admin}' is not a valid GitHub username: use letters, digits and hyphens, at most 39 characters, not starting with a hyphen",
+	RuleAdminsUsername + ".empty":           "admins entry is empty",
+	RuleAdminsUsernameForm + ".email":       "admin '{admin}' looks like an email address; list the GitHub username instead",
+	RuleAdminsUsernameForm + ".handle":      "admin '{admin}' starts with '@'; list the GitHub username '{username}' without it",
+	RuleExtrasUnknown:                       "runner '{runner}': unknown extra '{extra}' (expected one of {extras})",
+	RuleExtrasUnknown + ".suggest":          "runner '{runner}': unknown extra '{extra}'; did you mean '{suggestion}'?",
+	RuleExtrasRequirement:                   "runner '{runner}' enables extra '{extra}', which is only supported on {platforms} images, but its image is {platform}",
+	RuleExtrasRequirement + ".efs-volume":   "runner '{runner}' enables extra '{extra}' and requests a {size}GB volume, which is likely redundant: efs mounts a shared, elastic filesystem for persistent data",
+	RuleTagFormat:                           "runner '{runner}': tag '{tag}' must have the form Key:Value",
+	RuleTagFormat + ".reserved":             "runner '{runner}': tag key '{key}' starts with 'aws:', which is reserved by AWS",
+	RuleTagFormat + ".characters":           "runner '{runner}': tag '{key}' contains '{character}', which AWS does not allow in tags (allowed: letters, digits, spaces and _ . : / = + - @)",
+	RuleTagLimit:                            "runner '{runner}': tag key '{key}' is {length} characters long, more than the {max} AWS allows",
+	RuleTagLimit + ".value":                 "runner '{runner}': the value of tag '{key}' is {length} characters long, more than the {max} AWS allows",
+	RuleTagLimit + ".count":                 "runner '{runner}' sets {count} tags, more than the {max} that fit within the AWS limit of 50 tags per resource",
+	RuleVolumeSpec:                          "runner '{runner}': invalid volume component '{component}': expected a size (80gb), a volume type (gp3), a throughput (125mbs) or iops (3000iops)",
+	RuleVolumeSpec + ".type":                "runner '{runner}': unknown volume type '{type}' (expected one of {types})",
+	RuleVolumeSpec + ".duplicate":           "runner '{runner}': volume component '{component}' sets a value an earlier component already sets",
+	RuleVolumeSpec + ".size":                "runner '{runner}': {type} volumes must be between {min} and {max}GB, got {size}GB",
+	RuleVolumeSpec + ".iops":                "runner '{runner}': {type} volumes support between {min} and {max} iops, got {iops}",
+	RuleVolumeSpec + ".iops-size":           "runner '{runner}': {iops} iops exceed the {ratio} iops per GB {type} volumes allow, at most {max} for {size}GB",
+	RuleVolumeSpec + ".throughput":          "runner '{runner}': {type} volumes support a throughput between {min} and {max}mbs, got {throughput}mbs",
+	RuleVolumeSpec + ".throughput-iops":     "runner '{runner}': a throughput of {throughput}mbs needs more iops than {iops}: {type} volumes allow at most {max}mbs with {iops} iops",
+	RuleVolumeSpec + ".unsupported":         "runner '{runner}': the iops and throughput of {type} volumes cannot be set, remove '{component}'",
+	RulePreinstallShell + ".quote":          "runner '{runner}': preinstall script has a {quote} quote that is never closed",
+	RulePreinstallShell + ".substitution":   "runner '{runner}': preinstall script has a '{token}' that is never closed",
+	RulePreinstallShell + ".heredoc":        "runner '{runner}': preinstall script has a here-document that no '{delimiter}' line ends",
+	RulePreinstallShell + ".unclosed":       "runner '{runner}': preinstall script opens '{keyword}' without closing it with '{closer}'",
+	RulePreinstallShell + ".unexpected":     "runner '{runner}': preinstall script has '{keyword}' without a matching '{opener}'",
+	RulePreinstallShell + ".mismatch":       "runner '{runner}': preinstall script closes the '{opener}' of line {line} with '{keyword}' instead of '{closer}'",
+	RulePreinstallShellcheck:                "runner '{runner}': preinstall script {code}: {error}",
+	RuleUnusedAnchor:                        "anchor '&{anchor}' is never referenced by an alias",
+	RuleUnusedExtension:                     "'{field}' is never referenced by an alias",
+	RuleUnusedImage:                         "image '{image}' is not used by any runner",
+	RuleUnusedRunner:                        "runner '{runner}' is not used by any pool; list it as a label runner if jobs select it with runner=",
+	RuleUnknownField:                        "unknown top-level field '{field}'",
+	RuleUnknownField + ".suggest":           "unknown top-level field '{field}'; did you mean '{suggestion}'?",
+	RuleUnknownField + ".nested":            "unknown field '{field}' in {owner}",
+	RuleUnknownField + ".nested-suggest":    "unknown field '{field}' in {owner}; did you mean '{suggestion}'?",
+	RuleFieldTypo:                           "top-level field '{field}' is not defined by the schema and is ignored; did you mean '{suggestion}'?",
+	RuleNameFormat:                          "runner name '{name}' contains '{character}': use only letters, digits, '-', '_' and '.'",
+	RuleNameFormat + ".pool":                "pool name '{name}' contains '{character}': use only lowercase letters, digits, '-' and '_'",
+	RuleNameFormat + ".start":               "runner name '{name}' must start with a letter or a digit",
+	RuleNameFormat + ".length":              "runner name '{name}' is {length} characters long, more than the {max} allowed",
+	RuleNameFormat + ".empty":               "runner name must not be empty",
+	RulePoolMode:                            "pool '{pool}' sets {fields} on the pool, but pools are sized by their schedule entries only: move the counts into a schedule entry as 'hot' and 'stopped' (an entry without 'match' applies at all times)",
+	RulePoolMode + ".schedule":              "pool '{pool}' sets {fields} on the pool and has a schedule: pools are sized by their schedule entries only, so remove {fields} or move the counts into a schedule entry",
+	RulePoolCapacity:                        "{schedule} keeps {hot} hot instances, more than the {max} expected: hot instances are billed while idle, so check the count is not a typo",
+	RulePoolCapacity + ".ratio":             "{schedule} keeps {hot} hot instances but only {stopped} stopped: stopped instances start quickly and only cost their volumes, so consider keeping some of the hot instances stopped",
+	RulePoolCapacity + ".empty":             "pool '{pool}' keeps no hot or stopped instances in any schedule entry, so it does not speed up any job",
+	RulePoolNoSchedule:                      "pool '{pool}' has no schedule entry, so it keeps no instances: add a schedule entry with 'hot' and 'stopped' counts",
+	RuleBurstableCapacity:                   "runner '{runner}' may use burstable families ({families}): spot instances are replaced often and start with few CPU credits; prefer non-burstable families such as m7a or c7a",
+	RuleBurstableCapacity + ".hot":          "runner '{runner}' may use burstable families ({families}): hot instances of its pools ({pools}) spend CPU credits while idle and throttle or incur unlimited-mode charges under sustained CI load; prefer non-burstable families such as m7a or c7a",
+	RuleBurstableCapacity + ".spot-hot":     "runner '{runner}' may use burstable families ({families}): spot instances are replaced often and start with few CPU credits, and hot instances of its pools ({pools}) spend CPU credits while idle and throttle or incur unlimited-mode charges under sustained CI load; prefer non-burstable families such as m7a or c7a",
+	RuleScheduleMatch:                       "{schedule} matches unknown day '{day}'; use one of {days}",
+	RuleScheduleMatch + ".range":            "{schedule} must match a [start, end] time range, got {count} times",
+	RuleScheduleMatch + ".time":             "{schedule} matches invalid time '{time}'; use HH:MM, e.g. 08:30",
+	RuleScheduleConsistency:                 "{schedule} lists day '{day}' more than once",
+	RuleScheduleConsistency + ".whole-day":  "{schedule} matches a time range starting and ending at {time}, which spans the whole day; remove 'time' to match all day",
+	RuleScheduleConsistency + ".match":      "{schedule} has 'match' without days or times, so it only applies when no other entry matches; remove 'match' to make it the default entry",
+	RuleScheduleConsistency + ".shadowed":   "{schedule} has no match criteria and never applies: {fallback} already applies whenever no other entry matches",
+	RuleScheduleDuplicateName:               "{schedule} has the same name as {first}; later entries shadow earlier ones with the same name, so give each entry a unique name",
+	RuleShutdownHot:                         "schedule '{name}' of pool '{pool}' keeps {hot} hot instances on {day}, a shutdown day",
+	RuleTimezone:                            "pool '{pool}' has unknown timezone '{timezone}': use a zone of the IANA tz database, e.g. America/New_York or UTC",
+	RuleTimezoneLegacy:                      "pool '{pool}' uses the legacy timezone alias '{timezone}': use '{current}' instead",
+	RuleSchemaVersion:                       "unknown schema version '{version}' (available: {available})",
+	RuleSchemaVersion + ".type":             "'{field}' must be a schema version name such as '{version}'",
+	RuleSchemaVersion + ".both":             "'{field}' is ignored as the config also sets '{declared}': keep only one",
+	RuleSchemaVersionMissing:                "config does not declare its schema version: add 'schema-version: {version}' so that it is always validated against the same schema",
+	RuleSchemaVersionField:                  "'{field}' was added in schema version {added}, but the config declares {declared}: declare {added} or later, or remove the field",
+
+	// Image and pool variants of messages about runners, see kindKey
+	RuleMergeConflict + ".image":                "image '{name}' in {path} replaces a different definition in {base}",
+	RuleMergeConflict + ".pool":                 "pool '{name}' in {path} replaces a different definition in {base}",
+	RuleTagFormat + ".reserved.image":           "image '{image}': tag key '{key}' starts with 'aws:', which is reserved by AWS",
+	RuleTagFormat + ".characters.image":         "image '{image}': tag '{key}' contains '{character}', which AWS does not allow in tags (allowed: letters, digits, spaces and _ . : / = + - @)",
+	RuleTagLimit + ".image":                     "image '{image}': tag key '{key}' is {length} characters long, more than the {max} AWS allows",
+	RuleTagLimit + ".value.image":               "image '{image}': the value of tag '{key}' is {length} characters long, more than the {max} AWS allows",
+	RuleTagLimit + ".count.image":               "image '{image}' sets {count} tags, more than the {max} that fit within the AWS limit of 50 tags per resource",
+	RulePreinstallShell + ".quote.image":        "image '{image}': preinstall script has a {quote} quote that is never closed",
+	RulePreinstallShell + ".substitution.image": "image '{image}': preinstall script has a '{token}' that is never closed",
+	RulePreinstallShell + ".heredoc.image":      "image '{image}': preinstall script has a here-document that no '{delimiter}' line ends",
+	RulePreinstallShell + ".unclosed.image":     "image '{image}': preinstall script opens '{keyword}' without closing it with '{closer}'",
+	RulePreinstallShell + ".unexpected.image":   "image '{image}': preinstall script has '{keyword}' without a matching '{opener}'",
+	RulePreinstallShell + ".mismatch.image":     "image '{image}': preinstall script closes the '{opener}' of line {line} with '{keyword}' instead of '{closer}'",
+	RulePreinstallShellcheck + ".image":         "image '{image}': preinstall script {code}: {error}",
+	RuleNameFormat + ".image":                   "image name '{name}' contains '{character}': use only letters, digits, '-', '_' and '.'",
+	RuleNameFormat + ".start.image":             "image name '{name}' must start with a letter or a digit",
+	RuleNameFormat + ".start.pool":              "pool name '{name}' must start with a letter or a digit",
+	RuleNameFormat + ".length.image":            "image name '{name}' is {length} characters long, more than the {max} allowed",
+	RuleNameFormat + ".length.pool":             "pool name '{name}' is {length} characters long, more than the {max} allowed",
+	RuleNameFormat + ".empty.image":             "image name must not be empty",
+	RuleNameFormat + ".empty.pool":              "pool name must not be empty",
+}
+
+// messageText is a catalog message: its key and its parameters, given as name
+// and value pairs joined with NUL so that diagnostics stay comparable
+type messageText struct {
+	key    string
+	params string
+}
+
+// message returns the message of key with params, given as name and value
+// pairs
+func message(key string, params ...string) messageText {
+	return messageText{key: key, params: strings.Join(params, "\x00")}
+}
+
+// kindKey returns the variant of key, the key of a message about a runner,
+// for an entry of kind: "runner", "image" or "pool"
+func kindKey(key, kind string) string {
+	if kind == "runner" {
+		return key
+	}
+	return key + "." + kind
+}
+
+// String renders the English message
+func (t messageText) String() string {
+	return t.render(englishMessages[t.key])
+}
+
+// render renders template with the parameters of t
+func (t messageText) render(template string) string {
+	var params []string
+	if t.params != "" {
+		params = strings.Split(t.params, "\x00")
+	}
+	return render(template, params)
+}
+
+// setMessages sets the English message of diags reported with a catalog
+// message
+func setMessages(diags []Diagnostic) {
+	for i := range diags {
+		if diags[i].text.key != "" {
+			diags[i].Message = diags[i].text.String()
+		}
+	}
+}
+
+func render(template string, params []string) string {
+	pairs := make([]string, 0, len(params))
+	for i := 0; i+1 < len(params); i += 2 {
+		pairs = append(pairs, "{"+params[i]+"}", params[i+1])
+	}
+	return strings.NewReplacer(pairs...).Replace(template)
+}
+
+// Messages returns the English message catalog, the reference for
+// translations
+func Messages() Catalog {
+	return maps.Clone(englishMessages)
+}
+
+//go:embed locales/*.json
+var localesFS embed.FS
+
+// Locales returns the locales with a built-in catalog, e.g. "fr"
+func Locales() []string {
+	entries, _ := localesFS.ReadDir("locales")
+	var locales []string
+	for _, entry := range entries {
+		locales = append(locales, strings.TrimSuffix(entry.Name(), ".json"))
+	}
+	return locales
+}
+
+// CatalogFor returns the built-in catalog of a locale such as "fr" or
+// "fr-CA" (falling back to "fr"), or false if there is none
+func CatalogFor(locale string) (Catalog, bool) {
+	catalog, ok := builtinCatalog(locale)
+	return maps.Clone(catalog), ok
+}
+
+var builtinCatalogs sync.Map // locale -> Catalog
+
+func builtinCatalog(locale string) (Catalog, bool) {
+	locale = strings.ToLower(strings.ReplaceAll(locale, "_", "-"))
+	for {
+		if catalog, ok := builtinCatalogs.Load(locale); ok {
+			return catalog.(Catalog), true
+		}
+		if data, err := localesFS.ReadFile(path.Join("locales", locale+".json")); err == nil {
+			var catalog Catalog
+			if err := json.Unmarshal(data, &catalog); err != nil {
+				return nil, false
+			}
+			builtinCatalogs.Store(locale, catalog)
+			return catalog, true
+		}
+		i := strings.LastIndex(locale, "-")
+		if i < 0 {
+			return nil, false
+		}
+		locale = locale[:i]
+	}
+}
+
+// localize sets the English messages of diags and translates them with the
+// catalog of opts, see Localize
+func localize(opts Options, diags []Diagnostic) []Diagnostic {
+	setMessages(diags)
+	catalog := opts.Catalog
+	if catalog == nil && opts.Locale != "" {
+		catalog, _ = builtinCatalog(opts.Locale)
+	}
+	return Localize(diags, catalog)
+}
+
+// Localize returns diags with the messages c translates, rendered with the
+// parameters the validator reported them with. Messages without a
+// translation are kept in English, as are schema errors, whose text comes
+// from CUE, and diagnostics that did not come from the validator (e.g.
+// decoded from JSON).
+// Rule IDs and positions are unchanged.
+func Localize(diags []Diagnostic, c Catalog) []Diagnostic {
+	if len(c) == 0 || len(diags) == 0 {
+		return diags
+	}
+	localized := slices.Clone(diags)
+	for i, diag := range localized {
+		if translation, ok := c[diag.text.key]; ok && diag.text.key != "" {
+			localized[i].Message = diag.text.render(translation)
+		}
+	}
+	return localized
+}
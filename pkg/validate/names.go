@@ -0,0 +1,99 @@
+package validate
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+)
+
+// maxNameLength is the longest runner, image or pool name accepted
+const maxNameLength = 64
+
+// nameKinds are the sections whose entry names are checked, with the kind of
+// entry they define
+var nameKinds = []struct{ section, kind string }{
+	{"runners", "runner"},
+	{"images", "image"},
+	{"pools", "pool"},
+}
+
+// checkNames reports runner, image and pool names that cannot be used in job
+// labels (runner=, image=, pool=), whose parts are separated by '/' and ','
+// and written as key=value, nor in the names and tags of the AWS resources
+// RunsOn creates for them: names must be letters, digits, '-', '_' and '.',
+// starting with a letter or a digit, and at most maxNameLength long. Pool
+// names are limited to lowercase letters, digits, '-' and '_' by the schema.
+func checkNames(root *yaml.Node, sourceName string) []Diagnostic {
+	var errors []Diagnostic
+
+	for _, section := range nameKinds {
+		entries := resolveAlias(mappingValue(root, section.section))
+		if entries == nil || entries.Kind != yaml.MappingNode {
+			continue
+		}
+		for i := 0; i+1 < len(entries.Content); i += 2 {
+			key := entries.Content[i]
+			if key.Value == "<<" {
+				continue
+			}
+			text, ok := nameError(section.kind, key.Value)
+			if !ok {
+				continue
+			}
+			errors = append(errors, Diagnostic{
+				Path:     sourceName,
+				Line:     key.Line,
+				Column:   key.Column,
+				text:     text,
+				Severity: SeverityError,
+				RuleID:   RuleNameFormat,
+			})
+		}
+	}
+
+	return errors
+}
+
+// nameError returns the message reporting why a name of an entry of kind is
+// invalid, or false if it is valid
+func nameError(kind, name string) (messageText, bool) {
+	if name == "" {
+		return message(kindKey(RuleNameFormat+".empty", kind)), true
+	}
+	for _, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '-', r == '_':
+		case kind != "pool" && (r >= 'A' && r <= 'Z' || r == '.'):
+		default:
+			return message(kindKey(RuleNameFormat, kind), "name", name, "character", string(r)), true
+		}
+	}
+	if first, _ := utf8.DecodeRuneInString(name); strings.ContainsRune("-_.", first) {
+		return message(kindKey(RuleNameFormat+".start", kind), "name", name), true
+	}
+	if length := utf8.RuneCountInString(name); length > maxNameLength {
+		return message(kindKey(RuleNameFormat+".length", kind), "name", name,
+			"length", strconv.Itoa(length), "max", strconv.Itoa(maxNameLength)), true
+	}
+	return messageText{}, false
+}
+
+// dropPoolNameErrors removes the schema errors for pool names the schema
+// does not allow, which checkNames reports with the reason
+func dropPoolNameErrors(root *yaml.Node, schemaErrors []Diagnostic) []Diagnostic {
+	pools := resolveAlias(mappingValue(root, "pools"))
+	if pools == nil || pools.Kind != yaml.MappingNode {
+		return schemaErrors
+	}
+	return slices.DeleteFunc(schemaErrors, func(diag Diagnostic) bool {
+		name, ok := strings.CutPrefix(diag.FieldPath, "pools.")
+		if !ok || diag.RuleID != RuleSchema || mappingKey(pools, name) == nil {
+			return false
+		}
+		_, invalid := nameError("pool", name)
+		return invalid
+	})
+}
@@ -0,0 +1,252 @@
+package validate
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+)
+
+// rootMapping returns the top-level mapping of a parsed document, or nil if
+// the document is not a mapping
+func rootMapping(doc *yaml.Node) *yaml.Node {
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return nil
+	}
+	return doc.Content[0]
+}
+
+// mappingKey returns the key node for key in a mapping node, or nil
+func mappingKey(n *yaml.Node, key string) *yaml.Node {
+	if n == nil || n.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if n.Content[i].Value == key {
+			return n.Content[i]
+		}
+	}
+	return nil
+}
+
+// mappingValue returns the value node for key in a mapping node, or nil
+func mappingValue(n *yaml.Node, key string) *yaml.Node {
+	if n == nil || n.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if n.Content[i].Value == key {
+			return n.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// position returns the line and column of a node, or zeros for nil
+func position(n *yaml.Node) (int, int) {
+	if n == nil {
+		return 0, 0
+	}
+	return n.Line, n.Column
+}
+
+// nodeAtPath returns the node at a dotted field path of root, following
+// aliases and fields merged in with '<<', or nil
+func nodeAtPath(root *yaml.Node, fieldPath string) *yaml.Node {
+	n := root
+	for _, segment := range strings.Split(fieldPath, ".") {
+		if n == nil {
+			return nil
+		}
+		_, value := childNode(n, segment)
+		if value == nil && resolveAlias(n).Kind == yaml.MappingNode {
+			value = fieldNode(n, segment)
+		}
+		n = value
+	}
+	return resolveAlias(n)
+}
+
+// setSchemaPositions sets the position of schema diagnostics to the node at
+// their field path, or to 0:0 if the config has no such node
+func setSchemaPositions(root *yaml.Node, diags []Diagnostic) {
+	for i, diag := range diags {
+		diags[i].Line, diags[i].Column = position(nodeAtPath(root, diag.FieldPath))
+	}
+}
+
+// resolveAlias returns the node an alias refers to, or n itself
+func resolveAlias(n *yaml.Node) *yaml.Node {
+	for n != nil && n.Kind == yaml.AliasNode && n.Alias != nil {
+		n = n.Alias
+	}
+	return n
+}
+
+// setEndPositions sets the end of the diagnostics of sourceName that point at
+// a node of doc: the end of the token for single-line scalars, including
+// keys, and the last line of other nodes
+func setEndPositions(doc *yaml.Node, src []byte, sourceName string, diags []Diagnostic) {
+	nodes := make(map[[2]int]*yaml.Node)
+	walkNodes(doc, func(n *yaml.Node) {
+		// A block mapping starts at its first key: prefer the key
+		position := [2]int{n.Line, n.Column}
+		if nodes[position] == nil || n.Kind == yaml.ScalarNode {
+			nodes[position] = n
+		}
+	})
+	lines := strings.Split(string(src), "\n")
+
+	for i, diag := range diags {
+		n := nodes[[2]int{diag.Line, diag.Column}]
+		if diag.Path != sourceName || diag.Line == 0 || n == nil || diag.EndLine != 0 {
+			continue
+		}
+		diags[i].EndLine = lastLine(n)
+		if n.Kind == yaml.ScalarNode && diags[i].EndLine == n.Line && n.Line <= len(lines) {
+			if length := tokenLength(lines[n.Line-1], n); length > 0 {
+				diags[i].EndColumn = n.Column + length
+			}
+		}
+	}
+}
+
+// setOffsets sets the byte offsets of the diagnostics of sourceName that have
+// a position. Without an end column, the range ends with the end line, or
+// with the start line if the end is unknown.
+func setOffsets(src []byte, sourceName string, diags []Diagnostic) {
+	index := newLineIndex(src)
+	for i, diag := range diags {
+		if diag.Path != sourceName || diag.Line < 1 || diag.Line > len(index.lines) {
+			continue
+		}
+		diags[i].Offset = index.offset(diag.Line, max(diag.Column, 1))
+		endLine, endColumn := diag.EndLine, diag.EndColumn
+		if endLine < diag.Line || endLine > len(index.lines) {
+			endLine, endColumn = diag.Line, 0
+		}
+		diags[i].EndOffset = max(index.offset(endLine, endColumn), diags[i].Offset)
+	}
+}
+
+// lineIndex converts the line and column positions of a source to byte
+// offsets
+type lineIndex struct {
+	// lines are the lines of the source with their line breaks, and starts
+	// their offsets
+	lines  []string
+	starts []int
+}
+
+func newLineIndex(src []byte) *lineIndex {
+	lines := strings.SplitAfter(string(src), "\n")
+	starts := make([]int, len(lines))
+	for i := 1; i < len(lines); i++ {
+		starts[i] = starts[i-1] + len(lines[i-1])
+	}
+	return &lineIndex{lines: lines, starts: starts}
+}
+
+// offset returns the byte offset of a 1-based line and column, where column 0
+// means the end of the line (before its line break). Line must exist.
+func (x *lineIndex) offset(line, column int) int {
+	text := strings.TrimRight(x.lines[line-1], "\r\n")
+	if column == 0 {
+		return x.starts[line-1] + len(text)
+	}
+	byteColumn := 0
+	for n := 1; n < column && byteColumn < len(text); n++ {
+		_, size := utf8.DecodeRuneInString(text[byteColumn:])
+		byteColumn += size
+	}
+	return x.starts[line-1] + byteColumn
+}
+
+// lineEnd returns the offset just past the line break of a line, or the end
+// of the source for the last line
+func (x *lineIndex) lineEnd(line int) int {
+	return x.starts[line-1] + len(x.lines[line-1])
+}
+
+// setFieldPaths sets the field path of the diagnostics of sourceName that
+// point at a key or value of doc and have none yet
+func setFieldPaths(doc *yaml.Node, sourceName string, diags []Diagnostic) {
+	paths := make(map[[2]int]string)
+	// A block mapping starts at its first key, so deeper nodes, visited
+	// later, take precedence
+	var walk func(n *yaml.Node, path string)
+	walk = func(n *yaml.Node, path string) {
+		if path != "" {
+			paths[[2]int{n.Line, n.Column}] = path
+		}
+		child := func(segment string) string {
+			if path == "" {
+				return segment
+			}
+			return path + "." + segment
+		}
+		switch n.Kind {
+		case yaml.DocumentNode:
+			for _, c := range n.Content {
+				walk(c, path)
+			}
+		case yaml.MappingNode:
+			for i := 0; i+1 < len(n.Content); i += 2 {
+				key := n.Content[i]
+				if key.Value == "<<" {
+					continue
+				}
+				paths[[2]int{key.Line, key.Column}] = child(key.Value)
+				walk(n.Content[i+1], child(key.Value))
+			}
+		case yaml.SequenceNode:
+			for i, item := range n.Content {
+				walk(item, child(strconv.Itoa(i)))
+			}
+		}
+	}
+	walk(doc, "")
+
+	for i, diag := range diags {
+		if diag.Path == sourceName && diag.FieldPath == "" && diag.Line > 0 {
+			diags[i].FieldPath = paths[[2]int{diag.Line, diag.Column}]
+		}
+	}
+}
+
+// tokenLength returns the length in characters of a single-line scalar as
+// written on its line, or 0 if the token cannot be found there (e.g. after an
+// anchor or a tag)
+func tokenLength(line string, n *yaml.Node) int {
+	runes := []rune(line)
+	if n.Column < 1 || n.Column > len(runes) {
+		return 0
+	}
+	rest := runes[n.Column-1:]
+	switch n.Style {
+	case 0:
+		if strings.HasPrefix(string(rest), n.Value) {
+			return utf8.RuneCountInString(n.Value)
+		}
+	case yaml.DoubleQuotedStyle:
+		for i := 1; i < len(rest); i++ {
+			if rest[i] == '\\' {
+				i++
+			} else if rest[i] == '"' {
+				return i + 1
+			}
+		}
+	case yaml.SingleQuotedStyle:
+		for i := 1; i < len(rest); i++ {
+			if rest[i] == '\'' {
+				if i+1 < len(rest) && rest[i+1] == '\'' {
+					i++
+					continue
+				}
+				return i + 1
+			}
+		}
+	}
+	return 0
+}
@@ -0,0 +1,162 @@
+package validate
+
+import (
+	"cmp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// staticPoolFields are fields used to size a pool with fixed counts. Pools
+// are sized by their schedule entries only.
+var staticPoolFields = []string{"size", "hot", "stopped"}
+
+// checkPoolModes reports pools sized with fixed counts set on the pool itself,
+// alongside their schedule or instead of it, and pools with no schedule entry
+func checkPoolModes(root *yaml.Node, sourceName string) []Diagnostic {
+	var diags []Diagnostic
+	pools := resolveAlias(mappingValue(root, "pools"))
+	if pools == nil || pools.Kind != yaml.MappingNode {
+		return diags
+	}
+	for i := 0; i+1 < len(pools.Content); i += 2 {
+		name, pool := pools.Content[i], resolveAlias(pools.Content[i+1])
+		if pool.Kind != yaml.MappingNode {
+			continue
+		}
+		schedule := fieldNode(pool, "schedule")
+		hasSchedule := schedule != nil && schedule.Kind == yaml.SequenceNode && len(schedule.Content) > 0
+
+		var static []*yaml.Node
+		for _, field := range staticPoolFields {
+			if key := mappingKey(pool, field); key != nil {
+				static = append(static, key)
+			}
+		}
+		switch {
+		case len(static) > 0:
+			var names []string
+			for _, key := range static {
+				names = append(names, "'"+key.Value+"'")
+			}
+			key := RulePoolMode
+			if hasSchedule {
+				key += ".schedule"
+			}
+			diags = append(diags, Diagnostic{
+				Path:     sourceName,
+				Line:     static[0].Line,
+				Column:   static[0].Column,
+				text:     message(key, "pool", name.Value, "fields", strings.Join(names, ", ")),
+				Severity: SeverityError,
+				RuleID:   RulePoolMode,
+			})
+		case !hasSchedule:
+			diags = append(diags, Diagnostic{
+				Path:     sourceName,
+				Line:     name.Line,
+				Column:   name.Column,
+				text:     message(RulePoolNoSchedule, "pool", name.Value),
+				Severity: SeverityWarning,
+				RuleID:   RulePoolNoSchedule,
+			})
+		}
+	}
+	return diags
+}
+
+// dropStaticPoolFieldErrors removes the schema errors for static fields set
+// on pools, which checkPoolModes reports with the reason
+func dropStaticPoolFieldErrors(root *yaml.Node, schemaErrors []Diagnostic) []Diagnostic {
+	pools := resolveAlias(mappingValue(root, "pools"))
+	if pools == nil || pools.Kind != yaml.MappingNode {
+		return schemaErrors
+	}
+	return slices.DeleteFunc(schemaErrors, func(diag Diagnostic) bool {
+		path := strings.Split(diag.FieldPath, ".")
+		if diag.RuleID != RuleSchema || len(path) != 3 || path[0] != "pools" || !slices.Contains(staticPoolFields, path[2]) {
+			return false
+		}
+		return mappingKey(resolveAlias(mappingValue(pools, path[1])), path[2]) != nil
+	})
+}
+
+// poolModeFix returns a fix replacing the static fields of a pool with a
+// schedule entry holding their counts, or removing them if the pool has a
+// schedule. It returns nil unless every static field is an integer on a line
+// of its own.
+func poolModeFix(index *lineIndex, pool *yaml.Node) *SuggestedFix {
+	counts := make(map[string]string)
+	var edits []TextEdit
+	var first *yaml.Node
+	for _, field := range staticPoolFields {
+		key, value := mappingKey(pool, field), mappingValue(pool, field)
+		if key == nil {
+			continue
+		}
+		if _, err := strconv.Atoi(value.Value); err != nil || value.Kind != yaml.ScalarNode || !startsLine(index, key) || value.Line != key.Line {
+			return nil
+		}
+		counts[field] = value.Value
+		edits = append(edits, TextEdit{Offset: index.offset(key.Line, 1), EndOffset: index.lineEnd(key.Line)})
+		if first == nil || key.Line < first.Line {
+			first = key
+		}
+	}
+	if len(edits) == 0 {
+		return nil
+	}
+	if mappingKey(pool, "schedule") != nil {
+		return &SuggestedFix{Description: "Remove the static counts and keep the schedule", Edits: edits}
+	}
+
+	hot := counts["hot"]
+	if hot == "" {
+		hot = counts["size"]
+	}
+	entry := scheduleScaffold(strings.Repeat(" ", first.Column-1), cmp.Or(hot, "0"), cmp.Or(counts["stopped"], "0"))
+	for i := range edits {
+		if edits[i].Offset == index.offset(first.Line, 1) {
+			edits[i].NewText = entry
+		}
+	}
+	return &SuggestedFix{Description: "Move the static counts into a default schedule entry", Edits: edits}
+}
+
+// scheduleFix returns a fix adding a default schedule entry at the end of a
+// block pool mapping, or nil for a flow mapping
+func scheduleFix(index *lineIndex, pool *yaml.Node) *SuggestedFix {
+	if pool.Style&yaml.FlowStyle != 0 || len(pool.Content) == 0 || !startsLine(index, pool.Content[0]) {
+		return nil
+	}
+	line := min(lastLine(pool), len(index.lines))
+	entry := scheduleScaffold(strings.Repeat(" ", pool.Content[0].Column-1), "0", "1")
+	if !strings.HasSuffix(index.lines[line-1], "\n") {
+		entry = "\n" + strings.TrimSuffix(entry, "\n")
+	}
+	offset := index.lineEnd(line)
+	return &SuggestedFix{
+		Description: "Add a default schedule entry",
+		Edits:       []TextEdit{{Offset: offset, EndOffset: offset, NewText: entry}},
+	}
+}
+
+// scheduleScaffold returns the lines of a schedule with a single entry
+// without match criteria, indented by indent
+func scheduleScaffold(indent, hot, stopped string) string {
+	return indent + "schedule:\n" +
+		indent + "  - name: default\n" +
+		indent + "    hot: " + hot + "\n" +
+		indent + "    stopped: " + stopped + "\n"
+}
+
+// startsLine reports whether a node is the first token of its line
+func startsLine(index *lineIndex, n *yaml.Node) bool {
+	if n.Line < 1 || n.Line > len(index.lines) {
+		return false
+	}
+	start := index.offset(n.Line, 1)
+	return strings.TrimSpace(index.lines[n.Line-1][:index.offset(n.Line, n.Column)-start]) == ""
+}
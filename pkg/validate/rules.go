@@ -0,0 +1,820 @@
+package validate
+
+import "sort"
+
+// Rule IDs attached to diagnostics. They are stable and can be used to look up
+// rule documentation with LookupRule.
+const (
+	RuleYAMLSyntax            = "yaml-syntax"
+	RuleDuplicateKey          = "duplicate-key"
+	RuleSchema                = "schema"
+	RuleRequiredField         = "required-field"
+	RuleDeprecatedDisk        = "deprecated-disk"
+	RuleDeprecatedEnvironment = "deprecated-environment"
+	RulePoolRunnerUndefined   = "pool-runner-undefined"
+	RuleRunnerImageUndefined  = "runner-image-undefined"
+	RuleImageAMI              = "image-ami"
+	RuleImageSource           = "image-source"
+	RuleExtendsLocal          = "extends-local"
+	RulePublicSSH             = "public-ssh"
+	RuleFamilyNoMatch         = "family-no-match"
+	RuleNoMatchingInstance    = "no-matching-instance"
+	RuleAdminsDuplicate       = "admins-duplicate"
+	RuleAdminsOrder           = "admins-order"
+	RuleAdminsUsername        = "admins-username"
+	RuleAdminsUsernameForm    = "admins-username-form"
+	RuleExtrasRequirement     = "extras-requirement"
+	RuleExtrasUnknown         = "extras-unknown"
+	RuleTagFormat             = "tag-format"
+	RuleTagLimit              = "tag-limit"
+	RuleVolumeSpec            = "volume-spec"
+	RulePreinstallShell       = "preinstall-shell"
+	RulePreinstallShellcheck  = "preinstall-shellcheck"
+	RuleUnusedAnchor          = "unused-anchor"
+	RuleUnusedExtension       = "unused-extension"
+	RuleUnusedImage           = "unused-image"
+	RuleUnusedRunner          = "unused-runner"
+	RuleUnknownField          = "unknown-field"
+	RuleFieldTypo             = "field-typo"
+	RuleNameFormat            = "name-format"
+	RuleMergeConflict         = "merge-conflict"
+	RuleScheduleMatch         = "schedule-match"
+	RuleScheduleConsistency   = "schedule-consistency"
+	RuleScheduleDuplicateName = "schedule-duplicate-name"
+	RuleShutdownHot           = "shutdown-hot"
+	RuleTimezone              = "timezone"
+	RuleTimezoneLegacy        = "timezone-legacy"
+	RuleBurstableCapacity     = "burstable-capacity"
+	RulePoolCapacity          = "pool-capacity"
+	RulePoolMode              = "pool-mode"
+	RulePoolNoSchedule        = "pool-no-schedule"
+	RuleSchemaVersion         = "schema-version"
+	RuleSchemaVersionMissing  = "schema-version-missing"
+	RuleSchemaVersionField    = "schema-version-field"
+)
+
+// Rule groups gathering related rules
+const (
+	// GroupCapacityResilience rules flag configs whose runners may be slow
+	// or unavailable under load
+	GroupCapacityResilience = "capacity-resilience"
+)
+
+const (
+	docsRepoConfig = "https://runs-on.com/configuration/repo-config/"
+	docsJobLabels  = "https://runs-on.com/configuration/job-labels/"
+)
+
+// RuleInfo documents a validation rule
+type RuleInfo struct {
+	ID          string
+	Severity    Severity
+	Summary     string
+	Description string
+	BadExample  string
+	GoodExample string
+	DocURL      string
+	// Fixable is set for rules whose diagnostics the linter's -fix resolves
+	Fixable bool
+	// Group is the group the rule belongs to, e.g. GroupCapacityResilience,
+	// or empty
+	Group string
+}
+
+var ruleRegistry = map[string]RuleInfo{
+	RuleYAMLSyntax: {
+		ID:          RuleYAMLSyntax,
+		Severity:    SeverityError,
+		Summary:     "File must be valid YAML",
+		Description: "The file could not be parsed as YAML. This is usually caused by inconsistent indentation, or a missing colon after a key. Keys defined twice in the same mapping are reported as duplicate-key.",
+		BadExample: `runners:
+  my-runner:
+    cpu: [2]
+   ram: [16]`,
+		GoodExample: `runners:
+  my-runner:
+    cpu: [2]
+    ram: [16]`,
+		DocURL: docsRepoConfig,
+	},
+	RuleDuplicateKey: {
+		ID:          RuleDuplicateKey,
+		Severity:    SeverityError,
+		Summary:     "Keys must be unique within a mapping",
+		Description: "A key is defined more than once in the same mapping, e.g. a runner or a field of a runner. Only one definition could take effect, so the config is rejected. The diagnostic points at the repeated key and names the line of the first definition.",
+		BadExample: `runners:
+  my-runner:
+    cpu: [2]
+  my-runner:
+    cpu: [4]`,
+		GoodExample: `runners:
+  my-runner:
+    cpu: [2]
+  my-large-runner:
+    cpu: [4]`,
+		DocURL: docsRepoConfig,
+	},
+	RuleSchema: {
+		ID:          RuleSchema,
+		Severity:    SeverityError,
+		Summary:     "Config must match the runs-on.yml schema",
+		Description: "A field has the wrong type or violates a constraint (e.g. a negative instance count). The diagnostic message names the offending field and the expected value. Missing required fields are reported as required-field.",
+		BadExample: `pools:
+  my-pool:
+    runner: my-runner
+    schedule:
+      - name: default
+        hot: -1
+        stopped: 2`,
+		GoodExample: `pools:
+  my-pool:
+    runner: my-runner
+    schedule:
+      - name: default
+        hot: 1
+        stopped: 2`,
+		DocURL: docsRepoConfig,
+	},
+	RuleRequiredField: {
+		ID:          RuleRequiredField,
+		Severity:    SeverityError,
+		Summary:     "Required fields must be set",
+		Description: "A field the schema requires is missing or has no concrete value, such as a pool without a runner or a schedule entry without a name. The diagnostic message names the missing field.",
+		BadExample: `pools:
+  my-pool:
+    schedule:
+      - name: default
+        hot: 1`,
+		GoodExample: `pools:
+  my-pool:
+    runner: my-runner
+    schedule:
+      - name: default
+        hot: 1`,
+		DocURL: docsRepoConfig,
+	},
+	RuleDeprecatedDisk: {
+		ID:          RuleDeprecatedDisk,
+		Severity:    SeverityWarning,
+		Summary:     "Runner field 'disk' is deprecated and ignored",
+		Description: "The 'disk' runner field is no longer used by RunsOn and has no effect. Use 'volume' to configure the root volume size, type, throughput and IOPS. The linter's -fix flag removes the field.",
+		BadExample: `runners:
+  my-runner:
+    disk: large`,
+		GoodExample: `runners:
+  my-runner:
+    volume: 80gb:gp3:125mbs:3000iops`,
+		DocURL:  docsJobLabels,
+		Fixable: true,
+	},
+	RuleDeprecatedEnvironment: {
+		ID:          RuleDeprecatedEnvironment,
+		Severity:    SeverityWarning,
+		Summary:     "Pool field 'environment' is deprecated",
+		Description: "The 'environment' pool field has been renamed to 'env'. Both are accepted for now, but 'environment' will be removed in a future version. The linter's -fix flag renames the field unless 'env' is also set.",
+		BadExample: `pools:
+  my-pool:
+    environment: production`,
+		GoodExample: `pools:
+  my-pool:
+    env: production`,
+		DocURL:  docsRepoConfig,
+		Fixable: true,
+	},
+	RulePoolRunnerUndefined: {
+		ID:          RulePoolRunnerUndefined,
+		Severity:    SeverityError,
+		Summary:     "Pool runner must be defined in runners",
+		Description: "A pool that references its runner by name must use a key of the top-level 'runners' map in the same file. When a defined runner has a similar name, the message suggests it and editors offer a quick fix replacing the reference.",
+		BadExample: `runners:
+  small-x64:
+    cpu: [2]
+pools:
+  my-pool:
+    runner: small-arm64`,
+		GoodExample: `runners:
+  small-x64:
+    cpu: [2]
+pools:
+  my-pool:
+    runner: small-x64`,
+		DocURL: docsRepoConfig,
+	},
+	RuleRunnerImageUndefined: {
+		ID:          RuleRunnerImageUndefined,
+		Severity:    SeverityWarning,
+		Summary:     "Runner image must be defined in images or built in",
+		Description: "A runner's 'image' must be a key of the top-level 'images' map, including images inherited through _extends, or one of the images RunsOn provides, such as ubuntu24-full-x64. Otherwise jobs fail when the runner is launched. When an image has a similar name, the message suggests it. This is a warning rather than an error as RunsOn may add built-in images before the linter knows them.",
+		BadExample: `runners:
+  small:
+    image: ubuntu24-ful-x64`,
+		GoodExample: `runners:
+  small:
+    image: ubuntu24-full-x64`,
+		DocURL: docsRepoConfig,
+	},
+
+	RuleImageAMI: {
+		ID:          RuleImageAMI,
+		Severity:    SeverityError,
+		Summary:     "Image AMIs must be AMI IDs",
+		Description: "An image's 'ami' must be an AMI ID: 'ami-' followed by 8 or 17 lowercase hexadecimal characters. Placeholders left from examples, such as ami-xxxx, are pointed out as such. To select an AMI by name, use 'name' and 'owner' instead.",
+		BadExample: `images:
+  my-image:
+    ami: ami-xxxx`,
+		GoodExample: `images:
+  my-image:
+    ami: ami-0123456789abcdef0`,
+		DocURL: docsRepoConfig,
+	},
+	RuleImageSource: {
+		ID:          RuleImageSource,
+		Severity:    SeverityError,
+		Summary:     "Images must set either an AMI or an AMI search",
+		Description: "An image selects the AMI to launch either by ID, with 'ami', or by searching the AMIs of an account, with 'name' (which may contain wildcards, the most recent match being used) and 'owner'. Images setting both are ambiguous, and images setting neither cannot be launched. A search needs both fields: a name without owner matches AMIs published by any account, and an owner without name every AMI of the account.",
+		BadExample: `images:
+  my-image:
+    ami: ami-0123456789abcdef0
+    name: my-image-*`,
+		GoodExample: `images:
+  my-image:
+    name: my-image-*
+    owner: "123456789012"`,
+		DocURL: docsRepoConfig,
+	},
+	RuleExtendsLocal: {
+		ID:          RuleExtendsLocal,
+		Severity:    SeverityError,
+		Summary:     "Local _extends must point to a readable config",
+		Description: "An _extends value starting with ./ or ../ is read from a file relative to the config's directory. The file must exist, be valid YAML, not extend itself through a cycle, and, when validating in server mode, stay inside the repository. When the embedder fetches repository references (Options.FetchExtends), the fetched configs must be readable too, and cannot use local references.",
+		BadExample:  `_extends: ../../outside/base.yml`,
+		GoodExample: `_extends: ./shared/base-runners.yml`,
+		DocURL:      docsRepoConfig,
+	},
+	RulePublicSSH: {
+		ID:          RulePublicSSH,
+		Severity:    SeverityWarning,
+		Summary:     "SSH should not be enabled on runners with a public IP",
+		Description: "Runners get a public IP address unless 'private' is enabled. Enabling 'ssh' on such runners exposes the SSH port of every instance to the internet. Launch the runner in private subnets, or disable SSH if it is not needed.",
+		BadExample: `runners:
+  my-runner:
+    ssh: true`,
+		GoodExample: `runners:
+  my-runner:
+    ssh: true
+    private: true`,
+		DocURL: docsJobLabels,
+	},
+	RuleFamilyNoMatch: {
+		ID:          RuleFamilyNoMatch,
+		Severity:    SeverityError,
+		Summary:     "Family patterns must match a known instance family",
+		Description: "A runner 'family' entry can be a wildcard such as 'c7*'. Patterns are expanded against the instance catalog and must match at least one family. Plain family names and prefixes are not checked.",
+		BadExample: `runners:
+  my-runner:
+    family: [c9*]`,
+		GoodExample: `runners:
+  my-runner:
+    family: [c7*, m7*]`,
+		DocURL: docsJobLabels,
+	},
+	RuleNoMatchingInstance: {
+		ID:          RuleNoMatchingInstance,
+		Severity:    SeverityWarning,
+		Summary:     "Runner cpu and ram should be within reach of its families",
+		Description: "A runner whose cpu or ram exceeds the largest instance type of all its families, according to the built-in instance catalog, would never launch. The diagnostic names the constraint that eliminates every instance type. Families whose sizes the catalog does not know, such as accelerated computing families, are not checked.",
+		BadExample: `runners:
+  my-runner:
+    family: [c7a]
+    ram: [512]`,
+		GoodExample: `runners:
+  my-runner:
+    family: [r7a]
+    ram: [512]`,
+		DocURL: docsJobLabels,
+	},
+	RuleAdminsDuplicate: {
+		ID:          RuleAdminsDuplicate,
+		Severity:    SeverityWarning,
+		Summary:     "Admins should be listed once",
+		Description: "An admin appears more than once in 'admins'. GitHub usernames are case-insensitive, so entries differing only in case are duplicates. Duplicates have no effect but make access changes harder to review. The linter's -fix flag removes them.",
+		BadExample: `admins:
+  - alice
+  - bob
+  - Alice`,
+		GoodExample: `admins:
+  - alice
+  - bob`,
+		DocURL:  docsRepoConfig,
+		Fixable: true,
+	},
+	RuleAdminsOrder: {
+		ID:          RuleAdminsOrder,
+		Severity:    SeverityWarning,
+		Summary:     "Admins should be sorted alphabetically",
+		Description: "Only checked when strict admins ordering is enabled with the linter's -strict-admins flag. Keeping 'admins' sorted, ignoring case, makes additions and removals easy to spot in review. -strict-admins -fix sorts the list.",
+		BadExample: `admins:
+  - carol
+  - alice`,
+		GoodExample: `admins:
+  - alice
+  - carol`,
+		DocURL:  docsRepoConfig,
+		Fixable: true,
+	},
+	RuleAdminsUsername: {
+		ID:          RuleAdminsUsername,
+		Severity:    SeverityError,
+		Summary:     "Admins must be GitHub usernames",
+		Description: "Each 'admins' entry must be a GitHub username: letters, digits and hyphens, at most 39 characters, not starting with a hyphen. Empty and malformed entries are errors.",
+		BadExample: `admins:
+  - ""
+  - -alice`,
+		GoodExample: `admins:
+  - alice`,
+		DocURL: docsRepoConfig,
+	},
+	RuleAdminsUsernameForm: {
+		ID:          RuleAdminsUsernameForm,
+		Severity:    SeverityWarning,
+		Summary:     "Admins should be listed by GitHub username",
+		Description: "An 'admins' entry that is an email address or a handle written with a leading '@' likely stands for a GitHub user, but never matches one. The warning for a handle names the username to list instead.",
+		BadExample: `admins:
+  - "@alice"
+  - bob@example.com`,
+		GoodExample: `admins:
+  - alice
+  - bob`,
+		DocURL: docsRepoConfig,
+	},
+	RuleExtrasRequirement: {
+		ID:          RuleExtrasRequirement,
+		Severity:    SeverityWarning,
+		Summary:     "Runner extras should be compatible with the rest of the runner",
+		Description: "Some extras only work with specific runner settings. 'efs' and 'tmpfs' are only supported on Linux images. 'efs' mounts a shared, elastic filesystem, so requesting a large local volume (over 100GB) alongside it is likely redundant.",
+		BadExample: `runners:
+  my-runner:
+    extras: [efs]
+    volume: 500gb:gp3`,
+		GoodExample: `runners:
+  my-runner:
+    extras: [efs]
+    volume: 80gb:gp3`,
+		DocURL: docsJobLabels,
+	},
+	RuleExtrasUnknown: {
+		ID:          RuleExtrasUnknown,
+		Severity:    SeverityWarning,
+		Summary:     "Runner extras must be supported by RunsOn",
+		Description: "Each value of 'extras', including each item of a plus-separated string, must be one of s3-cache, ecr-cache, efs or tmpfs. RunsOn ignores other values, so a misspelled extra silently does nothing; the warning suggests the extra it most likely refers to.",
+		BadExample: `runners:
+  my-runner:
+    extras: s3cache+tmpfs`,
+		GoodExample: `runners:
+  my-runner:
+    extras: s3-cache+tmpfs`,
+		DocURL: docsJobLabels,
+	},
+	RuleTagFormat: {
+		ID:          RuleTagFormat,
+		Severity:    SeverityError,
+		Summary:     "Tags must be valid AWS tags",
+		Description: "Runner tags are written as Key:Value. AWS only allows letters, digits, spaces and the characters _ . : / = + - @ in tag keys and values, and reserves keys starting with 'aws:'. Tags breaking these rules make instance launches fail. Image tags are checked too.",
+		BadExample: `runners:
+  my-runner:
+    tags: ["team", "aws:owner:devops"]`,
+		GoodExample: `runners:
+  my-runner:
+    tags: ["Team:DevOps", "Owner:devops"]`,
+		DocURL: docsRepoConfig,
+	},
+	RuleTagLimit: {
+		ID:          RuleTagLimit,
+		Severity:    SeverityError,
+		Summary:     "Tags must fit AWS tag limits",
+		Description: "AWS limits tag keys to 128 characters, tag values to 256 characters and resources to 50 tags. Runners may set at most 40 tags, leaving room for the tags RunsOn adds to every instance; images may set 50.",
+		BadExample: `runners:
+  my-runner:
+    tags: ["Description:<a value longer than 256 characters>"]`,
+		GoodExample: `runners:
+  my-runner:
+    tags: ["Description:nightly builds"]`,
+		DocURL: docsRepoConfig,
+	},
+	RuleVolumeSpec: {
+		ID:          RuleVolumeSpec,
+		Severity:    SeverityError,
+		Summary:     "Volume specifications must be valid EBS volumes",
+		Description: "'volume' is written size:type:throughput:iops, e.g. 80gb:gp3:125mbs:3000iops, where every component is optional. Sizes are in gb, throughput in mbs (or mbps) and iops in iops; the type is an EBS volume type (gp2, gp3, io1, io2, sc1, st1, standard) and defaults to gp3. Each value must be within the AWS limits of the volume type, including the iops per GB of io1, io2 and gp3 volumes and the throughput per iops of gp3 volumes. Components may be written in any order.",
+		BadExample: `runners:
+  my-runner:
+    volume: 80gb:gp4:4000mbs:3000iops`,
+		GoodExample: `runners:
+  my-runner:
+    volume: 80gb:gp3:125mbs:3000iops`,
+		DocURL: docsJobLabels,
+	},
+	RulePreinstallShell: {
+		ID:          RulePreinstallShell,
+		Severity:    SeverityError,
+		Summary:     "Preinstall scripts must be valid shell scripts",
+		Description: "Runner and image 'preinstall' scripts run when an instance boots, where a syntax error fails the boot. They are checked for unterminated quotes, substitutions and here-documents, and for if, loop, case and brace blocks that are not closed, or closed by the wrong keyword. Errors point at the offending line within a literal block scalar. Scripts of Windows images, which are PowerShell, and scripts whose shebang names another interpreter are not checked.",
+		BadExample: `runners:
+  my-runner:
+    preinstall: |
+      if [ -f /etc/os-release ]; then
+        echo "linux
+      fi`,
+		GoodExample: `runners:
+  my-runner:
+    preinstall: |
+      if [ -f /etc/os-release ]; then
+        echo "linux"
+      fi`,
+		DocURL: docsRepoConfig,
+	},
+	RulePreinstallShellcheck: {
+		ID:          RulePreinstallShellcheck,
+		Severity:    SeverityWarning,
+		Summary:     "Preinstall scripts should pass shellcheck",
+		Description: "With the linter's -shellcheck flag, or Options.Shellcheck, preinstall scripts without syntax errors are also run through shellcheck, if it is installed, and its errors and warnings are reported with their SC code, such as unquoted variables that split on spaces. Scripts skipped by preinstall-shell are skipped too.",
+		BadExample: `runners:
+  my-runner:
+    preinstall: |
+      cp $HOME/config /etc/app`,
+		GoodExample: `runners:
+  my-runner:
+    preinstall: |
+      cp "$HOME/config" /etc/app`,
+		DocURL: docsRepoConfig,
+	},
+	RuleUnusedAnchor: {
+		ID:          RuleUnusedAnchor,
+		Severity:    SeverityWarning,
+		Summary:     "Anchors should be referenced",
+		Description: "A YAML anchor is defined but no alias refers to it, for example after the entries that merged it were removed. Unused anchors suggest the value is shared when it is not. Aliases inside unused x-* blocks do not count. The linter's -fix flag removes the anchor, keeping its value.",
+		BadExample: `runners:
+  small: &small
+    cpu: 2`,
+		GoodExample: `runners:
+  small:
+    cpu: 2`,
+		DocURL:  docsRepoConfig,
+		Fixable: true,
+	},
+	RuleUnusedExtension: {
+		ID:          RuleUnusedExtension,
+		Severity:    SeverityWarning,
+		Summary:     "x-* blocks should be referenced",
+		Description: "A top-level x-* block is ignored by RunsOn and only useful as the target of aliases, but nothing refers to it or to the anchors it contains. Large shared configs accumulate such dead default blocks, which mislead readers about the effective settings. The linter's -fix flag removes the block.",
+		BadExample: `x-defaults: &defaults
+  cpu: 2
+runners:
+  small:
+    cpu: 4`,
+		GoodExample: `x-defaults: &defaults
+  cpu: 2
+runners:
+  small:
+    <<: *defaults`,
+		DocURL:  docsRepoConfig,
+		Fixable: true,
+	},
+	RuleUnusedImage: {
+		ID:          RuleUnusedImage,
+		Severity:    SeverityWarning,
+		Summary:     "Images should be used by a runner",
+		Description: "Reported only when enabled (Options.UnusedImages, or lint -unused-images), as images are also selected from job labels and shared through _extends. An entry of 'images' that no runner of the config uses, including runners inherited through _extends, is usually a stale custom AMI left behind after its runners moved to another image. Configs that define no runners, such as shared image definitions, are not checked.",
+		BadExample: `images:
+  legacy:
+    ami: ami-1234567890abcdef0
+runners:
+  small:
+    image: ubuntu24-full-x64`,
+		GoodExample: `images:
+  custom:
+    ami: ami-1234567890abcdef0
+runners:
+  small:
+    image: custom`,
+		DocURL: docsRepoConfig,
+	},
+	RuleUnusedRunner: {
+		ID:          RuleUnusedRunner,
+		Severity:    SeverityWarning,
+		Summary:     "Runners should be used by a pool",
+		Description: "Reported only when enabled (Options.UnusedRunners, or lint -unused-runners), as runners are commonly selected from job labels without a pool. A runner that no pool of the config uses, including pools merged in through _extends, may be a dead entry of a large shared config. Runners that workflows select with runner= labels are listed as label runners (names or glob patterns) so that they are not reported.",
+		BadExample: `runners:
+  small:
+    cpu: 2
+  legacy:
+    cpu: 4
+pools:
+  main:
+    runner: small`,
+		GoodExample: `runners:
+  small:
+    cpu: 2
+pools:
+  main:
+    runner: small`,
+		DocURL: docsRepoConfig,
+	},
+	RuleUnknownField: {
+		ID:          RuleUnknownField,
+		Severity:    SeverityError,
+		Summary:     "Fields must be defined by the schema",
+		Description: "Runners, images, pools and schedule entries only accept the fields the schema defines. Unknown top-level fields are reported only in strict mode: the schema accepts them so that older linters keep working with newer configs, but a misspelled section such as 'runner' or 'pool' is then silently ignored by RunsOn. x-* blocks, which only hold anchors, are allowed. When a field is likely a misspelling of a known one, such as 'familly' for 'family', the message suggests it.",
+		BadExample: `runner:
+  small:
+    cpu: 2`,
+		GoodExample: `runners:
+  small:
+    cpu: 2`,
+		DocURL: docsRepoConfig,
+	},
+	RuleFieldTypo: {
+		ID:          RuleFieldTypo,
+		Severity:    SeverityWarning,
+		Summary:     "Top-level fields should not be misspellings of known ones",
+		Description: "Outside strict mode, unknown top-level fields are accepted for forward compatibility, and RunsOn ignores them. A field within two edits of a known one, such as 'runer' for 'runners', is likely a typo that silently drops a whole section, so it is reported with the field to use instead. In strict mode, unknown-field reports it as an error.",
+		BadExample: `runer:
+  small:
+    cpu: 2`,
+		GoodExample: `runners:
+  small:
+    cpu: 2`,
+		DocURL: docsRepoConfig,
+	},
+	RuleNameFormat: {
+		ID:          RuleNameFormat,
+		Severity:    SeverityError,
+		Summary:     "Runner, image and pool names must be usable in job labels",
+		Description: "Runner, image and pool names are written in job labels (runner=, image=, pool=), whose parts are separated by '/' and ',', and in the names and tags of the AWS resources RunsOn creates, where other characters fail only at runtime. Runner and image names may only contain letters, digits, '-', '_' and '.', must start with a letter or a digit and are at most 64 characters long. Pool names are further limited to lowercase letters, digits, '-' and '_'.",
+		BadExample: `runners:
+  large runner:
+    cpu: 16`,
+		GoodExample: `runners:
+  large-runner:
+    cpu: 16`,
+		DocURL: docsRepoConfig,
+	},
+	RuleMergeConflict: {
+		ID:          RuleMergeConflict,
+		Severity:    SeverityError,
+		Summary:     "Extending configs should not redefine entries differently",
+		Description: "Reported only in strict mode. Runners, images and pools of a local _extends chain are merged by name, and the extending file wins. In strict mode, an entry that replaces a different definition of the same name is reported with both files, so that shared defaults are not overridden by accident. Identical redefinitions are allowed; rename the entry to keep both.",
+		BadExample: `# base.yml defines runners.large with cpu: 16
+_extends: ./base.yml
+runners:
+  large:
+    cpu: 32`,
+		GoodExample: `_extends: ./base.yml
+runners:
+  xlarge:
+    cpu: 32`,
+		DocURL: docsRepoConfig,
+	},
+	RuleScheduleMatch: {
+		ID:          RuleScheduleMatch,
+		Severity:    SeverityError,
+		Summary:     "Schedule match criteria must be valid days and times",
+		Description: "A schedule entry applies on the days listed in 'match.day', which must be weekday names, and within the 'match.time' range, which must be a start and an end time written as HH:MM (24-hour clock). The range may wrap around midnight. An entry without match criteria applies whenever no other entry matches. Messages name entries by their field path, e.g. pools.main.schedule.1.",
+		BadExample: `schedule:
+  - name: business-hours
+    hot: 2
+    match:
+      day: [mon, tue]
+      time: ["8am"]`,
+		GoodExample: `schedule:
+  - name: business-hours
+    hot: 2
+    match:
+      day: [monday, tuesday]
+      time: ["08:00", "18:00"]`,
+		DocURL: docsRepoConfig,
+	},
+	RuleScheduleConsistency: {
+		ID:          RuleScheduleConsistency,
+		Severity:    SeverityWarning,
+		Summary:     "Schedule entries should be consistent",
+		Description: "A schedule entry whose criteria are valid but do not mean what they say: a day listed twice, a time range starting and ending at the same time (which spans the whole day), a 'match' without days or times, or an entry without criteria after the first one, which never applies since the first one already applies whenever no other entry matches.",
+		BadExample: `schedule:
+  - name: business-hours
+    hot: 2
+    match:
+      day: [monday, monday]
+      time: ["08:00", "08:00"]`,
+		GoodExample: `schedule:
+  - name: business-hours
+    hot: 2
+    match:
+      day: [monday]
+      time: ["08:00", "18:00"]`,
+		DocURL: docsRepoConfig,
+	},
+	RuleScheduleDuplicateName: {
+		ID:          RuleScheduleDuplicateName,
+		Severity:    SeverityError,
+		Summary:     "Schedule entry names must be unique within a pool",
+		Description: "Two schedule entries of the same pool share a 'name'. At runtime the later entry silently shadows the earlier one, so one of them never takes effect. Entries of different pools may share names.",
+		BadExample: `schedule:
+  - name: business-hours
+    hot: 2
+    match:
+      day: [monday, tuesday, wednesday, thursday, friday]
+  - name: business-hours
+    hot: 0`,
+		GoodExample: `schedule:
+  - name: business-hours
+    hot: 2
+    match:
+      day: [monday, tuesday, wednesday, thursday, friday]
+  - name: default
+    hot: 0`,
+		DocURL: docsRepoConfig,
+	},
+	RuleShutdownHot: {
+		ID:          RuleShutdownHot,
+		Severity:    SeverityWarning,
+		Summary:     "Hot instances should not be scheduled on shutdown days",
+		Description: "Reported only when shutdown days are configured, e.g. with the linter's -shutdown-days flag. A schedule entry keeps hot instances on a day the organization does not run jobs, which costs money for idle capacity. Entries without days apply every day, and the entry without match criteria applies on the days other entries do not cover all day.",
+		BadExample: `# -shutdown-days saturday,sunday
+schedule:
+  - name: default
+    hot: 2`,
+		GoodExample: `# -shutdown-days saturday,sunday
+schedule:
+  - name: weekend
+    hot: 0
+    match:
+      day: [saturday, sunday]
+  - name: default
+    hot: 2`,
+		DocURL: docsRepoConfig,
+	},
+	RuleTimezone: {
+		ID:          RuleTimezone,
+		Severity:    SeverityError,
+		Summary:     "Pool timezones must be tz database zones",
+		Description: "A pool's 'timezone' sets when its schedule entries apply and must be a zone of the IANA tz database, such as Europe/Paris or UTC, checked against the copy embedded in the linter. Unknown zones are errors, as they otherwise only fail at runtime.",
+		BadExample: `pools:
+  main:
+    runner: small
+    timezone: Eastern`,
+		GoodExample: `pools:
+  main:
+    runner: small
+    timezone: America/New_York`,
+		DocURL: docsRepoConfig,
+	},
+	RuleTimezoneLegacy: {
+		ID:          RuleTimezoneLegacy,
+		Severity:    SeverityWarning,
+		Summary:     "Pool timezones should not be legacy aliases",
+		Description: "Legacy aliases of the tz database, such as US/Eastern or Asia/Calcutta, still work as a pool's 'timezone' but are deprecated. The warning names the zone to use instead.",
+		BadExample: `pools:
+  main:
+    runner: small
+    timezone: US/Eastern`,
+		GoodExample: `pools:
+  main:
+    runner: small
+    timezone: America/New_York`,
+		DocURL: docsRepoConfig,
+	},
+	RuleBurstableCapacity: {
+		ID:          RuleBurstableCapacity,
+		Severity:    SeverityWarning,
+		Summary:     "Burstable families should not back spot runners or hot pools",
+		Description: "Burstable families (t3, t3a, t4g) run at a baseline CPU level and spend CPU credits to go above it, which CI jobs do most of the time. Spot instances are replaced often, so they rarely accumulate credits, and hot pool instances spend their credits while idle; builds are then throttled to the baseline, or charged extra in unlimited mode. Families are expanded with the instance catalog, so prefixes and patterns such as 't*' are reported too.",
+		BadExample: `runners:
+  small:
+    family: [t3]
+    spot: pco`,
+		GoodExample: `runners:
+  small:
+    family: [m7a, c7a]
+    spot: pco`,
+		DocURL: docsJobLabels,
+		Group:  GroupCapacityResilience,
+	},
+	RulePoolCapacity: {
+		ID:          RulePoolCapacity,
+		Severity:    SeverityWarning,
+		Summary:     "Pool schedule counts should be plausible",
+		Description: "Hot instances are billed while idle, so a mistyped count such as 'hot: 100' is expensive. Schedule entries keeping more than 50 hot instances are reported (the linter's -max-hot flag sets another limit), and so are entries keeping at least 10 hot instances and more than 10 times as many hot as stopped instances, since stopped instances start quickly and only cost their volumes. A pool whose schedule entries all keep no instances does not speed up any job.",
+		BadExample: `schedule:
+  - name: default
+    hot: 100
+    stopped: 0`,
+		GoodExample: `schedule:
+  - name: default
+    hot: 10
+    stopped: 5`,
+		DocURL: docsRepoConfig,
+	},
+	RulePoolMode: {
+		ID:          RulePoolMode,
+		Severity:    SeverityError,
+		Summary:     "Pools must be sized by schedule entries, not fixed counts",
+		Description: "A pool keeps the number of hot and stopped instances given by its schedule entries: the entry whose 'match' criteria apply, or the entry without criteria at other times. Fixed counts set on the pool itself ('size', 'hot', 'stopped') are not supported, whether or not the pool has a schedule. For a fixed-size pool, use a single schedule entry without 'match'. The fix moves the counts into such an entry, or removes them if the pool has a schedule.",
+		BadExample: `pools:
+  main:
+    runner: small
+    hot: 2
+    stopped: 3`,
+		GoodExample: `pools:
+  main:
+    runner: small
+    schedule:
+      - name: default
+        hot: 2
+        stopped: 3`,
+		DocURL:  docsRepoConfig,
+		Fixable: true,
+	},
+	RulePoolNoSchedule: {
+		ID:          RulePoolNoSchedule,
+		Severity:    SeverityWarning,
+		Summary:     "Pools should have a schedule entry",
+		Description: "A pool without schedule entries keeps no hot or stopped instances, so it does not speed up any job. Add an entry without 'match' for a fixed-size pool, and entries with 'match' criteria to vary its size by day and time. Editors offer a fix adding a default entry to adjust.",
+		BadExample: `pools:
+  main:
+    runner: small`,
+		GoodExample: `pools:
+  main:
+    runner: small
+    schedule:
+      - name: default
+        hot: 0
+        stopped: 1`,
+		DocURL: docsRepoConfig,
+	}, RuleSchemaVersion: {
+		ID:          RuleSchemaVersion,
+		Severity:    SeverityError,
+		Summary:     "The declared schema version must exist",
+		Description: "A config may declare the schema version it is written for with 'schema-version' (or 'x-schema-version'), so that it is validated against that version's schema rather than whichever one the validator embeds. The value must be a version name, such as v2, or 'latest'. A config declaring both fields is reported too, as only 'schema-version' is used.",
+		BadExample:  `schema-version: 2`,
+		GoodExample: `schema-version: v2`,
+		DocURL:      docsRepoConfig,
+	},
+	RuleSchemaVersionMissing: {
+		ID:          RuleSchemaVersionMissing,
+		Severity:    SeverityWarning,
+		Summary:     "Configs should declare their schema version",
+		Description: "Reported only with the prod profile, for production configs. A config declaring the schema version it is written for with 'schema-version' is validated against that version's schema, so its verdict does not change when the validator is upgraded.",
+		BadExample: `runners:
+  small:
+    cpu: 2`,
+		GoodExample: `schema-version: v3
+runners:
+  small:
+    cpu: 2`,
+		DocURL: docsRepoConfig,
+	},
+	RuleSchemaVersionField: {
+		ID:          RuleSchemaVersionField,
+		Severity:    SeverityWarning,
+		Summary:     "Fields must exist in the declared schema version",
+		Description: "A config declaring its schema version with 'schema-version' should only use the fields of that version. A field added in a later version, such as the pool field 'env' (v2), is not understood by the RunsOn releases the declared version stands for. Declare the version that added the field, or remove it.",
+		BadExample: `schema-version: v1
+pools:
+  main:
+    runner: small
+    env: staging`,
+		GoodExample: `schema-version: v2
+pools:
+  main:
+    runner: small
+    env: staging`,
+		DocURL: docsRepoConfig,
+	},
+}
+
+// LookupRule returns the documentation of the rule with the given ID,
+// registered rules included
+func LookupRule(id string) (RuleInfo, bool) {
+	if rule, ok := ruleRegistry[id]; ok {
+		return rule, true
+	}
+	for _, rule := range registeredRules() {
+		if rule.ID() == id {
+			return RuleInfo{ID: id, Severity: rule.Severity()}, true
+		}
+	}
+	return RuleInfo{}, false
+}
+
+// Rules returns all known rules, registered rules included, sorted by ID
+func Rules() []RuleInfo {
+	rules := make([]RuleInfo, 0, len(ruleRegistry))
+	for _, rule := range ruleRegistry {
+		rules = append(rules, rule)
+	}
+	for _, rule := range registeredRules() {
+		rules = append(rules, RuleInfo{ID: rule.ID(), Severity: rule.Severity()})
+	}
+	sort.Slice(rules, func(i, j int) bool { return rules[i].ID < rules[j].ID })
+	return rules
+}
@@ -0,0 +1,107 @@
+package validate
+
+import (
+	"fmt"
+	"path"
+	"slices"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+// runnerEntry is a runner spec to check, an entry of the runners section
+type runnerEntry struct {
+	name string
+	// path is the dotted field path of the runner, e.g. "runners.small"
+	path string
+	spec map[string]any
+	// key and node are the key the runner is defined at and its value, or
+	// nil when not found
+	key, node *yaml.Node
+}
+
+// runnerEntries returns the runners of a decoded config sorted by name
+func runnerEntries(data map[string]any, root *yaml.Node) []runnerEntry {
+	var entries []runnerEntry
+
+	runners, _ := data["runners"].(map[string]any)
+	runnersNode := resolveAlias(mappingValue(root, "runners"))
+	for _, name := range sortedKeys(runners) {
+		spec, ok := runners[name].(map[string]any)
+		if !ok {
+			continue
+		}
+		entries = append(entries, runnerEntry{
+			name: name,
+			path: "runners." + name,
+			spec: spec,
+			key:  mappingKey(runnersNode, name),
+			node: resolveAlias(mappingValue(runnersNode, name)),
+		})
+	}
+
+	return entries
+}
+
+// checkLabelRunners returns an error naming the first malformed glob pattern
+// of Options.LabelRunners
+func checkLabelRunners(patterns []string) error {
+	for _, pattern := range patterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return &Error{Code: CodeOption, Message: fmt.Sprintf("invalid label runner pattern %q", pattern), Err: err}
+		}
+	}
+	return nil
+}
+
+// checkUnusedRunners reports the runners of this file that no pool uses,
+// including pools merged in through _extends, unless they match one of
+// labelRunners
+func checkUnusedRunners(yamlData any, root *yaml.Node, sourceName string, labelRunners []string) []Diagnostic {
+	var warnings []Diagnostic
+
+	data, ok := yamlData.(map[string]any)
+	if !ok {
+		return warnings
+	}
+	used := make(map[string]bool)
+	pools, _ := data["pools"].(map[string]any)
+	for _, value := range pools {
+		if pool, ok := value.(map[string]any); ok {
+			if runner, ok := pool["runner"].(string); ok {
+				used[runner] = true
+			}
+		}
+	}
+
+	runners, _ := data["runners"].(map[string]any)
+	runnersNode := resolveAlias(mappingValue(root, "runners"))
+	for _, name := range sortedKeys(runners) {
+		key := mappingKey(runnersNode, name)
+		if used[name] || key == nil || slices.ContainsFunc(labelRunners, func(pattern string) bool {
+			matched, _ := path.Match(pattern, name)
+			return matched
+		}) {
+			continue
+		}
+		warnings = append(warnings, Diagnostic{
+			Path:     sourceName,
+			Line:     key.Line,
+			Column:   key.Column,
+			text:     message(RuleUnusedRunner, "runner", name),
+			Severity: SeverityWarning,
+			RuleID:   RuleUnusedRunner,
+		})
+	}
+
+	return warnings
+}
+
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
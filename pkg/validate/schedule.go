@@ -0,0 +1,209 @@
+package validate
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// weekdays are the day names accepted in schedule match criteria
+var weekdays = []string{"monday", "tuesday", "wednesday", "thursday", "friday", "saturday", "sunday"}
+
+// parseShutdownDays returns the lowercased weekday names of days, or an error
+// naming the first one that is not a weekday
+func parseShutdownDays(days []string) ([]string, error) {
+	parsed := make([]string, 0, len(days))
+	for _, day := range days {
+		name := strings.ToLower(strings.TrimSpace(day))
+		if !slices.Contains(weekdays, name) {
+			return nil, &Error{Code: CodeOption, Message: fmt.Sprintf("invalid shutdown day %q (valid: %s)", day, strings.Join(weekdays, ", "))}
+		}
+		parsed = append(parsed, name)
+	}
+	return parsed, nil
+}
+
+// checkSchedules reports pool schedule entries whose match criteria cannot be
+// parsed: days that are not weekdays, and times that are not a [start, end]
+// range of HH:MM times. Entries that parse but are inconsistent are warned
+// about: repeated days, ranges starting and ending at the same time, match
+// criteria without days or times, and entries without criteria shadowed by
+// an earlier one. Entries sharing their name with an earlier entry are
+// errors. Messages name entries by their field path, e.g.
+// pools.main.schedule.1. If shutdownDays is set, entries keeping hot
+// instances on one of these days are also reported.
+func checkSchedules(root *yaml.Node, sourceName string, shutdownDays []string) []Diagnostic {
+	var diags []Diagnostic
+
+	report := func(n *yaml.Node, rule string, severity Severity, text messageText) {
+		line, column := position(n)
+		diags = append(diags, Diagnostic{
+			Path:     sourceName,
+			Line:     line,
+			Column:   column,
+			text:     text,
+			Severity: severity,
+			RuleID:   rule,
+		})
+	}
+
+	pools := resolveAlias(mappingValue(root, "pools"))
+	if pools == nil || pools.Kind != yaml.MappingNode {
+		return diags
+	}
+	for i := 0; i+1 < len(pools.Content); i += 2 {
+		poolName := pools.Content[i].Value
+		schedule := fieldNode(pools.Content[i+1], "schedule")
+		if schedule == nil || schedule.Kind != yaml.SequenceNode {
+			continue
+		}
+
+		// allDay are the days matched all day by an entry, on which the
+		// entry without criteria does not apply
+		allDay := make(map[string]bool)
+		var entries []scheduleEntry
+		fallback := -1
+		// named maps the entry names to the index of the first entry with
+		// the name
+		named := make(map[string]int)
+		for index, item := range schedule.Content {
+			item = resolveAlias(item)
+			entry := scheduleEntry{node: item, name: fieldValue(item, "name")}
+			entry.hot, _ = strconv.Atoi(fieldValue(item, "hot"))
+			label := scheduleLabel(poolName, index, entry.name)
+
+			if first, ok := named[entry.name]; ok {
+				report(fieldNode(item, "name"), RuleScheduleDuplicateName, SeverityError, message(RuleScheduleDuplicateName,
+					"schedule", label, "first", scheduleLabel(poolName, first, entry.name)))
+			} else if entry.name != "" {
+				named[entry.name] = index
+			}
+
+			match := fieldNode(item, "match")
+			if match != nil && match.Kind == yaml.MappingNode {
+				if days := fieldNode(match, "day"); days != nil && days.Kind == yaml.SequenceNode {
+					entry.dayRestricted = len(days.Content) > 0
+					for _, day := range days.Content {
+						day = resolveAlias(day)
+						name := strings.ToLower(day.Value)
+						if day.Kind != yaml.ScalarNode || !slices.Contains(weekdays, name) {
+							report(day, RuleScheduleMatch, SeverityError, message(RuleScheduleMatch,
+								"schedule", label, "day", day.Value, "days", strings.Join(weekdays, ", ")))
+							continue
+						}
+						if slices.Contains(entry.days, name) {
+							report(day, RuleScheduleConsistency, SeverityWarning, message(RuleScheduleConsistency, "schedule", label, "day", day.Value))
+							continue
+						}
+						entry.days = append(entry.days, name)
+					}
+				}
+				times := fieldNode(match, "time")
+				if times != nil && times.Kind == yaml.SequenceNode {
+					entry.timed = len(times.Content) > 0
+					if len(times.Content) > 0 && len(times.Content) != 2 {
+						report(times, RuleScheduleMatch, SeverityError, message(RuleScheduleMatch+".range",
+							"schedule", label, "count", strconv.Itoa(len(times.Content))))
+					}
+					var parsed []time.Time
+					for _, t := range times.Content {
+						t = resolveAlias(t)
+						value, err := time.Parse("15:04", t.Value)
+						if t.Kind != yaml.ScalarNode || err != nil {
+							report(t, RuleScheduleMatch, SeverityError, message(RuleScheduleMatch+".time", "schedule", label, "time", t.Value))
+							continue
+						}
+						parsed = append(parsed, value)
+					}
+					if len(times.Content) == 2 && len(parsed) == 2 && parsed[0].Equal(parsed[1]) {
+						report(times, RuleScheduleConsistency, SeverityWarning, message(RuleScheduleConsistency+".whole-day",
+							"schedule", label, "time", parsed[0].Format("15:04")))
+					}
+				}
+				entry.matched = entry.dayRestricted || entry.timed
+				if !entry.matched {
+					report(mappingKey(item, "match"), RuleScheduleConsistency, SeverityWarning, message(RuleScheduleConsistency+".match", "schedule", label))
+				}
+			}
+			if !entry.matched {
+				if fallback < 0 {
+					fallback = len(entries)
+				} else {
+					report(item, RuleScheduleConsistency, SeverityWarning, message(RuleScheduleConsistency+".shadowed",
+						"schedule", label, "fallback", scheduleLabel(poolName, fallback, entries[fallback].name)))
+				}
+			}
+			if entry.dayRestricted && !entry.timed {
+				for _, day := range entry.days {
+					allDay[day] = true
+				}
+			}
+			entries = append(entries, entry)
+		}
+
+		if len(shutdownDays) == 0 {
+			continue
+		}
+		for i, entry := range entries {
+			if entry.hot <= 0 {
+				continue
+			}
+			var days []string
+			switch {
+			case entry.dayRestricted:
+				days = entry.days
+			case entry.matched:
+				// Times without days match every day
+				days = weekdays
+			case i == fallback:
+				// The first entry without criteria applies whenever no
+				// other entry matches
+				for _, day := range weekdays {
+					if !allDay[day] {
+						days = append(days, day)
+					}
+				}
+			}
+			for _, day := range shutdownDays {
+				if slices.Contains(days, day) {
+					node := mappingKey(entry.node, "hot")
+					if node == nil {
+						node = entry.node
+					}
+					report(node, RuleShutdownHot, SeverityWarning, message(RuleShutdownHot,
+						"name", entry.name, "pool", poolName, "hot", strconv.Itoa(entry.hot), "day", day))
+					break
+				}
+			}
+		}
+	}
+
+	return diags
+}
+
+// scheduleLabel refers to a schedule entry in messages by its field path,
+// followed by its name if it has one, e.g. "pools.main.schedule.1 ('night')"
+func scheduleLabel(pool string, index int, name string) string {
+	if name == "" {
+		return fmt.Sprintf("pools.%s.schedule.%d", pool, index)
+	}
+	return fmt.Sprintf("pools.%s.schedule.%d ('%s')", pool, index, name)
+}
+
+// scheduleEntry is an entry of a pool schedule
+type scheduleEntry struct {
+	node *yaml.Node
+	name string
+	hot  int
+	// days are the valid days matched, lowercased, if dayRestricted is set
+	days          []string
+	dayRestricted bool
+	// timed is set when the entry matches a time range
+	timed bool
+	// matched is set when the entry has match criteria
+	matched bool
+}
@@ -5,6 +5,9 @@ package schema
 	// Optional reference to another repository's config to extend
 	_extends?: string
 
+	// Schema version the config is written for, e.g. "v3" or "latest"
+	"schema-version"?: string
+
 	// Map of runner specifications
 	runners?: {
 		[string]: #RunnerSpec
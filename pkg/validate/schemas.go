@@ -0,0 +1,109 @@
+package validate
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"cuelang.org/go/cue/cuecontext"
+	cueformat "cuelang.org/go/cue/format"
+	"cuelang.org/go/encoding/jsonschema"
+)
+
+// LatestSchema names the current schema version in LoadSchema
+const LatestSchema = "latest"
+
+// SchemaVersion is a named version of the embedded schema. Versions follow
+// the config versions of the migrate package.
+type SchemaVersion struct {
+	Name        string
+	Description string
+	// overlay is CUE source unified with the embedded schema to restore the
+	// constraints of older versions
+	overlay string
+	// fields are the paths of the fields added in the version, with "*"
+	// matching any runner, image or pool name
+	fields []string
+}
+
+var schemaVersions = []SchemaVersion{
+	{
+		Name:        "v1",
+		Description: "Pools use 'environment'; 'env' is not supported",
+		overlay:     "#PoolSpec: env?: error(\"'env' was added in schema version v2; use 'environment'\")\n",
+	},
+	{
+		Name:        "v2",
+		Description: "Adds the pool field 'env' and deprecates 'environment'",
+		fields:      []string{"pools.*.env"},
+	},
+	{
+		Name:        "v3",
+		Description: "The runner field 'disk' is ignored (current schema)",
+	},
+}
+
+// SchemaVersions returns the named schema versions, oldest first. The last
+// one is the embedded schema.
+func SchemaVersions() []SchemaVersion {
+	return append([]SchemaVersion(nil), schemaVersions...)
+}
+
+// LoadSchema returns CUE source for Options.Schema from a named schema
+// version (e.g. "v2", or "latest" for the embedded schema) or a local CUE or
+// JSON Schema file. The schema is checked with CheckSchema.
+func LoadSchema(ref string) ([]byte, error) {
+	if ref == LatestSchema {
+		return CUESchema(), nil
+	}
+	for _, version := range schemaVersions {
+		if version.Name == ref {
+			return append(CUESchema(), "\n"+version.overlay...), nil
+		}
+	}
+
+	data, err := os.ReadFile(ref)
+	if err != nil {
+		if os.IsNotExist(err) && !strings.ContainsAny(ref, `./\`) {
+			names := []string{LatestSchema}
+			for _, version := range schemaVersions {
+				names = append(names, version.Name)
+			}
+			return nil, &Error{Code: CodeSchema, Message: fmt.Sprintf("unknown schema version %q (available: %s)", ref, strings.Join(names, ", "))}
+		}
+		return nil, &Error{Code: CodeSchema, Message: "failed to read schema", Err: err}
+	}
+	if strings.EqualFold(filepath.Ext(ref), ".json") {
+		if data, err = convertJSONSchema(data); err != nil {
+			return nil, &Error{Code: CodeSchema, Message: "invalid JSON schema " + ref, Err: err}
+		}
+	}
+	if err := CheckSchema(data); err != nil {
+		return nil, &Error{Code: CodeSchema, Message: "invalid schema " + ref, Err: err}
+	}
+	return data, nil
+}
+
+// convertJSONSchema converts a JSON Schema document into CUE source defining
+// #Config
+func convertJSONSchema(data []byte) ([]byte, error) {
+	ctx := cuecontext.New()
+	value := ctx.CompileBytes(data)
+	if err := value.Err(); err != nil {
+		return nil, err
+	}
+	file, err := jsonschema.Extract(value, &jsonschema.Config{})
+	if err != nil {
+		return nil, err
+	}
+	schema := ctx.BuildFile(file)
+	if err := schema.Err(); err != nil {
+		return nil, err
+	}
+	src, err := cueformat.Node(schema.Syntax())
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte("#Config: "), src...), nil
+}
@@ -0,0 +1,203 @@
+package validate
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// schemaVersionFields are the top-level fields declaring the schema version a
+// config is written for, e.g. "schema-version: v2"
+var schemaVersionFields = []string{"schema-version", "x-schema-version"}
+
+// ProfileProd is the profile of production configs, which must also declare
+// their schema version so that their verdict does not change with the
+// validator
+const ProfileProd = "prod"
+
+// Profiles are the values of Options.Profile besides the default, ""
+var Profiles = []string{ProfileProd}
+
+// checkProfile returns an error for an unknown Options.Profile
+func checkProfile(profile string) error {
+	if profile != "" && !slices.Contains(Profiles, profile) {
+		return &Error{Code: CodeOption, Message: fmt.Sprintf("invalid profile %q (valid: %s)", profile, strings.Join(Profiles, ", "))}
+	}
+	return nil
+}
+
+// versionedSchema is the compiled schema of a named version, compiled on
+// first use
+type versionedSchema struct {
+	once   sync.Once
+	schema *compiledSchema
+	err    error
+}
+
+var versionedSchemas sync.Map // version name -> *versionedSchema
+
+// declaredSchemaVersion returns the key and value of the schema version
+// declared by a config, or nils if there is none
+func declaredSchemaVersion(root *yaml.Node) (*yaml.Node, *yaml.Node) {
+	for _, field := range schemaVersionFields {
+		if key := mappingKey(root, field); key != nil {
+			return key, resolveAlias(mappingValue(root, field))
+		}
+	}
+	return nil, nil
+}
+
+// findSchemaVersion returns the named schema version, or false if there is
+// none by that name. "latest" is the last version.
+func findSchemaVersion(name string) (SchemaVersion, bool) {
+	if name == LatestSchema {
+		return schemaVersions[len(schemaVersions)-1], true
+	}
+	for _, version := range schemaVersions {
+		if version.Name == name {
+			return version, true
+		}
+	}
+	return SchemaVersion{}, false
+}
+
+// schemaForVersion returns the schema to validate a config declaring version
+// against: embedded, the embedded schema, unified with the overlay of the
+// version if it has one
+func schemaForVersion(embedded *compiledSchema, version SchemaVersion) (*compiledSchema, error) {
+	if version.overlay == "" {
+		return embedded, nil
+	}
+	entry, _ := versionedSchemas.LoadOrStore(version.Name, &versionedSchema{})
+	versioned := entry.(*versionedSchema)
+	versioned.once.Do(func() {
+		schema, err := loadSchema(append(CUESchema(), "\n"+version.overlay...))
+		if err != nil {
+			versioned.err = &Error{Code: CodeEmbeddedSchema, Message: fmt.Sprintf("failed to load schema %s", version.Name), Err: err}
+			return
+		}
+		versioned.schema = schema
+	})
+	return versioned.schema, versioned.err
+}
+
+// checkSchemaVersion reports a declared schema version that is not a known
+// version name, and, if required (by the prod profile), a config declaring
+// none
+func checkSchemaVersion(root *yaml.Node, sourceName string, required bool) []Diagnostic {
+	key, value := declaredSchemaVersion(root)
+	if key == nil {
+		if !required || root == nil {
+			return nil
+		}
+		return []Diagnostic{{
+			Path:     sourceName,
+			Line:     1,
+			Column:   1,
+			text:     message(RuleSchemaVersionMissing, "version", schemaVersions[len(schemaVersions)-1].Name),
+			Severity: SeverityWarning,
+			RuleID:   RuleSchemaVersionMissing,
+		}}
+	}
+
+	var diags []Diagnostic
+	if value.Kind != yaml.ScalarNode || value.Tag != "!!str" {
+		diags = append(diags, Diagnostic{
+			Path:     sourceName,
+			Line:     value.Line,
+			Column:   value.Column,
+			text:     message(RuleSchemaVersion+".type", "field", key.Value, "version", schemaVersions[len(schemaVersions)-1].Name),
+			Severity: SeverityError,
+			RuleID:   RuleSchemaVersion,
+		})
+	} else if _, ok := findSchemaVersion(value.Value); !ok {
+		names := []string{LatestSchema}
+		for _, version := range schemaVersions {
+			names = append(names, version.Name)
+		}
+		diags = append(diags, Diagnostic{
+			Path:     sourceName,
+			Line:     value.Line,
+			Column:   value.Column,
+			text:     message(RuleSchemaVersion, "version", value.Value, "available", strings.Join(names, ", ")),
+			Severity: SeverityError,
+			RuleID:   RuleSchemaVersion,
+		})
+	}
+	for _, field := range schemaVersionFields[1:] {
+		if other := mappingKey(root, field); other != nil && other != key {
+			diags = append(diags, Diagnostic{
+				Path:     sourceName,
+				Line:     other.Line,
+				Column:   other.Column,
+				text:     message(RuleSchemaVersion+".both", "field", other.Value, "declared", key.Value),
+				Severity: SeverityError,
+				RuleID:   RuleSchemaVersion,
+			})
+		}
+	}
+	return diags
+}
+
+// checkNewerFields reports the fields a config uses that were added in a
+// later schema version than the one it declares. The schema of the declared
+// version may reject them too; they are reported by this rule only.
+func checkNewerFields(root *yaml.Node, sourceName string) []Diagnostic {
+	_, value := declaredSchemaVersion(root)
+	if value == nil || value.Kind != yaml.ScalarNode {
+		return nil
+	}
+	declared, ok := findSchemaVersion(value.Value)
+	if !ok {
+		return nil
+	}
+
+	var diags []Diagnostic
+	newer := false
+	for _, version := range schemaVersions {
+		if newer {
+			for _, field := range version.fields {
+				matchFields(root, strings.Split(field, "."), "", func(key *yaml.Node, path string) {
+					diags = append(diags, Diagnostic{
+						Path:      sourceName,
+						Line:      key.Line,
+						Column:    key.Column,
+						text:      message(RuleSchemaVersionField, "field", path, "added", version.Name, "declared", declared.Name),
+						Severity:  SeverityWarning,
+						RuleID:    RuleSchemaVersionField,
+						FieldPath: path,
+					})
+				})
+			}
+		}
+		newer = newer || version.Name == declared.Name
+	}
+	return diags
+}
+
+// matchFields calls fn with the key and path of the fields of n matching
+// segments, where "*" matches any key
+func matchFields(n *yaml.Node, segments []string, path string, fn func(key *yaml.Node, path string)) {
+	n = resolveAlias(n)
+	if n == nil || n.Kind != yaml.MappingNode {
+		return
+	}
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		key := n.Content[i]
+		if segments[0] != "*" && key.Value != segments[0] {
+			continue
+		}
+		keyPath := key.Value
+		if path != "" {
+			keyPath = path + "." + key.Value
+		}
+		if len(segments) == 1 {
+			fn(key, keyPath)
+		} else {
+			matchFields(n.Content[i+1], segments[1:], keyPath, fn)
+		}
+	}
+}
@@ -0,0 +1,222 @@
+package validate
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// scope restricts validation to a subtree of a document (see
+// Options.ScopePath)
+type scope struct {
+	// ranges are the line ranges of the subtree and of the anchors it uses
+	ranges [][2]int
+	// references are the dotted paths of the entries validated only because
+	// the subtree refers to them, e.g. the runner of a pool
+	references []string
+}
+
+// scopeDocument returns a copy of doc reduced to the subtree at path, a
+// dotted path such as "runners.gpu-runner" or "pools.main.schedule.0", and to
+// the entries the subtree references: the runner of a pool and the image of a
+// runner. Nodes are shared with doc, so positions are preserved.
+func scopeDocument(doc *yaml.Node, path string) (*yaml.Node, *scope, error) {
+	root := rootMapping(doc)
+	segments := strings.Split(path, ".")
+	node, key := root, (*yaml.Node)(nil)
+	for _, segment := range segments {
+		key, node = childNode(node, segment)
+		if node == nil {
+			return nil, nil, &Error{Code: CodeOption, Message: fmt.Sprintf("scope path %q not found", path)}
+		}
+	}
+
+	s := &scope{}
+	s.addRange(key, node, make(map[*yaml.Node]bool))
+
+	// Entries to keep by top-level section. A nil list keeps the whole value.
+	keep := map[string][]string{segments[0]: nil}
+	var entries []string
+	if len(segments) > 1 {
+		entries = []string{segments[1]}
+		keep[segments[0]] = entries
+	} else if section := mappingValue(root, segments[0]); section != nil {
+		section = resolveAlias(section)
+		for i := 0; i+1 < len(section.Content); i += 2 {
+			entries = append(entries, section.Content[i].Value)
+		}
+	}
+
+	addReference := func(section, name string) {
+		if name == "" || slices.Contains(keep[section], name) {
+			return
+		}
+		if _, whole := keep[section]; whole && keep[section] == nil {
+			return
+		}
+		keep[section] = append(keep[section], name)
+		s.references = append(s.references, section+"."+name)
+	}
+	runnerImage := func(runner string) {
+		if entry := mappingValue(resolveAlias(mappingValue(root, "runners")), runner); entry != nil {
+			addReference("images", fieldValue(entry, "image"))
+		}
+	}
+	switch segments[0] {
+	case "pools":
+		// Pools may use runners of extended files, so _extends is resolved
+		// and its errors are reported
+		keep["_extends"] = nil
+		if key := mappingKey(root, "_extends"); key != nil {
+			s.addRange(key, mappingValue(root, "_extends"), make(map[*yaml.Node]bool))
+		}
+		if _, ok := keep["runners"]; !ok {
+			keep["runners"] = []string{}
+		}
+		for _, name := range entries {
+			if entry := mappingValue(resolveAlias(mappingValue(root, "pools")), name); entry != nil {
+				runner := fieldValue(entry, "runner")
+				addReference("runners", runner)
+				runnerImage(runner)
+			}
+		}
+	case "runners":
+		for _, name := range entries {
+			runnerImage(name)
+		}
+	}
+
+	pruned := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", Line: root.Line, Column: root.Column}
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		sectionKey, sectionValue := root.Content[i], root.Content[i+1]
+		names, ok := keep[sectionKey.Value]
+		if !ok {
+			continue
+		}
+		if names != nil {
+			sectionValue = subMapping(resolveAlias(sectionValue), names)
+		}
+		pruned.Content = append(pruned.Content, sectionKey, sectionValue)
+	}
+	return &yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{pruned}}, s, nil
+}
+
+// contains reports whether a diagnostic belongs to the scope. Diagnostics
+// without a position are kept unless they are about a referenced entry.
+func (s *scope) contains(diag Diagnostic) bool {
+	if diag.Line == 0 {
+		for _, reference := range s.references {
+			section, name, _ := strings.Cut(reference, ".")
+			// Schema errors name the path; other checks name the entry, e.g.
+			// "runner 'small' ..."
+			entry := fmt.Sprintf("%s '%s'", strings.TrimSuffix(section, "s"), name)
+			if strings.Contains(diag.Message, reference+".") || strings.Contains(diag.Message, reference+":") ||
+				strings.Contains(diag.Message, entry) {
+				return false
+			}
+		}
+		return true
+	}
+	for _, r := range s.ranges {
+		if diag.Line >= r[0] && diag.Line <= r[1] {
+			return true
+		}
+	}
+	return false
+}
+
+// addRange adds the lines spanned by a key and its value, and by the anchors
+// aliased within the value
+func (s *scope) addRange(key, value *yaml.Node, seen map[*yaml.Node]bool) {
+	if seen[value] {
+		return
+	}
+	seen[value] = true
+	start := value.Line
+	if key != nil {
+		start = key.Line
+	}
+	s.ranges = append(s.ranges, [2]int{start, lastLine(value)})
+
+	var walk func(n *yaml.Node)
+	walk = func(n *yaml.Node) {
+		if n.Kind == yaml.AliasNode && n.Alias != nil {
+			s.addRange(nil, n.Alias, seen)
+			return
+		}
+		for _, child := range n.Content {
+			walk(child)
+		}
+	}
+	walk(value)
+}
+
+// childNode returns the key and value of a mapping entry, or the item of a
+// sequence at a numeric index (with a nil key)
+func childNode(n *yaml.Node, segment string) (*yaml.Node, *yaml.Node) {
+	if n == nil {
+		return nil, nil
+	}
+	n = resolveAlias(n)
+	if n.Kind == yaml.SequenceNode {
+		index, err := strconv.Atoi(segment)
+		if err != nil || index < 0 || index >= len(n.Content) {
+			return nil, nil
+		}
+		return nil, n.Content[index]
+	}
+	return mappingKey(n, segment), mappingValue(n, segment)
+}
+
+// subMapping returns a mapping with only the given keys of n
+func subMapping(n *yaml.Node, keys []string) *yaml.Node {
+	result := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", Line: n.Line, Column: n.Column}
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if slices.Contains(keys, n.Content[i].Value) {
+			result.Content = append(result.Content, n.Content[i], n.Content[i+1])
+		}
+	}
+	return result
+}
+
+// fieldNode returns the value of a field of a mapping with aliases resolved,
+// including fields merged in with '<<', or nil
+func fieldNode(n *yaml.Node, field string) *yaml.Node {
+	n = resolveAlias(n)
+	if v := mappingValue(n, field); v != nil {
+		// Fields set directly override merged ones
+		return resolveAlias(v)
+	}
+	var value *yaml.Node
+	eachField(n, make(map[*yaml.Node]bool), func(key, v *yaml.Node) {
+		if key.Value == field && value == nil {
+			value = resolveAlias(v)
+		}
+	})
+	return value
+}
+
+// fieldValue returns the scalar value of a field of a mapping, including
+// fields merged in with '<<'
+func fieldValue(n *yaml.Node, field string) string {
+	if v := fieldNode(n, field); v != nil && v.Kind == yaml.ScalarNode {
+		return v.Value
+	}
+	return ""
+}
+
+// lastLine returns the last line spanned by a node, not following aliases
+func lastLine(n *yaml.Node) int {
+	last := n.Line + strings.Count(strings.TrimRight(n.Value, "\n"), "\n")
+	if n.Style&(yaml.LiteralStyle|yaml.FoldedStyle) != 0 {
+		// Block scalar content starts on the line after the indicator
+		last++
+	}
+	for _, child := range n.Content {
+		last = max(last, lastLine(child))
+	}
+	return last
+}
@@ -0,0 +1,50 @@
+package validate
+
+import (
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+)
+
+// checkPublicSSH warns about runners that explicitly enable SSH while keeping
+// a public IP address, which exposes port 22 of the instance to the internet
+func checkPublicSSH(yamlData any, root *yaml.Node, sourceName string) []Diagnostic {
+	var warnings []Diagnostic
+
+	data, ok := yamlData.(map[string]any)
+	if !ok {
+		return warnings
+	}
+	for _, runner := range runnerEntries(data, root) {
+		if !isTrue(runner.spec["ssh"]) || isTrue(runner.spec["private"]) {
+			continue
+		}
+
+		line, column := position(runner.key)
+		if sshKey := mappingKey(runner.node, "ssh"); sshKey != nil {
+			line, column = position(sshKey)
+		}
+		warnings = append(warnings, Diagnostic{
+			Path:     sourceName,
+			Line:     line,
+			Column:   column,
+			text:     message(RulePublicSSH, "runner", runner.name),
+			Severity: SeverityWarning,
+			RuleID:   RulePublicSSH,
+		})
+	}
+
+	return warnings
+}
+
+// isTrue reports whether a bool-or-string field is set to true
+func isTrue(value any) bool {
+	switch v := value.(type) {
+	case bool:
+		return v
+	case string:
+		b, err := strconv.ParseBool(v)
+		return err == nil && b
+	}
+	return false
+}
@@ -0,0 +1,587 @@
+package validate
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// checkPreinstallScripts reports syntax errors in the preinstall scripts of
+// runners and images, at the offending line of the script within the YAML
+// block. Scripts of Windows images, which are PowerShell, and scripts whose
+// shebang names another interpreter are not checked. With shellcheck set,
+// scripts without syntax errors are also checked with shellcheck if it is
+// installed, reporting its errors and warnings as warnings.
+func checkPreinstallScripts(ctx context.Context, yamlData any, root *yaml.Node, src []byte, sourceName string, shellcheck bool) []Diagnostic {
+	var diags []Diagnostic
+
+	data, ok := yamlData.(map[string]any)
+	if !ok {
+		return diags
+	}
+	images, _ := data["images"].(map[string]any)
+	index := newLineIndex(src)
+	if shellcheck {
+		if _, err := exec.LookPath("shellcheck"); err != nil {
+			shellcheck = false
+		}
+	}
+
+	check := func(kind, name, path string, n *yaml.Node) {
+		if n == nil || n.Kind != yaml.ScalarNode || !isShellScript(n.Value) {
+			return
+		}
+		report := func(line, column int, ruleID string, severity Severity, text messageText) {
+			diag := Diagnostic{
+				Path:      sourceName,
+				text:      text,
+				Severity:  severity,
+				RuleID:    ruleID,
+				FieldPath: path,
+			}
+			diag.Line, diag.Column = scriptPosition(index, n, line, column)
+			diags = append(diags, diag)
+		}
+
+		if issue := shellSyntax(n.Value); issue != nil {
+			params := append([]string{kind, name}, issue.params...)
+			if issue.openLine > 0 {
+				line, _ := scriptPosition(index, n, issue.openLine, 1)
+				params = append(params, "line", strconv.Itoa(line))
+			}
+			report(issue.line, issue.column, RulePreinstallShell, SeverityError, message(kindKey(RulePreinstallShell+"."+issue.kind, kind), params...))
+			return
+		}
+		if !shellcheck {
+			return
+		}
+		for _, comment := range runShellcheck(ctx, n.Value) {
+			report(comment.Line, comment.Column, RulePreinstallShellcheck, SeverityWarning, message(kindKey(RulePreinstallShellcheck, kind),
+				kind, name, "code", fmt.Sprintf("SC%d", comment.Code), "error", comment.Message))
+		}
+	}
+
+	for _, runner := range runnerEntries(data, root) {
+		if runner.node == nil || imagePlatform(runner.spec["image"], images) == "windows" {
+			continue
+		}
+		check("runner", runner.name, runner.path+".preinstall", fieldNode(runner.node, "preinstall"))
+	}
+	imagesNode := resolveAlias(mappingValue(root, "images"))
+	for _, name := range sortedKeys(images) {
+		image, _ := images[name].(map[string]any)
+		platform, _ := image["platform"].(string)
+		imageNode := resolveAlias(mappingValue(imagesNode, name))
+		if imageNode == nil || strings.EqualFold(platform, "windows") {
+			continue
+		}
+		check("image", name, "images."+name+".preinstall", fieldNode(imageNode, "preinstall"))
+	}
+
+	return diags
+}
+
+// isShellScript reports whether a script is run by a POSIX shell: it has no
+// shebang, or one naming sh, bash, dash or zsh
+func isShellScript(script string) bool {
+	first, _, _ := strings.Cut(strings.TrimLeft(script, " \t\n"), "\n")
+	if !strings.HasPrefix(first, "#!") {
+		return true
+	}
+	fields := strings.Fields(strings.TrimPrefix(first, "#!"))
+	if len(fields) == 0 {
+		return true
+	}
+	interpreter := fields[0]
+	if strings.HasSuffix(interpreter, "/env") && len(fields) > 1 {
+		interpreter = fields[1]
+	}
+	interpreter = interpreter[strings.LastIndex(interpreter, "/")+1:]
+	switch interpreter {
+	case "sh", "bash", "dash", "zsh":
+		return true
+	}
+	return false
+}
+
+// scriptPosition returns the position in the YAML source of a 1-based line
+// and column of the script held by a scalar. Lines of literal block scalars
+// map to their own line; other scalars only locate the first line of a
+// single-line plain scalar, and the scalar itself otherwise.
+func scriptPosition(index *lineIndex, n *yaml.Node, line, column int) (int, int) {
+	switch {
+	case n.Style&yaml.LiteralStyle != 0:
+		yamlLine := n.Line + line
+		if yamlLine > len(index.lines) {
+			return n.Line, n.Column
+		}
+		return yamlLine, blockIndent(index, n) + column
+	case n.Style == 0 && line == 1 && !strings.Contains(n.Value, "\n"):
+		return n.Line, n.Column + column - 1
+	}
+	return n.Line, n.Column
+}
+
+// blockIndent returns the indentation of the content of a block scalar: that
+// of its first non-blank line
+func blockIndent(index *lineIndex, n *yaml.Node) int {
+	for line := n.Line + 1; line <= len(index.lines); line++ {
+		text := strings.TrimRight(index.lines[line-1], "\r\n")
+		if trimmed := strings.TrimLeft(text, " "); trimmed != "" {
+			return len(text) - len(trimmed)
+		}
+	}
+	return 0
+}
+
+// shellcheckComment is a finding of shellcheck's json1 output format
+type shellcheckComment struct {
+	Line    int    `json:"line"`
+	Column  int    `json:"column"`
+	Level   string `json:"level"`
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// runShellcheck returns the errors and warnings shellcheck reports for a
+// script, or nothing if it cannot be run
+func runShellcheck(ctx context.Context, script string) []shellcheckComment {
+	cmd := exec.CommandContext(ctx, "shellcheck", "--format=json1", "--shell=bash", "--severity=warning", "-")
+	cmd.Stdin = strings.NewReader(script)
+	// shellcheck exits with 1 when it reports findings
+	out, _ := cmd.Output()
+	var result struct {
+		Comments []shellcheckComment `json:"comments"`
+	}
+	if err := json.Unmarshal(out, &result); err != nil {
+		return nil
+	}
+	return result.Comments
+}
+
+// shellIssue is a syntax error of a shell script, at a 1-based line and
+// column of the script. kind is the message variant reporting it, with
+// params; openLine is the line of the construct the error is about, if any.
+type shellIssue struct {
+	line, column int
+	kind         string
+	params       []string
+	openLine     int
+}
+
+// shellBlock is a compound command being parsed, such as an if or a loop
+type shellBlock struct {
+	keyword      string
+	line, column int
+}
+
+// shellLoops names the keywords opening loops in messages
+const shellLoops = "for/while/until"
+
+// shellClosers maps the keywords opening compound commands to those closing
+// them
+var shellClosers = map[string]string{
+	"if": "fi", "for": "done", "while": "done", "until": "done", "select": "done", "case": "esac", "{": "}",
+}
+
+// shellScanner is a lightweight shell syntax checker. It tracks quotes,
+// substitutions, here-documents and compound commands, which account for
+// most syntax errors of preinstall scripts, without parsing commands.
+type shellScanner struct {
+	src          []rune
+	pos          int
+	line, column int
+	issue        *shellIssue
+	// heredocs are the delimiters of the here-documents starting after the
+	// current line, and the lines they were declared at
+	heredocs []shellHeredoc
+}
+
+type shellHeredoc struct {
+	delimiter string
+	stripTabs bool
+	line      int
+}
+
+// shellSyntax returns the first syntax error of a shell script, or nil
+func shellSyntax(script string) *shellIssue {
+	s := &shellScanner{src: []rune(script), line: 1, column: 1}
+	s.commands(0)
+	return s.issue
+}
+
+func (s *shellScanner) peek(offset int) rune {
+	if s.pos+offset < len(s.src) {
+		return s.src[s.pos+offset]
+	}
+	return 0
+}
+
+func (s *shellScanner) done() bool {
+	return s.issue != nil || s.pos >= len(s.src)
+}
+
+func (s *shellScanner) next() rune {
+	r := s.src[s.pos]
+	s.pos++
+	if r == '\n' {
+		s.line++
+		s.column = 1
+	} else {
+		s.column++
+	}
+	return r
+}
+
+func (s *shellScanner) fail(line, column int, kind string, params ...string) {
+	if s.issue == nil {
+		s.issue = &shellIssue{line: line, column: column, kind: kind, params: params}
+	}
+}
+
+// commands scans commands up to closer, the rune ending a command
+// substitution, or to the end of the script if 0. It reports whether closer
+// was found.
+func (s *shellScanner) commands(closer rune) bool {
+	var blocks []shellBlock
+	commandStart := true
+	parens := 0
+	for !s.done() {
+		r := s.peek(0)
+		switch {
+		case r == ')' && parens == 0 && closer == ')' && !inCase(blocks):
+			s.next()
+			return s.closed(blocks)
+		case r == '\n':
+			s.next()
+			s.readHeredocs()
+			commandStart = true
+		case r == ' ' || r == '\t' || r == '\r':
+			s.next()
+		case r == '#':
+			for !s.done() && s.peek(0) != '\n' {
+				s.next()
+			}
+		case r == ';' || r == '&' || r == '|':
+			s.next()
+			if s.peek(0) == r {
+				s.next()
+			}
+			commandStart = true
+		case r == '(':
+			s.next()
+			parens++
+			commandStart = true
+		case r == ')':
+			// Closes a subshell, or a case pattern
+			s.next()
+			if parens > 0 {
+				parens--
+			}
+			commandStart = true
+		case r == '<' || r == '>':
+			s.redirection()
+		default:
+			line, column := s.line, s.column
+			word, quoted := s.word()
+			if !commandStart || quoted {
+				continue
+			}
+			commandStart = false
+			switch word {
+			case "if", "while", "until", "{":
+				blocks = append(blocks, shellBlock{word, line, column})
+				commandStart = true
+			case "!":
+				commandStart = true
+			case "for", "select", "case":
+				blocks = append(blocks, shellBlock{word, line, column})
+			case "then", "elif", "else", "do":
+				opener, expected := "if", "fi"
+				if word == "do" {
+					opener, expected = shellLoops, "done"
+				}
+				if len(blocks) == 0 || shellClosers[blocks[len(blocks)-1].keyword] != expected {
+					s.fail(line, column, "unexpected", "keyword", word, "opener", opener)
+				}
+				commandStart = true
+			case "fi", "done", "esac", "}":
+				if len(blocks) == 0 {
+					opener := map[string]string{"fi": "if", "done": shellLoops, "esac": "case", "}": "{"}[word]
+					s.fail(line, column, "unexpected", "keyword", word, "opener", opener)
+					continue
+				}
+				block := blocks[len(blocks)-1]
+				if expected := shellClosers[block.keyword]; expected != word {
+					s.fail(line, column, "mismatch", "keyword", word, "opener", block.keyword, "closer", expected)
+					s.issue.openLine = block.line
+					continue
+				}
+				blocks = blocks[:len(blocks)-1]
+			default:
+				// Assignments before a command keep the command start
+				name, _, assignment := strings.Cut(word, "=")
+				commandStart = assignment && isShellName(name)
+			}
+		}
+	}
+	if s.issue != nil || closer != 0 {
+		return false
+	}
+	s.readHeredocs()
+	return s.closed(blocks)
+}
+
+// inCase reports whether the innermost compound command of blocks is a case,
+// whose patterns end with ')'
+func inCase(blocks []shellBlock) bool {
+	return len(blocks) > 0 && blocks[len(blocks)-1].keyword == "case"
+}
+
+// closed reports the first compound command of blocks left open, and
+// whether there is none
+func (s *shellScanner) closed(blocks []shellBlock) bool {
+	if len(blocks) == 0 {
+		return true
+	}
+	block := blocks[0]
+	s.fail(block.line, block.column, "unclosed", "keyword", block.keyword, "closer", shellClosers[block.keyword])
+	return false
+}
+
+// word scans a word, returning its text and whether part of it is quoted
+// or substituted
+func (s *shellScanner) word() (string, bool) {
+	var text strings.Builder
+	quoted := false
+	for !s.done() {
+		r := s.peek(0)
+		if strings.ContainsRune(" \t\r\n;&|<>()", r) {
+			break
+		}
+		switch r {
+		case '\\':
+			s.next()
+			if !s.done() {
+				s.next()
+			}
+			quoted = true
+		case '\'':
+			quoted = true
+			s.singleQuoted()
+		case '"':
+			quoted = true
+			s.doubleQuoted()
+		case '`':
+			quoted = true
+			s.backquoted()
+		case '$':
+			if p := s.peek(1); p == '(' || p == '{' {
+				quoted = true
+				s.dollar()
+				continue
+			}
+			text.WriteRune(s.next())
+		default:
+			text.WriteRune(s.next())
+		}
+	}
+	return text.String(), quoted
+}
+
+func (s *shellScanner) singleQuoted() {
+	line, column := s.line, s.column
+	s.next()
+	for !s.done() {
+		if s.next() == '\'' {
+			return
+		}
+	}
+	s.fail(line, column, "quote", "quote", "'")
+}
+
+func (s *shellScanner) doubleQuoted() {
+	line, column := s.line, s.column
+	s.next()
+	for !s.done() {
+		switch s.peek(0) {
+		case '\\':
+			s.next()
+			if !s.done() {
+				s.next()
+			}
+		case '"':
+			s.next()
+			return
+		case '`':
+			s.backquoted()
+		case '$':
+			if p := s.peek(1); p == '(' || p == '{' {
+				s.dollar()
+				continue
+			}
+			s.next()
+		default:
+			s.next()
+		}
+	}
+	s.fail(line, column, "quote", "quote", `"`)
+}
+
+func (s *shellScanner) backquoted() {
+	line, column := s.line, s.column
+	s.next()
+	for !s.done() {
+		switch s.next() {
+		case '\\':
+			if !s.done() {
+				s.next()
+			}
+		case '`':
+			return
+		}
+	}
+	s.fail(line, column, "quote", "quote", "`")
+}
+
+// dollar scans a command substitution $(...), an arithmetic expansion
+// $((...)) or a parameter expansion ${...}
+func (s *shellScanner) dollar() {
+	line, column := s.line, s.column
+	s.next()
+	switch {
+	case s.peek(0) == '(' && s.peek(1) == '(':
+		s.next()
+		s.next()
+		depth := 0
+		for !s.done() {
+			switch s.next() {
+			case '(':
+				depth++
+			case ')':
+				if depth > 0 {
+					depth--
+				} else if s.peek(0) == ')' {
+					s.next()
+					return
+				}
+			}
+		}
+		s.fail(line, column, "substitution", "token", "$((")
+	case s.peek(0) == '(':
+		s.next()
+		if !s.commands(')') && s.issue == nil {
+			s.fail(line, column, "substitution", "token", "$(")
+		}
+	default:
+		s.next()
+		for !s.done() {
+			switch s.peek(0) {
+			case '}':
+				s.next()
+				return
+			case '\\':
+				s.next()
+				if !s.done() {
+					s.next()
+				}
+			case '\'':
+				s.singleQuoted()
+			case '"':
+				s.doubleQuoted()
+			case '`':
+				s.backquoted()
+			case '$':
+				if p := s.peek(1); p == '(' || p == '{' {
+					s.dollar()
+					continue
+				}
+				s.next()
+			default:
+				s.next()
+			}
+		}
+		s.fail(line, column, "substitution", "token", "${")
+	}
+}
+
+// redirection scans a redirection operator, recording the delimiter of a
+// here-document
+func (s *shellScanner) redirection() {
+	line := s.line
+	first := s.next()
+	if first != '<' || s.peek(0) != '<' {
+		for !s.done() && strings.ContainsRune("<>&|", s.peek(0)) {
+			s.next()
+		}
+		return
+	}
+	s.next()
+	if s.peek(0) == '<' {
+		// A here-string
+		s.next()
+		return
+	}
+	heredoc := shellHeredoc{line: line}
+	if s.peek(0) == '-' {
+		s.next()
+		heredoc.stripTabs = true
+	}
+	for s.peek(0) == ' ' || s.peek(0) == '\t' {
+		s.next()
+	}
+	var delimiter strings.Builder
+	for !s.done() && !strings.ContainsRune(" \t\r\n;&|<>()", s.peek(0)) {
+		if r := s.next(); r != '\'' && r != '"' && r != '\\' {
+			delimiter.WriteRune(r)
+		}
+	}
+	if delimiter.Len() > 0 {
+		heredoc.delimiter = delimiter.String()
+		s.heredocs = append(s.heredocs, heredoc)
+	}
+}
+
+// readHeredocs skips the bodies of the here-documents declared on the line
+// just ended
+func (s *shellScanner) readHeredocs() {
+	for _, heredoc := range s.heredocs {
+		terminated := false
+		for !terminated && s.pos < len(s.src) {
+			start := s.pos
+			for s.pos < len(s.src) && s.src[s.pos] != '\n' {
+				s.next()
+			}
+			text := strings.TrimSuffix(string(s.src[start:s.pos]), "\r")
+			if heredoc.stripTabs {
+				text = strings.TrimLeft(text, "\t")
+			}
+			terminated = text == heredoc.delimiter
+			if s.pos < len(s.src) {
+				s.next()
+			}
+		}
+		if !terminated {
+			s.fail(heredoc.line, 1, "heredoc", "delimiter", heredoc.delimiter)
+		}
+	}
+	s.heredocs = nil
+}
+
+// isShellName reports whether name is a valid shell variable name
+func isShellName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i, r := range name {
+		if r != '_' && !(r >= 'a' && r <= 'z') && !(r >= 'A' && r <= 'Z') && (i == 0 || r < '0' || r > '9') {
+			return false
+		}
+	}
+	return true
+}
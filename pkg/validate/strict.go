@@ -0,0 +1,137 @@
+package validate
+
+import (
+	"maps"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// checkUnknownFields reports top-level keys that the schema does not define.
+// The schema accepts them for forward compatibility; strict validation
+// catches typos such as "runner:" for "runners:". Otherwise, only keys that
+// are likely misspellings of a known field are reported, as warnings. x-*
+// blocks, which only hold anchors, are allowed.
+func checkUnknownFields(root *yaml.Node, sourceName string, known map[string]bool, strict bool) []Diagnostic {
+	var diags []Diagnostic
+	if root == nil {
+		return diags
+	}
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		key := root.Content[i]
+		if known[key.Value] || key.Value == "_extends" || strings.HasPrefix(key.Value, "x-") || key.Value == "<<" {
+			continue
+		}
+		diag := Diagnostic{
+			Path:      sourceName,
+			Severity:  SeverityError,
+			RuleID:    RuleUnknownField,
+			FieldPath: key.Value,
+		}
+		diag.Line, diag.Column = position(key)
+		suggestion := suggestField(root, key.Value, fieldCandidates(key.Value, known, nil))
+		switch {
+		case strict && suggestion != "":
+			diag.text = message(RuleUnknownField+".suggest", "field", key.Value, "suggestion", suggestion)
+		case strict:
+			diag.text = message(RuleUnknownField, "field", key.Value)
+		case suggestion != "":
+			diag.Severity = SeverityWarning
+			diag.RuleID = RuleFieldTypo
+			diag.text = message(RuleFieldTypo, "field", key.Value, "suggestion", suggestion)
+		default:
+			continue
+		}
+		diags = append(diags, diag)
+	}
+	return diags
+}
+
+// reportNotAllowedFields replaces the schema errors about fields that runner,
+// image, pool and schedule entries do not define, given the fields of their
+// definitions, with unknown-field errors at the field, suggesting the field
+// it is likely a misspelling of
+func reportNotAllowedFields(root *yaml.Node, sourceName string, definitions map[string][]string, schemaErrors []Diagnostic) []Diagnostic {
+	for i, diag := range schemaErrors {
+		if diag.RuleID != RuleSchema {
+			continue
+		}
+		candidates := fieldCandidates(diag.FieldPath, nil, definitions)
+		path := strings.Split(diag.FieldPath, ".")
+		if len(candidates) == 0 || slices.Contains(candidates, path[len(path)-1]) {
+			continue
+		}
+		parent := root
+		for _, segment := range path[:len(path)-1] {
+			_, parent = childNode(parent, segment)
+		}
+		field := path[len(path)-1]
+		key := mergedKey(parent, field)
+		if key == nil {
+			continue
+		}
+		owner := strings.Join(path[:len(path)-1], ".")
+		updated := Diagnostic{
+			Path:      sourceName,
+			Severity:  SeverityError,
+			RuleID:    RuleUnknownField,
+			FieldPath: diag.FieldPath,
+			text:      message(RuleUnknownField+".nested", "field", field, "owner", owner),
+		}
+		updated.Line, updated.Column = position(key)
+		if suggestion := suggestField(parent, field, candidates); suggestion != "" {
+			updated.text = message(RuleUnknownField+".nested-suggest", "field", field, "owner", owner, "suggestion", suggestion)
+		}
+		schemaErrors[i] = updated
+	}
+	return schemaErrors
+}
+
+// mergedKey returns the key of a field of a mapping, including fields merged
+// in with '<<', or nil
+func mergedKey(n *yaml.Node, field string) *yaml.Node {
+	if key := mappingKey(resolveAlias(n), field); key != nil {
+		return key
+	}
+	var found *yaml.Node
+	if n != nil {
+		eachField(n, make(map[*yaml.Node]bool), func(key, _ *yaml.Node) {
+			if key.Value == field && found == nil {
+				found = key
+			}
+		})
+	}
+	return found
+}
+
+// fieldCandidates returns the fields the schema defines where a field path
+// points: the known top-level fields, or the fields of a runner, image, pool,
+// schedule entry or schedule match among definitions
+func fieldCandidates(fieldPath string, known map[string]bool, definitions map[string][]string) []string {
+	path := strings.Split(fieldPath, ".")
+	var definition string
+	switch {
+	case len(path) == 1:
+		return slices.Sorted(maps.Keys(known))
+	case len(path) == 3:
+		definition = sectionDefinitions[path[0]]
+	case len(path) == 5 && path[0] == "pools" && path[2] == "schedule":
+		definition = "#PoolSchedule"
+	case len(path) == 6 && path[0] == "pools" && path[2] == "schedule" && path[4] == "match":
+		definition = "#ScheduleMatch"
+	}
+	return definitions[definition]
+}
+
+// suggestField returns the field a key of a mapping is likely a misspelling
+// of among candidates, leaving out fields the mapping already sets, or ""
+func suggestField(parent *yaml.Node, name string, candidates []string) string {
+	var unset []string
+	for _, candidate := range candidates {
+		if mergedKey(parent, candidate) == nil {
+			unset = append(unset, candidate)
+		}
+	}
+	return closestName(name, unset)
+}
@@ -0,0 +1,137 @@
+package validate
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/runs-on/config/pkg/config"
+	"gopkg.in/yaml.v3"
+)
+
+// AWS tag restrictions
+const (
+	maxTagKeyLength   = 128
+	maxTagValueLength = 256
+	maxResourceTags   = 50
+	// runsOnTags is the room left for the tags RunsOn adds to every instance
+	// it launches, on top of the runner tags
+	runsOnTags = 10
+)
+
+// tagCharacter matches the characters AWS allows in tag keys and values
+var tagCharacter = regexp.MustCompile(`[\p{L}\p{Z}\p{N}_.:/=+\-@]`)
+
+// checkTags reports runner tags that are not of the form Key:Value, and
+// runner and image tags that AWS would reject: keys and values that are too
+// long, contain other characters than AWS allows or use the reserved aws:
+// prefix, and resources with more tags than fit the per-resource limit
+func checkTags(yamlData any, root *yaml.Node, sourceName string) []Diagnostic {
+	var errors []Diagnostic
+
+	data, ok := yamlData.(map[string]any)
+	if !ok {
+		return errors
+	}
+	report := func(n *yaml.Node, ruleID string, text messageText) {
+		line, column := position(n)
+		errors = append(errors, Diagnostic{
+			Path:     sourceName,
+			Line:     line,
+			Column:   column,
+			text:     text,
+			Severity: SeverityError,
+			RuleID:   ruleID,
+		})
+	}
+
+	for _, runner := range runnerEntries(data, root) {
+		tags := config.Strings(runner.spec["tags"])
+		if len(tags) == 0 {
+			continue
+		}
+		tagsNode := runner.key
+		if runner.node != nil {
+			if n := fieldNode(runner.node, "tags"); n != nil {
+				tagsNode = n
+			}
+		}
+		for _, tag := range tags {
+			// Point at the list item if there is one
+			n := tagsNode
+			if tagsNode != nil && tagsNode.Kind == yaml.SequenceNode {
+				for _, item := range tagsNode.Content {
+					if item.Value == tag {
+						n = item
+						break
+					}
+				}
+			}
+			key, value, ok := strings.Cut(tag, ":")
+			if !ok || key == "" {
+				report(n, RuleTagFormat, message(RuleTagFormat, "runner", runner.name, "tag", tag))
+				continue
+			}
+			// Runner tags are split at the first ':', so a reserved key
+			// such as aws:owner ends up as the key 'aws'
+			if strings.EqualFold(key, "aws") {
+				report(n, RuleTagFormat, message(RuleTagFormat+".reserved", "runner", runner.name, "key", tag))
+			}
+			checkTag("runner", runner.name, key, value, func(ruleID string, text messageText) {
+				report(n, ruleID, text)
+			})
+		}
+		if limit := maxResourceTags - runsOnTags; len(tags) > limit {
+			report(tagsNode, RuleTagLimit, message(RuleTagLimit+".count",
+				"runner", runner.name, "count", strconv.Itoa(len(tags)), "max", strconv.Itoa(limit)))
+		}
+	}
+
+	images, _ := data["images"].(map[string]any)
+	imagesNode := resolveAlias(mappingValue(root, "images"))
+	for _, name := range sortedKeys(images) {
+		image, _ := images[name].(map[string]any)
+		tags, _ := image["tags"].(map[string]any)
+		if len(tags) == 0 {
+			continue
+		}
+		imageNode := resolveAlias(mappingValue(imagesNode, name))
+		var tagsNode *yaml.Node
+		if imageNode != nil {
+			tagsNode = fieldNode(imageNode, "tags")
+		}
+		for _, key := range sortedKeys(tags) {
+			value, _ := tags[key].(string)
+			checkTag("image", name, key, value, func(ruleID string, text messageText) {
+				report(mappingKey(tagsNode, key), ruleID, text)
+			})
+		}
+		if len(tags) > maxResourceTags {
+			report(mappingKey(imagesNode, name), RuleTagLimit, message(RuleTagLimit+".count.image",
+				"image", name, "count", strconv.Itoa(len(tags)), "max", strconv.Itoa(maxResourceTags)))
+		}
+	}
+
+	return errors
+}
+
+// checkTag reports the AWS restrictions a tag of the entry of kind named name
+// breaks
+func checkTag(kind, name, key, value string, report func(ruleID string, text messageText)) {
+	if strings.HasPrefix(strings.ToLower(key), "aws:") {
+		report(RuleTagFormat, message(kindKey(RuleTagFormat+".reserved", kind), kind, name, "key", key))
+	}
+	if invalid := tagCharacter.ReplaceAllString(key+value, ""); invalid != "" {
+		character, _ := utf8.DecodeRuneInString(invalid)
+		report(RuleTagFormat, message(kindKey(RuleTagFormat+".characters", kind), kind, name, "key", key, "character", string(character)))
+	}
+	if length := utf8.RuneCountInString(key); length > maxTagKeyLength {
+		report(RuleTagLimit, message(kindKey(RuleTagLimit, kind),
+			kind, name, "key", key, "length", strconv.Itoa(length), "max", strconv.Itoa(maxTagKeyLength)))
+	}
+	if length := utf8.RuneCountInString(value); length > maxTagValueLength {
+		report(RuleTagLimit, message(kindKey(RuleTagLimit+".value", kind),
+			kind, name, "key", key, "length", strconv.Itoa(length), "max", strconv.Itoa(maxTagValueLength)))
+	}
+}
@@ -0,0 +1,146 @@
+package validate
+
+import (
+	"strings"
+	"time"
+	// Pool timezones are checked against the tz database embedded in the
+	// binary, so that results do not depend on the host
+	_ "time/tzdata"
+
+	"gopkg.in/yaml.v3"
+)
+
+// legacyTimezones maps the backward-compatibility links of the tz database
+// that are still commonly found in configs to the zones they link to
+var legacyTimezones = map[string]string{
+	"America/Argentina/ComodRivadavia": "America/Argentina/Catamarca",
+	"America/Buenos_Aires":             "America/Argentina/Buenos_Aires",
+	"America/Catamarca":                "America/Argentina/Catamarca",
+	"America/Cordoba":                  "America/Argentina/Cordoba",
+	"America/Fort_Wayne":               "America/Indiana/Indianapolis",
+	"America/Godthab":                  "America/Nuuk",
+	"America/Indianapolis":             "America/Indiana/Indianapolis",
+	"America/Knox_IN":                  "America/Indiana/Knox",
+	"America/Louisville":               "America/Kentucky/Louisville",
+	"America/Mendoza":                  "America/Argentina/Mendoza",
+	"America/Montreal":                 "America/Toronto",
+	"America/Shiprock":                 "America/Denver",
+	"America/Virgin":                   "America/St_Thomas",
+	"Asia/Calcutta":                    "Asia/Kolkata",
+	"Asia/Chongqing":                   "Asia/Shanghai",
+	"Asia/Chungking":                   "Asia/Shanghai",
+	"Asia/Dacca":                       "Asia/Dhaka",
+	"Asia/Harbin":                      "Asia/Shanghai",
+	"Asia/Istanbul":                    "Europe/Istanbul",
+	"Asia/Katmandu":                    "Asia/Kathmandu",
+	"Asia/Macao":                       "Asia/Macau",
+	"Asia/Rangoon":                     "Asia/Yangon",
+	"Asia/Saigon":                      "Asia/Ho_Chi_Minh",
+	"Asia/Tel_Aviv":                    "Asia/Jerusalem",
+	"Asia/Thimbu":                      "Asia/Thimphu",
+	"Asia/Ujung_Pandang":               "Asia/Makassar",
+	"Asia/Ulan_Bator":                  "Asia/Ulaanbaatar",
+	"Atlantic/Faeroe":                  "Atlantic/Faroe",
+	"Australia/ACT":                    "Australia/Sydney",
+	"Australia/Canberra":               "Australia/Sydney",
+	"Australia/NSW":                    "Australia/Sydney",
+	"Australia/North":                  "Australia/Darwin",
+	"Australia/Queensland":             "Australia/Brisbane",
+	"Australia/South":                  "Australia/Adelaide",
+	"Australia/Tasmania":               "Australia/Hobart",
+	"Australia/Victoria":               "Australia/Melbourne",
+	"Australia/West":                   "Australia/Perth",
+	"Brazil/East":                      "America/Sao_Paulo",
+	"Brazil/West":                      "America/Manaus",
+	"Canada/Atlantic":                  "America/Halifax",
+	"Canada/Central":                   "America/Winnipeg",
+	"Canada/Eastern":                   "America/Toronto",
+	"Canada/Mountain":                  "America/Edmonton",
+	"Canada/Newfoundland":              "America/St_Johns",
+	"Canada/Pacific":                   "America/Vancouver",
+	"Chile/Continental":                "America/Santiago",
+	"Cuba":                             "America/Havana",
+	"Egypt":                            "Africa/Cairo",
+	"Eire":                             "Europe/Dublin",
+	"Etc/UCT":                          "Etc/UTC",
+	"Europe/Belfast":                   "Europe/London",
+	"Europe/Kiev":                      "Europe/Kyiv",
+	"Europe/Nicosia":                   "Asia/Nicosia",
+	"GB":                               "Europe/London",
+	"GB-Eire":                          "Europe/London",
+	"Hongkong":                         "Asia/Hong_Kong",
+	"Iceland":                          "Atlantic/Reykjavik",
+	"Iran":                             "Asia/Tehran",
+	"Israel":                           "Asia/Jerusalem",
+	"Jamaica":                          "America/Jamaica",
+	"Japan":                            "Asia/Tokyo",
+	"Libya":                            "Africa/Tripoli",
+	"Mexico/BajaNorte":                 "America/Tijuana",
+	"Mexico/BajaSur":                   "America/Mazatlan",
+	"Mexico/General":                   "America/Mexico_City",
+	"NZ":                               "Pacific/Auckland",
+	"Navajo":                           "America/Denver",
+	"PRC":                              "Asia/Shanghai",
+	"Pacific/Samoa":                    "Pacific/Pago_Pago",
+	"Poland":                           "Europe/Warsaw",
+	"Portugal":                         "Europe/Lisbon",
+	"ROC":                              "Asia/Taipei",
+	"ROK":                              "Asia/Seoul",
+	"Singapore":                        "Asia/Singapore",
+	"Turkey":                           "Europe/Istanbul",
+	"UCT":                              "Etc/UTC",
+	"US/Alaska":                        "America/Anchorage",
+	"US/Aleutian":                      "America/Adak",
+	"US/Arizona":                       "America/Phoenix",
+	"US/Central":                       "America/Chicago",
+	"US/East-Indiana":                  "America/Indiana/Indianapolis",
+	"US/Eastern":                       "America/New_York",
+	"US/Hawaii":                        "Pacific/Honolulu",
+	"US/Indiana-Starke":                "America/Indiana/Knox",
+	"US/Michigan":                      "America/Detroit",
+	"US/Mountain":                      "America/Denver",
+	"US/Pacific":                       "America/Los_Angeles",
+	"US/Samoa":                         "Pacific/Pago_Pago",
+	"Universal":                        "Etc/UTC",
+	"W-SU":                             "Europe/Moscow",
+	"Zulu":                             "Etc/UTC",
+}
+
+// checkTimezones reports pool timezones that are not zones of the tz
+// database, and warns about legacy aliases of a zone, suggesting the
+// current name
+func checkTimezones(root *yaml.Node, sourceName string) []Diagnostic {
+	var diags []Diagnostic
+
+	pools := resolveAlias(mappingValue(root, "pools"))
+	if pools == nil || pools.Kind != yaml.MappingNode {
+		return diags
+	}
+	for i := 0; i+1 < len(pools.Content); i += 2 {
+		poolName := pools.Content[i].Value
+		n := fieldNode(pools.Content[i+1], "timezone")
+		if n == nil || n.Kind != yaml.ScalarNode {
+			continue
+		}
+		diag := Diagnostic{
+			Path:     sourceName,
+			Line:     n.Line,
+			Column:   n.Column,
+			Severity: SeverityError,
+			RuleID:   RuleTimezone,
+		}
+		zone := n.Value
+		if _, err := time.LoadLocation(zone); err != nil || zone == "" || zone == "Local" || strings.TrimSpace(zone) != zone {
+			diag.text = message(RuleTimezone, "pool", poolName, "timezone", zone)
+		} else if current, ok := legacyTimezones[zone]; ok {
+			diag.Severity = SeverityWarning
+			diag.RuleID = RuleTimezoneLegacy
+			diag.text = message(RuleTimezoneLegacy, "pool", poolName, "timezone", zone, "current", current)
+		} else {
+			continue
+		}
+		diags = append(diags, diag)
+	}
+
+	return diags
+}
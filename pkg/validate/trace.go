@@ -0,0 +1,52 @@
+package validate
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// tracer logs the duration of the steps of a validation at debug level. A nil
+// tracer logs nothing.
+type tracer struct {
+	logger     *slog.Logger
+	sourceName string
+	start      time.Time
+	last       time.Time
+}
+
+// newTracer returns a tracer for the validation of sourceName, or nil if opts
+// has no logger or it does not log debug messages
+func newTracer(ctx context.Context, opts Options, sourceName string) *tracer {
+	if opts.Logger == nil || !opts.Logger.Enabled(ctx, slog.LevelDebug) {
+		return nil
+	}
+	now := time.Now()
+	return &tracer{logger: opts.Logger, sourceName: sourceName, start: now, last: now}
+}
+
+// step logs the time since the previous step (or the start) as that of step,
+// e.g. a rule ID, which reported diags diagnostics
+func (t *tracer) step(ctx context.Context, step string, diags int) {
+	if t == nil {
+		return
+	}
+	now := time.Now()
+	t.logger.DebugContext(ctx, "validation step",
+		slog.String("source", t.sourceName),
+		slog.String("step", step),
+		slog.Duration("duration", now.Sub(t.last)),
+		slog.Int("diagnostics", diags))
+	t.last = now
+}
+
+// done logs the total duration of the validation
+func (t *tracer) done(ctx context.Context, diags int) {
+	if t == nil {
+		return
+	}
+	t.logger.DebugContext(ctx, "validated",
+		slog.String("source", t.sourceName),
+		slog.Duration("duration", time.Since(t.start)),
+		slog.Int("diagnostics", diags))
+}
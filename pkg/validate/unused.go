@@ -0,0 +1,91 @@
+package validate
+
+import (
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// checkUnused reports top-level x-* blocks, other than x-schema-version, and
+// anchors that are never referenced by an alias. Aliases inside unused x-* blocks do not count, so
+// an anchor only used by dead blocks is reported too.
+func checkUnused(root *yaml.Node, sourceName string) []Diagnostic {
+	var warnings []Diagnostic
+	if root == nil {
+		return warnings
+	}
+
+	unusedBlocks := make(map[*yaml.Node]bool)
+	var aliased map[*yaml.Node]bool
+	for changed := true; changed; {
+		changed = false
+		aliased = make(map[*yaml.Node]bool)
+		for i := 0; i+1 < len(root.Content); i += 2 {
+			if !unusedBlocks[root.Content[i]] {
+				collectAliased(root.Content[i+1], aliased)
+			}
+		}
+		for i := 0; i+1 < len(root.Content); i += 2 {
+			key := root.Content[i]
+			if strings.HasPrefix(key.Value, "x-") && !slices.Contains(schemaVersionFields, key.Value) && !unusedBlocks[key] && !containsAliased(root.Content[i+1], aliased) {
+				unusedBlocks[key] = true
+				changed = true
+			}
+		}
+	}
+
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		key, value := root.Content[i], root.Content[i+1]
+		if unusedBlocks[key] {
+			warnings = append(warnings, Diagnostic{
+				Path:     sourceName,
+				Line:     key.Line,
+				Column:   key.Column,
+				text:     message(RuleUnusedExtension, "field", key.Value),
+				Severity: SeverityWarning,
+				RuleID:   RuleUnusedExtension,
+			})
+			continue
+		}
+		walkNodes(value, func(n *yaml.Node) {
+			if n.Anchor != "" && !aliased[n] {
+				warnings = append(warnings, Diagnostic{
+					Path:     sourceName,
+					Line:     n.Line,
+					Column:   n.Column,
+					text:     message(RuleUnusedAnchor, "anchor", n.Anchor),
+					Severity: SeverityWarning,
+					RuleID:   RuleUnusedAnchor,
+				})
+			}
+		})
+	}
+	return warnings
+}
+
+// collectAliased adds the nodes aliases under n refer to
+func collectAliased(n *yaml.Node, aliased map[*yaml.Node]bool) {
+	walkNodes(n, func(n *yaml.Node) {
+		if n.Kind == yaml.AliasNode && n.Alias != nil {
+			aliased[n.Alias] = true
+		}
+	})
+}
+
+// containsAliased reports whether n or a node under it is an alias target
+func containsAliased(n *yaml.Node, aliased map[*yaml.Node]bool) bool {
+	found := false
+	walkNodes(n, func(n *yaml.Node) {
+		found = found || aliased[n]
+	})
+	return found
+}
+
+// walkNodes calls fn for n and every node under it, without following aliases
+func walkNodes(n *yaml.Node, fn func(*yaml.Node)) {
+	fn(n)
+	for _, child := range n.Content {
+		walkNodes(child, fn)
+	}
+}
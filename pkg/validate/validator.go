@@ -5,12 +5,21 @@ import (
 	"embed"
 	"fmt"
 	"io"
+	"log/slog"
+	"maps"
 	"os"
+	"slices"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 
 	"cuelang.org/go/cue"
 	"cuelang.org/go/cue/cuecontext"
 	"cuelang.org/go/cue/errors"
+	"github.com/runs-on/config/pkg/advisory"
+	"github.com/runs-on/config/pkg/config"
+	"github.com/runs-on/config/pkg/extends"
 	"gopkg.in/yaml.v3"
 )
 
@@ -24,6 +33,33 @@ type Diagnostic struct {
 	Column   int
 	Message  string
 	Severity Severity
+	// RuleID identifies the check that produced the diagnostic (see
+	// LookupRule), or the matched advisory. It is set on every diagnostic and
+	// stable across releases, so it can key suppressions and baselines
+	// instead of the message.
+	RuleID string
+	// EndLine and EndColumn locate the end of the reported node, with
+	// EndColumn just past its last character. They are zero when unknown;
+	// EndColumn is also zero for nodes spanning several lines.
+	EndLine   int
+	EndColumn int
+	// Offset and EndOffset are the byte offsets in the file of the start and
+	// the end (exclusive) of the reported range, for editors and fixes. When
+	// the end column is unknown, the range extends to the end of the end
+	// line, or of the start line. Both are zero when Line is zero.
+	Offset    int
+	EndOffset int
+	// FieldPath is the dotted path of the config field the diagnostic is
+	// about, with list indexes as segments, e.g. "runners.my-runner.cpu" or
+	// "pools.main.schedule.0.hot". It is empty for diagnostics about the
+	// file as a whole, such as YAML syntax errors.
+	FieldPath string
+	// Fix is a change resolving the diagnostic, for deprecated fields and
+	// likely typos, or nil
+	Fix *SuggestedFix
+
+	// text is the catalog message Message renders, if any, for Localize
+	text messageText
 }
 
 // Severity indicates the severity of a diagnostic
@@ -36,65 +72,655 @@ const (
 
 // ValidateFile validates a runs-on.yml file at the given path
 func ValidateFile(ctx context.Context, filePath string) ([]Diagnostic, error) {
-	file, err := os.Open(filePath)
-	if err != nil {
-		return nil, fmt.Errorf("failed to open file: %w", err)
+	return ValidateFileWithOptions(ctx, filePath, Options{})
+}
+
+// ValidateFileWithOptions validates a runs-on.yml file at the given path with
+// the given options
+func ValidateFileWithOptions(ctx context.Context, filePath string, opts Options) ([]Diagnostic, error) {
+	return validateFile(ctx, filePath, os.ReadFile, opts, nil)
+}
+
+// Options configures validation
+type Options struct {
+	// DisableLocalExtends stops local _extends files from being read. Set it
+	// when validating untrusted input, e.g. in server mode. Pool runner
+	// references are then not checked for configs with a local _extends, as
+	// the runners may be defined in the extended file.
+	DisableLocalExtends bool
+	// FetchExtends returns the config a repository _extends reference such
+	// as ".github-private" points to, e.g. through the GitHub API. When set,
+	// repository configs are merged like local files, so that pool runner
+	// references and, in strict mode, merge conflicts are checked against
+	// them. Fetch errors are reported as extends-local diagnostics.
+	FetchExtends func(ref string) ([]byte, error)
+	// ReadExtends reads the local configs _extends references instead of
+	// the file system, e.g. from a git revision. Paths are relative to the
+	// directory of the source name, e.g. ".github/base.yml" for a config
+	// validated as ".github/runs-on.yml".
+	ReadExtends func(path string) ([]byte, error)
+	// Schema is CUE source to validate against instead of the embedded
+	// schema. It must define #Config; use CheckSchema to verify it first.
+	Schema []byte
+	// StrictAdmins also reports an admins list that is not sorted
+	// alphabetically. Duplicate admins are always reported.
+	StrictAdmins bool
+	// ScopePath restricts validation to the subtree at a dotted path such as
+	// "runners.gpu-runner" or "pools.main.schedule.0". The entries it
+	// references (the runner of a pool, the image of a runner) are validated
+	// along with it, but only diagnostics within the subtree are reported.
+	// Validation fails if the path does not exist.
+	ScopePath string
+	// Advisories are checked against the config, reporting matched entries
+	// with the advisory ID as rule ID. Nil means the database snapshot
+	// embedded at build time (see advisory.Load for a newer one).
+	Advisories *advisory.Database
+	// Strict also reports top-level fields the schema does not define,
+	// except x-* blocks, which the schema accepts for forward compatibility,
+	// and entries of a local _extends chain replaced by a different
+	// definition of the same name.
+	Strict bool
+	// Profile is the set of checks a config is held to: ProfileProd also
+	// reports configs that do not declare their schema version. Empty means
+	// the default profile.
+	Profile string
+	// Rules turns rules on or off by ID (advisory IDs included). Rules that
+	// are not listed are on.
+	Rules map[string]bool
+	// MaxErrors limits the number of errors reported; the first ones in
+	// document order are kept. Warnings are not limited. 0 means no limit.
+	MaxErrors int
+	// ShutdownDays are weekday names, e.g. "saturday", on which pool
+	// schedules are expected to keep no hot instances. Entries that do are
+	// reported as warnings.
+	ShutdownDays []string
+	// UnusedRunners also reports runners that no pool uses, to find dead
+	// entries in large shared configs. Runners matching LabelRunners, names
+	// or glob patterns such as "gpu-*", are not reported, as jobs select
+	// them with runner= labels.
+	UnusedRunners bool
+	LabelRunners  []string
+	// UnusedImages also reports images that no runner uses, to find stale
+	// custom AMIs. Images-only configs shared through _extends are not
+	// reported.
+	UnusedImages bool
+	// Shellcheck also checks preinstall scripts without syntax errors with
+	// shellcheck, if it is installed, reporting its errors and warnings
+	Shellcheck bool
+	// MaxHot is the number of hot instances above which pool schedule
+	// entries are reported as implausible. 0 means DefaultMaxHot, and a
+	// negative value turns the limit off.
+	MaxHot int
+	// Locale translates diagnostic messages with the built-in catalog of a
+	// language, e.g. "fr" or "fr-CA" (see Locales). Messages stay in English
+	// for other locales, and those without a translation. Rule IDs are not
+	// translated.
+	Locale string
+	// Catalog translates diagnostic messages instead of the built-in catalog
+	// of Locale, e.g. for a language without one (see Messages)
+	Catalog Catalog
+	// Logger receives debug messages with the time taken to load the schema
+	// (and whether the compiled embedded schema was reused) and by each step
+	// of a validation, to investigate slow validations. Nil logs nothing.
+	Logger *slog.Logger
+	// OnFileStart and OnFileDone are called before and after validating each
+	// file read by path (ValidateFile, ValidateFiles), and OnDiagnostic with
+	// each diagnostic of a config once it is validated, so that embedders can
+	// stream progress and partial results. They are called on the goroutine
+	// validating the config.
+	OnFileStart  func(path string)
+	OnFileDone   func(path string, diags []Diagnostic, err error)
+	OnDiagnostic func(diag Diagnostic)
+}
+
+// Option sets a validation option for New
+type Option func(*Options)
+
+// WithStrict reports top-level fields the schema does not define and
+// entries redefined differently across a local _extends chain
+func WithStrict() Option {
+	return func(opts *Options) { opts.Strict = true }
+}
+
+// WithProfile validates against a profile, e.g. ProfileProd
+func WithProfile(profile string) Option {
+	return func(opts *Options) { opts.Profile = profile }
+}
+
+// WithSchema validates against CUE source defining #Config instead of the
+// embedded schema
+func WithSchema(schema []byte) Option {
+	return func(opts *Options) { opts.Schema = schema }
+}
+
+// WithRules turns rules on or off by ID, e.g. {"public-ssh": false}
+func WithRules(rules map[string]bool) Option {
+	return func(opts *Options) {
+		if opts.Rules == nil {
+			opts.Rules = make(map[string]bool, len(rules))
+		}
+		maps.Copy(opts.Rules, rules)
 	}
-	defer func() {
-		//nolint:errcheck // Close errors on read-only files are safe to ignore
-		_ = file.Close()
-	}()
+}
+
+// WithLocale translates diagnostic messages into the language of locale,
+// e.g. "fr"
+func WithLocale(locale string) Option {
+	return func(opts *Options) { opts.Locale = locale }
+}
+
+// WithLogger logs schema load and validation step timings to logger at debug
+// level
+func WithLogger(logger *slog.Logger) Option {
+	return func(opts *Options) { opts.Logger = logger }
+}
+
+// WithFetchExtends merges the repository configs fetch returns for
+// repository _extends references
+func WithFetchExtends(fetch func(ref string) ([]byte, error)) Option {
+	return func(opts *Options) { opts.FetchExtends = fetch }
+}
 
-	return ValidateReader(ctx, file, filePath)
+// WithReadExtends reads local _extends references with read instead of from
+// the file system
+func WithReadExtends(read func(path string) ([]byte, error)) Option {
+	return func(opts *Options) { opts.ReadExtends = read }
+}
+
+// WithMaxErrors reports at most n errors
+func WithMaxErrors(n int) Option {
+	return func(opts *Options) { opts.MaxErrors = n }
 }
 
 // ValidateReader validates YAML content from a reader
 func ValidateReader(ctx context.Context, r io.Reader, sourceName string) ([]Diagnostic, error) {
-	// Read the YAML content
+	return ValidateReaderWithOptions(ctx, r, sourceName, Options{})
+}
+
+// ValidateReaderWithOptions validates YAML content from a reader with the
+// given options
+func ValidateReaderWithOptions(ctx context.Context, r io.Reader, sourceName string, opts Options) ([]Diagnostic, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, &Error{Code: CodeInput, Message: "failed to read content", Err: err}
+	}
+	return ValidateBytesWithOptions(ctx, data, sourceName, opts)
+}
+
+// ValidateBytes validates YAML content held in memory
+func ValidateBytes(ctx context.Context, data []byte, sourceName string) ([]Diagnostic, error) {
+	return ValidateBytesWithOptions(ctx, data, sourceName, Options{})
+}
+
+// ValidateBytesWithOptions validates YAML content held in memory with the
+// given options. Like all validation functions, it stops with a CodeCanceled
+// error once ctx is done.
+func ValidateBytesWithOptions(ctx context.Context, data []byte, sourceName string, opts Options) ([]Diagnostic, error) {
+	schema, err := schemaFor(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	diags, _, err := validateBytes(ctx, data, sourceName, opts, schema)
+	return diags, err
+}
+
+// ValidateAndParse validates YAML content from a reader and decodes it into a
+// typed config, parsing the YAML only once. The config is returned whenever
+// the content could be decoded, even if there are validation errors; it is
+// nil when the YAML is malformed or a field cannot be decoded into its typed
+// form. Local _extends are not merged into the returned config.
+func ValidateAndParse(ctx context.Context, r io.Reader, sourceName string, opts Options) (*config.Config, []Diagnostic, error) {
 	data, err := io.ReadAll(r)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read content: %w", err)
+		return nil, nil, &Error{Code: CodeInput, Message: "failed to read content", Err: err}
+	}
+	schema, err := schemaFor(ctx, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	return validateAndParse(ctx, data, sourceName, opts, schema)
+}
+
+// validateAndParse validates YAML content and decodes it, see ValidateAndParse
+func validateAndParse(ctx context.Context, data []byte, sourceName string, opts Options, schema *compiledSchema) (*config.Config, []Diagnostic, error) {
+	diags, doc, err := validateBytes(ctx, data, sourceName, opts, schema)
+	if err != nil || doc == nil {
+		return nil, diags, err
 	}
+	var cfg config.Config
+	if err := doc.Decode(&cfg); err != nil {
+		return nil, diags, nil
+	}
+	return &cfg, diags, nil
+}
+
+// Validator validates configs with fixed options, compiling the schema only
+// once. Use it to validate many configs, e.g. in a server or an editor. It is
+// safe for concurrent use.
+type Validator struct {
+	opts   Options
+	schema *compiledSchema
+}
 
-	// Parse YAML (this will expand anchors automatically)
+// New returns a Validator configured by the given options, validating
+// against the embedded schema unless WithSchema is given
+func New(options ...Option) (*Validator, error) {
+	var opts Options
+	for _, option := range options {
+		option(&opts)
+	}
+	return NewWithOptions(opts)
+}
+
+// NewWithOptions returns a Validator using opts for every config. It fails if
+// opts.Schema cannot be compiled.
+func NewWithOptions(opts Options) (*Validator, error) {
+	schema, err := compileSchema(opts)
+	if err != nil {
+		return nil, err
+	}
+	return &Validator{opts: opts, schema: schema}, nil
+}
+
+// ValidateFile validates a runs-on.yml file at the given path
+func (v *Validator) ValidateFile(ctx context.Context, filePath string) ([]Diagnostic, error) {
+	return validateFile(ctx, filePath, os.ReadFile, v.opts, v.schema)
+}
+
+// ValidateReader validates YAML content from a reader
+func (v *Validator) ValidateReader(ctx context.Context, r io.Reader, sourceName string) ([]Diagnostic, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, &Error{Code: CodeInput, Message: "failed to read content", Err: err}
+	}
+	return v.ValidateBytes(ctx, data, sourceName)
+}
+
+// ValidateAndParse validates YAML content from a reader and decodes it into a
+// typed config, like the ValidateAndParse function
+func (v *Validator) ValidateAndParse(ctx context.Context, r io.Reader, sourceName string) (*config.Config, []Diagnostic, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, nil, &Error{Code: CodeInput, Message: "failed to read content", Err: err}
+	}
+	return validateAndParse(ctx, data, sourceName, v.opts, v.schema)
+}
+
+// ValidateBytes validates YAML content held in memory
+func (v *Validator) ValidateBytes(ctx context.Context, data []byte, sourceName string) ([]Diagnostic, error) {
+	diags, _, err := validateBytes(ctx, data, sourceName, v.opts, v.schema)
+	return diags, err
+}
+
+// validateBytes validates YAML content and returns the parsed document
+// alongside the diagnostics. The document is nil if the YAML is malformed.
+func validateBytes(ctx context.Context, data []byte, sourceName string, opts Options, schema *compiledSchema) ([]Diagnostic, *yaml.Node, error) {
+	if err := checkContext(ctx); err != nil {
+		return nil, nil, err
+	}
+	shutdownDays, err := parseShutdownDays(opts.ShutdownDays)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := checkLabelRunners(opts.LabelRunners); err != nil {
+		return nil, nil, err
+	}
+	if err := checkProfile(opts.Profile); err != nil {
+		return nil, nil, err
+	}
+	trace := newTracer(ctx, opts, sourceName)
+
+	// Parse YAML once; the node tree is shared by all checks and decoding.
+	// Decoding expands anchors automatically.
+	var doc yaml.Node
 	var yamlData any
-	if err := yaml.Unmarshal(data, &yamlData); err != nil {
-		return []Diagnostic{
+	var fieldWarnings []Diagnostic
+	err = yaml.Unmarshal(data, &doc)
+	if err == nil {
+		// Decoding fails on duplicate keys: report where they are defined
+		// instead of the decoding error
+		if duplicateErrors := checkDuplicateKeys(&doc, sourceName); len(duplicateErrors) > 0 {
+			setEndPositions(&doc, data, sourceName, duplicateErrors)
+			setOffsets(data, sourceName, duplicateErrors)
+			setFieldPaths(&doc, sourceName, duplicateErrors)
+			trace.done(ctx, len(duplicateErrors))
+			return notifyDiagnostics(opts, localize(opts, duplicateErrors)), nil, nil
+		}
+	}
+	checked, scoped := &doc, (*scope)(nil)
+	if err == nil && opts.ScopePath != "" {
+		if checked, scoped, err = scopeDocument(&doc, opts.ScopePath); err != nil {
+			return nil, nil, err
+		}
+	}
+	if err == nil {
+		// Check deprecated fields and normalize values (e.g. boolean spot
+		// values to strings, as the CUE schema expects) before decoding
+		fieldWarnings = visitFields(checked, sourceName)
+		err = checked.Decode(&yamlData)
+	}
+	if err != nil {
+		trace.done(ctx, 1)
+		return notifyDiagnostics(opts, localize(opts, []Diagnostic{
 			{
 				Path:     sourceName,
 				Line:     0,
 				Column:   0,
-				Message:  fmt.Sprintf("YAML parse error: %v", err),
+				text:     message(RuleYAMLSyntax, "error", err.Error()),
 				Severity: SeverityError,
+				RuleID:   RuleYAMLSyntax,
 			},
-		}, nil
+		})), nil, nil
 	}
+	root := rootMapping(checked)
+	trace.step(ctx, "parse", len(fieldWarnings))
 
-	// Normalize boolean spot values to strings (CUE schema expects strings)
-	yamlData = normalizeSpotValues(yamlData)
+	// Check the declared schema version. Unless a schema is given, validate
+	// against the schema of that version and report fields added after it.
+	versionDiags := checkSchemaVersion(rootMapping(&doc), sourceName, opts.Profile == ProfileProd)
+	if _, value := declaredSchemaVersion(rootMapping(&doc)); value != nil && len(opts.Schema) == 0 {
+		versionDiags = append(versionDiags, checkNewerFields(rootMapping(&doc), sourceName)...)
+		if version, ok := findSchemaVersion(value.Value); ok {
+			if schema, err = schemaForVersion(schema, version); err != nil {
+				return nil, nil, err
+			}
+		}
+	}
 
-	// Re-marshal and unmarshal to ensure types are properly converted
-	// This ensures boolean values are properly converted to strings
-	normalizedYAML, err := yaml.Marshal(yamlData)
+	// Validate against the schema
+	schemaErrors, err := schema.check(ctx, yamlData, sourceName)
 	if err != nil {
-		return nil, fmt.Errorf("failed to marshal normalized YAML: %w", err)
+		return nil, nil, err
+	}
+	// The declared schema version is checked on its own, and so are the
+	// fields added after it
+	schemaErrors = slices.DeleteFunc(schemaErrors, func(diag Diagnostic) bool {
+		return slices.Contains(schemaVersionFields, diag.FieldPath) || slices.ContainsFunc(versionDiags, func(version Diagnostic) bool {
+			return version.RuleID == RuleSchemaVersionField && version.FieldPath == diag.FieldPath
+		})
+	})
+	setSchemaPositions(root, schemaErrors)
+	schemaErrors = dropPoolNameErrors(root, schemaErrors)
+	schemaErrors = dropStaticPoolFieldErrors(root, schemaErrors)
+	schemaErrors = reportNotAllowedFields(root, sourceName, schema.definitions, schemaErrors)
+	trace.step(ctx, "schema", len(schemaErrors))
+
+	// Check that runner, image and pool names can be used in job labels
+	nameErrors := checkNames(root, sourceName)
+	trace.step(ctx, "names", len(nameErrors))
+
+	// Check for runners exposing SSH on public IPs
+	securityWarnings := checkPublicSSH(yamlData, root, sourceName)
+	trace.step(ctx, "public-ssh", len(securityWarnings))
+
+	// Check that family wildcards match instance families
+	familyErrors := checkFamilyPatterns(yamlData, root, sourceName)
+	trace.step(ctx, "family-patterns", len(familyErrors))
+
+	// Check that cpu, ram and family match at least one instance type
+	feasibilityWarnings := checkFeasibility(yamlData, root, sourceName)
+	trace.step(ctx, "feasibility", len(feasibilityWarnings))
+
+	// Check for burstable families backing spot runners or hot pools
+	capacityWarnings := checkBurstableCapacity(yamlData, root, sourceName)
+	trace.step(ctx, "burstable-capacity", len(capacityWarnings))
+
+	// Check that runner extras are compatible with the rest of the runner
+	extrasWarnings := checkExtras(yamlData, root, sourceName)
+	trace.step(ctx, "extras", len(extrasWarnings))
+
+	// Check that runner extras are supported by RunsOn
+	unknownExtras := checkUnknownExtras(yamlData, root, sourceName)
+	trace.step(ctx, "unknown-extras", len(unknownExtras))
+
+	// Check that runner and image tags are valid AWS tags
+	tagErrors := checkTags(yamlData, root, sourceName)
+	trace.step(ctx, "tags", len(tagErrors))
+
+	// Check runner volume specifications against the EBS volume limits
+	volumeDiags := checkVolumes(yamlData, root, sourceName)
+	trace.step(ctx, "volumes", len(volumeDiags))
+
+	// Check the syntax of preinstall scripts
+	shellDiags := checkPreinstallScripts(ctx, yamlData, root, data, sourceName, opts.Shellcheck)
+	trace.step(ctx, "preinstall", len(shellDiags))
+
+	// Check that image AMIs are AMI IDs
+	amiErrors := checkImageAMIs(yamlData, root, sourceName)
+	trace.step(ctx, "amis", len(amiErrors))
+
+	// Check that images set either an AMI or a complete AMI search
+	imageSourceErrors := checkImageSources(yamlData, root, sourceName)
+	trace.step(ctx, "image-sources", len(imageSourceErrors))
+
+	// Check schedule match criteria and, optionally, hot instances on
+	// shutdown days
+	scheduleDiags := checkSchedules(root, sourceName, shutdownDays)
+	trace.step(ctx, "schedules", len(scheduleDiags))
+
+	// Check that pool timezones are tz database zones
+	timezoneDiags := checkTimezones(root, sourceName)
+	trace.step(ctx, "timezones", len(timezoneDiags))
+
+	// Check pool schedule counts for likely typos
+	maxHot := opts.MaxHot
+	if maxHot == 0 {
+		maxHot = DefaultMaxHot
+	}
+	poolCapacityWarnings := checkPoolCapacity(root, sourceName, maxHot)
+	trace.step(ctx, "pool-capacity", len(poolCapacityWarnings))
+
+	// Check that pools are sized by a schedule
+	poolModeDiags := checkPoolModes(root, sourceName)
+	trace.step(ctx, "pool-modes", len(poolModeDiags))
+
+	// Check for duplicate and, optionally, unsorted admins
+	adminWarnings := checkAdmins(root, sourceName, opts.StrictAdmins)
+	trace.step(ctx, "admins", len(adminWarnings))
+
+	// Check for x-* blocks and anchors that are never referenced. Aliases
+	// outside a scoped subtree count too, so the whole document is checked.
+	unusedWarnings := checkUnused(rootMapping(&doc), sourceName)
+	trace.step(ctx, "unused", len(unusedWarnings))
+
+	// Check for top-level fields the schema does not define: all of them in
+	// strict mode, likely typos otherwise
+	known := schema.fields()
+	unknownFields := checkUnknownFields(rootMapping(&doc), sourceName, known, opts.Strict)
+	trace.step(ctx, "unknown-fields", len(unknownFields))
+
+	if err := checkContext(ctx); err != nil {
+		return nil, nil, err
+	}
+
+	// Check for config patterns with published advisories
+	advisories := opts.Advisories
+	if advisories == nil {
+		advisories = advisory.Embedded()
+	}
+	advisoryDiags := checkAdvisories(yamlData, root, sourceName, advisories)
+	trace.step(ctx, "advisories", len(advisoryDiags))
+
+	// Run the rules registered by embedders
+	customDiags, err := checkCustomRules(ctx, &ConfigNode{SourceName: sourceName, Root: root, Data: yamlData}, opts, trace)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := checkContext(ctx); err != nil {
+		return nil, nil, err
+	}
+
+	// Resolve _extends so that pools can reference inherited runners
+	var extendsErrors, runnerReferenceErrors, imageReferenceWarnings, unusedImageWarnings, unusedRunnerWarnings, conflictErrors []Diagnostic
+	if !opts.DisableLocalExtends || !hasLocalExtends(yamlData) {
+		var referenceData any
+		var merged *extends.Document
+		referenceData, merged, extendsErrors = resolveLocalExtends(yamlData, data, rootMapping(&doc), sourceName,
+			extends.Options{Fetch: opts.FetchExtends, ReadFile: opts.ReadExtends})
+
+		// Check for invalid runner references in pools
+		runnerReferenceErrors = checkRunnerReferences(referenceData, rootMapping(&doc), sourceName)
+
+		// Check for runner images that are neither defined nor built in
+		imageReferenceWarnings = checkImageReferences(referenceData, rootMapping(&doc), sourceName)
+
+		// Optionally check for images no runner uses
+		if opts.UnusedImages {
+			unusedImageWarnings = checkUnusedImages(referenceData, rootMapping(&doc), sourceName)
+		}
+
+		// Optionally check for runners no pool uses
+		if opts.UnusedRunners {
+			unusedRunnerWarnings = checkUnusedRunners(referenceData, rootMapping(&doc), sourceName, opts.LabelRunners)
+		}
+
+		// Optionally check for entries replaced by a different definition
+		if opts.Strict && merged != nil {
+			conflictErrors = checkMergeConflicts(merged, rootMapping(&doc), sourceName)
+		}
 	}
-	if err := yaml.Unmarshal(normalizedYAML, &yamlData); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal normalized YAML: %w", err)
+	trace.step(ctx, "extends", len(extendsErrors)+len(runnerReferenceErrors)+len(imageReferenceWarnings)+len(unusedImageWarnings)+len(unusedRunnerWarnings)+len(conflictErrors))
+
+	// Combine all diagnostics
+	allDiagnostics := append(schemaErrors, fieldWarnings...)
+	allDiagnostics = append(allDiagnostics, versionDiags...)
+	allDiagnostics = append(allDiagnostics, nameErrors...)
+	allDiagnostics = append(allDiagnostics, securityWarnings...)
+	allDiagnostics = append(allDiagnostics, familyErrors...)
+	allDiagnostics = append(allDiagnostics, feasibilityWarnings...)
+	allDiagnostics = append(allDiagnostics, capacityWarnings...)
+	allDiagnostics = append(allDiagnostics, extrasWarnings...)
+	allDiagnostics = append(allDiagnostics, unknownExtras...)
+	allDiagnostics = append(allDiagnostics, tagErrors...)
+	allDiagnostics = append(allDiagnostics, volumeDiags...)
+	allDiagnostics = append(allDiagnostics, shellDiags...)
+	allDiagnostics = append(allDiagnostics, amiErrors...)
+	allDiagnostics = append(allDiagnostics, imageSourceErrors...)
+	allDiagnostics = append(allDiagnostics, scheduleDiags...)
+	allDiagnostics = append(allDiagnostics, timezoneDiags...)
+	allDiagnostics = append(allDiagnostics, poolCapacityWarnings...)
+	allDiagnostics = append(allDiagnostics, poolModeDiags...)
+	allDiagnostics = append(allDiagnostics, adminWarnings...)
+	allDiagnostics = append(allDiagnostics, unusedWarnings...)
+	allDiagnostics = append(allDiagnostics, unknownFields...)
+	allDiagnostics = append(allDiagnostics, advisoryDiags...)
+	allDiagnostics = append(allDiagnostics, customDiags...)
+	allDiagnostics = append(allDiagnostics, extendsErrors...)
+	allDiagnostics = append(allDiagnostics, runnerReferenceErrors...)
+	allDiagnostics = append(allDiagnostics, imageReferenceWarnings...)
+	allDiagnostics = append(allDiagnostics, unusedImageWarnings...)
+	allDiagnostics = append(allDiagnostics, unusedRunnerWarnings...)
+	allDiagnostics = append(allDiagnostics, conflictErrors...)
+
+	// Scoping matches the English messages
+	setMessages(allDiagnostics)
+	setEndPositions(&doc, data, sourceName, allDiagnostics)
+	setOffsets(data, sourceName, allDiagnostics)
+	setFieldPaths(&doc, sourceName, allDiagnostics)
+	suggestFixes(&doc, data, sourceName, known, schema.definitions, allDiagnostics)
+
+	if scoped != nil {
+		allDiagnostics = slices.DeleteFunc(allDiagnostics, func(diag Diagnostic) bool {
+			return !scoped.contains(diag)
+		})
 	}
 
-	// Load CUE schema
-	schema, err := loadSchema()
+	allDiagnostics = localize(opts, limitDiagnostics(allDiagnostics, opts))
+	trace.done(ctx, len(allDiagnostics))
+	return notifyDiagnostics(opts, allDiagnostics), &doc, nil
+}
+
+// compiledSchema is a compiled #Config definition. CUE values are not safe
+// for concurrent use, so evaluations against it are serialized.
+type compiledSchema struct {
+	mu    sync.Mutex
+	value cue.Value
+	// definitions holds the fields of the runner, image, pool and schedule
+	// entry definitions, see definitionFields
+	definitions map[string][]string
+}
+
+var (
+	embeddedOnce   sync.Once
+	embeddedSchema *compiledSchema
+	embeddedErr    error
+)
+
+// schemaFor returns the compiled schema for opts, or a CodeCanceled error if
+// ctx is done before it is compiled
+func schemaFor(ctx context.Context, opts Options) (*compiledSchema, error) {
+	type result struct {
+		schema *compiledSchema
+		err    error
+	}
+	compiled, err := withContext(ctx, func() result {
+		schema, err := compileSchema(opts)
+		return result{schema, err}
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to load schema: %w", err)
+		return nil, err
 	}
+	return compiled.schema, compiled.err
+}
 
-	// Create CUE context and compile the data
-	ctx2 := cuecontext.New()
-	dataValue := ctx2.Encode(yamlData)
+// compileSchema returns the compiled schema for opts. The embedded schema is
+// compiled once and shared by all calls.
+func compileSchema(opts Options) (*compiledSchema, error) {
+	start := time.Now()
+	if len(opts.Schema) > 0 {
+		schema, err := loadSchema(opts.Schema)
+		if err != nil {
+			return nil, &Error{Code: CodeSchema, Message: "failed to load schema", Err: err}
+		}
+		logSchema(opts, "custom", false, start)
+		return schema, nil
+	}
+	cached := true
+	embeddedOnce.Do(func() {
+		cached = false
+		if embeddedSchema, embeddedErr = loadSchema(nil); embeddedErr != nil {
+			embeddedErr = &Error{Code: CodeEmbeddedSchema, Message: "failed to load schema", Err: embeddedErr}
+		}
+	})
+	if embeddedErr == nil {
+		logSchema(opts, "embedded", cached, start)
+	}
+	return embeddedSchema, embeddedErr
+}
+
+// logSchema logs at debug level that a schema was loaded since start, or
+// found already compiled if cached
+func logSchema(opts Options, source string, cached bool, start time.Time) {
+	if opts.Logger == nil {
+		return
+	}
+	opts.Logger.Debug("schema loaded",
+		slog.String("schema", source),
+		slog.Bool("cached", cached),
+		slog.Duration("duration", time.Since(start)))
+}
 
-	// Unify with schema and validate
-	unified := schema.Unify(dataValue)
+// check unifies the decoded config with the schema and reports type errors,
+// constraint violations and missing required fields. It returns a
+// CodeCanceled error as soon as ctx is done; an evaluation already started
+// then completes in the background, and those waiting for it are skipped.
+func (s *compiledSchema) check(ctx context.Context, yamlData any, sourceName string) ([]Diagnostic, error) {
+	return withContext(ctx, func() []Diagnostic {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if ctx.Err() != nil {
+			return nil
+		}
+		return s.evaluate(yamlData, sourceName)
+	})
+}
+
+func (s *compiledSchema) evaluate(yamlData any, sourceName string) []Diagnostic {
+
+	// Encode the data in the schema's context so that they can be unified
+	dataValue := s.value.Context().Encode(yamlData)
+	unified := s.value.Unify(dataValue)
 	var schemaErrors []Diagnostic
 
 	// Validate for type errors and constraint violations
@@ -116,32 +742,105 @@ func ValidateReader(ctx context.Context, r io.Reader, sourceName string) ([]Diag
 		}
 		for _, diag := range incompleteErrors {
 			if !existingMsgs[diag.Message] {
+				diag.RuleID = RuleRequiredField
+				diag.text = message(RuleRequiredField, "error", diag.Message)
 				schemaErrors = append(schemaErrors, diag)
 			}
 		}
 	}
+	return schemaErrors
+}
+
+// fields returns the top-level fields of the schema, optional ones included
+func (s *compiledSchema) fields() map[string]bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	fields := make(map[string]bool)
+	iter, err := s.value.Fields(cue.Optional(true))
+	if err != nil {
+		return fields
+	}
+	for iter.Next() {
+		fields[iter.Selector().Unquoted()] = true
+	}
+	return fields
+}
 
-	// Check for deprecated fields and add warnings
-	deprecationWarnings := checkDeprecatedFields(yamlData, sourceName, data)
+// limitDiagnostics drops the diagnostics of rules turned off in opts.Rules
+// and the errors beyond opts.MaxErrors
+func limitDiagnostics(diags []Diagnostic, opts Options) []Diagnostic {
+	diags = slices.DeleteFunc(diags, func(diag Diagnostic) bool {
+		enabled, ok := opts.Rules[diag.RuleID]
+		return ok && !enabled
+	})
+	if opts.MaxErrors <= 0 {
+		return diags
+	}
 
-	// Check for invalid runner references in pools
-	runnerReferenceErrors := checkRunnerReferences(yamlData, sourceName)
+	var errors []Diagnostic
+	for _, diag := range diags {
+		if diag.Severity == SeverityError {
+			errors = append(errors, diag)
+		}
+	}
+	if len(errors) <= opts.MaxErrors {
+		return diags
+	}
+	slices.SortStableFunc(errors, func(a, b Diagnostic) int {
+		if a.Line != b.Line {
+			return a.Line - b.Line
+		}
+		return a.Column - b.Column
+	})
+	kept := make(map[Diagnostic]int)
+	for _, diag := range errors[:opts.MaxErrors] {
+		kept[diag]++
+	}
+	return slices.DeleteFunc(diags, func(diag Diagnostic) bool {
+		if diag.Severity != SeverityError {
+			return false
+		}
+		if kept[diag] == 0 {
+			return true
+		}
+		kept[diag]--
+		return false
+	})
+}
 
-	// Combine all diagnostics
-	allDiagnostics := append(schemaErrors, deprecationWarnings...)
-	allDiagnostics = append(allDiagnostics, runnerReferenceErrors...)
+// CUESchema returns the source of the CUE schema configs are validated against
+func CUESchema() []byte {
+	data, err := schemaFS.ReadFile("schema.cue")
+	if err != nil {
+		// The schema is embedded at build time, so this cannot happen
+		panic(fmt.Sprintf("embedded schema missing: %v", err))
+	}
+	return data
+}
 
-	return allDiagnostics, nil
+// CheckSchema reports whether src is a CUE schema that can be used as
+// Options.Schema
+func CheckSchema(src []byte) error {
+	if len(src) == 0 {
+		return &Error{Code: CodeSchema, Message: "schema is empty"}
+	}
+	if _, err := loadSchema(src); err != nil {
+		return &Error{Code: CodeSchema, Err: err}
+	}
+	return nil
 }
 
-// loadSchema loads and compiles the CUE schema
-func loadSchema() (cue.Value, error) {
+// loadSchema compiles the CUE schema, using the embedded schema when
+// schemaData is empty
+func loadSchema(schemaData []byte) (*compiledSchema, error) {
 	ctx := cuecontext.New()
 
-	// Try to load embedded schema first
-	var schemaData []byte
+	// Use the embedded schema unless one is given
 	var err error
-	schemaData, err = schemaFS.ReadFile("schema.cue")
+	if len(schemaData) == 0 {
+		schemaData, err = schemaFS.ReadFile("schema.cue")
+	}
 	if err != nil {
 		// Fallback to file system (for development)
 		paths := []string{"schema/runs_on.cue", "../../schema/runs_on.cue", "runs_on.cue"}
@@ -152,61 +851,130 @@ func loadSchema() (cue.Value, error) {
 			}
 		}
 		if len(schemaData) == 0 {
-			return cue.Value{}, fmt.Errorf("failed to read schema file")
+			return nil, fmt.Errorf("failed to read schema file")
 		}
 	}
 
 	// Compile the schema
 	value := ctx.CompileBytes(schemaData)
 	if value.Err() != nil {
-		return cue.Value{}, fmt.Errorf("failed to compile schema: %w", value.Err())
+		return nil, fmt.Errorf("failed to compile schema: %w", value.Err())
 	}
 
 	// Get the #Config definition
 	config := value.LookupPath(cue.ParsePath("#Config"))
 	if !config.Exists() {
-		return cue.Value{}, fmt.Errorf("schema does not define #Config")
+		return nil, fmt.Errorf("schema does not define #Config")
 	}
 
-	return config, nil
+	return &compiledSchema{value: config, definitions: definitionFields(value)}, nil
 }
 
-// convertCueErrors converts CUE validation errors to Diagnostic slice
+// convertCueErrors converts CUE validation errors to Diagnostic slice. The
+// config is encoded from decoded values, so CUE positions point into the
+// schema: diagnostics get their position from setSchemaPositions.
 func convertCueErrors(err error, sourceName string) []Diagnostic {
 	var diagnostics []Diagnostic
 
 	// CUE uses errors.List for multiple errors
 	errList := errors.Errors(err)
 	for _, err := range errList {
-		pos := errors.Positions(err)
-		line := 0
-		column := 0
-		if len(pos) > 0 {
-			line = pos[0].Line()
-			column = pos[0].Column()
-		}
-
 		msg := err.Error()
 		// Clean up CUE error messages
 		msg = strings.TrimPrefix(msg, "#Config:")
 		msg = strings.TrimSpace(msg)
 
 		diagnostics = append(diagnostics, Diagnostic{
-			Path:     sourceName,
-			Line:     line,
-			Column:   column,
-			Message:  msg,
-			Severity: SeverityError,
+			Path:      sourceName,
+			Message:   msg,
+			text:      message(RuleSchema, "error", msg),
+			Severity:  SeverityError,
+			RuleID:    RuleSchema,
+			FieldPath: cuePath(errors.Path(err)),
 		})
 	}
 
 	return diagnostics
 }
 
+// cuePath returns the dotted config path of a CUE error path, without the
+// definitions it starts with (e.g. #Config) and with quoted labels such as
+// "schema-version" unquoted
+func cuePath(path []string) string {
+	for len(path) > 0 && strings.HasPrefix(path[0], "#") {
+		path = path[1:]
+	}
+	labels := make([]string, len(path))
+	for i, label := range path {
+		if unquoted, err := strconv.Unquote(label); err == nil {
+			label = unquoted
+		}
+		labels[i] = label
+	}
+	return strings.Join(labels, ".")
+}
+
+// resolveLocalExtends merges the runners of locally extended configs, and of
+// the repository configs extendsOpts fetches if any, into yamlData. Pools are
+// left untouched so that reference errors are only reported for pools
+// defined in this file. The merged document is nil when the config extends
+// nothing to resolve. Resolution errors are reported on the _extends value
+// of root.
+func resolveLocalExtends(yamlData any, originalYAML []byte, root *yaml.Node, sourceName string, extendsOpts extends.Options) (any, *extends.Document, []Diagnostic) {
+	data, ok := yamlData.(map[string]any)
+	if !ok {
+		return yamlData, nil, nil
+	}
+	ref, _ := data["_extends"].(string)
+	if !extends.IsLocal(ref) && (ref == "" || extendsOpts.Fetch == nil) {
+		return yamlData, nil, nil
+	}
+
+	doc, err := extends.LoadBytes(sourceName, originalYAML, extendsOpts)
+	if err != nil {
+		line, column := position(resolveAlias(mappingValue(root, "_extends")))
+		return yamlData, nil, []Diagnostic{
+			{
+				Path:     sourceName,
+				Line:     line,
+				Column:   column,
+				text:     message(RuleExtendsLocal, "error", err.Error()),
+				Severity: SeverityError,
+				RuleID:   RuleExtendsLocal,
+			},
+		}
+	}
+
+	merged := make(map[string]any, len(data))
+	for key, value := range data {
+		merged[key] = value
+	}
+	if runners, ok := doc.Data["runners"]; ok {
+		merged["runners"] = runners
+	}
+	return merged, doc, nil
+}
+
+// hasLocalExtends reports whether a config extends a local file
+func hasLocalExtends(yamlData any) bool {
+	data, ok := yamlData.(map[string]any)
+	if !ok {
+		return false
+	}
+	ref, _ := data["_extends"].(string)
+	return extends.IsLocal(ref)
+}
+
 // checkRunnerReferences checks that pool runners exist in the runners map
-func checkRunnerReferences(yamlData any, sourceName string) []Diagnostic {
+func checkRunnerReferences(yamlData any, root *yaml.Node, sourceName string) []Diagnostic {
 	var errors []Diagnostic
 
+	// runnerPosition returns the position of the runner of a pool
+	runnerPosition := func(poolName string) (int, int) {
+		pool := resolveAlias(mappingValue(resolveAlias(mappingValue(root, "pools")), poolName))
+		return position(resolveAlias(mappingValue(pool, "runner")))
+	}
+
 	// Type assert to map
 	data, ok := yamlData.(map[string]any)
 	if !ok {
@@ -231,12 +999,14 @@ func checkRunnerReferences(yamlData any, sourceName string) []Diagnostic {
 		for poolName, poolValue := range pools {
 			if pool, ok := poolValue.(map[string]any); ok {
 				if runnerName, hasRunner := pool["runner"]; hasRunner {
+					line, column := runnerPosition(poolName)
 					errors = append(errors, Diagnostic{
 						Path:     sourceName,
-						Line:     0,
-						Column:   0,
-						Message:  fmt.Sprintf("pool '%s' references runner '%v' but no runners are defined", poolName, runnerName),
+						Line:     line,
+						Column:   column,
+						text:     message(RulePoolRunnerUndefined+".no-runners", "pool", poolName, "runner", fmt.Sprint(runnerName)),
 						Severity: SeverityError,
+						RuleID:   RulePoolRunnerUndefined,
 					})
 				}
 			}
@@ -268,316 +1038,21 @@ func checkRunnerReferences(yamlData any, sourceName string) []Diagnostic {
 
 		// Check if the runner exists in the runners map
 		if _, exists := runners[runnerNameStr]; !exists {
+			line, column := runnerPosition(poolName)
+			msg := message(RulePoolRunnerUndefined, "pool", poolName, "runner", runnerNameStr)
+			if name := closestName(runnerNameStr, slices.Collect(maps.Keys(runners))); name != "" {
+				msg = message(RulePoolRunnerUndefined+".suggest", "pool", poolName, "runner", runnerNameStr, "suggestion", name)
+			}
 			errors = append(errors, Diagnostic{
 				Path:     sourceName,
-				Line:     0,
-				Column:   0,
-				Message:  fmt.Sprintf("pool '%s' references runner '%s' which is not defined in runners", poolName, runnerNameStr),
+				Line:     line,
+				Column:   column,
+				text:     msg,
 				Severity: SeverityError,
+				RuleID:   RulePoolRunnerUndefined,
 			})
 		}
 	}
 
 	return errors
 }
-
-// checkDeprecatedFields checks for deprecated fields and returns warnings
-func checkDeprecatedFields(yamlData any, sourceName string, originalYAML []byte) []Diagnostic {
-	var warnings []Diagnostic
-
-	// Parse YAML with line information to get accurate line numbers
-	var yamlNode yaml.Node
-	if err := yaml.Unmarshal(originalYAML, &yamlNode); err != nil {
-		// If we can't parse with line info, skip line numbers
-		return checkDeprecatedFieldsRecursive(yamlData, sourceName, "")
-	}
-
-	// Check for deprecated fields
-	if yamlNode.Kind == yaml.DocumentNode && len(yamlNode.Content) > 0 {
-		root := yamlNode.Content[0]
-		if root.Kind == yaml.MappingNode {
-			for i := 0; i < len(root.Content); i += 2 {
-				if i+1 >= len(root.Content) {
-					break
-				}
-				keyNode := root.Content[i]
-				valueNode := root.Content[i+1]
-				if keyNode.Value == "runners" && valueNode.Kind == yaml.MappingNode {
-					// Found runners map, check each runner for deprecated disk field
-					for j := 0; j < len(valueNode.Content); j += 2 {
-						if j+1 >= len(valueNode.Content) {
-							break
-						}
-						_ = valueNode.Content[j] // runner key node (not used, but needed for iteration)
-						runnerValueNode := valueNode.Content[j+1]
-						if runnerValueNode.Kind == yaml.MappingNode {
-							// Check if this runner has a disk field
-							for k := 0; k < len(runnerValueNode.Content); k += 2 {
-								if k >= len(runnerValueNode.Content) {
-									break
-								}
-								fieldKeyNode := runnerValueNode.Content[k]
-								if fieldKeyNode.Value == "disk" {
-									// Found deprecated disk field
-									warnings = append(warnings, Diagnostic{
-										Path:     sourceName,
-										Line:     fieldKeyNode.Line,
-										Column:   fieldKeyNode.Column,
-										Message:  "field 'disk' is deprecated and ignored; use 'volume' instead (e.g., volume=80gb:gp3:125mbs:3000iops)",
-										Severity: SeverityWarning,
-									})
-								}
-							}
-						}
-					}
-				} else if keyNode.Value == "pools" && valueNode.Kind == yaml.MappingNode {
-					// Found pools map, check each pool for deprecated environment field
-					for j := 0; j < len(valueNode.Content); j += 2 {
-						if j+1 >= len(valueNode.Content) {
-							break
-						}
-						_ = valueNode.Content[j] // pool key node (not used, but needed for iteration)
-						poolValueNode := valueNode.Content[j+1]
-						if poolValueNode.Kind == yaml.MappingNode {
-							// Check if this pool has an environment field
-							for k := 0; k < len(poolValueNode.Content); k += 2 {
-								if k >= len(poolValueNode.Content) {
-									break
-								}
-								fieldKeyNode := poolValueNode.Content[k]
-								if fieldKeyNode.Value == "environment" {
-									// Found deprecated environment field
-									warnings = append(warnings, Diagnostic{
-										Path:     sourceName,
-										Line:     fieldKeyNode.Line,
-										Column:   fieldKeyNode.Column,
-										Message:  "field 'environment' is deprecated, use 'env' instead",
-										Severity: SeverityWarning,
-									})
-								}
-							}
-						}
-					}
-				}
-			}
-		}
-	}
-
-	return warnings
-}
-
-// checkDeprecatedFieldsRecursive is a fallback that checks without line numbers
-func checkDeprecatedFieldsRecursive(data any, sourceName string, path string) []Diagnostic {
-	var warnings []Diagnostic
-
-	switch v := data.(type) {
-	case map[string]any:
-		for key, value := range v {
-			currentPath := path
-			if currentPath != "" {
-				currentPath += "."
-			}
-			currentPath += key
-
-			switch key {
-			case "runners":
-				// Check runners map
-				if runnersMap, ok := value.(map[string]any); ok {
-					for runnerKey, runnerValue := range runnersMap {
-						if runnerSpec, ok := runnerValue.(map[string]any); ok {
-							if _, hasDisk := runnerSpec["disk"]; hasDisk {
-								warnings = append(warnings, Diagnostic{
-									Path:     sourceName,
-									Line:     0,
-									Column:   0,
-									Message:  fmt.Sprintf("field 'runners.%s.disk' is deprecated and ignored; use 'volume' instead (e.g., volume=80gb:gp3:125mbs:3000iops)", runnerKey),
-									Severity: SeverityWarning,
-								})
-							}
-						}
-					}
-				}
-			case "pools":
-				// Check pools map
-				if poolsMap, ok := value.(map[string]any); ok {
-					for poolKey, poolValue := range poolsMap {
-						if poolSpec, ok := poolValue.(map[string]any); ok {
-							if _, hasEnvironment := poolSpec["environment"]; hasEnvironment {
-								warnings = append(warnings, Diagnostic{
-									Path:     sourceName,
-									Line:     0,
-									Column:   0,
-									Message:  fmt.Sprintf("field 'pools.%s.environment' is deprecated, use 'env' instead", poolKey),
-									Severity: SeverityWarning,
-								})
-							}
-						}
-					}
-				}
-			default:
-				// Recurse into nested structures
-				warnings = append(warnings, checkDeprecatedFieldsRecursive(value, sourceName, currentPath)...)
-			}
-		}
-	case map[any]any:
-		for key, value := range v {
-			keyStr, ok := key.(string)
-			if !ok {
-				continue
-			}
-			currentPath := path
-			if currentPath != "" {
-				currentPath += "."
-			}
-			currentPath += keyStr
-
-			if keyStr == "runners" {
-				// Check runners map
-				if runnersMap, ok := value.(map[any]any); ok {
-					for runnerKey, runnerValue := range runnersMap {
-						runnerKeyStr, ok := runnerKey.(string)
-						if !ok {
-							continue
-						}
-						if runnerSpec, ok := runnerValue.(map[any]any); ok {
-							if _, hasDisk := runnerSpec["disk"]; hasDisk {
-								warnings = append(warnings, Diagnostic{
-									Path:     sourceName,
-									Line:     0,
-									Column:   0,
-									Message:  fmt.Sprintf("field 'runners.%s.disk' is deprecated and ignored; use 'volume' instead (e.g., volume=80gb:gp3:125mbs:3000iops)", runnerKeyStr),
-									Severity: SeverityWarning,
-								})
-							}
-						}
-					}
-				}
-			} else if keyStr == "pools" {
-				// Check pools map
-				if poolsMap, ok := value.(map[any]any); ok {
-					for poolKey, poolValue := range poolsMap {
-						poolKeyStr, ok := poolKey.(string)
-						if !ok {
-							continue
-						}
-						if poolSpec, ok := poolValue.(map[any]any); ok {
-							if _, hasEnvironment := poolSpec["environment"]; hasEnvironment {
-								warnings = append(warnings, Diagnostic{
-									Path:     sourceName,
-									Line:     0,
-									Column:   0,
-									Message:  fmt.Sprintf("field 'pools.%s.environment' is deprecated, use 'env' instead", poolKeyStr),
-									Severity: SeverityWarning,
-								})
-							}
-						}
-					}
-				}
-			} else {
-				// Recurse into nested structures
-				warnings = append(warnings, checkDeprecatedFieldsRecursive(value, sourceName, currentPath)...)
-			}
-		}
-	case []any:
-		for i, item := range v {
-			warnings = append(warnings, checkDeprecatedFieldsRecursive(item, sourceName, fmt.Sprintf("%s[%d]", path, i))...)
-		}
-	}
-
-	return warnings
-}
-
-// normalizeSpotValues recursively normalizes boolean spot values to strings
-// This allows YAML files to use spot: false (boolean) which gets converted to spot: "false" (string)
-func normalizeSpotValues(data any) any {
-	switch v := data.(type) {
-	case map[string]any:
-		result := make(map[string]any)
-		for key, value := range v {
-			if key == "runners" {
-				// Handle runners map specially to normalize spot values
-				if runnersMap, ok := value.(map[string]any); ok {
-					normalizedRunners := make(map[string]any)
-					for runnerKey, runnerValue := range runnersMap {
-						if runnerSpec, ok := runnerValue.(map[string]any); ok {
-							normalizedSpec := make(map[string]any)
-							for specKey, specValue := range runnerSpec {
-								if specKey == "spot" {
-									// Convert boolean to string
-									if spotBool, ok := specValue.(bool); ok {
-										if spotBool {
-											normalizedSpec[specKey] = "true"
-										} else {
-											normalizedSpec[specKey] = "false"
-										}
-									} else {
-										normalizedSpec[specKey] = normalizeSpotValues(specValue)
-									}
-								} else {
-									normalizedSpec[specKey] = normalizeSpotValues(specValue)
-								}
-							}
-							normalizedRunners[runnerKey] = normalizedSpec
-						} else {
-							normalizedRunners[runnerKey] = normalizeSpotValues(runnerValue)
-						}
-					}
-					result[key] = normalizedRunners
-				} else {
-					result[key] = normalizeSpotValues(value)
-				}
-			} else {
-				result[key] = normalizeSpotValues(value)
-			}
-		}
-		return result
-	case map[any]any:
-		result := make(map[any]any)
-		for key, value := range v {
-			if keyStr, ok := key.(string); ok && keyStr == "runners" {
-				// Handle runners map specially to normalize spot values
-				if runnersMap, ok := value.(map[any]any); ok {
-					normalizedRunners := make(map[any]any)
-					for runnerKey, runnerValue := range runnersMap {
-						if runnerSpec, ok := runnerValue.(map[any]any); ok {
-							normalizedSpec := make(map[any]any)
-							for specKey, specValue := range runnerSpec {
-								if specKeyStr, ok := specKey.(string); ok && specKeyStr == "spot" {
-									// Convert boolean to string
-									if spotBool, ok := specValue.(bool); ok {
-										if spotBool {
-											normalizedSpec[specKey] = "true"
-										} else {
-											normalizedSpec[specKey] = "false"
-										}
-									} else {
-										normalizedSpec[specKey] = normalizeSpotValues(specValue)
-									}
-								} else {
-									normalizedSpec[specKey] = normalizeSpotValues(specValue)
-								}
-							}
-							normalizedRunners[runnerKey] = normalizedSpec
-						} else {
-							normalizedRunners[runnerKey] = normalizeSpotValues(runnerValue)
-						}
-					}
-					result[key] = normalizedRunners
-				} else {
-					result[key] = normalizeSpotValues(value)
-				}
-			} else {
-				result[key] = normalizeSpotValues(value)
-			}
-		}
-		return result
-	case []any:
-		result := make([]any, len(v))
-		for i, item := range v {
-			result[i] = normalizeSpotValues(item)
-		}
-		return result
-	default:
-		return v
-	}
-}
@@ -1,12 +1,29 @@
 package validate_test
 
 import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"maps"
 	"os"
 	"path/filepath"
+	"reflect"
+	"regexp"
+	"slices"
+	"sort"
 	"strings"
+	"sync"
 	"testing"
+	"testing/fstest"
 
+	"github.com/runs-on/config/pkg/advisory"
+	"github.com/runs-on/config/pkg/migrate"
 	"github.com/runs-on/config/pkg/validate"
 )
 
@@ -18,6 +35,8 @@ func TestValidateFile_Valid(t *testing.T) {
 		"../../schema/testdata/valid/pool-runner-reference.yml",
 		"../../schema/testdata/valid/nested-virt.yml",
 		"../../schema/testdata/valid/github-private-runs-on.yml",
+		"../../schema/testdata/valid/extends-local.yml",
+		"../../schema/testdata/valid/family-patterns.yml",
 	}
 
 	for _, testFile := range testFiles {
@@ -51,6 +70,10 @@ func TestValidateFile_Invalid(t *testing.T) {
 		"../../schema/testdata/invalid/indentation-issue.yml",
 		"../../schema/testdata/invalid/indentation-nested.yml",
 		"../../schema/testdata/invalid/nested-virt.yml",
+		"../../schema/testdata/invalid/extends-local-missing.yml",
+		"../../schema/testdata/invalid/family-no-match.yml",
+		"../../schema/testdata/invalid/pool-invalid-schedule-match.yml",
+		"../../schema/testdata/invalid/pool-static-size.yml",
 	}
 
 	for _, testFile := range testFiles {
@@ -66,6 +89,9 @@ func TestValidateFile_Invalid(t *testing.T) {
 				t.Logf("Found %d diagnostics for %s:", len(diags), testFile)
 				for _, diag := range diags {
 					t.Logf("  %s:%d:%d: %s", diag.Path, diag.Line, diag.Column, diag.Message)
+					if _, ok := validate.LookupRule(diag.RuleID); !ok {
+						t.Errorf("Expected a registered rule ID, got %q for %v", diag.RuleID, diag)
+					}
 				}
 			}
 		})
@@ -87,7 +113,7 @@ func TestValidateFile_PoolMissingRunner(t *testing.T) {
 	foundRunnerError := false
 	for _, diag := range diags {
 		if contains(diag.Message, "runner") || contains(diag.Message, "required") {
-			foundRunnerError = true
+			foundRunnerError = diag.RuleID == validate.RuleRequiredField
 			break
 		}
 	}
@@ -122,6 +148,541 @@ func TestValidateFile_PoolInvalidSchedule(t *testing.T) {
 	}
 }
 
+func TestValidateReaderWithOptions_DisableLocalExtends(t *testing.T) {
+	yamlContent := `_extends: ./missing.yml
+pools:
+  my-pool:
+    runner: inherited-runner
+    schedule:
+      - name: default
+        hot: 1
+        stopped: 1
+`
+	// Place the config next to the fixtures so the _extends is looked up on disk
+	sourceName := "../../schema/testdata/valid/runs-on.yml"
+
+	diags, err := validate.ValidateReader(context.Background(), strings.NewReader(yamlContent), sourceName)
+	if err != nil {
+		t.Fatalf("ValidateReader failed: %v", err)
+	}
+	if len(filterErrors(diags)) == 0 {
+		t.Fatal("Expected the missing local _extends to be reported by default")
+	}
+
+	opts := validate.Options{DisableLocalExtends: true}
+	diags, err = validate.ValidateReaderWithOptions(context.Background(), strings.NewReader(yamlContent), sourceName, opts)
+	if err != nil {
+		t.Fatalf("ValidateReaderWithOptions failed: %v", err)
+	}
+	if errors := filterErrors(diags); len(errors) > 0 {
+		t.Errorf("Expected no errors with local _extends disabled, got: %v", errors)
+	}
+}
+
+func TestValidateFile_FamilyNoMatch(t *testing.T) {
+	testFile := "../../schema/testdata/invalid/family-no-match.yml"
+	diags, err := validate.ValidateFile(context.Background(), testFile)
+	if err != nil {
+		t.Fatalf("ValidateFile failed: %v", err)
+	}
+
+	errors := filterErrors(diags)
+	if len(errors) != 1 || errors[0].RuleID != validate.RuleFamilyNoMatch {
+		t.Fatalf("Expected one %s error, got: %v", validate.RuleFamilyNoMatch, diags)
+	}
+	if !strings.Contains(errors[0].Message, "q9*") || errors[0].Line != 4 {
+		t.Errorf("Expected error for 'q9*' on line 4, got %d: %s", errors[0].Line, errors[0].Message)
+	}
+}
+
+func TestValidateFile_ExtendsLocalMissing(t *testing.T) {
+	testFile := "../../schema/testdata/invalid/extends-local-missing.yml"
+	diags, err := validate.ValidateFile(context.Background(), testFile)
+	if err != nil {
+		t.Fatalf("ValidateFile failed: %v", err)
+	}
+
+	found := false
+	for _, diag := range diags {
+		if diag.RuleID == validate.RuleExtendsLocal {
+			found = true
+			break
+		}
+	}
+	if !found {
+		t.Errorf("Expected an %s diagnostic, got: %v", validate.RuleExtendsLocal, diags)
+	}
+}
+
+func TestValidateBytes_FetchExtends(t *testing.T) {
+	yamlContent := `_extends: .github-private
+pools:
+  shared:
+    runner: org-runner
+    schedule:
+      - name: default
+        hot: 1
+        stopped: 1
+  local:
+    runner: missing-runner
+    schedule:
+      - name: default
+        hot: 1
+        stopped: 1
+`
+	var fetched []string
+	opts := validate.Options{FetchExtends: func(ref string) ([]byte, error) {
+		fetched = append(fetched, ref)
+		return []byte("runners:\n  org-runner:\n    cpu: 2\n"), nil
+	}}
+	diags, err := validate.ValidateBytesWithOptions(context.Background(), []byte(yamlContent), "runs-on.yml", opts)
+	if err != nil {
+		t.Fatalf("ValidateBytesWithOptions failed: %v", err)
+	}
+	if !slices.Equal(fetched, []string{".github-private"}) {
+		t.Errorf("Expected the repository config to be fetched, got %q", fetched)
+	}
+	errors := filterErrors(diags)
+	if len(errors) != 1 || errors[0].RuleID != validate.RulePoolRunnerUndefined || !strings.Contains(errors[0].Message, "missing-runner") {
+		t.Errorf("Expected only missing-runner to be undefined, got %v", diags)
+	}
+
+	opts.FetchExtends = func(string) ([]byte, error) { return nil, fs.ErrNotExist }
+	diags, err = validate.ValidateBytesWithOptions(context.Background(), []byte(yamlContent), "runs-on.yml", opts)
+	if err != nil {
+		t.Fatalf("ValidateBytesWithOptions failed: %v", err)
+	}
+	if !slices.ContainsFunc(diags, func(diag validate.Diagnostic) bool { return diag.RuleID == validate.RuleExtendsLocal && diag.Line == 1 }) {
+		t.Errorf("Expected the fetch error on _extends, got %v", diags)
+	}
+}
+
+func TestValidateBytes_ReadExtends(t *testing.T) {
+	yamlContent := "_extends: ./base.yml\npools:\n  main:\n    runner: small\n    schedule:\n      - name: default\n        hot: 1\n        stopped: 1\n"
+	var read []string
+	opts := validate.Options{ReadExtends: func(path string) ([]byte, error) {
+		read = append(read, filepath.ToSlash(path))
+		return []byte("runners:\n  small:\n    cpu: 2\n"), nil
+	}}
+	diags, err := validate.ValidateBytesWithOptions(context.Background(), []byte(yamlContent), ".github/runs-on.yml", opts)
+	if err != nil {
+		t.Fatalf("ValidateBytesWithOptions failed: %v", err)
+	}
+	if !slices.Equal(read, []string{".github/base.yml"}) {
+		t.Errorf("Expected the extended config to be read relative to the source, got %q", read)
+	}
+	if errors := filterErrors(diags); len(errors) != 0 {
+		t.Errorf("Expected the runner of the config read to be found, got %v", errors)
+	}
+}
+
+func TestValidateBytes_RunnerImages(t *testing.T) {
+	yamlContent := `runners:
+  builtin:
+    image: ubuntu24-full-x64
+  defined:
+    image: custom
+  typo:
+    image: ubuntu24-ful-x64
+  unknown:
+    image: my-image
+images:
+  custom:
+    platform: linux
+    ami: ami-1234567890abcdef0
+`
+	diags, err := validate.ValidateBytes(context.Background(), []byte(yamlContent), "runs-on.yml")
+	if err != nil {
+		t.Fatalf("ValidateBytes failed: %v", err)
+	}
+	var got []string
+	for _, diag := range diags {
+		if diag.RuleID == validate.RuleRunnerImageUndefined {
+			if diag.Severity != validate.SeverityWarning {
+				t.Errorf("Expected a warning, got %+v", diag)
+			}
+			got = append(got, fmt.Sprintf("%d: %s", diag.Line, diag.Message))
+		}
+	}
+	want := []string{
+		"7: runner 'typo' uses image 'ubuntu24-ful-x64' which is neither defined in images nor a built-in image; did you mean 'ubuntu24-full-x64'?",
+		"9: runner 'unknown' uses image 'my-image' which is neither defined in images nor a built-in image",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("Expected warnings\n%q\ngot\n%q", want, got)
+	}
+}
+
+func TestValidateBytes_UnusedImages(t *testing.T) {
+	yamlContent := `images:
+  used:
+    ami: ami-1234567890abcdef0
+  shared:
+    ami: ami-1234567890abcdef1
+  legacy:
+    ami: ami-1234567890abcdef2
+runners:
+  small:
+    image: used
+  other:
+    image: shared
+`
+	unusedImages := func(src string, opts validate.Options) []validate.Diagnostic {
+		diags, err := validate.ValidateBytesWithOptions(context.Background(), []byte(src), "runs-on.yml", opts)
+		if err != nil {
+			t.Fatalf("ValidateBytesWithOptions failed: %v", err)
+		}
+		var unused []validate.Diagnostic
+		for _, diag := range diags {
+			if diag.RuleID == validate.RuleUnusedImage {
+				unused = append(unused, diag)
+			}
+		}
+		return unused
+	}
+	if unused := unusedImages(yamlContent, validate.Options{}); len(unused) != 0 {
+		t.Errorf("Expected unused images to be reported only when enabled, got %+v", unused)
+	}
+	unused := unusedImages(yamlContent, validate.Options{UnusedImages: true})
+	if len(unused) != 1 || unused[0].Line != 6 || unused[0].Severity != validate.SeverityWarning || unused[0].Message != "image 'legacy' is not used by any runner" {
+		t.Errorf("Expected a warning for image 'legacy', got %+v", unused)
+	}
+
+	// Images-only configs are shared through _extends
+	if unused := unusedImages("images:\n  shared:\n    ami: ami-1234567890abcdef0\n", validate.Options{UnusedImages: true}); len(unused) != 0 {
+		t.Errorf("Expected no warnings for an images-only config, got %+v", unused)
+	}
+}
+
+func TestValidateBytes_UnusedRunners(t *testing.T) {
+	yamlContent := `runners:
+  small:
+    cpu: [2]
+  gpu-large:
+    family: [g5]
+  legacy:
+    cpu: [4]
+pools:
+  main:
+    runner: small
+    schedule:
+      - name: default
+        hot: 1
+`
+	unusedRunners := func(opts validate.Options) []validate.Diagnostic {
+		t.Helper()
+		diags, err := validate.ValidateBytesWithOptions(context.Background(), []byte(yamlContent), "runs-on.yml", opts)
+		if err != nil {
+			t.Fatalf("ValidateBytesWithOptions failed: %v", err)
+		}
+		var unused []validate.Diagnostic
+		for _, diag := range diags {
+			if diag.RuleID == validate.RuleUnusedRunner {
+				unused = append(unused, diag)
+			}
+		}
+		return unused
+	}
+
+	if unused := unusedRunners(validate.Options{}); len(unused) != 0 {
+		t.Errorf("Expected no unused runner warnings by default, got %+v", unused)
+	}
+	if unused := unusedRunners(validate.Options{UnusedRunners: true}); len(unused) != 2 || unused[0].Line != 4 || unused[1].Line != 6 {
+		t.Errorf("Expected warnings for runners 'gpu-large' and 'legacy', got %+v", unused)
+	}
+	unused := unusedRunners(validate.Options{UnusedRunners: true, LabelRunners: []string{"gpu-*"}})
+	if len(unused) != 1 || unused[0].Line != 6 || unused[0].Severity != validate.SeverityWarning ||
+		unused[0].Message != "runner 'legacy' is not used by any pool; list it as a label runner if jobs select it with runner=" {
+		t.Errorf("Expected a warning for runner 'legacy', got %+v", unused)
+	}
+
+	if _, err := validate.ValidateBytesWithOptions(context.Background(), []byte(yamlContent), "runs-on.yml",
+		validate.Options{UnusedRunners: true, LabelRunners: []string{"["}}); err == nil {
+		t.Error("Expected an error for an invalid label runner pattern")
+	}
+}
+
+func TestValidateBytes_DuplicateKeys(t *testing.T) {
+	yamlContent := `runners:
+  small:
+    cpu: [2]
+    ram: [4]
+    cpu: [4]
+  small:
+    cpu: [8]
+pools:
+  main:
+    runner: small
+    schedule:
+      - name: default
+        hot: 1
+`
+	diags, err := validate.ValidateBytes(context.Background(), []byte(yamlContent), "runs-on.yml")
+	if err != nil {
+		t.Fatalf("ValidateBytes failed: %v", err)
+	}
+	var got []string
+	for _, diag := range diags {
+		if diag.RuleID != validate.RuleDuplicateKey || diag.Severity != validate.SeverityError {
+			t.Errorf("Unexpected diagnostic %+v", diag)
+			continue
+		}
+		got = append(got, fmt.Sprintf("%d:%d %s %s", diag.Line, diag.Column, diag.FieldPath, diag.Message))
+	}
+	want := []string{
+		"6:3 runners.small key 'small' is already defined at line 2",
+		"5:5 runners.small.cpu key 'cpu' is already defined at line 3",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("Expected errors\n%q\ngot\n%q", want, got)
+	}
+}
+
+func TestValidateBytes_SchemaErrorPositions(t *testing.T) {
+	yamlContent := `runners:
+  defaults: &defaults
+    private: 12
+  small:
+    <<: *defaults
+    cpu: [2]
+    spot: maybe
+`
+	diags, err := validate.ValidateBytes(context.Background(), []byte(yamlContent), "runs-on.yml")
+	if err != nil {
+		t.Fatalf("ValidateBytes failed: %v", err)
+	}
+	positions := make(map[string]string)
+	for _, diag := range diags {
+		if diag.RuleID == validate.RuleSchema {
+			positions[diag.FieldPath] = fmt.Sprintf("%d:%d", diag.Line, diag.Column)
+		}
+	}
+	want := map[string]string{
+		"runners.defaults.private": "3:14",
+		"runners.small.private":    "3:14",
+		"runners.small.spot":       "7:11",
+	}
+	if !maps.Equal(positions, want) {
+		t.Errorf("Expected schema errors at the YAML values %v, got %v", want, positions)
+	}
+}
+
+func TestValidateBytes_Tags(t *testing.T) {
+	var many []string
+	for i := range 41 {
+		many = append(many, fmt.Sprintf("Key%d:value", i))
+	}
+	yamlContent := `runners:
+  small:
+    tags:
+      - Team:DevOps
+      - team
+      - aws:owner:devops
+      - Cost#center:1
+      - Description:` + strings.Repeat("x", 257) + `
+  tagged:
+    tags: "` + strings.Join(many, "+") + `"
+images:
+  custom:
+    ami: ami-1234567890abcdef0
+    tags:
+      Team: DevOps
+      ` + strings.Repeat("k", 129) + `: value
+`
+	diags, err := validate.ValidateBytes(context.Background(), []byte(yamlContent), "runs-on.yml")
+	if err != nil {
+		t.Fatalf("ValidateBytes failed: %v", err)
+	}
+	var got []string
+	for _, diag := range diags {
+		if diag.RuleID == validate.RuleTagFormat || diag.RuleID == validate.RuleTagLimit {
+			got = append(got, fmt.Sprintf("%d %s", diag.Line, diag.RuleID))
+		}
+	}
+	want := []string{
+		"5 tag-format", "6 tag-format", "7 tag-format", "8 tag-limit",
+		"10 tag-limit", "16 tag-limit",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("Expected diagnostics %q, got %q: %v", want, got, diags)
+	}
+}
+
+func TestValidateBytes_Volumes(t *testing.T) {
+	yamlContent := `runners:
+  ok:
+    volume: 80gb:gp3:125mbs:3000iops
+  reordered:
+    volume: gp3:40gb:125mbps:3000iops
+  typo:
+    volume: "80gb:gp4"
+  unit:
+    volume: 80tb:gp3
+  twice:
+    volume: 80gb:gp3:100gb
+  small-io2:
+    volume: 2gb:io2:3000iops
+  gp2-iops:
+    volume: 80gb:gp2:3000iops
+  fast:
+    volume: 80gb:gp3:1000mbs
+  empty:
+    volume: ":::::"
+`
+	diags, err := validate.ValidateBytes(context.Background(), []byte(yamlContent), "runs-on.yml")
+	if err != nil {
+		t.Fatalf("ValidateBytes failed: %v", err)
+	}
+	var got []string
+	for _, diag := range diags {
+		if diag.RuleID == validate.RuleVolumeSpec {
+			got = append(got, fmt.Sprintf("%d:%d-%d %s %s", diag.Line, diag.Column, diag.EndColumn, diag.Severity, diag.FieldPath))
+		}
+	}
+	want := []string{
+		// Empty components are reported once
+		"19:14-14 error runners.empty.volume",
+		"17:22-29 error runners.fast.volume",
+		"15:22-30 error runners.gp2-iops.volume",
+		"13:13-16 error runners.small-io2.volume",
+		"13:21-29 error runners.small-io2.volume",
+		"11:22-27 error runners.twice.volume",
+		"7:19-22 error runners.typo.volume",
+		"9:13-17 error runners.unit.volume",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("Expected diagnostics\n%q\ngot\n%q", want, got)
+	}
+
+	for _, diag := range diags {
+		if diag.Line == 7 && diag.Message != "runner 'typo': unknown volume type 'gp4' (expected one of gp2, gp3, io1, io2, sc1, st1, standard)" {
+			t.Errorf("Unexpected message: %s", diag.Message)
+		}
+	}
+}
+
+func TestValidateBytes_ImageAMIs(t *testing.T) {
+	yamlContent := `images:
+  current:
+    ami: ami-0123456789abcdef0
+  legacy:
+    ami: ami-1a2b3c4d
+  placeholder:
+    ami: ami-xxxx
+  uppercase:
+    ami: ami-0123456789ABCDEF0
+  by-name:
+    name: ubuntu-22.04
+    owner: "123456789012"
+runners:
+  small:
+    image: current
+  old:
+    image: legacy
+  todo:
+    image: placeholder
+  upper:
+    image: uppercase
+  named:
+    image: by-name
+`
+	diags, err := validate.ValidateBytes(context.Background(), []byte(yamlContent), "runs-on.yml")
+	if err != nil {
+		t.Fatalf("ValidateBytes failed: %v", err)
+	}
+	var got []string
+	for _, diag := range diags {
+		if diag.RuleID == validate.RuleImageAMI {
+			got = append(got, fmt.Sprintf("%d %s", diag.Line, diag.Message))
+		}
+	}
+	want := []string{
+		"7 image 'placeholder': ami 'ami-xxxx' is a placeholder: set the ID of the AMI to launch",
+		"9 image 'uppercase': ami 'ami-0123456789ABCDEF0' is not an AMI ID: expected 'ami-' followed by 8 or 17 hexadecimal characters",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("Expected errors\n%q\ngot\n%q", want, got)
+	}
+}
+
+func TestValidateBytes_Feasibility(t *testing.T) {
+	yamlContent := `runners:
+  fits:
+    family: [c7a]
+    cpu: [2]
+    ram: [16]
+  too-much-ram:
+    family: [c7a]
+    ram: [512]
+  too-many-cpus:
+    family: "m7a+c7a"
+    cpu: [256, 512]
+    ram: [4096]
+  memory-optimized:
+    family: [r7a]
+    ram: [512]
+  small-type:
+    family: [t3.large]
+    ram: 16
+  accelerated:
+    family: [g5]
+    ram: [4096]
+`
+	diags, err := validate.ValidateBytes(context.Background(), []byte(yamlContent), "runs-on.yml")
+	if err != nil {
+		t.Fatalf("ValidateBytes failed: %v", err)
+	}
+	var got []string
+	for _, diag := range diags {
+		if diag.RuleID == validate.RuleNoMatchingInstance {
+			got = append(got, fmt.Sprintf("%d %s", diag.Line, diag.Message))
+		}
+	}
+	want := []string{
+		"18 runner 'small-type': t3.large instance types have at most 8GB of memory, less than the 16GB requested",
+		"11 runner 'too-many-cpus': m7a, c7a instance types have at most 192 vCPUs, fewer than the 256 requested",
+		"8 runner 'too-much-ram': c7a instance types have at most 384GB of memory, less than the 512GB requested",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("Expected warnings\n%q\ngot\n%q", want, got)
+	}
+}
+
+func TestValidateBytes_UnknownExtras(t *testing.T) {
+	yamlContent := `runners:
+  listed:
+    extras: [s3cache, tmpfs, EFS]
+  plus:
+    extras: "tmpfs + ecr_cache+gpu"
+  valid:
+    extras: s3-cache+ecr-cache+efs+tmpfs
+`
+	diags, err := validate.ValidateBytes(context.Background(), []byte(yamlContent), "runs-on.yml")
+	if err != nil {
+		t.Fatalf("ValidateBytes failed: %v", err)
+	}
+	var got []string
+	for _, diag := range diags {
+		if diag.RuleID != validate.RuleExtrasUnknown {
+			continue
+		}
+		entry := fmt.Sprintf("%d:%d-%d %s %s", diag.Line, diag.Column, diag.EndColumn, diag.FieldPath, diag.Message)
+		if diag.Fix != nil {
+			entry += " (" + diag.Fix.Description + ")"
+		}
+		got = append(got, entry)
+	}
+	want := []string{
+		"3:14-21 runners.listed.extras runner 'listed': unknown extra 's3cache'; did you mean 's3-cache'? (Replace 's3cache' with 's3-cache')",
+		"3:30-33 runners.listed.extras runner 'listed': unknown extra 'EFS'; did you mean 'efs'? (Replace 'EFS' with 'efs')",
+		"5:22-31 runners.plus.extras runner 'plus': unknown extra 'ecr_cache'; did you mean 'ecr-cache'?",
+		"5:32-35 runners.plus.extras runner 'plus': unknown extra 'gpu' (expected one of ecr-cache, efs, s3-cache, tmpfs)",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("Expected warnings\n%q\ngot\n%q", want, got)
+	}
+}
+
 func TestValidateFile_IndentationIssues(t *testing.T) {
 	testFiles := []string{
 		"../../schema/testdata/invalid/indentation-issue.yml",
@@ -444,7 +1005,11 @@ func TestValidateReader_ImageAllFields(t *testing.T) {
 		t.Fatalf("ValidateReader failed: %v", err)
 	}
 
-	errors := filterErrors(diags)
+	// The schema accepts all fields, though setting both 'ami' and an AMI
+	// search is reported by image-source
+	errors := slices.DeleteFunc(filterErrors(diags), func(diag validate.Diagnostic) bool {
+		return diag.RuleID == validate.RuleImageSource
+	})
 	if len(errors) > 0 {
 		t.Errorf("Expected no errors for image with all fields, got %d:", len(errors))
 		for _, diag := range errors {
@@ -970,20 +1535,2088 @@ pools:
 	}
 }
 
-// filterErrors returns only error-level diagnostics, filtering out warnings
-func filterErrors(diags []validate.Diagnostic) []validate.Diagnostic {
-	var errors []validate.Diagnostic
+func TestValidateFile_RuleIDs(t *testing.T) {
+	testFiles, err := filepath.Glob("../../schema/testdata/invalid/*.yml")
+	if err != nil {
+		t.Fatalf("Failed to list testdata: %v", err)
+	}
+	testFiles = append(testFiles, "../../schema/testdata/valid/with-deprecated-disk.yml")
+
+	for _, testFile := range testFiles {
+		t.Run(filepath.Base(testFile), func(t *testing.T) {
+			diags, err := validate.ValidateFile(context.Background(), testFile)
+			if err != nil {
+				t.Fatalf("ValidateFile failed: %v", err)
+			}
+
+			for _, diag := range diags {
+				rule, ok := validate.LookupRule(diag.RuleID)
+				if !ok {
+					t.Errorf("Diagnostic has unregistered rule ID %q: %s", diag.RuleID, diag.Message)
+					continue
+				}
+				if rule.Severity != diag.Severity {
+					t.Errorf("Rule %s has severity %s but diagnostic has %s", rule.ID, rule.Severity, diag.Severity)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateReader_PublicSSH(t *testing.T) {
+	yamlContent := `runners:
+  public-ssh:
+    cpu: [2]
+    ssh: true
+  private-ssh:
+    cpu: [2]
+    ssh: "true"
+    private: "true"
+  no-ssh:
+    cpu: [2]
+    ssh: false
+  default-ssh:
+    cpu: [2]
+`
+
+	diags, err := validate.ValidateReader(context.Background(), strings.NewReader(yamlContent), "test.yml")
+	if err != nil {
+		t.Fatalf("ValidateReader failed: %v", err)
+	}
+
+	var warnings []validate.Diagnostic
 	for _, diag := range diags {
-		if diag.Severity == validate.SeverityError {
-			errors = append(errors, diag)
+		if diag.RuleID == validate.RulePublicSSH {
+			warnings = append(warnings, diag)
 		}
 	}
-	return errors
+	if len(warnings) != 1 {
+		t.Fatalf("Expected 1 %s warning, got %d: %v", validate.RulePublicSSH, len(warnings), diags)
+	}
+	if warnings[0].Severity != validate.SeverityWarning || warnings[0].Line != 4 || !contains(warnings[0].Message, "public-ssh") {
+		t.Errorf("Unexpected warning: %+v", warnings[0])
+	}
 }
 
-// Helper function to check if a string contains a substring (case-insensitive)
-func contains(s, substr string) bool {
-	sLower := strings.ToLower(s)
-	substrLower := strings.ToLower(substr)
+func TestValidateReader_Extras(t *testing.T) {
+	yamlContent := `runners:
+  efs-large-volume:
+    extras: efs+s3-cache
+    volume: 500gb:gp3
+  efs-small-volume:
+    extras: [efs]
+    volume: 80gb:gp3
+  efs-windows:
+    extras: [efs]
+    image: windows22-full-x64
+  tmpfs-custom-windows:
+    extras: [tmpfs]
+    image: custom
+  s3-cache-only:
+    extras: [s3-cache]
+    image: windows22-full-x64
+images:
+  custom:
+    platform: windows
+    ami: ami-1234567890abcdef0
+`
+
+	diags, err := validate.ValidateReader(context.Background(), strings.NewReader(yamlContent), "test.yml")
+	if err != nil {
+		t.Fatalf("ValidateReader failed: %v", err)
+	}
+
+	var runners []string
+	for _, diag := range diags {
+		if diag.RuleID != validate.RuleExtrasRequirement {
+			continue
+		}
+		if diag.Severity != validate.SeverityWarning {
+			t.Errorf("Expected a warning, got %+v", diag)
+		}
+		runner := strings.Split(diag.Message, "'")[1]
+		if runner == "efs-large-volume" && diag.Line != 3 {
+			t.Errorf("Expected the warning on the extras line, got %+v", diag)
+		}
+		runners = append(runners, runner)
+	}
+	expected := []string{"efs-large-volume", "efs-windows", "tmpfs-custom-windows"}
+	if strings.Join(runners, ",") != strings.Join(expected, ",") {
+		t.Errorf("Expected warnings for %v, got %v: %v", expected, runners, diags)
+	}
+}
+
+func TestValidateReader_Admins(t *testing.T) {
+	yamlContent := `admins:
+  - carol
+  - alice
+  - Carol
+  - bob
+`
+
+	ruleCounts := func(diags []validate.Diagnostic) map[string]int {
+		counts := make(map[string]int)
+		for _, diag := range diags {
+			counts[diag.RuleID]++
+		}
+		return counts
+	}
+
+	diags, err := validate.ValidateReader(context.Background(), strings.NewReader(yamlContent), "test.yml")
+	if err != nil {
+		t.Fatalf("ValidateReader failed: %v", err)
+	}
+	counts := ruleCounts(diags)
+	if counts[validate.RuleAdminsDuplicate] != 1 || counts[validate.RuleAdminsOrder] != 0 {
+		t.Fatalf("Expected only a duplicate warning, got %v", diags)
+	}
+	for _, diag := range diags {
+		if diag.RuleID == validate.RuleAdminsDuplicate && (diag.Line != 4 || !contains(diag.Message, "first at line 2")) {
+			t.Errorf("Unexpected warning: %+v", diag)
+		}
+	}
+
+	diags, err = validate.ValidateReaderWithOptions(context.Background(), strings.NewReader(yamlContent), "test.yml", validate.Options{StrictAdmins: true})
+	if err != nil {
+		t.Fatalf("ValidateReaderWithOptions failed: %v", err)
+	}
+	counts = ruleCounts(diags)
+	if counts[validate.RuleAdminsDuplicate] != 1 || counts[validate.RuleAdminsOrder] != 1 {
+		t.Fatalf("Expected a duplicate and an order warning, got %v", diags)
+	}
+
+	diags, err = validate.ValidateReaderWithOptions(context.Background(), strings.NewReader("admins: [Alice, bob, carol]\n"), "test.yml", validate.Options{StrictAdmins: true})
+	if err != nil {
+		t.Fatalf("ValidateReaderWithOptions failed: %v", err)
+	}
+	if len(diags) != 0 {
+		t.Errorf("Expected no diagnostics for sorted admins, got %v", diags)
+	}
+}
+
+func TestValidateReader_AdminUsernames(t *testing.T) {
+	yamlContent := `admins:
+  - alice
+  - Bob-42
+  - ""
+  - -carol
+  - dave_smith
+  - "@erin"
+  - frank@example.com
+  - a-very-long-username-exceeding-the-limit
+`
+	diags, err := validate.ValidateReader(context.Background(), strings.NewReader(yamlContent), "test.yml")
+	if err != nil {
+		t.Fatalf("ValidateReader failed: %v", err)
+	}
+	var got []string
+	for _, diag := range diags {
+		if diag.RuleID == validate.RuleAdminsUsername || diag.RuleID == validate.RuleAdminsUsernameForm {
+			got = append(got, fmt.Sprintf("%d %s", diag.Line, diag.RuleID))
+		}
+	}
+	want := []string{"4 admins-username", "5 admins-username", "6 admins-username", "7 admins-username-form", "8 admins-username-form", "9 admins-username"}
+	if !slices.Equal(got, want) {
+		t.Errorf("Expected diagnostics %q, got %q: %v", want, got, diags)
+	}
+}
+
+func TestValidateReader_Unused(t *testing.T) {
+	yamlContent := `x-base: &base
+  family: [c7a]
+x-extended: &extended
+  <<: *base
+  cpu: 2
+x-defaults: &defaults
+  ram: 16
+runners:
+  small: &small
+    <<: *defaults
+    cpu: &cpu 2
+`
+	diags, err := validate.ValidateReader(context.Background(), strings.NewReader(yamlContent), "test.yml")
+	if err != nil {
+		t.Fatalf("ValidateReader failed: %v", err)
+	}
+
+	// x-base is only used by the unused x-extended block
+	var got []string
+	for _, diag := range diags {
+		if diag.RuleID == validate.RuleUnusedAnchor || diag.RuleID == validate.RuleUnusedExtension {
+			got = append(got, fmt.Sprintf("%s:%d:%d", diag.RuleID, diag.Line, diag.Column))
+		}
+	}
+	expected := []string{
+		validate.RuleUnusedExtension + ":1:1",
+		validate.RuleUnusedExtension + ":3:1",
+		validate.RuleUnusedAnchor + ":9:10",
+		validate.RuleUnusedAnchor + ":11:10",
+	}
+	if strings.Join(got, " ") != strings.Join(expected, " ") {
+		t.Errorf("Expected %v, got %v", expected, diags)
+	}
+}
+
+func TestValidateReader_EndPositions(t *testing.T) {
+	yamlContent := `runners:
+  small:
+    cpu: 2
+    disk: default
+admins:
+  - alice
+  - "Alice"
+`
+	diags, err := validate.ValidateReader(context.Background(), strings.NewReader(yamlContent), "test.yml")
+	if err != nil {
+		t.Fatalf("ValidateReader failed: %v", err)
+	}
+	ends := make(map[string][4]int)
+	for _, diag := range diags {
+		ends[diag.RuleID] = [4]int{diag.Line, diag.Column, diag.EndLine, diag.EndColumn}
+	}
+	if got := ends[validate.RuleDeprecatedDisk]; got != [4]int{4, 5, 4, 9} {
+		t.Errorf("Expected the deprecated disk warning to span the key, got %v", got)
+	}
+	if got := ends[validate.RuleAdminsDuplicate]; got != [4]int{7, 5, 7, 12} {
+		t.Errorf("Expected the duplicate admin warning to span the quoted name, got %v", got)
+	}
+}
+
+func TestValidateReader_Offsets(t *testing.T) {
+	yamlContent := `# café
+runners:
+  small:
+    cpu: 2
+    disk: default
+pools:
+  main:
+    runner: missing
+admins:
+  - zoë
+  - "Zoë"
+`
+	diags, err := validate.ValidateReader(context.Background(), strings.NewReader(yamlContent), "test.yml")
+	if err != nil {
+		t.Fatalf("ValidateReader failed: %v", err)
+	}
+	spans := make(map[string]string)
+	for _, diag := range diags {
+		if diag.Offset < 0 || diag.EndOffset < diag.Offset || diag.EndOffset > len(yamlContent) {
+			t.Fatalf("Invalid range %d-%d for %+v", diag.Offset, diag.EndOffset, diag)
+		}
+		spans[diag.RuleID] = yamlContent[diag.Offset:diag.EndOffset]
+	}
+	for rule, want := range map[string]string{
+		validate.RuleDeprecatedDisk:      "disk",
+		validate.RulePoolRunnerUndefined: "missing",
+		validate.RuleAdminsDuplicate:     `"Zoë"`,
+	} {
+		if got, ok := spans[rule]; !ok || got != want {
+			t.Errorf("Expected %s to span %q, got %q", rule, want, got)
+		}
+	}
+}
+
+func TestValidateBytes_Schedules(t *testing.T) {
+	yamlContent := `runners:
+  small:
+    cpu: 2
+pools:
+  main:
+    runner: small
+    schedule:
+      - name: weekend
+        hot: 0
+        match:
+          day: [Saturday]
+      - name: night
+        hot: 1
+        match:
+          time: ["22:00", "06:00"]
+      - name: default
+        hot: 2
+  office:
+    runner: small
+    schedule:
+      - name: office
+        hot: 1
+        match:
+          day: [monday, frday]
+          time: ["08:00"]
+`
+	diags, err := validate.ValidateBytes(context.Background(), []byte(yamlContent), "test.yml")
+	if err != nil {
+		t.Fatalf("ValidateBytes failed: %v", err)
+	}
+	var lines []int
+	for _, diag := range diags {
+		if diag.RuleID == validate.RuleScheduleMatch {
+			lines = append(lines, diag.Line)
+		}
+		if diag.RuleID == validate.RuleShutdownHot {
+			t.Errorf("Expected no shutdown day warning without shutdown days, got %v", diag)
+		}
+	}
+	if !slices.Equal(lines, []int{24, 25}) {
+		t.Errorf("Expected the invalid day and time range to be reported at lines 24 and 25, got %v", lines)
+	}
+
+	diags, err = validate.ValidateBytesWithOptions(context.Background(), []byte(yamlContent), "test.yml",
+		validate.Options{ShutdownDays: []string{"saturday", "Sunday"}})
+	if err != nil {
+		t.Fatalf("ValidateBytesWithOptions failed: %v", err)
+	}
+	var warnings []string
+	for _, diag := range diags {
+		if diag.RuleID == validate.RuleShutdownHot {
+			warnings = append(warnings, fmt.Sprintf("%d: %s", diag.Line, diag.Message))
+		}
+	}
+	// The weekend entry keeps no hot instances on saturday, but the default
+	// entry still applies on sunday and the night entry every day
+	want := []string{
+		"13: schedule 'night' of pool 'main' keeps 1 hot instances on saturday, a shutdown day",
+		"17: schedule 'default' of pool 'main' keeps 2 hot instances on sunday, a shutdown day",
+	}
+	if !slices.Equal(warnings, want) {
+		t.Errorf("Expected warnings %q, got %q", want, warnings)
+	}
+
+	if _, err := validate.ValidateBytesWithOptions(context.Background(), []byte(yamlContent), "test.yml",
+		validate.Options{ShutdownDays: []string{"weekend"}}); err == nil {
+		t.Error("Expected an error for an invalid shutdown day")
+	}
+}
+
+func TestValidateBytes_ScheduleConsistency(t *testing.T) {
+	yamlContent := `runners:
+  small:
+    cpu: 2
+pools:
+  main:
+    runner: small
+    schedule:
+      - name: weekdays
+        hot: 1
+        match:
+          day: [monday, Monday, tuesday]
+          time: ["08:00", "8:00"]
+      - name: empty
+        hot: 0
+        match: {}
+      - name: default
+        hot: 0
+`
+	diags, err := validate.ValidateBytes(context.Background(), []byte(yamlContent), "test.yml")
+	if err != nil {
+		t.Fatalf("ValidateBytes failed: %v", err)
+	}
+	var got []string
+	for _, diag := range diags {
+		if diag.RuleID == validate.RuleScheduleConsistency {
+			got = append(got, fmt.Sprintf("%d:%d %s %s", diag.Line, diag.Column, diag.Severity, diag.Message))
+		}
+	}
+	want := []string{
+		"11:25 warning pools.main.schedule.0 ('weekdays') lists day 'Monday' more than once",
+		"12:17 warning pools.main.schedule.0 ('weekdays') matches a time range starting and ending at 08:00, which spans the whole day; remove 'time' to match all day",
+		"15:9 warning pools.main.schedule.1 ('empty') has 'match' without days or times, so it only applies when no other entry matches; remove 'match' to make it the default entry",
+		"16:9 warning pools.main.schedule.2 ('default') has no match criteria and never applies: pools.main.schedule.1 ('empty') already applies whenever no other entry matches",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("Expected diagnostics\n%q\ngot\n%q", want, got)
+	}
+}
+
+func TestValidateBytes_ScheduleDuplicateNames(t *testing.T) {
+	yamlContent := `runners:
+  small:
+    cpu: 2
+pools:
+  main:
+    runner: small
+    schedule:
+      - name: office
+        hot: 2
+        match:
+          day: [monday]
+      - name: office
+        hot: 1
+        match:
+          day: [tuesday]
+      - name: default
+        hot: 0
+  other:
+    runner: small
+    schedule:
+      - name: office
+        hot: 1
+`
+	diags, err := validate.ValidateBytes(context.Background(), []byte(yamlContent), "test.yml")
+	if err != nil {
+		t.Fatalf("ValidateBytes failed: %v", err)
+	}
+	var got []string
+	for _, diag := range diags {
+		if diag.RuleID == validate.RuleScheduleDuplicateName {
+			got = append(got, fmt.Sprintf("%d:%d %s %s", diag.Line, diag.Column, diag.Severity, diag.Message))
+		}
+	}
+	want := []string{
+		"12:15 error pools.main.schedule.1 ('office') has the same name as pools.main.schedule.0 ('office'); later entries shadow earlier ones with the same name, so give each entry a unique name",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("Expected diagnostics\n%q\ngot\n%q", want, got)
+	}
+}
+
+func TestValidateBytes_PoolCapacity(t *testing.T) {
+	yamlContent := `runners:
+  small:
+    cpu: 2
+pools:
+  busy:
+    runner: small
+    schedule:
+      - name: office
+        hot: 100
+        stopped: 10
+        match:
+          day: [monday]
+      - name: default
+        hot: 30
+        stopped: 2
+  idle:
+    runner: small
+    schedule:
+      - name: default
+        hot: 0
+        stopped: 0
+  sized:
+    runner: small
+    schedule:
+      - name: default
+        hot: 10
+        stopped: 1
+`
+	for _, tc := range []struct {
+		maxHot int
+		want   []string
+	}{
+		{0, []string{
+			"9 pools.busy.schedule.0 ('office') keeps 100 hot instances, more than the 50 expected: hot instances are billed while idle, so check the count is not a typo",
+			"14 pools.busy.schedule.1 ('default') keeps 30 hot instances but only 2 stopped: stopped instances start quickly and only cost their volumes, so consider keeping some of the hot instances stopped",
+			"16 pool 'idle' keeps no hot or stopped instances in any schedule entry, so it does not speed up any job",
+		}},
+		{20, []string{
+			"9 pools.busy.schedule.0 ('office') keeps 100 hot instances, more than the 20 expected: hot instances are billed while idle, so check the count is not a typo",
+			"14 pools.busy.schedule.1 ('default') keeps 30 hot instances, more than the 20 expected: hot instances are billed while idle, so check the count is not a typo",
+			"16 pool 'idle' keeps no hot or stopped instances in any schedule entry, so it does not speed up any job",
+		}},
+		{-1, []string{
+			"14 pools.busy.schedule.1 ('default') keeps 30 hot instances but only 2 stopped: stopped instances start quickly and only cost their volumes, so consider keeping some of the hot instances stopped",
+			"16 pool 'idle' keeps no hot or stopped instances in any schedule entry, so it does not speed up any job",
+		}},
+	} {
+		diags, err := validate.ValidateBytesWithOptions(context.Background(), []byte(yamlContent), "test.yml", validate.Options{MaxHot: tc.maxHot})
+		if err != nil {
+			t.Fatalf("ValidateBytesWithOptions failed: %v", err)
+		}
+		var got []string
+		for _, diag := range diags {
+			if diag.RuleID == validate.RulePoolCapacity {
+				got = append(got, fmt.Sprintf("%d %s", diag.Line, diag.Message))
+			}
+		}
+		if !slices.Equal(got, tc.want) {
+			t.Errorf("MaxHot %d: expected warnings\n%q\ngot\n%q", tc.maxHot, tc.want, got)
+		}
+	}
+}
+
+func TestValidateBytes_Timezones(t *testing.T) {
+	yamlContent := `runners:
+  small:
+    cpu: 2
+pools:
+  paris:
+    runner: small
+    timezone: Europe/Paris
+    schedule: &schedule
+      - name: default
+        hot: 1
+        stopped: 1
+  eastern:
+    runner: small
+    timezone: US/Eastern
+    schedule: *schedule
+  mars:
+    runner: small
+    timezone: Mars/Olympus
+    schedule: *schedule
+  local:
+    runner: small
+    timezone: Local
+    schedule: *schedule
+`
+	diags, err := validate.ValidateBytes(context.Background(), []byte(yamlContent), "test.yml")
+	if err != nil {
+		t.Fatalf("ValidateBytes failed: %v", err)
+	}
+	var got []string
+	for _, diag := range diags {
+		if diag.RuleID == validate.RuleTimezone || diag.RuleID == validate.RuleTimezoneLegacy {
+			got = append(got, fmt.Sprintf("%d:%d %s %s", diag.Line, diag.Column, diag.Severity, diag.Message))
+		}
+	}
+	want := []string{
+		"14:15 warning pool 'eastern' uses the legacy timezone alias 'US/Eastern': use 'America/New_York' instead",
+		"18:15 error pool 'mars' has unknown timezone 'Mars/Olympus': use a zone of the IANA tz database, e.g. America/New_York or UTC",
+		"22:15 error pool 'local' has unknown timezone 'Local': use a zone of the IANA tz database, e.g. America/New_York or UTC",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("Expected diagnostics\n%q\ngot\n%q", want, got)
+	}
+}
+
+func TestValidateBytes_PreinstallShell(t *testing.T) {
+	yamlContent := `runners:
+  quote:
+    preinstall: |
+      if [ -f /etc/os-release ]; then
+        echo "linux
+      fi
+  mismatch:
+    preinstall: |
+      for f in *.sh; do
+        bash "$f"
+      fi
+  python:
+    preinstall: |
+      #!/usr/bin/env python3
+      print("x
+  windows:
+    image: win
+    preinstall: "if ("
+  valid:
+    preinstall: |
+      cat <<EOF > /etc/motd
+      $(hostname) "quoted
+      EOF
+      case "$1" in
+        a) echo a ;;
+      esac
+  inline:
+    preinstall: echo $(date
+images:
+  win:
+    platform: windows
+    ami: ami-1234567890abcdef0
+    preinstall: 'Write-Host "hi'
+  linux:
+    platform: linux
+    ami: ami-1234567890abcdef0
+    preinstall: |
+      echo ${HOME
+`
+	diags, err := validate.ValidateBytes(context.Background(), []byte(yamlContent), "test.yml")
+	if err != nil {
+		t.Fatalf("ValidateBytes failed: %v", err)
+	}
+	var got []string
+	for _, diag := range diags {
+		if diag.RuleID == validate.RulePreinstallShell {
+			got = append(got, fmt.Sprintf("%d:%d %s %s", diag.Line, diag.Column, diag.FieldPath, diag.Message))
+		}
+	}
+	want := []string{
+		"28:22 runners.inline.preinstall runner 'inline': preinstall script has a '$(' that is never closed",
+		"11:7 runners.mismatch.preinstall runner 'mismatch': preinstall script closes the 'for' of line 9 with 'fi' instead of 'done'",
+		"5:14 runners.quote.preinstall runner 'quote': preinstall script has a \" quote that is never closed",
+		"38:12 images.linux.preinstall image 'linux': preinstall script has a '${' that is never closed",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("Expected diagnostics\n%q\ngot\n%q", want, got)
+	}
+}
+
+func TestValidateBytes_FieldPaths(t *testing.T) {
+	yamlContent := `runners:
+  small:
+    cpu: 2
+    disk: default
+pools:
+  main:
+    runner: missing
+    schedule:
+      - name: default
+        hot: -1
+        stopped: 0
+        match:
+          day: [monday, mon]
+admins:
+  - alice
+  - alice
+`
+	diags, err := validate.ValidateBytes(context.Background(), []byte(yamlContent), "test.yml")
+	if err != nil {
+		t.Fatalf("ValidateBytes failed: %v", err)
+	}
+	paths := make(map[string]string)
+	for _, diag := range diags {
+		paths[diag.RuleID] = diag.FieldPath
+	}
+	for rule, want := range map[string]string{
+		validate.RuleDeprecatedDisk:      "runners.small.disk",
+		validate.RulePoolRunnerUndefined: "pools.main.runner",
+		validate.RuleScheduleMatch:       "pools.main.schedule.0.match.day.1",
+		validate.RuleAdminsDuplicate:     "admins.1",
+	} {
+		if got := paths[rule]; got != want {
+			t.Errorf("Expected %s to have field path %q, got %q", rule, want, got)
+		}
+	}
+	if got := paths[validate.RuleSchema]; !strings.HasPrefix(got, "pools.main.schedule.0") {
+		t.Errorf("Expected the schema error to have the path of the schedule entry, got %q", got)
+	}
+
+	diags, err = validate.ValidateBytes(context.Background(), []byte("runners: ["), "test.yml")
+	if err != nil {
+		t.Fatalf("ValidateBytes failed: %v", err)
+	}
+	if len(diags) != 1 || diags[0].FieldPath != "" {
+		t.Errorf("Expected a syntax error without field path, got %+v", diags)
+	}
+}
+
+func TestValidateBytes_PoolModes(t *testing.T) {
+	yamlContent := `runners:
+  small:
+    cpu: 2
+pools:
+  static:
+    runner: small
+    hot: 2
+    stopped: 3
+  both:
+    runner: small
+    size: 1
+    schedule:
+      - name: default
+        hot: 1
+        stopped: 0
+  empty:
+    runner: small
+`
+	diags, err := validate.ValidateBytes(context.Background(), []byte(yamlContent), "test.yml")
+	if err != nil {
+		t.Fatalf("ValidateBytes failed: %v", err)
+	}
+	var found []string
+	for _, diag := range diags {
+		if diag.RuleID != validate.RulePoolMode && diag.RuleID != validate.RulePoolNoSchedule {
+			// Static fields are reported once, by pool-mode
+			t.Errorf("Unexpected diagnostic %+v", diag)
+			continue
+		}
+		found = append(found, fmt.Sprintf("%d:%s:%s", diag.Line, diag.RuleID, diag.Severity))
+		if diag.Fix == nil {
+			t.Errorf("Expected a fix for %v", diag)
+		}
+	}
+	if want := []string{"7:pool-mode:error", "11:pool-mode:error", "16:pool-no-schedule:warning"}; !slices.Equal(found, want) {
+		t.Errorf("Expected %q, got %q", want, found)
+	}
+
+	fixed, _ := validate.ApplyFixes([]byte(yamlContent), diags)
+	wantFixed := `runners:
+  small:
+    cpu: 2
+pools:
+  static:
+    runner: small
+    schedule:
+      - name: default
+        hot: 2
+        stopped: 3
+  both:
+    runner: small
+    schedule:
+      - name: default
+        hot: 1
+        stopped: 0
+  empty:
+    runner: small
+    schedule:
+      - name: default
+        hot: 0
+        stopped: 1
+`
+	if string(fixed) != wantFixed {
+		t.Errorf("Unexpected fixed config:\n%s", fixed)
+	}
+}
+
+func TestSuggestedFixes(t *testing.T) {
+	yamlContent := `runner:
+  small:
+    cpu: 2
+runners:
+  small:
+    cpu: 2
+    disk: default
+pools:
+  main:
+    runner: small
+    environment: staging
+    schedule:
+      - name: default
+        hot: 1
+        stopped: 0
+        match:
+          day: [mon, fryday]
+  other:
+    runner: smal
+    schedule:
+      - name: default
+        hot: 0
+        stopped: 1
+`
+	v, err := validate.New(validate.WithStrict())
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	diags, err := v.ValidateBytes(context.Background(), []byte(yamlContent), "test.yml")
+	if err != nil {
+		t.Fatalf("ValidateBytes failed: %v", err)
+	}
+	var descriptions []string
+	for _, diag := range diags {
+		if diag.RuleID == validate.RulePoolRunnerUndefined && !strings.Contains(diag.Message, "did you mean 'small'?") {
+			t.Errorf("Expected a suggestion in %q", diag.Message)
+		}
+		if diag.Fix != nil {
+			descriptions = append(descriptions, diag.Fix.Description)
+		}
+	}
+	sort.Strings(descriptions)
+	want := []string{
+		"Remove the ignored 'disk' field",
+		"Rename 'environment' to 'env'",
+		"Replace 'fryday' with 'friday'",
+		"Replace 'mon' with 'monday'",
+		"Replace 'smal' with 'small'",
+	}
+	if !slices.Equal(descriptions, want) {
+		t.Errorf("Expected fixes %q, got %q", want, descriptions)
+	}
+
+	fixed, applied := validate.ApplyFixes([]byte(yamlContent), diags)
+	if applied != len(want) {
+		t.Errorf("Expected %d fixes to be applied, got %d", len(want), applied)
+	}
+	wantFixed := `runner:
+  small:
+    cpu: 2
+runners:
+  small:
+    cpu: 2
+pools:
+  main:
+    runner: small
+    env: staging
+    schedule:
+      - name: default
+        hot: 1
+        stopped: 0
+        match:
+          day: [monday, friday]
+  other:
+    runner: small
+    schedule:
+      - name: default
+        hot: 0
+        stopped: 1
+`
+	if string(fixed) != wantFixed {
+		t.Errorf("Unexpected fixed config:\n%s", fixed)
+	}
+}
+
+func TestValidateBytes_BurstableCapacity(t *testing.T) {
+	yamlContent := `runners:
+  spot:
+    family: [t3]
+    spot: pco
+  on-demand:
+    family: [t4g]
+    spot: false
+  pooled:
+    family: "c7a+t*"
+  regular:
+    family: [m7a]
+    spot: true
+pools:
+  main:
+    runner: pooled
+    schedule:
+      - name: default
+        hot: 2
+  cold:
+    runner: on-demand
+    schedule:
+      - name: default
+        hot: 0
+        stopped: 2
+`
+	diags, err := validate.ValidateBytes(context.Background(), []byte(yamlContent), "test.yml")
+	if err != nil {
+		t.Fatalf("ValidateBytes failed: %v", err)
+	}
+	var warnings []string
+	for _, diag := range diags {
+		if diag.RuleID == validate.RuleBurstableCapacity {
+			warnings = append(warnings, fmt.Sprintf("%d: %s", diag.Line, diag.Message))
+		}
+	}
+	if len(warnings) != 2 || !strings.HasPrefix(warnings[0], "9: runner 'pooled' may use burstable families (t3, t3a, t4g): hot instances of its pools ('main')") ||
+		!strings.HasPrefix(warnings[1], "3: runner 'spot' may use burstable families (t3, t3a): spot instances") {
+		t.Errorf("Expected warnings for the pooled and spot runners, got %q", warnings)
+	}
+
+	rule, ok := validate.LookupRule(validate.RuleBurstableCapacity)
+	if !ok || rule.Group != validate.GroupCapacityResilience {
+		t.Errorf("Expected the rule to be in the capacity-resilience group, got %+v", rule)
+	}
+}
+
+func TestValidateBytes_Coercions(t *testing.T) {
+	yamlContent := `runners:
+  small:
+    cpu: 2
+    spot: False
+    ssh: "true"
+    private: "true"
+    nested-virt: "false"
+    debug: "false"
+    image: custom
+  typo:
+    cpu: 2
+    ssh: "yes"
+images:
+  custom:
+    name: runs-on-custom-*
+    owner: 012345678901
+pools:
+  main:
+    runner: small
+    schedule:
+      - name: default
+        hot: 0
+        stopped: 1
+`
+	diags, err := validate.ValidateBytes(context.Background(), []byte(yamlContent), "test.yml")
+	if err != nil {
+		t.Fatalf("ValidateBytes failed: %v", err)
+	}
+	var got []string
+	for _, diag := range filterErrors(diags) {
+		got = append(got, fmt.Sprintf("%s %s", diag.RuleID, diag.FieldPath))
+	}
+	got = slices.Compact(got)
+	// Only strings spelling a boolean become booleans
+	want := []string{validate.RuleSchema + " runners.typo.ssh"}
+	if !slices.Equal(got, want) {
+		t.Errorf("Expected errors %q, got %v", want, diags)
+	}
+}
+
+func TestValidateReader_DeprecatedFields(t *testing.T) {
+	yamlContent := `x-legacy: &legacy
+  disk: default
+  spot: true
+runners:
+  small:
+    <<: *legacy
+    cpu: 2
+  large:
+    <<: *legacy
+    cpu: 8
+pools:
+  main:
+    runner: small
+    environment: production
+    schedule:
+      - name: default
+        hot: 1
+        stopped: 0
+`
+	diags, err := validate.ValidateReader(context.Background(), strings.NewReader(yamlContent), "test.yml")
+	if err != nil {
+		t.Fatalf("ValidateReader failed: %v", err)
+	}
+	if errs := filterErrors(diags); len(errs) != 0 {
+		t.Errorf("Expected merged spot: true to be accepted, got %v", errs)
+	}
+
+	// The disk field shared through the anchor is reported once, at the anchor
+	lines := make(map[string][]int)
+	for _, diag := range diags {
+		lines[diag.RuleID] = append(lines[diag.RuleID], diag.Line)
+	}
+	if got := lines[validate.RuleDeprecatedDisk]; len(got) != 1 || got[0] != 2 {
+		t.Errorf("Expected one deprecated disk warning at line 2, got %v", diags)
+	}
+	if got := lines[validate.RuleDeprecatedEnvironment]; len(got) != 1 || got[0] != 14 {
+		t.Errorf("Expected one deprecated environment warning at line 14, got %v", diags)
+	}
+}
+
+func TestValidateReaderWithOptions_ScopePath(t *testing.T) {
+	yamlContent := `x-base: &base
+  family: zz9*
+  disk: default
+runners:
+  small:
+    <<: *base
+    cpu: 2
+  gpu-runner:
+    family: qq9*
+    disk: default
+pools:
+  main:
+    runner: small
+    environment: production
+    schedule:
+      - name: default
+        hot: 1
+        stopped: 0
+`
+	testCases := []struct {
+		path     string
+		expected []string
+	}{
+		{
+			path:     "runners.gpu-runner",
+			expected: []string{validate.RuleDeprecatedDisk + ":10", validate.RuleFamilyNoMatch + ":9"},
+		},
+		{
+			// The referenced runner is checked, but its diagnostics are not reported
+			path:     "pools.main",
+			expected: []string{validate.RuleDeprecatedEnvironment + ":14"},
+		},
+		{
+			// Diagnostics in anchors merged into the subtree are reported
+			path:     "runners.small",
+			expected: []string{validate.RuleDeprecatedDisk + ":3", validate.RuleFamilyNoMatch + ":0"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.path, func(t *testing.T) {
+			diags, err := validate.ValidateReaderWithOptions(context.Background(), strings.NewReader(yamlContent), "test.yml", validate.Options{ScopePath: tc.path})
+			if err != nil {
+				t.Fatalf("ValidateReaderWithOptions failed: %v", err)
+			}
+			var got []string
+			for _, diag := range diags {
+				got = append(got, fmt.Sprintf("%s:%d", diag.RuleID, diag.Line))
+			}
+			sort.Strings(got)
+			if strings.Join(got, " ") != strings.Join(tc.expected, " ") {
+				t.Errorf("Expected %v, got %v", tc.expected, diags)
+			}
+		})
+	}
+
+	_, err := validate.ValidateReaderWithOptions(context.Background(), strings.NewReader(yamlContent), "test.yml", validate.Options{ScopePath: "runners.missing"})
+	if err == nil {
+		t.Error("Expected an error for a missing scope path")
+	}
+}
+
+func TestValidateReaderWithOptions_Advisories(t *testing.T) {
+	db, err := advisory.Parse([]byte(`{
+  "updated": "2026-10-01",
+  "advisories": [{
+    "id": "ROC-2026-0001",
+    "summary": "efs with tmpfs exposes cached secrets",
+    "severity": "error",
+    "url": "https://example.com/ROC-2026-0001",
+    "match": {"section": "runners", "extras": ["efs", "tmpfs"]}
+  }]
+}`))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	yamlContent := `runners:
+  cached:
+    cpu: 2
+    extras: efs+tmpfs
+  plain:
+    extras: [efs]
+`
+	diags, err := validate.ValidateReaderWithOptions(context.Background(), strings.NewReader(yamlContent), "test.yml", validate.Options{Advisories: db})
+	if err != nil {
+		t.Fatalf("ValidateReaderWithOptions failed: %v", err)
+	}
+	var found []validate.Diagnostic
+	for _, diag := range diags {
+		if diag.RuleID == "ROC-2026-0001" {
+			found = append(found, diag)
+		}
+	}
+	if len(found) != 1 || found[0].Line != 4 || found[0].Severity != validate.SeverityError || !contains(found[0].Message, "runner 'cached'") {
+		t.Errorf("Expected one advisory error for runner 'cached' at line 4, got %v", diags)
+	}
+}
+
+func TestValidateReaderWithOptions_Schema(t *testing.T) {
+	if err := validate.CheckSchema(validate.CUESchema()); err != nil {
+		t.Errorf("Expected the embedded schema to be valid, got %v", err)
+	}
+	for _, src := range []string{"", "#Config: {", "#Other: {}"} {
+		if err := validate.CheckSchema([]byte(src)); err == nil {
+			t.Errorf("Expected CheckSchema to reject %q", src)
+		}
+	}
+
+	// A schema that only allows admins
+	schema := []byte("#Config: close({admins?: [...string]})\n")
+	yamlContent := "runners:\n  small:\n    cpu: [2]\n"
+
+	diags, err := validate.ValidateReader(context.Background(), strings.NewReader(yamlContent), "test.yml")
+	if err != nil {
+		t.Fatalf("ValidateReader failed: %v", err)
+	}
+	if len(filterErrors(diags)) > 0 {
+		t.Fatalf("Expected no errors with the embedded schema, got %v", diags)
+	}
+
+	diags, err = validate.ValidateReaderWithOptions(context.Background(), strings.NewReader(yamlContent), "test.yml", validate.Options{Schema: schema})
+	if err != nil {
+		t.Fatalf("ValidateReaderWithOptions failed: %v", err)
+	}
+	if len(filterErrors(diags)) == 0 {
+		t.Error("Expected the custom schema to reject runners")
+	}
+}
+
+func TestValidateBytes(t *testing.T) {
+	yamlContent := `runners:
+  small:
+    cpu: 2
+pools:
+  main:
+    runner: large
+`
+	diags, err := validate.ValidateBytes(context.Background(), []byte(yamlContent), "test.yml")
+	if err != nil {
+		t.Fatalf("ValidateBytes failed: %v", err)
+	}
+	fromReader, err := validate.ValidateReader(context.Background(), strings.NewReader(yamlContent), "test.yml")
+	if err != nil {
+		t.Fatalf("ValidateReader failed: %v", err)
+	}
+	if len(filterErrors(diags)) == 0 || !reflect.DeepEqual(diags, fromReader) {
+		t.Errorf("Expected the reader diagnostics %v, got %v", fromReader, diags)
+	}
+}
+
+func TestValidator(t *testing.T) {
+	v, err := validate.New()
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	valid := []byte("runners:\n  small:\n    cpu: 2\n")
+	invalid := []byte("runners:\n  small:\n    cpu: 2\npools:\n  main:\n    runner: large\n")
+
+	// The compiled schema is shared by concurrent calls
+	var wg sync.WaitGroup
+	results := make([][]validate.Diagnostic, 8)
+	for i := range results {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			src := valid
+			if i%2 == 1 {
+				src = invalid
+			}
+			results[i], _ = v.ValidateBytes(context.Background(), src, "test.yml")
+		}()
+	}
+	wg.Wait()
+
+	expected, err := validate.ValidateBytes(context.Background(), invalid, "test.yml")
+	if err != nil {
+		t.Fatalf("ValidateBytes failed: %v", err)
+	}
+	for i, diags := range results {
+		if i%2 == 0 && len(filterErrors(diags)) != 0 {
+			t.Errorf("Expected no errors for the valid config, got %v", diags)
+		}
+		if i%2 == 1 && !reflect.DeepEqual(diags, expected) {
+			t.Errorf("Expected %v, got %v", expected, diags)
+		}
+	}
+
+	if _, err := validate.NewWithOptions(validate.Options{Schema: []byte("#Other: string")}); err == nil {
+		t.Error("Expected an error for a schema without #Config")
+	}
+}
+
+func TestValidateBytes_UnknownFieldSuggestions(t *testing.T) {
+	yamlContent := `x-defaults: &defaults
+  familly: [c7a]
+runners:
+  small:
+    <<: *defaults
+    cpu: 2
+images:
+  custom:
+    ami: ami-1234567890abcdef0
+    platfrom: linux
+pools:
+  main:
+    runner: small
+    timezon: UTC
+    schedule:
+      - name: default
+        hot: 0
+        stopped: 1
+        stoped: 1
+        match:
+          days: [monday]
+admin: [alice]
+`
+	for _, strict := range []bool{false, true} {
+		diags, err := validate.ValidateBytesWithOptions(context.Background(), []byte(yamlContent), "test.yml", validate.Options{Strict: strict})
+		if err != nil {
+			t.Fatalf("ValidateBytesWithOptions failed: %v", err)
+		}
+		var got []string
+		for _, diag := range diags {
+			if diag.RuleID != validate.RuleUnknownField && diag.RuleID != validate.RuleFieldTypo {
+				continue
+			}
+			entry := fmt.Sprintf("%d:%d %s %s", diag.Line, diag.Column, diag.FieldPath, diag.Message)
+			if diag.Fix != nil {
+				entry += " (" + diag.Fix.Description + ")"
+			}
+			got = append(got, entry)
+		}
+		slices.Sort(got)
+		want := []string{
+			"10:5 images.custom.platfrom unknown field 'platfrom' in images.custom; did you mean 'platform'? (Rename 'platfrom' to 'platform')",
+			"14:5 pools.main.timezon unknown field 'timezon' in pools.main; did you mean 'timezone'? (Rename 'timezon' to 'timezone')",
+			"19:9 pools.main.schedule.0.stoped unknown field 'stoped' in pools.main.schedule.0",
+			"21:11 pools.main.schedule.0.match.days unknown field 'days' in pools.main.schedule.0.match; did you mean 'day'? (Rename 'days' to 'day')",
+			"22:1 admin top-level field 'admin' is not defined by the schema and is ignored; did you mean 'admins'? (Rename 'admin' to 'admins')",
+			"2:3 runners.small.familly unknown field 'familly' in runners.small; did you mean 'family'? (Rename 'familly' to 'family')",
+		}
+		if strict {
+			want[4] = "22:1 admin unknown top-level field 'admin'; did you mean 'admins'? (Rename 'admin' to 'admins')"
+		}
+		if !slices.Equal(got, want) {
+			t.Errorf("Expected diagnostics with strict=%v\n%q\ngot\n%q", strict, want, got)
+		}
+	}
+
+	// Fields are suggested from the schema the config is validated against
+	schema := append(validate.CUESchema(), "\n#RunnerSpec: {region?: string}\n"...)
+	diags, err := validate.ValidateBytesWithOptions(context.Background(), []byte("runners:\n  small:\n    reigon: eu-west-1\n"), "test.yml", validate.Options{Schema: schema})
+	if err != nil {
+		t.Fatalf("ValidateBytesWithOptions failed: %v", err)
+	}
+	want := "unknown field 'reigon' in runners.small; did you mean 'region'?"
+	if len(diags) != 1 || diags[0].RuleID != validate.RuleUnknownField || diags[0].Message != want {
+		t.Errorf("Expected %q, got %+v", want, diags)
+	}
+}
+
+func TestValidator_Options(t *testing.T) {
+	yamlContent := `x-defaults: &defaults
+  cpu: 2
+runners:
+  small:
+    <<: *defaults
+    ssh: true
+runner:
+  large:
+    cpu: 8
+pool:
+  main:
+    runner: large
+`
+	diags, err := validate.ValidateBytes(context.Background(), []byte(yamlContent), "test.yml")
+	if err != nil {
+		t.Fatalf("ValidateBytes failed: %v", err)
+	}
+	for _, diag := range diags {
+		if diag.RuleID == validate.RuleUnknownField {
+			t.Errorf("Expected unknown fields to be allowed by default, got %v", diag)
+		}
+	}
+
+	v, err := validate.New(validate.WithStrict(), validate.WithRules(map[string]bool{validate.RulePublicSSH: false}), validate.WithMaxErrors(1))
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	diags, err = v.ValidateBytes(context.Background(), []byte(yamlContent), "test.yml")
+	if err != nil {
+		t.Fatalf("ValidateBytes failed: %v", err)
+	}
+	var got []string
+	for _, diag := range diags {
+		got = append(got, fmt.Sprintf("%s:%d", diag.RuleID, diag.Line))
+	}
+	expected := []string{validate.RuleUnknownField + ":7"}
+	if !slices.Equal(got, expected) {
+		t.Errorf("Expected %v, got %v", expected, diags)
+	}
+
+	if _, err := validate.New(validate.WithSchema([]byte("#Other: string"))); err == nil {
+		t.Error("Expected an error for a schema without #Config")
+	}
+}
+
+func TestValidator_MergeConflicts(t *testing.T) {
+	dir := t.TempDir()
+	base := filepath.Join(dir, "base.yml")
+	config := filepath.Join(dir, "runs-on.yml")
+	if err := os.WriteFile(base, []byte("runners:\n  small:\n    cpu: 2\n  large:\n    cpu: 16\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(config, []byte("_extends: ./base.yml\nrunners:\n  small:\n    cpu: 2\n  large:\n    cpu: 32\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	diags, err := validate.ValidateFile(context.Background(), config)
+	if err != nil {
+		t.Fatalf("ValidateFile failed: %v", err)
+	}
+	if len(filterErrors(diags)) != 0 {
+		t.Errorf("Expected overrides to be allowed by default, got %v", diags)
+	}
+
+	v, err := validate.New(validate.WithStrict())
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	diags, err = v.ValidateFile(context.Background(), config)
+	if err != nil {
+		t.Fatalf("ValidateFile failed: %v", err)
+	}
+	var conflicts []validate.Diagnostic
+	for _, diag := range diags {
+		if diag.RuleID == validate.RuleMergeConflict {
+			conflicts = append(conflicts, diag)
+		}
+	}
+	expected := fmt.Sprintf("runner 'large' in %s replaces a different definition in %s", config, base)
+	if len(conflicts) != 1 || conflicts[0].Message != expected || conflicts[0].Line != 5 {
+		t.Errorf("Expected one conflict for runner 'large' at line 5, got %v", conflicts)
+	}
+}
+
+func TestValidateAndParse(t *testing.T) {
+	yamlContent := `x-defaults: &defaults
+  family: c7a+m7a
+  spot: false
+runners:
+  small:
+    <<: *defaults
+    cpu: "2+4"
+    image: custom
+images:
+  custom:
+    name: custom-*
+    owner: "123456789012"
+pools:
+  main:
+    runner: small
+    schedule:
+      - name: default
+        hot: 1
+        stopped: 2
+admins: [alice]
+`
+	cfg, diags, err := validate.ValidateAndParse(context.Background(), strings.NewReader(yamlContent), "test.yml", validate.Options{})
+	if err != nil {
+		t.Fatalf("ValidateAndParse failed: %v", err)
+	}
+	if len(diags) != 0 {
+		t.Errorf("Expected no diagnostics, got %v", diags)
+	}
+	if cfg == nil {
+		t.Fatal("Expected a parsed config")
+	}
+
+	runner := cfg.Runners["small"]
+	if len(runner.CPU) != 2 || runner.CPU[1] != 4 || len(runner.Family) != 2 || runner.Spot != "false" {
+		t.Errorf("Expected anchors merged and flexible fields normalized, got %+v", runner)
+	}
+	if cfg.Images["custom"].Owner != "123456789012" {
+		t.Errorf("Expected the owner account ID, got %q", cfg.Images["custom"].Owner)
+	}
+	if pool := cfg.Pools["main"]; pool.Runner != "small" || len(pool.Schedule) != 1 || pool.Schedule[0].Stopped != 2 {
+		t.Errorf("Unexpected pool: %+v", pool)
+	}
+	if len(cfg.Admins) != 1 || cfg.Admins[0] != "alice" {
+		t.Errorf("Unexpected admins: %v", cfg.Admins)
+	}
+
+	v, err := validate.New()
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	fromValidator, validatorDiags, err := v.ValidateAndParse(context.Background(), strings.NewReader(yamlContent), "test.yml")
+	if err != nil || len(validatorDiags) != 0 || fromValidator == nil || fromValidator.Pools["main"].Runner != "small" {
+		t.Errorf("Expected the Validator to parse the config too, got %+v, %v, %v", fromValidator, validatorDiags, err)
+	}
+}
+
+func TestValidateAndParse_Invalid(t *testing.T) {
+	// Validation errors still return the config
+	cfg, diags, err := validate.ValidateAndParse(context.Background(),
+		strings.NewReader("pools:\n  main:\n    runner: missing\nrunners: {}\n"), "test.yml", validate.Options{})
+	if err != nil {
+		t.Fatalf("ValidateAndParse failed: %v", err)
+	}
+	if cfg == nil || cfg.Pools["main"].Runner != "missing" {
+		t.Errorf("Expected the config despite validation errors, got %+v", cfg)
+	}
+	if len(filterErrors(diags)) == 0 {
+		t.Error("Expected validation errors")
+	}
+
+	// Malformed YAML returns no config
+	cfg, diags, err = validate.ValidateAndParse(context.Background(), strings.NewReader("runners: [\n"), "test.yml", validate.Options{})
+	if err != nil {
+		t.Fatalf("ValidateAndParse failed: %v", err)
+	}
+	if cfg != nil || len(diags) != 1 || diags[0].RuleID != validate.RuleYAMLSyntax {
+		t.Errorf("Expected only a YAML syntax error, got %+v, %v", cfg, diags)
+	}
+}
+
+func TestLoadSchema(t *testing.T) {
+	versions := validate.SchemaVersions()
+	if last := versions[len(versions)-1].Name; last != fmt.Sprintf("v%d", migrate.LatestVersion()) {
+		t.Errorf("Expected the last schema version to match the latest migration, got %s", last)
+	}
+
+	poolWithEnv := "runners:\n  small:\n    cpu: [2]\npools:\n  main:\n    runner: small\n    env: staging\n"
+	for _, ref := range []string{"latest", "v1", "v2", "v3", "../../schema/runs_on.cue", "../../schema/schema.json"} {
+		t.Run(ref, func(t *testing.T) {
+			schema, err := validate.LoadSchema(ref)
+			if err != nil {
+				t.Fatalf("LoadSchema failed: %v", err)
+			}
+			diags, err := validate.ValidateReaderWithOptions(context.Background(), strings.NewReader(poolWithEnv), "test.yml", validate.Options{Schema: schema})
+			if err != nil {
+				t.Fatalf("ValidateReaderWithOptions failed: %v", err)
+			}
+			errors := filterErrors(diags)
+			if (len(errors) > 0) != (ref == "v1") {
+				t.Errorf("Unexpected errors for pool env with schema %s: %v", ref, errors)
+			}
+			// v1 reports env at the field, with an actionable message
+			if ref == "v1" && (len(errors) != 1 || errors[0].FieldPath != "pools.main.env" || errors[0].Line != 7 ||
+				!strings.HasSuffix(errors[0].Message, "'env' was added in schema version v2; use 'environment'")) {
+				t.Errorf("Expected an error at pools.main.env, got %+v", errors)
+			}
+		})
+	}
+
+	for _, ref := range []string{"v0", "./missing.cue"} {
+		if _, err := validate.LoadSchema(ref); err == nil {
+			t.Errorf("Expected an error for schema %q", ref)
+		}
+	}
+}
+
+func TestValidate_SchemaVersion(t *testing.T) {
+	poolWithEnv := "runners:\n  small:\n    cpu: [2]\npools:\n  main:\n    runner: small\n    env: staging\n"
+	tests := []struct {
+		name    string
+		header  string
+		opts    validate.Options
+		wantIDs []string
+	}{
+		{name: "none", header: ""},
+		{name: "current", header: "schema-version: v3\n"},
+		{name: "latest", header: "x-schema-version: latest\n"},
+		// v1 pools have no env field, reported once as added in v2
+		{name: "v1", header: "schema-version: v1\n", wantIDs: []string{validate.RuleSchemaVersionField}},
+		// env was added in v2
+		{name: "v2", header: "schema-version: v2\n"},
+		// A given schema takes precedence over the declared version
+		{name: "v1 with schema", header: "schema-version: v1\n", opts: validate.Options{Schema: validate.CUESchema()}},
+		{name: "unknown", header: "schema-version: v9\n", wantIDs: []string{validate.RuleSchemaVersion}},
+		{name: "not a name", header: "schema-version: 3\n", wantIDs: []string{validate.RuleSchemaVersion}},
+		{name: "both", header: "schema-version: v3\nx-schema-version: v2\n", wantIDs: []string{validate.RuleSchemaVersion}},
+		{name: "missing in strict mode", header: "", opts: validate.Options{Strict: true}},
+		{name: "missing with the prod profile", header: "", opts: validate.Options{Profile: validate.ProfileProd}, wantIDs: []string{validate.RuleSchemaVersionMissing}},
+		{name: "declared with the prod profile", header: "schema-version: v3\n", opts: validate.Options{Profile: validate.ProfileProd}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diags, err := validate.ValidateBytesWithOptions(context.Background(), []byte(tt.header+poolWithEnv), "runs-on.yml", tt.opts)
+			if err != nil {
+				t.Fatalf("ValidateBytesWithOptions failed: %v", err)
+			}
+			var ids []string
+			for _, diag := range diags {
+				if diag.RuleID != validate.RulePoolNoSchedule {
+					ids = append(ids, diag.RuleID)
+				}
+			}
+			slices.Sort(ids)
+			ids = slices.Compact(ids)
+			if !slices.Equal(ids, tt.wantIDs) {
+				t.Errorf("Expected rules %v, got %v", tt.wantIDs, diags)
+			}
+		})
+	}
+}
+
+func TestCUESchema(t *testing.T) {
+	schema := string(validate.CUESchema())
+	for _, definition := range []string{"#Config", "#RunnerSpec", "#PoolSpec"} {
+		if !strings.Contains(schema, definition) {
+			t.Errorf("Expected embedded schema to define %s", definition)
+		}
+	}
+}
+
+func TestFieldSpecs(t *testing.T) {
+	specs, err := validate.FieldSpecs("runners")
+	if err != nil {
+		t.Fatalf("FieldSpecs failed: %v", err)
+	}
+	byName := make(map[string]validate.FieldSpec)
+	for _, spec := range specs {
+		byName[spec.Name] = spec
+	}
+	if spot := byName["spot"]; !slices.Contains(spot.Enum, "pco") || !slices.Contains(spot.Enum, "never") {
+		t.Errorf("Expected spot to list the spot strategies, got %v", spot.Enum)
+	}
+	if ssh := byName["ssh"]; !slices.Equal(ssh.Enum, []string{"true", "false"}) {
+		t.Errorf("Expected ssh to accept true and false, got %v", ssh.Enum)
+	}
+	if family, ok := byName["family"]; !ok || family.Enum != nil || family.Required {
+		t.Errorf("Expected an optional family without values, got %+v", family)
+	}
+
+	pools, err := validate.FieldSpecs("pools")
+	if err != nil {
+		t.Fatalf("FieldSpecs failed: %v", err)
+	}
+	for _, spec := range pools {
+		if spec.Required != (spec.Name == "runner") {
+			t.Errorf("Unexpected Required for pool field %+v", spec)
+		}
+	}
+
+	if _, err := validate.FieldSpecs("admins"); err == nil {
+		t.Error("Expected an error for a section without entries")
+	}
+}
+
+func TestRules(t *testing.T) {
+	rules := validate.Rules()
+	if len(rules) == 0 {
+		t.Fatal("Expected registered rules, got none")
+	}
+	for _, rule := range rules {
+		if rule.Summary == "" || rule.Description == "" || rule.DocURL == "" {
+			t.Errorf("Rule %s is missing documentation: %+v", rule.ID, rule)
+		}
+	}
+
+	if _, ok := validate.LookupRule("no-such-rule"); ok {
+		t.Error("Expected LookupRule to fail for an unknown rule")
+	}
+}
+
+// filterErrors returns only error-level diagnostics, filtering out warnings
+func filterErrors(diags []validate.Diagnostic) []validate.Diagnostic {
+	var errors []validate.Diagnostic
+	for _, diag := range diags {
+		if diag.Severity == validate.SeverityError {
+			errors = append(errors, diag)
+		}
+	}
+	return errors
+}
+
+// Helper function to check if a string contains a substring (case-insensitive)
+func contains(s, substr string) bool {
+	sLower := strings.ToLower(s)
+	substrLower := strings.ToLower(substr)
 	return strings.Contains(sLower, substrLower)
 }
+
+func TestBuildInfo(t *testing.T) {
+	build := validate.BuildInfo()
+	if build.Version == "" || build.GoVersion == "" {
+		t.Errorf("BuildInfo() = %+v, want a version and a Go version", build)
+	}
+	if want := validate.SchemaDigest(validate.CUESchema()); build.SchemaDigest != want {
+		t.Errorf("SchemaDigest = %q, want %q", build.SchemaDigest, want)
+	}
+	if digest := validate.SchemaDigest([]byte("")); digest != "sha256:e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855" {
+		t.Errorf("SchemaDigest(\"\") = %q", digest)
+	}
+}
+
+func TestErrorCodes(t *testing.T) {
+	ctx := context.Background()
+
+	_, err := validate.ValidateFile(ctx, filepath.Join(t.TempDir(), "missing.yml"))
+	if validate.CodeOf(err) != validate.CodeInput || !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("missing file: got %v (code %q), want an input error wrapping fs.ErrNotExist", err, validate.CodeOf(err))
+	}
+
+	_, err = validate.ValidateBytesWithOptions(ctx, []byte("runners: {}\n"), "runs-on.yml", validate.Options{Schema: []byte("#Config: {")})
+	if validate.CodeOf(err) != validate.CodeSchema {
+		t.Errorf("invalid schema: got %v (code %q), want a schema error", err, validate.CodeOf(err))
+	}
+
+	_, err = validate.ValidateBytesWithOptions(ctx, []byte("runners: {}\n"), "runs-on.yml", validate.Options{ScopePath: "pools.main"})
+	var validateErr *validate.Error
+	if !errors.As(err, &validateErr) || validateErr.Code != validate.CodeOption {
+		t.Errorf("missing scope path: got %v, want an option error", err)
+	}
+
+	_, err = validate.ValidateBytesWithOptions(ctx, []byte("runners: {}\n"), "runs-on.yml", validate.Options{Profile: "staging"})
+	if validate.CodeOf(err) != validate.CodeOption {
+		t.Errorf("unknown profile: got %v (code %q), want an option error", err, validate.CodeOf(err))
+	}
+
+	if code := validate.CodeOf(fmt.Errorf("other")); code != "" {
+		t.Errorf("CodeOf(other) = %q, want none", code)
+	}
+}
+
+func TestValidate_Canceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := validate.ValidateBytes(ctx, []byte("runners: {}\n"), "runs-on.yml")
+	if validate.CodeOf(err) != validate.CodeCanceled || !errors.Is(err, context.Canceled) {
+		t.Errorf("got %v, want a canceled error wrapping context.Canceled", err)
+	}
+
+	v, err := validate.New()
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	if _, err := v.ValidateBytes(ctx, []byte("runners: {}\n"), "runs-on.yml"); !errors.Is(err, context.Canceled) {
+		t.Errorf("Validator: got %v, want context.Canceled", err)
+	}
+	if _, err := v.ValidateBytes(context.Background(), []byte("runners: {}\n"), "runs-on.yml"); err != nil {
+		t.Errorf("Validator failed after a canceled validation: %v", err)
+	}
+}
+
+func TestValidateFiles(t *testing.T) {
+	dir := t.TempDir()
+	valid := filepath.Join(dir, "valid.yml")
+	invalid := filepath.Join(dir, "invalid.yml")
+	missing := filepath.Join(dir, "missing.yml")
+	if err := os.WriteFile(valid, []byte("runners:\n  small:\n    cpu: 2\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(invalid, []byte("runners:\n  small:\n    cpu: [\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var events []string
+	opts := validate.Options{
+		OnFileStart: func(path string) { events = append(events, "start "+filepath.Base(path)) },
+		OnFileDone: func(path string, diags []validate.Diagnostic, err error) {
+			events = append(events, fmt.Sprintf("done %s %d %t", filepath.Base(path), len(diags), err != nil))
+		},
+		OnDiagnostic: func(diag validate.Diagnostic) { events = append(events, "diagnostic "+diag.RuleID) },
+	}
+	results, err := validate.ValidateFiles(context.Background(), []string{valid, invalid, missing}, opts)
+	if err != nil {
+		t.Fatalf("ValidateFiles failed: %v", err)
+	}
+	if len(results) != 3 || len(results[0].Diagnostics) != 0 || len(results[1].Diagnostics) != 1 || validate.CodeOf(results[2].Err) != validate.CodeInput {
+		t.Errorf("Unexpected results: %+v", results)
+	}
+	want := []string{
+		"start valid.yml", "done valid.yml 0 false",
+		"start invalid.yml", "diagnostic yaml-syntax", "done invalid.yml 1 false",
+		"start missing.yml", "done missing.yml 0 true",
+	}
+	if !slices.Equal(events, want) {
+		t.Errorf("Expected events %q, got %q", want, events)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if results, err := validate.ValidateFiles(ctx, []string{valid}, validate.Options{}); len(results) != 0 || !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected a canceled validation, got %v, %v", results, err)
+	}
+}
+
+func TestValidateFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"api/.github/runs-on.yml":  {Data: []byte("pools:\n  web:\n    runner: missing\n    schedule:\n      - name: default\n        hot: 1\n")},
+		"web/.github/runs-on.yaml": {Data: []byte("runners:\n  small:\n    cpu: 2\n")},
+		"web/.github/other.yml":    {Data: []byte("runners: [\n")},
+	}
+	results, err := validate.ValidateFS(context.Background(), fsys, validate.Options{})
+	if err != nil {
+		t.Fatalf("ValidateFS failed: %v", err)
+	}
+	byPath := validate.DiagnosticsByPath(results)
+	if got := slices.Sorted(maps.Keys(byPath)); !slices.Equal(got, []string{"api/.github/runs-on.yml", "web/.github/runs-on.yaml"}) {
+		t.Fatalf("Unexpected paths %q", got)
+	}
+	if diags := byPath["api/.github/runs-on.yml"]; !slices.ContainsFunc(diags, func(diag validate.Diagnostic) bool { return diag.RuleID == validate.RulePoolRunnerUndefined }) {
+		t.Errorf("Expected an undefined runner, got %+v", diags)
+	}
+	if diags := byPath["web/.github/runs-on.yaml"]; len(diags) != 0 {
+		t.Errorf("Expected no diagnostics, got %+v", diags)
+	}
+}
+
+func TestValidateArchive(t *testing.T) {
+	archive := func(files map[string]string) *bytes.Buffer {
+		var buf bytes.Buffer
+		gz := gzip.NewWriter(&buf)
+		tw := tar.NewWriter(gz)
+		names := slices.Sorted(maps.Keys(files))
+		for _, name := range names {
+			if err := tw.WriteHeader(&tar.Header{Name: name, Mode: 0o644, Size: int64(len(files[name])), Typeflag: tar.TypeReg}); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := tw.Write([]byte(files[name])); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := tw.Close(); err != nil {
+			t.Fatal(err)
+		}
+		if err := gz.Close(); err != nil {
+			t.Fatal(err)
+		}
+		return &buf
+	}
+
+	results, err := validate.ValidateArchive(context.Background(), archive(map[string]string{
+		"./acme/web/.github/runs-on.yml": "runners:\n  small:\n    cpu: 2\n",
+		"acme/api/.github/runs-on.yaml":  "runners:\n  small:\n    cpu: [\n",
+		"acme/api/README.md":             "not a config",
+		"acme/lib/.github/runs-on.yml":   "_extends: ./base.yml\n",
+	}), validate.Options{})
+	if err != nil {
+		t.Fatalf("ValidateArchive failed: %v", err)
+	}
+	var paths []string
+	for _, result := range results {
+		paths = append(paths, result.Path)
+		if result.Err != nil {
+			t.Errorf("%s: unexpected error %v", result.Path, result.Err)
+		}
+		for _, diag := range result.Diagnostics {
+			if diag.Path != result.Path {
+				t.Errorf("Expected diagnostic path %q, got %q", result.Path, diag.Path)
+			}
+		}
+	}
+	want := []string{"acme/api/.github/runs-on.yaml", "acme/lib/.github/runs-on.yml", "acme/web/.github/runs-on.yml"}
+	if !slices.Equal(paths, want) {
+		t.Fatalf("Expected results for %q, got %q", want, paths)
+	}
+	if len(results[0].Diagnostics) != 1 || len(results[2].Diagnostics) != 0 {
+		t.Errorf("Unexpected results: %+v", results)
+	}
+
+	// Entries must stay inside the archive
+	if _, err := validate.OpenArchive(archive(map[string]string{"../runs-on.yml": "runners: {}\n"})); validate.CodeOf(err) != validate.CodeInput {
+		t.Errorf("Expected an input error for an entry outside the archive, got %v", err)
+	}
+	if _, err := validate.OpenArchive(strings.NewReader("runners: {}\n")); validate.CodeOf(err) != validate.CodeInput {
+		t.Errorf("Expected an input error for a config that is not an archive, got %v", err)
+	}
+}
+
+func TestValidate_Logger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	v, err := validate.New(validate.WithLogger(logger))
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	if _, err := v.ValidateBytes(context.Background(), []byte("runners:\n  small:\n    cpu: 2\n"), "runs-on.yml"); err != nil {
+		t.Fatalf("ValidateBytes failed: %v", err)
+	}
+	for _, want := range []string{`msg="schema loaded" schema=embedded`, "step=parse", "step=schema", "step=public-ssh", `msg=validated source=runs-on.yml`} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("Expected log to contain %q, got:\n%s", want, buf.String())
+		}
+	}
+
+	// Nothing is logged above debug level
+	buf.Reset()
+	logger = slog.New(slog.NewTextHandler(&buf, nil))
+	if _, err := validate.ValidateBytesWithOptions(context.Background(), []byte("runners: {}\n"), "runs-on.yml", validate.Options{Logger: logger}); err != nil {
+		t.Fatalf("ValidateBytesWithOptions failed: %v", err)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("Expected no log at info level, got:\n%s", buf.String())
+	}
+}
+
+// runnerPrefixRule reports runners whose name does not start with prefix, in
+// the config named source only so that other tests are not affected
+type runnerPrefixRule struct {
+	id, prefix, source string
+}
+
+func (r runnerPrefixRule) ID() string                  { return r.id }
+func (r runnerPrefixRule) Severity() validate.Severity { return validate.SeverityWarning }
+func (r runnerPrefixRule) Check(_ context.Context, config *validate.ConfigNode) []validate.Diagnostic {
+	if config.SourceName != r.source {
+		return nil
+	}
+	var diags []validate.Diagnostic
+	if runners := config.Lookup("runners"); runners != nil {
+		for i := 0; i+1 < len(runners.Content); i += 2 {
+			if key := runners.Content[i]; !strings.HasPrefix(key.Value, r.prefix) {
+				diags = append(diags, validate.Diagnostic{Line: key.Line, Column: key.Column, Message: fmt.Sprintf("runner %s must start with %s", key.Value, r.prefix)})
+			}
+		}
+	}
+	return diags
+}
+
+func TestRegisterRule(t *testing.T) {
+	validate.RegisterRule(runnerPrefixRule{id: "test-runner-prefix", prefix: "acme-", source: "custom.yml"})
+
+	yamlContent := "runners:\n  acme-small:\n    cpu: 2\n  large:\n    cpu: 16\n"
+	diags, err := validate.ValidateBytes(context.Background(), []byte(yamlContent), "custom.yml")
+	if err != nil {
+		t.Fatalf("ValidateBytes failed: %v", err)
+	}
+	if len(diags) != 1 {
+		t.Fatalf("Expected 1 diagnostic, got %+v", diags)
+	}
+	want := validate.Diagnostic{Path: "custom.yml", Line: 4, Column: 3, RuleID: "test-runner-prefix", Severity: validate.SeverityWarning, FieldPath: "runners.large"}
+	if got := diags[0]; got.Path != want.Path || got.Line != want.Line || got.Column != want.Column || got.RuleID != want.RuleID || got.Severity != want.Severity || got.FieldPath != want.FieldPath {
+		t.Errorf("Expected %+v, got %+v", want, got)
+	}
+
+	// Registered rules are turned off by ID and documented like built-in ones
+	diags, err = validate.ValidateBytesWithOptions(context.Background(), []byte(yamlContent), "custom.yml", validate.Options{Rules: map[string]bool{"test-runner-prefix": false}})
+	if err != nil || len(diags) != 0 {
+		t.Errorf("Expected no diagnostics with the rule off, got %+v, %v", diags, err)
+	}
+	if info, ok := validate.LookupRule("test-runner-prefix"); !ok || info.Severity != validate.SeverityWarning {
+		t.Errorf("Expected the rule to be documented, got %+v", info)
+	}
+
+	for _, rule := range []runnerPrefixRule{{id: "test-runner-prefix"}, {id: validate.RuleSchema}, {}} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("Expected registering %q to panic", rule.id)
+				}
+			}()
+			validate.RegisterRule(rule)
+		}()
+	}
+}
+
+func TestFilter(t *testing.T) {
+	diags := []validate.Diagnostic{
+		{Path: ".github/runs-on.yml", Line: 3, Severity: validate.SeverityError, RuleID: validate.RuleSchema, FieldPath: "runners.gpu.cpu"},
+		{Path: ".github/runs-on.yml", Line: 8, Severity: validate.SeverityWarning, RuleID: validate.RulePublicSSH, FieldPath: "runners.gpu-large"},
+		{Path: "services/api/runs-on.yml", Line: 5, Severity: validate.SeverityWarning, RuleID: validate.RulePublicSSH, FieldPath: "runners.debug"},
+	}
+	lines := func(diags []validate.Diagnostic) []int {
+		var lines []int
+		for _, diag := range diags {
+			lines = append(lines, diag.Line)
+		}
+		return lines
+	}
+
+	tests := []struct {
+		name       string
+		predicates []validate.Predicate
+		want       []int
+	}{
+		{"none", nil, []int{3, 8, 5}},
+		{"rule", []validate.Predicate{validate.ByRule(validate.RulePublicSSH)}, []int{8, 5}},
+		{"severity", []validate.Predicate{validate.BySeverity(validate.SeverityError)}, []int{3}},
+		{"path prefix", []validate.Predicate{validate.ByPathPrefix("services/")}, []int{5}},
+		{"field path", []validate.Predicate{validate.ByFieldPath("runners.gpu")}, []int{3}},
+		{"lines", []validate.Predicate{validate.InLines(4, 8)}, []int{8, 5}},
+		{"all of", []validate.Predicate{validate.ByRule(validate.RulePublicSSH), validate.Not(validate.ByPathPrefix("services/"))}, []int{8}},
+		{"suppressions", []validate.Predicate{validate.Suppress([]validate.Suppression{
+			{Rule: validate.RulePublicSSH, Path: "services/*/runs-on.yml", Reason: "debug runners"},
+			{Field: "runners.gpu"},
+		})}, []int{8}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := lines(validate.Filter(diags, tt.predicates...)); !slices.Equal(got, tt.want) {
+				t.Errorf("Expected lines %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestLocalize(t *testing.T) {
+	// Translations have the keys and parameters of the English messages
+	placeholders := regexp.MustCompile(`\{[a-z]+\}`)
+	templates := validate.Messages()
+	for _, locale := range validate.Locales() {
+		catalog, ok := validate.CatalogFor(locale)
+		if !ok {
+			t.Fatalf("Expected a catalog for %s", locale)
+		}
+		for key, template := range catalog {
+			want := slices.Sorted(slices.Values(placeholders.FindAllString(templates[key], -1)))
+			if got := slices.Sorted(slices.Values(placeholders.FindAllString(template, -1))); !slices.Equal(slices.Compact(got), slices.Compact(want)) {
+				t.Errorf("%s: %s has parameters %v, expected %v", locale, key, got, want)
+			}
+		}
+		for key := range templates {
+			if _, ok := catalog[key]; !ok {
+				t.Errorf("%s: missing translation of %s", locale, key)
+			}
+		}
+	}
+	if _, ok := validate.CatalogFor("fr_CA"); !ok {
+		t.Error("Expected fr_CA to fall back to fr")
+	}
+	if _, ok := validate.CatalogFor("xx"); ok {
+		t.Error("Expected no catalog for xx")
+	}
+
+	src := []byte("admins: [alice, alice]\nimages:\n  base/x:\n    ami: ami-12345678\npools:\n  main:\n    runner: small\n")
+	english, err := validate.ValidateBytesWithOptions(context.Background(), src, "runs-on.yml", validate.Options{})
+	if err != nil {
+		t.Fatalf("ValidateBytesWithOptions failed: %v", err)
+	}
+	v, err := validate.New(validate.WithLocale("fr-CA"))
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	french, err := v.ValidateBytes(context.Background(), src, "runs-on.yml")
+	if err != nil {
+		t.Fatalf("ValidateBytes failed: %v", err)
+	}
+	if len(french) != len(english) {
+		t.Fatalf("Expected the same diagnostics, got %v and %v", english, french)
+	}
+	messages := make(map[string]string)
+	for i, diag := range french {
+		if diag.RuleID != english[i].RuleID || diag.Line != english[i].Line {
+			t.Errorf("Expected only messages to change, got %+v for %+v", diag, english[i])
+		}
+		messages[diag.RuleID] = diag.Message
+	}
+	for rule, want := range map[string]string{
+		validate.RuleAdminsDuplicate:     "l'administrateur 'alice' est listé plusieurs fois (d'abord à la ligne 1)",
+		validate.RulePoolRunnerUndefined: "le pool 'main' fait référence au runner 'small', mais aucun runner n'est défini",
+		validate.RuleNameFormat:          "le nom d'image 'base/x' contient '/' : n'utilisez que des lettres, des chiffres, '-', '_' et '.'",
+	} {
+		if messages[rule] != want {
+			t.Errorf("Expected %s message %q, got %q", rule, want, messages[rule])
+		}
+	}
+
+	// Messages without a translation are kept
+	custom := validate.Localize(english, validate.Catalog{validate.RuleAdminsDuplicate: "{admin} twice"})
+	for i, diag := range custom {
+		want := english[i].Message
+		if diag.RuleID == validate.RuleAdminsDuplicate {
+			want = "alice twice"
+		}
+		if diag.Message != want {
+			t.Errorf("Expected message %q, got %q", want, diag.Message)
+		}
+	}
+
+	// Diagnostics the validator did not report, e.g. decoded from JSON, are
+	// kept in English
+	data, err := json.Marshal(english)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded []validate.Diagnostic
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	for i, diag := range validate.Localize(decoded, validate.Catalog{validate.RuleAdminsDuplicate: "{admin} twice"}) {
+		if diag.Message != english[i].Message {
+			t.Errorf("Expected message %q, got %q", english[i].Message, diag.Message)
+		}
+	}
+}
+
+func TestTestCorpus(t *testing.T) {
+	corpus := validate.TestCorpus()
+
+	// The embedded corpus is a copy of schema/testdata
+	err := fs.WalkDir(corpus, ".", func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+		embedded, err := fs.ReadFile(corpus, path)
+		if err != nil {
+			return err
+		}
+		if source, err := os.ReadFile(filepath.Join("../../schema/testdata", path)); err != nil || string(source) != string(embedded) {
+			t.Errorf("%s differs from schema/testdata, run make sync-schema", path)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	if err := os.CopyFS(dir, corpus); err != nil {
+		t.Fatal(err)
+	}
+	// Valid cases report no warnings either, except those they are about or
+	// that a config in use has
+	expectedWarnings := map[string][]string{
+		"with-deprecated-disk.yml":   {validate.RuleDeprecatedDisk},
+		"github-private-runs-on.yml": {validate.RuleDeprecatedEnvironment, validate.RulePublicSSH, validate.RuleBurstableCapacity},
+	}
+	for _, verdict := range []string{"valid", "invalid"} {
+		entries, err := fs.ReadDir(corpus, verdict)
+		if err != nil || len(entries) == 0 {
+			t.Fatalf("Expected %s cases, got %v", verdict, err)
+		}
+		for _, entry := range entries {
+			if entry.IsDir() {
+				continue
+			}
+			diags, err := validate.ValidateFile(context.Background(), filepath.Join(dir, verdict, entry.Name()))
+			if err != nil {
+				t.Fatalf("ValidateFile failed: %v", err)
+			}
+			if hasErrors := len(filterErrors(diags)) > 0; hasErrors != (verdict == "invalid") {
+				t.Errorf("%s/%s: unexpected verdict, got %v", verdict, entry.Name(), filterErrors(diags))
+			}
+			for _, diag := range diags {
+				if verdict == "valid" && !slices.Contains(expectedWarnings[entry.Name()], diag.RuleID) {
+					t.Errorf("%s/%s: unexpected %s warning: %s", verdict, entry.Name(), diag.RuleID, diag.Message)
+				}
+			}
+		}
+	}
+
+	if digest := validate.TestCorpusDigest(); !strings.HasPrefix(digest, "sha256:") || digest != validate.TestCorpusDigest() {
+		t.Errorf("Unexpected corpus digest %q", digest)
+	}
+}
+
+func TestValidateBytes_Names(t *testing.T) {
+	yamlContent := `runners:
+  large runner:
+    cpu: 16
+  gpu/large:
+    cpu: 8
+  -small:
+    cpu: 2
+  Medium.v2:
+    cpu: 4
+    image: ` + strings.Repeat("x", 65) + `
+images:
+  ` + strings.Repeat("x", 65) + `:
+    ami: ami-0123456789abcdef0
+pools:
+  Main:
+    runner: Medium.v2
+    schedule:
+      - name: default
+        hot: 0
+        stopped: 1
+  main_2:
+    runner: Medium.v2
+    schedule:
+      - name: default
+        hot: 0
+        stopped: 1
+`
+	diags, err := validate.ValidateBytes(context.Background(), []byte(yamlContent), "test.yml")
+	if err != nil {
+		t.Fatalf("ValidateBytes failed: %v", err)
+	}
+	var got []string
+	for _, diag := range filterErrors(diags) {
+		got = append(got, fmt.Sprintf("%d:%d %s: %s", diag.Line, diag.Column, diag.RuleID, diag.Message))
+	}
+	// The schema error for the pool name is replaced by its reason
+	want := []string{
+		"2:3 name-format: runner name 'large runner' contains ' ': use only letters, digits, '-', '_' and '.'",
+		"4:3 name-format: runner name 'gpu/large' contains '/': use only letters, digits, '-', '_' and '.'",
+		"6:3 name-format: runner name '-small' must start with a letter or a digit",
+		"12:3 name-format: image name '" + strings.Repeat("x", 65) + "' is 65 characters long, more than the 64 allowed",
+		"15:3 name-format: pool name 'Main' contains 'M': use only lowercase letters, digits, '-' and '_'",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("Expected errors:\n%s\ngot:\n%s", strings.Join(want, "\n"), strings.Join(got, "\n"))
+	}
+}
+
+func TestValidateBytes_ImageSources(t *testing.T) {
+	yamlContent := `x-search: &search
+  owner: "123456789012"
+images:
+  by-id:
+    ami: ami-0123456789abcdef0
+  by-search:
+    <<: *search
+    name: custom-*
+  both:
+    ami: ami-0123456789abcdef0
+    name: custom-*
+  neither:
+    platform: linux
+  no-owner:
+    name: custom-*
+  no-name:
+    <<: *search
+  empty:
+`
+	diags, err := validate.ValidateBytes(context.Background(), []byte(yamlContent), "test.yml")
+	if err != nil {
+		t.Fatalf("ValidateBytes failed: %v", err)
+	}
+	var got []string
+	var schemaError bool
+	for _, diag := range diags {
+		if diag.RuleID == validate.RuleImageSource {
+			got = append(got, fmt.Sprintf("%d:%d %s", diag.Line, diag.Column, diag.Message))
+		}
+		schemaError = schemaError || diag.RuleID == validate.RuleSchema && diag.FieldPath == "images.empty"
+	}
+	// A null image is reported by the schema only
+	if !schemaError {
+		t.Errorf("Expected a schema error for the null image, got %v", diags)
+	}
+	want := []string{
+		"10:5 image 'both' sets both 'ami' and an AMI search ('name'): use either 'ami' or 'name' and 'owner'",
+		"12:3 image 'neither' sets neither 'ami' nor 'name' and 'owner': set the AMI ID, or the name and owner to search the AMI by",
+		"2:3 image 'no-name' searches its AMI by 'owner' without 'name': set both 'name' and 'owner'",
+		"15:5 image 'no-owner' searches its AMI by 'name' without 'owner': set both 'name' and 'owner'",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("Expected errors:\n%s\ngot:\n%s", strings.Join(want, "\n"), strings.Join(got, "\n"))
+	}
+}
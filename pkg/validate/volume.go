@@ -0,0 +1,181 @@
+package validate
+
+import (
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/runs-on/config/pkg/config"
+	"gopkg.in/yaml.v3"
+)
+
+// volumeLimits are the AWS limits of an EBS volume type. Zero IOPS and
+// throughput bounds mean the value cannot be set for the type.
+type volumeLimits struct {
+	minSize, maxSize             int
+	minIOPS, maxIOPS             int
+	iopsPerGB                    int
+	minThroughput, maxThroughput int
+	// throughputPerIOPS is the maximum throughput in MB/s per provisioned
+	// iops
+	throughputPerIOPS float64
+}
+
+var volumeTypes = map[string]volumeLimits{
+	"gp3": {
+		minSize: 1, maxSize: 65536,
+		minIOPS: 3000, maxIOPS: 80000, iopsPerGB: 500,
+		minThroughput: 125, maxThroughput: 2000, throughputPerIOPS: 0.25,
+	},
+	"gp2":      {minSize: 1, maxSize: 16384},
+	"io1":      {minSize: 4, maxSize: 16384, minIOPS: 100, maxIOPS: 64000, iopsPerGB: 50},
+	"io2":      {minSize: 4, maxSize: 65536, minIOPS: 100, maxIOPS: 256000, iopsPerGB: 1000},
+	"st1":      {minSize: 125, maxSize: 16384},
+	"sc1":      {minSize: 125, maxSize: 16384},
+	"standard": {minSize: 1, maxSize: 1024},
+}
+
+const (
+	// defaultVolumeType and defaultVolumeIOPS apply when a volume
+	// specification leaves them out (see config.DefaultVolume)
+	defaultVolumeType = "gp3"
+	defaultVolumeIOPS = 3000
+)
+
+// checkVolumes parses runner volume specifications such as
+// "80gb:gp3:125mbs:3000iops" and reports malformed components, unknown
+// volume types and values outside of the AWS limits of the volume type, at
+// the offending component. Components may be given in any order.
+func checkVolumes(yamlData any, root *yaml.Node, sourceName string) []Diagnostic {
+	var diags []Diagnostic
+
+	data, ok := yamlData.(map[string]any)
+	if !ok {
+		return diags
+	}
+
+	for _, runner := range runnerEntries(data, root) {
+		spec, ok := runner.spec["volume"].(string)
+		if !ok || spec == "" {
+			continue
+		}
+		var n *yaml.Node
+		if runner.node != nil {
+			n = fieldNode(runner.node, "volume")
+		}
+		fail := func(component *config.VolumeComponent, key string, params ...string) {
+			diag := Diagnostic{
+				Path:      sourceName,
+				text:      message(key, append([]string{"runner", runner.name}, params...)...),
+				Severity:  SeverityError,
+				RuleID:    RuleVolumeSpec,
+				FieldPath: runner.path + ".volume",
+			}
+			diag.Line, diag.Column = position(n)
+			if start := scalarColumn(n); start > 0 {
+				diag.Column = start + component.Offset
+				diag.EndLine = diag.Line
+				diag.EndColumn = diag.Column + len(component.Text)
+			}
+			diags = append(diags, diag)
+		}
+
+		var components []config.VolumeComponent
+		valid := true
+		// An empty component, e.g. of "80gb::gp3", is reported once
+		reportedEmpty := false
+		for _, component := range config.ParseVolume(spec) {
+			_, knownType := volumeTypes[component.Name]
+			switch {
+			case component.Kind == config.VolumeInvalid && strings.TrimSpace(component.Text) == "":
+				if !reportedEmpty {
+					fail(&component, RuleVolumeSpec, "component", component.Text)
+				}
+				reportedEmpty = true
+				valid = false
+				continue
+			case component.Kind == config.VolumeInvalid:
+				fail(&component, RuleVolumeSpec, "component", component.Text)
+				valid = false
+				continue
+			case component.Kind == config.VolumeType && !knownType:
+				fail(&component, RuleVolumeSpec+".type", "type", component.Text, "types", strings.Join(slices.Sorted(maps.Keys(volumeTypes)), ", "))
+				valid = false
+				continue
+			}
+			if slices.ContainsFunc(components, func(other config.VolumeComponent) bool { return other.Kind == component.Kind }) {
+				fail(&component, RuleVolumeSpec+".duplicate", "component", component.Text)
+				valid = false
+				continue
+			}
+			components = append(components, component)
+		}
+		if !valid {
+			continue
+		}
+
+		byKind := make(map[config.VolumeComponentKind]*config.VolumeComponent)
+		for i, component := range components {
+			byKind[component.Kind] = &components[i]
+		}
+		volumeType := defaultVolumeType
+		if component := byKind[config.VolumeType]; component != nil {
+			volumeType = component.Name
+		}
+		limits := volumeTypes[volumeType]
+
+		if size := byKind[config.VolumeSize]; size != nil && (size.Value < limits.minSize || size.Value > limits.maxSize) {
+			fail(size, RuleVolumeSpec+".size", "type", volumeType, "min", strconv.Itoa(limits.minSize),
+				"max", strconv.Itoa(limits.maxSize), "size", strconv.Itoa(size.Value))
+		}
+		iopsValue := defaultVolumeIOPS
+		if iops := byKind[config.VolumeIOPS]; iops != nil {
+			iopsValue = iops.Value
+			switch {
+			case limits.maxIOPS == 0:
+				fail(iops, RuleVolumeSpec+".unsupported", "type", volumeType, "component", iops.Text)
+			case iops.Value < limits.minIOPS || iops.Value > limits.maxIOPS:
+				fail(iops, RuleVolumeSpec+".iops", "type", volumeType, "min", strconv.Itoa(limits.minIOPS),
+					"max", strconv.Itoa(limits.maxIOPS), "iops", strconv.Itoa(iops.Value))
+			default:
+				if size := byKind[config.VolumeSize]; size != nil && iops.Value > size.Value*limits.iopsPerGB {
+					fail(iops, RuleVolumeSpec+".iops-size", "type", volumeType, "iops", strconv.Itoa(iops.Value),
+						"ratio", strconv.Itoa(limits.iopsPerGB), "size", strconv.Itoa(size.Value), "max", strconv.Itoa(size.Value*limits.iopsPerGB))
+				}
+			}
+		}
+		if throughput := byKind[config.VolumeThroughput]; throughput != nil {
+			switch {
+			case limits.maxThroughput == 0:
+				fail(throughput, RuleVolumeSpec+".unsupported", "type", volumeType, "component", throughput.Text)
+			case throughput.Value < limits.minThroughput || throughput.Value > limits.maxThroughput:
+				fail(throughput, RuleVolumeSpec+".throughput", "type", volumeType, "min", strconv.Itoa(limits.minThroughput),
+					"max", strconv.Itoa(limits.maxThroughput), "throughput", strconv.Itoa(throughput.Value))
+			default:
+				if limit := int(float64(iopsValue) * limits.throughputPerIOPS); throughput.Value > limit {
+					fail(throughput, RuleVolumeSpec+".throughput-iops", "type", volumeType, "throughput", strconv.Itoa(throughput.Value),
+						"iops", strconv.Itoa(iopsValue), "max", strconv.Itoa(limit))
+				}
+			}
+		}
+	}
+
+	return diags
+}
+
+// scalarColumn returns the column of the first character of the value of a
+// single-line scalar, or 0 if the characters of the value cannot be located
+// in the source
+func scalarColumn(n *yaml.Node) int {
+	if n == nil || n.Kind != yaml.ScalarNode {
+		return 0
+	}
+	switch n.Style {
+	case 0:
+		return n.Column
+	case yaml.DoubleQuotedStyle, yaml.SingleQuotedStyle:
+		return n.Column + 1
+	}
+	return 0
+}